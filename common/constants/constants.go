@@ -9,14 +9,17 @@ const (
 	OFFICE_DOC_TYPE    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
 	CORE_PROP_TYPE     = "http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties"
 	EXTENDED_PROP_TYPE = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/extended-properties"
+	CUSTOM_PROP_TYPE   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/custom-properties"
 	StylesType         = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles"
 	HeaderType         = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/header"
 	FooterType         = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer"
+	SettingsType       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings"
 )
 
 var (
-	DrawingMLMainNS = "http://schemas.openxmlformats.org/drawingml/2006/main"
-	DrawingMLPicNS  = "http://schemas.openxmlformats.org/drawingml/2006/picture"
+	DrawingMLMainNS  = "http://schemas.openxmlformats.org/drawingml/2006/main"
+	DrawingMLPicNS   = "http://schemas.openxmlformats.org/drawingml/2006/picture"
+	DrawingMLChartNS = "http://schemas.openxmlformats.org/drawingml/2006/chart"
 
 	NameSpaceDocumentPropertiesVariantTypes = xml.Attr{Name: xml.Name{Local: "vt", Space: "xmlns"}, Value: "http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes"}
 	NameSpaceDrawing2016SVG                 = xml.Attr{Name: xml.Name{Local: "asvg", Space: "xmlns"}, Value: "http://schemas.microsoft.com/office/drawing/2016/SVG/main"}
@@ -78,6 +81,7 @@ const (
 	SourceRelationshipImage            = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
 	SourceRelationshipOfficeDocument   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
 	SourceRelationshipHyperLink        = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+	SourceRelationshipPackage          = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/package"
 )
 
 const (
@@ -156,8 +156,10 @@ func (a Anchor) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	}
 
 	// 5. EffectExtent
-	if err := a.EffectExtent.MarshalXML(e, xml.StartElement{}); err != nil {
-		return fmt.Errorf("EffectExtent: %v", err)
+	if a.EffectExtent != nil {
+		if err := a.EffectExtent.MarshalXML(e, xml.StartElement{}); err != nil {
+			return fmt.Errorf("EffectExtent: %v", err)
+		}
 	}
 	// 6. New:WrapTight
 	//if err := a.WrapTight.MarshalXML(e, xml.StartElement{}); err != nil {
@@ -0,0 +1,44 @@
+package dml
+
+import (
+	"encoding/xml"
+
+	"github.com/iEvan-lhr/docx-agent/common/constants"
+)
+
+// ChartRef refers to a chart part from a graphic, <c:chart r:id="..."/>.
+type ChartRef struct {
+	RID string `xml:"id,attr"`
+}
+
+// NewChartGraphic returns a graphic showing the chart part of the relationship rID.
+func NewChartGraphic(rID string) *Graphic {
+	return &Graphic{
+		Data: &GraphicData{
+			URI:   constants.DrawingMLChartNS,
+			Chart: &ChartRef{RID: rID},
+		},
+	}
+}
+
+func (c ChartRef) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:chart"
+	start.Attr = []xml.Attr{
+		{Name: xml.Name{Local: "xmlns:c"}, Value: constants.DrawingMLChartNS},
+		{Name: xml.Name{Local: "xmlns:r"}, Value: constants.XMLNS_R},
+		{Name: xml.Name{Local: "r:id"}, Value: c.RID},
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+func (c *ChartRef) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "id" {
+			c.RID = attr.Value
+		}
+	}
+	return d.Skip()
+}
@@ -0,0 +1,506 @@
+package dmlchart
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/iEvan-lhr/docx-agent/common/constants"
+)
+
+// Val is an element whose only content is a val attribute, such as <c:idx val="0"/>.
+type Val struct {
+	Val string `xml:"val,attr"`
+}
+
+// NewVal returns a Val holding v.
+func NewVal(v string) *Val {
+	return &Val{Val: v}
+}
+
+// encodeVal writes <name val="..."/> when v is set.
+func encodeVal(e *xml.Encoder, name string, v *Val) error {
+	if v == nil {
+		return nil
+	}
+	start := xml.StartElement{
+		Name: xml.Name{Local: name},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "val"}, Value: v.Val}},
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// encodeEmpty writes the empty element <name/>.
+func encodeEmpty(e *xml.Encoder, name string) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// ChartSpace is the root element of a chart part.
+type ChartSpace struct {
+	Date1904       *Val          `xml:"date1904"`
+	Lang           *Val          `xml:"lang"`
+	RoundedCorners *Val          `xml:"roundedCorners"`
+	Chart          Chart         `xml:"chart"`
+	ExternalData   *ExternalData `xml:"externalData"`
+}
+
+func (cs ChartSpace) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:chartSpace"
+	start.Attr = []xml.Attr{
+		{Name: xml.Name{Local: "xmlns:c"}, Value: constants.DrawingMLChartNS},
+		{Name: xml.Name{Local: "xmlns:a"}, Value: constants.DrawingMLMainNS},
+		{Name: xml.Name{Local: "xmlns:r"}, Value: constants.XMLNS_R},
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:date1904", cs.Date1904); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:lang", cs.Lang); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:roundedCorners", cs.RoundedCorners); err != nil {
+		return err
+	}
+	if err := cs.Chart.MarshalXML(e, xml.StartElement{}); err != nil {
+		return fmt.Errorf("marshalling Chart: %w", err)
+	}
+	if cs.ExternalData != nil {
+		if err := cs.ExternalData.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// Chart holds the title, plot area and legend of a chart.
+type Chart struct {
+	Title            *Title   `xml:"title"`
+	AutoTitleDeleted *Val     `xml:"autoTitleDeleted"`
+	PlotArea         PlotArea `xml:"plotArea"`
+	Legend           *Legend  `xml:"legend"`
+	PlotVisOnly      *Val     `xml:"plotVisOnly"`
+	DispBlanksAs     *Val     `xml:"dispBlanksAs"`
+}
+
+func (c Chart) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:chart"
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if c.Title != nil {
+		if err := c.Title.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "c:title"}}); err != nil {
+			return err
+		}
+	}
+	if err := encodeVal(e, "c:autoTitleDeleted", c.AutoTitleDeleted); err != nil {
+		return err
+	}
+	if err := c.PlotArea.MarshalXML(e, xml.StartElement{}); err != nil {
+		return fmt.Errorf("marshalling PlotArea: %w", err)
+	}
+	if c.Legend != nil {
+		if err := c.Legend.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	if err := encodeVal(e, "c:plotVisOnly", c.PlotVisOnly); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:dispBlanksAs", c.DispBlanksAs); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// PlotArea holds the chart groups and axes of a chart.
+type PlotArea struct {
+	// Groups are the chart types plotted, such as a bar chart combined with a line chart.
+	Groups []*PlotGroup
+	Axes   []*Axis
+}
+
+func (p PlotArea) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:plotArea"
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeEmpty(e, "c:layout"); err != nil {
+		return err
+	}
+	for _, g := range p.Groups {
+		if err := g.MarshalXML(e, xml.StartElement{}); err != nil {
+			return fmt.Errorf("marshalling %s: %w", g.Kind, err)
+		}
+	}
+	for _, ax := range p.Axes {
+		if err := ax.MarshalXML(e, xml.StartElement{}); err != nil {
+			return fmt.Errorf("marshalling %s: %w", ax.Kind, err)
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+func (p *PlotArea) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+loop:
+	for {
+		token, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				break loop
+			}
+			return err
+		}
+		switch elem := token.(type) {
+		case xml.StartElement:
+			switch elem.Name.Local {
+			case "areaChart", "area3DChart", "lineChart", "line3DChart", "stockChart", "radarChart",
+				"scatterChart", "pieChart", "pie3DChart", "doughnutChart", "barChart", "bar3DChart",
+				"ofPieChart", "surfaceChart", "surface3DChart", "bubbleChart":
+				g := &PlotGroup{Kind: elem.Name.Local}
+				if err := d.DecodeElement(g, &elem); err != nil {
+					return err
+				}
+				p.Groups = append(p.Groups, g)
+			case "catAx", "valAx", "dateAx", "serAx":
+				ax := &Axis{Kind: elem.Name.Local}
+				if err := d.DecodeElement(ax, &elem); err != nil {
+					return err
+				}
+				p.Axes = append(p.Axes, ax)
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			if elem.Name == start.Name {
+				break loop
+			}
+		}
+	}
+	return nil
+}
+
+// PlotGroup is one chart type of a plot area, such as <c:barChart>. Only the elements
+// that apply to Kind are written.
+type PlotGroup struct {
+	// Kind is the local name of the element, such as "barChart" or "pieChart".
+	Kind string `xml:"-"`
+
+	BarDir        *Val      `xml:"barDir"`
+	ScatterStyle  *Val      `xml:"scatterStyle"`
+	Grouping      *Val      `xml:"grouping"`
+	VaryColors    *Val      `xml:"varyColors"`
+	Series        []*Series `xml:"ser"`
+	GapWidth      *Val      `xml:"gapWidth"`
+	Overlap       *Val      `xml:"overlap"`
+	Marker        *Val      `xml:"marker"`
+	FirstSliceAng *Val      `xml:"firstSliceAng"`
+	HoleSize      *Val      `xml:"holeSize"`
+	AxIDs         []*Val    `xml:"axId"`
+}
+
+func (g PlotGroup) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:" + g.Kind
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, v := range []struct {
+		name string
+		val  *Val
+	}{
+		{"c:barDir", g.BarDir},
+		{"c:scatterStyle", g.ScatterStyle},
+		{"c:grouping", g.Grouping},
+		{"c:varyColors", g.VaryColors},
+	} {
+		if err := encodeVal(e, v.name, v.val); err != nil {
+			return err
+		}
+	}
+	for _, s := range g.Series {
+		if err := s.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	for _, v := range []struct {
+		name string
+		val  *Val
+	}{
+		{"c:gapWidth", g.GapWidth},
+		{"c:overlap", g.Overlap},
+		{"c:marker", g.Marker},
+		{"c:firstSliceAng", g.FirstSliceAng},
+		{"c:holeSize", g.HoleSize},
+	} {
+		if err := encodeVal(e, v.name, v.val); err != nil {
+			return err
+		}
+	}
+	for _, id := range g.AxIDs {
+		if err := encodeVal(e, "c:axId", id); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// Series is a data series of a chart group. Category charts use Cat and Val; scatter
+// charts use XVal and YVal.
+type Series struct {
+	Idx              *Val             `xml:"idx"`
+	Order            *Val             `xml:"order"`
+	Tx               *SeriesText      `xml:"tx"`
+	SpPr             *ShapeProperties `xml:"spPr"`
+	InvertIfNegative *Val             `xml:"invertIfNegative"`
+	Marker           *Marker          `xml:"marker"`
+	Cat              *AxDataSource    `xml:"cat"`
+	Val              *NumDataSource   `xml:"val"`
+	XVal             *AxDataSource    `xml:"xVal"`
+	YVal             *NumDataSource   `xml:"yVal"`
+	Smooth           *Val             `xml:"smooth"`
+}
+
+func (s Series) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:ser"
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:idx", s.Idx); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:order", s.Order); err != nil {
+		return err
+	}
+	if s.Tx != nil {
+		if err := s.Tx.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	if s.SpPr != nil {
+		if err := s.SpPr.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	if err := encodeVal(e, "c:invertIfNegative", s.InvertIfNegative); err != nil {
+		return err
+	}
+	if s.Marker != nil {
+		if err := s.Marker.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	if s.Cat != nil {
+		if err := s.Cat.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "c:cat"}}); err != nil {
+			return err
+		}
+	}
+	if s.Val != nil {
+		if err := s.Val.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "c:val"}}); err != nil {
+			return err
+		}
+	}
+	if s.XVal != nil {
+		if err := s.XVal.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "c:xVal"}}); err != nil {
+			return err
+		}
+	}
+	if s.YVal != nil {
+		if err := s.YVal.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "c:yVal"}}); err != nil {
+			return err
+		}
+	}
+	if err := encodeVal(e, "c:smooth", s.Smooth); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// Marker is the data point marker of a line or scatter series.
+type Marker struct {
+	Symbol *Val             `xml:"symbol"`
+	Size   *Val             `xml:"size"`
+	SpPr   *ShapeProperties `xml:"spPr"`
+}
+
+func (m Marker) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:marker"
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:symbol", m.Symbol); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:size", m.Size); err != nil {
+		return err
+	}
+	if m.SpPr != nil {
+		if err := m.SpPr.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// Axis is a category, value, date or series axis.
+type Axis struct {
+	// Kind is the local name of the element, such as "catAx" or "valAx".
+	Kind string `xml:"-"`
+
+	AxID           *Val       `xml:"axId"`
+	Scaling        *Scaling   `xml:"scaling"`
+	Delete         *Val       `xml:"delete"`
+	AxPos          *Val       `xml:"axPos"`
+	MajorGridlines *Gridlines `xml:"majorGridlines"`
+	Title          *Title     `xml:"title"`
+	NumFmt         *NumFmt    `xml:"numFmt"`
+	MajorTickMark  *Val       `xml:"majorTickMark"`
+	MinorTickMark  *Val       `xml:"minorTickMark"`
+	TickLblPos     *Val       `xml:"tickLblPos"`
+	CrossAx        *Val       `xml:"crossAx"`
+	Crosses        *Val       `xml:"crosses"`
+	CrossBetween   *Val       `xml:"crossBetween"`
+	Auto           *Val       `xml:"auto"`
+	LblAlgn        *Val       `xml:"lblAlgn"`
+	LblOffset      *Val       `xml:"lblOffset"`
+}
+
+func (a Axis) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:" + a.Kind
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:axId", a.AxID); err != nil {
+		return err
+	}
+	if a.Scaling != nil {
+		if err := a.Scaling.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	if err := encodeVal(e, "c:delete", a.Delete); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:axPos", a.AxPos); err != nil {
+		return err
+	}
+	if a.MajorGridlines != nil {
+		if err := encodeEmpty(e, "c:majorGridlines"); err != nil {
+			return err
+		}
+	}
+	if a.Title != nil {
+		if err := a.Title.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "c:title"}}); err != nil {
+			return err
+		}
+	}
+	if a.NumFmt != nil {
+		if err := a.NumFmt.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	for _, v := range []struct {
+		name string
+		val  *Val
+	}{
+		{"c:majorTickMark", a.MajorTickMark},
+		{"c:minorTickMark", a.MinorTickMark},
+		{"c:tickLblPos", a.TickLblPos},
+		{"c:crossAx", a.CrossAx},
+		{"c:crosses", a.Crosses},
+		{"c:crossBetween", a.CrossBetween},
+		{"c:auto", a.Auto},
+		{"c:lblAlgn", a.LblAlgn},
+		{"c:lblOffset", a.LblOffset},
+	} {
+		if err := encodeVal(e, v.name, v.val); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// Scaling sets the orientation of an axis.
+type Scaling struct {
+	Orientation *Val `xml:"orientation"`
+}
+
+func (s Scaling) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:scaling"
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:orientation", s.Orientation); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// Gridlines turns on the gridlines of an axis.
+type Gridlines struct{}
+
+// NumFmt is the number format of axis labels.
+type NumFmt struct {
+	FormatCode   string `xml:"formatCode,attr"`
+	SourceLinked string `xml:"sourceLinked,attr,omitempty"`
+}
+
+func (n NumFmt) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:numFmt"
+	start.Attr = []xml.Attr{{Name: xml.Name{Local: "formatCode"}, Value: n.FormatCode}}
+	if n.SourceLinked != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "sourceLinked"}, Value: n.SourceLinked})
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// Legend places the legend of a chart.
+type Legend struct {
+	LegendPos *Val `xml:"legendPos"`
+	Overlay   *Val `xml:"overlay"`
+}
+
+func (l Legend) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:legend"
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:legendPos", l.LegendPos); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:overlay", l.Overlay); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// ExternalData refers to the embedded workbook holding the chart data.
+type ExternalData struct {
+	RID        string `xml:"id,attr"`
+	AutoUpdate *Val   `xml:"autoUpdate"`
+}
+
+func (x ExternalData) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:externalData"
+	start.Attr = []xml.Attr{{Name: xml.Name{Local: "r:id"}, Value: x.RID}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeVal(e, "c:autoUpdate", x.AutoUpdate); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
@@ -0,0 +1,469 @@
+package dmlchart
+
+import (
+	"encoding/xml"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/dml"
+)
+
+// Title is the title of a chart or axis. Only plain rich text is modelled.
+type Title struct {
+	Tx      *TitleText `xml:"tx"`
+	Overlay *Val       `xml:"overlay"`
+}
+
+// TitleText is the rich text of a title.
+type TitleText struct {
+	Rich *RichText `xml:"rich"`
+}
+
+// RichText is a DrawingML text body of paragraphs.
+type RichText struct {
+	Paras []TextPara `xml:"p"`
+}
+
+// TextPara is a DrawingML paragraph.
+type TextPara struct {
+	Runs []TextRun `xml:"r"`
+}
+
+// TextRun is a DrawingML text run.
+type TextRun struct {
+	T string `xml:"t"`
+}
+
+// NewTitle returns a title of a single paragraph of text.
+func NewTitle(text string) *Title {
+	return &Title{
+		Tx: &TitleText{Rich: &RichText{
+			Paras: []TextPara{{Runs: []TextRun{{T: text}}}},
+		}},
+		Overlay: NewVal("0"),
+	}
+}
+
+// Text returns the text of the title, paragraphs separated by newlines. It is empty for
+// automatic titles.
+func (t *Title) Text() string {
+	if t == nil || t.Tx == nil || t.Tx.Rich == nil {
+		return ""
+	}
+	paras := make([]string, 0, len(t.Tx.Rich.Paras))
+	for _, p := range t.Tx.Rich.Paras {
+		var sb strings.Builder
+		for _, r := range p.Runs {
+			sb.WriteString(r.T)
+		}
+		paras = append(paras, sb.String())
+	}
+	return strings.Join(paras, "\n")
+}
+
+func (t Title) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:title"
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if t.Tx != nil && t.Tx.Rich != nil {
+		for _, name := range []string{"c:tx", "c:rich"} {
+			if err := e.EncodeToken(xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+				return err
+			}
+		}
+		if err := encodeEmpty(e, "a:bodyPr"); err != nil {
+			return err
+		}
+		if err := encodeEmpty(e, "a:lstStyle"); err != nil {
+			return err
+		}
+		for _, p := range t.Tx.Rich.Paras {
+			if err := e.EncodeToken(xml.StartElement{Name: xml.Name{Local: "a:p"}}); err != nil {
+				return err
+			}
+			for _, r := range p.Runs {
+				if err := e.EncodeToken(xml.StartElement{Name: xml.Name{Local: "a:r"}}); err != nil {
+					return err
+				}
+				if err := e.EncodeElement(r.T, xml.StartElement{Name: xml.Name{Local: "a:t"}}); err != nil {
+					return err
+				}
+				if err := e.EncodeToken(xml.EndElement{Name: xml.Name{Local: "a:r"}}); err != nil {
+					return err
+				}
+			}
+			if err := e.EncodeToken(xml.EndElement{Name: xml.Name{Local: "a:p"}}); err != nil {
+				return err
+			}
+		}
+		for _, name := range []string{"c:rich", "c:tx"} {
+			if err := e.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+				return err
+			}
+		}
+	}
+	if err := encodeVal(e, "c:overlay", t.Overlay); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// SeriesText is the name of a series, either a reference to a worksheet cell or a literal.
+type SeriesText struct {
+	StrRef *StrRef `xml:"strRef"`
+	V      string  `xml:"v"`
+}
+
+// Text returns the name of the series.
+func (t *SeriesText) Text() string {
+	if t == nil {
+		return ""
+	}
+	if t.StrRef != nil && t.StrRef.StrCache != nil {
+		if values := t.StrRef.StrCache.Values(); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return t.V
+}
+
+func (t SeriesText) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:tx"
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if t.StrRef != nil {
+		if err := t.StrRef.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	} else if err := e.EncodeElement(t.V, xml.StartElement{Name: xml.Name{Local: "c:v"}}); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// AxDataSource is the category or x data of a series.
+type AxDataSource struct {
+	StrRef *StrRef  `xml:"strRef"`
+	NumRef *NumRef  `xml:"numRef"`
+	StrLit *StrData `xml:"strLit"`
+	NumLit *NumData `xml:"numLit"`
+}
+
+// Strings returns the cached values of the data source as text.
+func (a *AxDataSource) Strings() []string {
+	switch {
+	case a == nil:
+		return nil
+	case a.StrRef != nil && a.StrRef.StrCache != nil:
+		return a.StrRef.StrCache.Values()
+	case a.StrLit != nil:
+		return a.StrLit.Values()
+	case a.NumRef != nil && a.NumRef.NumCache != nil:
+		return a.NumRef.NumCache.Strings()
+	case a.NumLit != nil:
+		return a.NumLit.Strings()
+	}
+	return nil
+}
+
+// Numbers returns the cached values of a numeric data source; text values are NaN.
+func (a *AxDataSource) Numbers() []float64 {
+	switch {
+	case a == nil:
+		return nil
+	case a.NumRef != nil && a.NumRef.NumCache != nil:
+		return a.NumRef.NumCache.Values()
+	case a.NumLit != nil:
+		return a.NumLit.Values()
+	}
+	values := a.Strings()
+	numbers := make([]float64, len(values))
+	for i, v := range values {
+		numbers[i] = parseNumber(v)
+	}
+	return numbers
+}
+
+// MarshalXML writes the data source under the name of start, <c:cat> or <c:xVal>.
+func (a AxDataSource) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	var err error
+	switch {
+	case a.StrRef != nil:
+		err = a.StrRef.MarshalXML(e, xml.StartElement{})
+	case a.NumRef != nil:
+		err = a.NumRef.MarshalXML(e, xml.StartElement{})
+	case a.StrLit != nil:
+		err = a.StrLit.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "c:strLit"}})
+	case a.NumLit != nil:
+		err = a.NumLit.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "c:numLit"}})
+	}
+	if err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// NumDataSource is the numeric data of a series.
+type NumDataSource struct {
+	NumRef *NumRef  `xml:"numRef"`
+	NumLit *NumData `xml:"numLit"`
+}
+
+// Numbers returns the cached values of the data source; missing points are NaN.
+func (n *NumDataSource) Numbers() []float64 {
+	switch {
+	case n == nil:
+		return nil
+	case n.NumRef != nil && n.NumRef.NumCache != nil:
+		return n.NumRef.NumCache.Values()
+	case n.NumLit != nil:
+		return n.NumLit.Values()
+	}
+	return nil
+}
+
+// MarshalXML writes the data source under the name of start, <c:val> or <c:yVal>.
+func (n NumDataSource) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	var err error
+	switch {
+	case n.NumRef != nil:
+		err = n.NumRef.MarshalXML(e, xml.StartElement{})
+	case n.NumLit != nil:
+		err = n.NumLit.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "c:numLit"}})
+	}
+	if err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// StrRef is a reference to worksheet cells holding text, with their cached values.
+type StrRef struct {
+	F        string   `xml:"f"`
+	StrCache *StrData `xml:"strCache"`
+}
+
+func (r StrRef) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:strRef"
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.EncodeElement(r.F, xml.StartElement{Name: xml.Name{Local: "c:f"}}); err != nil {
+		return err
+	}
+	if r.StrCache != nil {
+		if err := r.StrCache.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "c:strCache"}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// NumRef is a reference to worksheet cells holding numbers, with their cached values.
+type NumRef struct {
+	F        string   `xml:"f"`
+	NumCache *NumData `xml:"numCache"`
+}
+
+func (r NumRef) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:numRef"
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.EncodeElement(r.F, xml.StartElement{Name: xml.Name{Local: "c:f"}}); err != nil {
+		return err
+	}
+	if r.NumCache != nil {
+		if err := r.NumCache.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "c:numCache"}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// Point is a cached or literal data point.
+type Point struct {
+	Idx int    `xml:"idx,attr"`
+	V   string `xml:"v"`
+}
+
+// encodePoints writes the point count and the points.
+func encodePoints(e *xml.Encoder, count *Val, pts []Point) error {
+	if err := encodeVal(e, "c:ptCount", count); err != nil {
+		return err
+	}
+	for _, pt := range pts {
+		start := xml.StartElement{
+			Name: xml.Name{Local: "c:pt"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "idx"}, Value: strconv.Itoa(pt.Idx)}},
+		}
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		if err := e.EncodeElement(pt.V, xml.StartElement{Name: xml.Name{Local: "c:v"}}); err != nil {
+			return err
+		}
+		if err := e.EncodeToken(xml.EndElement{Name: start.Name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxPoints bounds the number of points read from a chart part, as many as the rows of
+// a worksheet.
+const maxPoints = 1 << 20
+
+// pointCount returns the number of points given by count, or implied by the points. Counts
+// and indexes out of the range [0, maxPoints) in corrupt parts are ignored.
+func pointCount(count *Val, pts []Point) int {
+	n := 0
+	if count != nil {
+		n, _ = strconv.Atoi(count.Val)
+		n = min(max(n, 0), maxPoints)
+	}
+	for _, pt := range pts {
+		if pt.Idx >= n && pt.Idx < maxPoints {
+			n = pt.Idx + 1
+		}
+	}
+	return n
+}
+
+// StrData is a list of text points.
+type StrData struct {
+	PtCount *Val    `xml:"ptCount"`
+	Pts     []Point `xml:"pt"`
+}
+
+// NewStrData returns the points of values.
+func NewStrData(values []string) *StrData {
+	data := &StrData{PtCount: NewVal(strconv.Itoa(len(values)))}
+	for i, v := range values {
+		data.Pts = append(data.Pts, Point{Idx: i, V: v})
+	}
+	return data
+}
+
+// Values returns the points by index; missing points are empty.
+func (s *StrData) Values() []string {
+	values := make([]string, pointCount(s.PtCount, s.Pts))
+	for _, pt := range s.Pts {
+		if pt.Idx >= 0 && pt.Idx < len(values) {
+			values[pt.Idx] = pt.V
+		}
+	}
+	return values
+}
+
+// MarshalXML writes the points under the name of start, such as <c:strCache>.
+func (s StrData) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodePoints(e, s.PtCount, s.Pts); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// NumData is a list of numeric points.
+type NumData struct {
+	FormatCode string  `xml:"formatCode"`
+	PtCount    *Val    `xml:"ptCount"`
+	Pts        []Point `xml:"pt"`
+}
+
+// NewNumData returns the points of values; NaN values are left out as missing points.
+func NewNumData(values []float64) *NumData {
+	data := &NumData{FormatCode: "General", PtCount: NewVal(strconv.Itoa(len(values)))}
+	for i, v := range values {
+		if !math.IsNaN(v) {
+			data.Pts = append(data.Pts, Point{Idx: i, V: strconv.FormatFloat(v, 'g', -1, 64)})
+		}
+	}
+	return data
+}
+
+// Values returns the points by index; missing points are NaN.
+func (n *NumData) Values() []float64 {
+	values := make([]float64, pointCount(n.PtCount, n.Pts))
+	for i := range values {
+		values[i] = math.NaN()
+	}
+	for _, pt := range n.Pts {
+		if pt.Idx >= 0 && pt.Idx < len(values) {
+			values[pt.Idx] = parseNumber(pt.V)
+		}
+	}
+	return values
+}
+
+// Strings returns the points by index as written; missing points are empty.
+func (n *NumData) Strings() []string {
+	values := make([]string, pointCount(n.PtCount, n.Pts))
+	for _, pt := range n.Pts {
+		if pt.Idx >= 0 && pt.Idx < len(values) {
+			values[pt.Idx] = pt.V
+		}
+	}
+	return values
+}
+
+// MarshalXML writes the points under the name of start, such as <c:numCache>.
+func (n NumData) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if n.FormatCode != "" {
+		if err := e.EncodeElement(n.FormatCode, xml.StartElement{Name: xml.Name{Local: "c:formatCode"}}); err != nil {
+			return err
+		}
+	}
+	if err := encodePoints(e, n.PtCount, n.Pts); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// parseNumber parses a point value, returning NaN for text.
+func parseNumber(v string) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return f
+}
+
+// ShapeProperties is the fill and outline of a series.
+type ShapeProperties struct {
+	SolidFill *dml.SolidFill      `xml:"solidFill"`
+	Ln        *dml.LineProperties `xml:"ln"`
+}
+
+func (p ShapeProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "c:spPr"
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if p.SolidFill != nil {
+		if err := p.SolidFill.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	if p.Ln != nil {
+		if err := p.Ln.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
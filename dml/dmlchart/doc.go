@@ -0,0 +1,3 @@
+// Package dmlchart provides the DrawingML chart part (<c:chartSpace>) of the Office Open
+// XML (OOXML) standard, as used by charts embedded in WordprocessingML documents.
+package dmlchart
@@ -55,11 +55,33 @@ func (u *A14UseLocalDpi) UnmarshalXML(d *xml.Decoder, start xml.StartElement) er
 	}
 }
 
+// SVGBlipExtURI identifies the blip extension holding the SVG version of a picture.
+const SVGBlipExtURI = "{96DAC541-7B7A-43D3-8B79-37D633B846F1}"
+
+// SVGBlip refers to the SVG image of a picture whose blip embeds a raster fallback.
+// Corresponds to <asvg:svgBlip r:embed="...">.
+type SVGBlip struct {
+	EmbedID string `xml:"embed,attr,omitempty"`
+}
+
+func (s SVGBlip) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "asvg:svgBlip"
+	start.Attr = []xml.Attr{
+		{Name: xml.Name{Local: "xmlns:asvg"}, Value: constants.NameSpaceDrawing2016SVG.Value},
+		{Name: xml.Name{Local: "r:embed"}, Value: s.EmbedID},
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
 // --- 新增结构体：AExt ---
 // 对应 <a:ext ...>
 type Ext struct {
 	URI         string          `xml:"uri,omitempty"`
 	UseLocalDpi *A14UseLocalDpi `xml:"a14:useLocalDpi,omitempty"`
+	SVGBlip     *SVGBlip        `xml:"asvg:svgBlip,omitempty"`
 }
 
 // MarshalXML 实现了 <a:ext> 的手动序列化
@@ -78,6 +100,11 @@ func (a Ext) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 			return err
 		}
 	}
+	if a.SVGBlip != nil {
+		if err := a.SVGBlip.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
 
 	return e.EncodeToken(xml.EndElement{Name: start.Name})
 }
@@ -109,6 +136,11 @@ loop:
 				if err := a.UseLocalDpi.UnmarshalXML(d, elem); err != nil {
 					return err
 				}
+			} else if elem.Name.Local == "svgBlip" && elem.Name.Space == constants.NameSpaceDrawing2016SVG.Value {
+				a.SVGBlip = new(SVGBlip)
+				if err := d.DecodeElement(a.SVGBlip, &elem); err != nil {
+					return err
+				}
 			} else {
 				// 跳过其他不认识的子元素
 				if err := d.Skip(); err != nil {
@@ -67,8 +67,10 @@ func (b BlipFill) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	}
 
 	// 3. Choice: FillModProperties
-	if err = b.Stretch.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "a:stretch"}}); err != nil {
-		return err
+	if b.Stretch != nil {
+		if err = b.Stretch.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "a:stretch"}}); err != nil {
+			return err
+		}
 	}
 
 	// 3. Choice: FillModProperties
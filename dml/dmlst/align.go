@@ -0,0 +1,63 @@
+package dmlst
+
+import (
+	"errors"
+)
+
+// AlignH is the horizontal alignment of a floating object relative to its positionH base.
+type AlignH string
+
+const (
+	AlignHLeft    AlignH = "left"
+	AlignHRight   AlignH = "right"
+	AlignHCenter  AlignH = "center"
+	AlignHInside  AlignH = "inside"
+	AlignHOutside AlignH = "outside"
+)
+
+// AlignHFromStr converts a string to AlignH type.
+func AlignHFromStr(value string) (AlignH, error) {
+	switch value {
+	case "left":
+		return AlignHLeft, nil
+	case "right":
+		return AlignHRight, nil
+	case "center":
+		return AlignHCenter, nil
+	case "inside":
+		return AlignHInside, nil
+	case "outside":
+		return AlignHOutside, nil
+	default:
+		return "", errors.New("Invalid AlignH value")
+	}
+}
+
+// AlignV is the vertical alignment of a floating object relative to its positionV base.
+type AlignV string
+
+const (
+	AlignVTop     AlignV = "top"
+	AlignVBottom  AlignV = "bottom"
+	AlignVCenter  AlignV = "center"
+	AlignVInside  AlignV = "inside"
+	AlignVOutside AlignV = "outside"
+)
+
+// AlignVFromStr converts a string to AlignV type.
+func AlignVFromStr(value string) (AlignV, error) {
+	switch value {
+	case "top":
+		return AlignVTop, nil
+	case "bottom":
+		return AlignVBottom, nil
+	case "center":
+		return AlignVCenter, nil
+	case "inside":
+		return AlignVInside, nil
+	case "outside":
+		return AlignVOutside, nil
+	default:
+		return "", errors.New("Invalid AlignV value")
+	}
+}
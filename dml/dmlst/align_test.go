@@ -0,0 +1,27 @@
+package dmlst
+
+import (
+	"testing"
+)
+
+func TestAlignFromStr(t *testing.T) {
+	for _, v := range []string{"left", "right", "center", "inside", "outside"} {
+		result, err := AlignHFromStr(v)
+		if err != nil || string(result) != v {
+			t.Errorf("AlignHFromStr(%q) = %q, %v", v, result, err)
+		}
+	}
+	for _, v := range []string{"top", "bottom", "center", "inside", "outside"} {
+		result, err := AlignVFromStr(v)
+		if err != nil || string(result) != v {
+			t.Errorf("AlignVFromStr(%q) = %q, %v", v, result, err)
+		}
+	}
+
+	if _, err := AlignHFromStr("top"); err == nil {
+		t.Error("Expected error for invalid AlignH value")
+	}
+	if _, err := AlignVFromStr("left"); err == nil {
+		t.Error("Expected error for invalid AlignV value")
+	}
+}
@@ -9,6 +9,7 @@ type DocProp struct {
 	ID          uint64 `xml:"id,attr,omitempty"`
 	Name        string `xml:"name,attr,omitempty"`
 	Description string `xml:"descr,attr,omitempty"`
+	Title       string `xml:"title,attr,omitempty"`
 
 	//TODO: Remaining attrs & child elements
 }
@@ -24,6 +25,10 @@ func (d DocProp) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "descr"}, Value: d.Description})
 	}
 
+	if d.Title != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "title"}, Value: d.Title})
+	}
+
 	err := e.EncodeToken(start)
 	if err != nil {
 		return err
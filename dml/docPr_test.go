@@ -26,6 +26,15 @@ func TestMarshalDocProp(t *testing.T) {
 			},
 			expectedXML: `<wp:docPr id="2" name="Document2"></wp:docPr>`,
 		},
+		{
+			docProp: &DocProp{
+				ID:          3,
+				Name:        "Picture 3",
+				Description: "A gopher",
+				Title:       "Mascot",
+			},
+			expectedXML: `<wp:docPr id="3" name="Picture 3" descr="A gopher" title="Mascot"></wp:docPr>`,
+		},
 	}
 
 	for _, tt := range tests {
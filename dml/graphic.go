@@ -21,9 +21,11 @@ func DefaultGraphic() *Graphic {
 }
 
 type GraphicData struct {
-	URI      string      `xml:"uri,attr,omitempty"`
-	WPGGroup *WPGGroup   `xml:"wgp,omitempty"`
-	Pic      *dmlpic.Pic `xml:"pic,omitempty"`
+	URI      string                  `xml:"uri,attr,omitempty"`
+	WPGGroup *WPGGroup               `xml:"wgp,omitempty"`
+	Pic      *dmlpic.Pic             `xml:"pic,omitempty"`
+	Wsp      *WPSWordprocessingShape `xml:"wsp,omitempty"`
+	Chart    *ChartRef               `xml:"chart,omitempty"`
 }
 
 func NewPicGraphic(pic *dmlpic.Pic) *Graphic {
@@ -55,10 +57,42 @@ func (g Graphic) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	return e.EncodeToken(xml.EndElement{Name: start.Name})
 }
 
+// NewShapeGraphic returns a graphic holding a WordprocessingML shape.
+func NewShapeGraphic(wsp *WPSWordprocessingShape) *Graphic {
+	return &Graphic{
+		Data: &GraphicData{
+			URI: constants.WPSNamespace,
+			Wsp: wsp,
+		},
+	}
+}
+
+// NewGroupGraphic returns a graphic holding a WordprocessingML group.
+func NewGroupGraphic(group *WPGGroup) *Graphic {
+	return &Graphic{
+		Data: &GraphicData{
+			URI:      constants.WPGNamespace,
+			WPGGroup: group,
+		},
+	}
+}
+
 func (gd GraphicData) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	start.Name.Local = "a:graphicData"
+
+	uri := gd.URI
+	switch {
+	case gd.Wsp != nil:
+		uri = constants.WPSNamespace
+	case gd.WPGGroup != nil:
+		uri = constants.WPGNamespace
+	case gd.Chart != nil:
+		uri = constants.DrawingMLChartNS
+	case uri == "":
+		uri = constants.DrawingMLPicNS
+	}
 	start.Attr = []xml.Attr{
-		{Name: xml.Name{Local: "uri"}, Value: constants.DrawingMLPicNS},
+		{Name: xml.Name{Local: "uri"}, Value: uri},
 	}
 
 	err := e.EncodeToken(start)
@@ -76,6 +110,16 @@ func (gd GraphicData) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 			return err
 		}
 	}
+	if gd.Wsp != nil {
+		if err := gd.Wsp.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	if gd.Chart != nil {
+		if err := gd.Chart.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
 
 	return e.EncodeToken(xml.EndElement{Name: start.Name})
 }
@@ -143,6 +187,16 @@ loop:
 				if err = d.DecodeElement(gd.WPGGroup, &elem); err != nil {
 					return err
 				}
+			case xml.Name{Space: constants.DrawingMLChartNS, Local: "chart"}:
+				gd.Chart = new(ChartRef)
+				if err = gd.Chart.UnmarshalXML(d, elem); err != nil {
+					return err
+				}
+			case xml.Name{Space: constants.WPSNamespace, Local: "wsp"}:
+				gd.Wsp = new(WPSWordprocessingShape)
+				if err = gd.Wsp.UnmarshalXML(d, elem); err != nil {
+					return err
+				}
 			default:
 				if err = d.Skip(); err != nil {
 					return err
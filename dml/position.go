@@ -12,12 +12,18 @@ import (
 
 type PoistionH struct {
 	RelativeFrom dmlst.RelFromH `xml:"relativeFrom,attr"`
-	PosOffset    int            `xml:"posOffset"`
+
+	// Align, when set, positions the object by alignment and PosOffset is ignored.
+	Align     *dmlst.AlignH `xml:"align,omitempty"`
+	PosOffset int           `xml:"posOffset"`
 }
 
 type PoistionV struct {
 	RelativeFrom dmlst.RelFromV `xml:"relativeFrom,attr"`
-	PosOffset    int            `xml:"posOffset"`
+
+	// Align, when set, positions the object by alignment and PosOffset is ignored.
+	Align     *dmlst.AlignV `xml:"align,omitempty"`
+	PosOffset int           `xml:"posOffset"`
 }
 
 func getInt(val string) (int, error) {
@@ -52,6 +58,16 @@ loop:
 				if err = d.DecodeElement(&p.PosOffset, &elem); err != nil {
 					return err
 				}
+			case xml.Name{Space: constants.WMLDrawingNS, Local: "align"}:
+				var value string
+				if err = d.DecodeElement(&value, &elem); err != nil {
+					return err
+				}
+				align, err := dmlst.AlignHFromStr(value)
+				if err != nil {
+					return err
+				}
+				p.Align = &align
 			default:
 				if err = d.Skip(); err != nil {
 					return err
@@ -90,6 +106,16 @@ loop:
 				if err = d.DecodeElement(&p.PosOffset, &elem); err != nil {
 					return err
 				}
+			case xml.Name{Space: constants.WMLDrawingNS, Local: "align"}:
+				var value string
+				if err = d.DecodeElement(&value, &elem); err != nil {
+					return err
+				}
+				align, err := dmlst.AlignVFromStr(value)
+				if err != nil {
+					return err
+				}
+				p.Align = &align
 			default:
 				if err = d.Skip(); err != nil {
 					return err
@@ -118,9 +144,16 @@ func (p PoistionH) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		return err
 	}
 
-	offsetElem := xml.StartElement{Name: xml.Name{Local: "wp:posOffset"}}
-	if err = e.EncodeElement(p.PosOffset, offsetElem); err != nil {
-		return err
+	if p.Align != nil {
+		alignElem := xml.StartElement{Name: xml.Name{Local: "wp:align"}}
+		if err = e.EncodeElement(string(*p.Align), alignElem); err != nil {
+			return err
+		}
+	} else {
+		offsetElem := xml.StartElement{Name: xml.Name{Local: "wp:posOffset"}}
+		if err = e.EncodeElement(p.PosOffset, offsetElem); err != nil {
+			return err
+		}
 	}
 
 	return e.EncodeToken(xml.EndElement{Name: start.Name})
@@ -140,9 +173,16 @@ func (p PoistionV) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		return err
 	}
 
-	offsetElem := xml.StartElement{Name: xml.Name{Local: "wp:posOffset"}}
-	if err = e.EncodeElement(p.PosOffset, offsetElem); err != nil {
-		return err
+	if p.Align != nil {
+		alignElem := xml.StartElement{Name: xml.Name{Local: "wp:align"}}
+		if err = e.EncodeElement(string(*p.Align), alignElem); err != nil {
+			return err
+		}
+	} else {
+		offsetElem := xml.StartElement{Name: xml.Name{Local: "wp:posOffset"}}
+		if err = e.EncodeElement(p.PosOffset, offsetElem); err != nil {
+			return err
+		}
 	}
 
 	return e.EncodeToken(xml.EndElement{Name: start.Name})
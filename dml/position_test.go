@@ -4,6 +4,8 @@ import (
 	"encoding/xml"
 	"testing"
 
+	"github.com/iEvan-lhr/docx-agent/internal"
+
 	"github.com/iEvan-lhr/docx-agent/dml/dmlst"
 )
 
@@ -19,6 +21,13 @@ func TestMarshalPoistionH(t *testing.T) {
 			},
 			expectedXML: `<wp:positionH relativeFrom="margin"><wp:posOffset>100</wp:posOffset></wp:positionH>`,
 		},
+		{
+			positionH: &PoistionH{
+				RelativeFrom: dmlst.RelFromHPage,
+				Align:        internal.ToPtr(dmlst.AlignHCenter),
+			},
+			expectedXML: `<wp:positionH relativeFrom="page"><wp:align>center</wp:align></wp:positionH>`,
+		},
 	}
 
 	for _, tt := range tests {
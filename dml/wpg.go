@@ -17,17 +17,25 @@ import (
 type WPGGroup struct {
 	CNvGrpSpPr *WPGNonVisualGroupShapeProps `xml:"cnvGrpSpPr,omitempty"`
 	GrpSpPr    *WPGGroupShapeProperties     `xml:"grpSpPr,omitempty"`
-	Wsp        *WPSWordprocessingShape      `xml:"wsp,omitempty"`
-	Pic        *dmlpic.Pic                  `xml:"pic,omitempty"` // 复用 dmlpic 中的 Pic 结构
+
+	// Children are the shapes, pictures and nested groups of the group in drawing order.
+	Children []WPGGroupChild
+}
+
+// WPGGroupChild is a member of a group; exactly one of its fields is set.
+type WPGGroupChild struct {
+	Wsp   *WPSWordprocessingShape // <wps:wsp>
+	Pic   *dmlpic.Pic             // <pic:pic>
+	Group *WPGGroup               // <wpg:grpSp>
 }
 
+// MarshalXML writes the group as <wpg:wgp>, or under the name of start when it is set,
+// as for nested <wpg:grpSp> groups.
 func (g *WPGGroup) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
-	start.Name.Local = "wpg:wgp"
-	// 确保定义 wpg 命名空间 (通常在父元素 a:graphicData 中定义，这里可能不需要重复)
-	// start.Attr = append(start.Attr, xml.Attr{
-	// 	Name:  xml.Name{Local: "xmlns:wpg"},
-	// 	Value: constants.WPGNamespace, // 假设你有这个常量
-	// })
+	if start.Name.Local == "" {
+		start.Name.Local = "wpg:wgp"
+	}
+	start.Attr = nil
 
 	if err := e.EncodeToken(start); err != nil {
 		return err
@@ -43,15 +51,20 @@ func (g *WPGGroup) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 			return fmt.Errorf("marshalling GrpSpPr: %w", err)
 		}
 	}
-	if g.Wsp != nil {
-		if err := g.Wsp.MarshalXML(e, xml.StartElement{}); err != nil {
-			return fmt.Errorf("marshalling Wsp: %w", err)
-		}
-	}
-	if g.Pic != nil {
-		// Pic 可能也有自己的 MarshalXML
-		if err := g.Pic.MarshalXML(e, xml.StartElement{}); err != nil {
-			return fmt.Errorf("marshalling Pic: %w", err)
+	for _, child := range g.Children {
+		switch {
+		case child.Wsp != nil:
+			if err := child.Wsp.MarshalXML(e, xml.StartElement{}); err != nil {
+				return fmt.Errorf("marshalling Wsp: %w", err)
+			}
+		case child.Pic != nil:
+			if err := child.Pic.MarshalXML(e, xml.StartElement{}); err != nil {
+				return fmt.Errorf("marshalling Pic: %w", err)
+			}
+		case child.Group != nil:
+			if err := child.Group.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "wpg:grpSp"}}); err != nil {
+				return fmt.Errorf("marshalling GrpSp: %w", err)
+			}
 		}
 	}
 
@@ -71,33 +84,38 @@ loop:
 
 		switch elem := token.(type) {
 		case xml.StartElement:
-			// 注意：Go 的 XML 解析器会自动处理命名空间 URI
-			// "http://schemas.microsoft.com/office/word/2010/wordprocessingGroup"
-			wpgNS := constants.WPGNamespace // 假设你有这个常量
-			wpsNS := constants.WPSNamespace // 假设你有这个常量
-			if elem.Name.Local == "cNvGrpSpPr" && elem.Name.Space == wpgNS {
+			wpgNS := constants.WPGNamespace
+			wpsNS := constants.WPSNamespace
+			switch {
+			case elem.Name.Local == "cNvGrpSpPr" && elem.Name.Space == wpgNS:
 				g.CNvGrpSpPr = new(WPGNonVisualGroupShapeProps)
 				if err := g.CNvGrpSpPr.UnmarshalXML(d, elem); err != nil {
 					return err
 				}
-			} else if elem.Name.Local == "grpSpPr" && elem.Name.Space == wpgNS {
+			case elem.Name.Local == "grpSpPr" && elem.Name.Space == wpgNS:
 				g.GrpSpPr = new(WPGGroupShapeProperties)
 				if err := g.GrpSpPr.UnmarshalXML(d, elem); err != nil {
 					return err
 				}
-			} else if elem.Name.Local == "wsp" && elem.Name.Space == wpsNS {
-				g.Wsp = new(WPSWordprocessingShape)
-				if err := g.Wsp.UnmarshalXML(d, elem); err != nil {
-
+			case elem.Name.Local == "wsp" && elem.Name.Space == wpsNS:
+				wsp := new(WPSWordprocessingShape)
+				if err := wsp.UnmarshalXML(d, elem); err != nil {
 					return err
 				}
-			} else if elem.Name.Local == "pic" && elem.Name.Space == constants.DrawingMLPicNS { //
-				g.Pic = new(dmlpic.Pic)
-				// 假设 Pic 也有 UnmarshalXML
-				if err := g.Pic.UnmarshalXML(d, elem); err != nil {
+				g.Children = append(g.Children, WPGGroupChild{Wsp: wsp})
+			case elem.Name.Local == "pic" && elem.Name.Space == constants.DrawingMLPicNS:
+				pic := new(dmlpic.Pic)
+				if err := pic.UnmarshalXML(d, elem); err != nil {
 					return err
 				}
-			} else {
+				g.Children = append(g.Children, WPGGroupChild{Pic: pic})
+			case elem.Name.Local == "grpSp" && elem.Name.Space == wpgNS:
+				group := new(WPGGroup)
+				if err := group.UnmarshalXML(d, elem); err != nil {
+					return err
+				}
+				g.Children = append(g.Children, WPGGroupChild{Group: group})
+			default:
 				if err := d.Skip(); err != nil {
 					return err
 				}
@@ -111,6 +129,34 @@ loop:
 	return nil
 }
 
+// Shapes returns the shapes of the group and of its nested groups in drawing order.
+func (g *WPGGroup) Shapes() []*WPSWordprocessingShape {
+	var shapes []*WPSWordprocessingShape
+	for _, child := range g.Children {
+		switch {
+		case child.Wsp != nil:
+			shapes = append(shapes, child.Wsp)
+		case child.Group != nil:
+			shapes = append(shapes, child.Group.Shapes()...)
+		}
+	}
+	return shapes
+}
+
+// Pictures returns the pictures of the group and of its nested groups in drawing order.
+func (g *WPGGroup) Pictures() []*dmlpic.Pic {
+	var pics []*dmlpic.Pic
+	for _, child := range g.Children {
+		switch {
+		case child.Pic != nil:
+			pics = append(pics, child.Pic)
+		case child.Group != nil:
+			pics = append(pics, child.Group.Pictures()...)
+		}
+	}
+	return pics
+}
+
 // ==========================================================
 // <wpg:cNvGrpSpPr> (Non-Visual Properties for Group Shape)
 // ==========================================================
@@ -244,11 +290,13 @@ type WPSWordprocessingShape struct {
 	CNvPr   *dmlct.CNvPr
 	CNvSpPr *WPSNonVisualShapeDrawingProps
 	SpPr    *WPSShapeProperties
+	TextBox *WPSTextBox
 	BodyPr  *ABodyProperties
 }
 
 func (s *WPSWordprocessingShape) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	start.Name.Local = "wps:wsp"
+	start.Attr = nil
 	if err := e.EncodeToken(start); err != nil {
 		return err
 	}
@@ -260,23 +308,22 @@ func (s *WPSWordprocessingShape) MarshalXML(e *xml.Encoder, start xml.StartEleme
 		}
 	}
 	if s.CNvSpPr != nil {
-		if err := s.CNvSpPr.MarshalXML(e, xml.StartElement{
-			Name: xml.Name{Local: "wps:cNvSpPr"},
-		}); err != nil {
+		if err := s.CNvSpPr.MarshalXML(e, xml.StartElement{}); err != nil {
 			return err
 		}
 	}
 	if s.SpPr != nil {
-		if err := s.SpPr.MarshalXML(e, xml.StartElement{
-			Name: xml.Name{Local: "wps:cSpPr"},
-		}); err != nil {
+		if err := s.SpPr.MarshalXML(e, xml.StartElement{}); err != nil {
 			return err
 		}
 	}
+	if s.TextBox != nil {
+		if err := s.TextBox.MarshalXML(e, xml.StartElement{}); err != nil {
+			return fmt.Errorf("marshalling TextBox: %w", err)
+		}
+	}
 	if s.BodyPr != nil {
-		if err := s.BodyPr.MarshalXML(e, xml.StartElement{
-			Name: xml.Name{Local: "wps:cBodyPr"},
-		}); err != nil {
+		if err := s.BodyPr.MarshalXML(e, xml.StartElement{}); err != nil {
 			return err
 		}
 	}
@@ -294,7 +341,7 @@ loop:
 		}
 		switch elem := token.(type) {
 		case xml.StartElement:
-			ns := constants.WPSNamespace // 假设有
+			ns := constants.WPSNamespace
 			if elem.Name.Local == "cNvPr" && elem.Name.Space == ns {
 				s.CNvPr = new(dmlct.CNvPr)
 				if err := s.CNvPr.UnmarshalXML(d, elem); err != nil {
@@ -310,6 +357,11 @@ loop:
 				if err := s.SpPr.UnmarshalXML(d, elem); err != nil {
 					return err
 				}
+			} else if elem.Name.Local == "txbx" && elem.Name.Space == ns {
+				s.TextBox = new(WPSTextBox)
+				if err := s.TextBox.UnmarshalXML(d, elem); err != nil {
+					return err
+				}
 			} else if elem.Name.Local == "bodyPr" && elem.Name.Space == ns {
 				s.BodyPr = new(ABodyProperties)
 				if err := s.BodyPr.UnmarshalXML(d, elem); err != nil {
@@ -329,6 +381,76 @@ loop:
 	return nil
 }
 
+// ==========================================================
+// <wps:txbx> (Text Box)
+// ==========================================================
+
+// TextBoxContent is the block-level content of a text box, <w:txbxContent>. It is
+// WordprocessingML rather than DrawingML, so it is modelled by the wml packages, which
+// register it with RegisterTextBoxContent.
+type TextBoxContent interface {
+	xml.Marshaler
+	xml.Unmarshaler
+}
+
+// newTextBoxContent creates the content decoded for <w:txbxContent>.
+var newTextBoxContent func() TextBoxContent
+
+// RegisterTextBoxContent sets the constructor for the content decoded for
+// <w:txbxContent>. Without one, the content of text boxes is skipped when reading.
+func RegisterTextBoxContent(fn func() TextBoxContent) {
+	newTextBoxContent = fn
+}
+
+// WPSTextBox is the text box of a shape.
+type WPSTextBox struct {
+	Content TextBoxContent
+}
+
+func (t *WPSTextBox) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "wps:txbx"
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if t.Content != nil {
+		if err := t.Content.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+func (t *WPSTextBox) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+loop:
+	for {
+		token, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				break loop
+			}
+			return err
+		}
+		switch elem := token.(type) {
+		case xml.StartElement:
+			if elem.Name.Local == "txbxContent" && elem.Name.Space == constants.XMLNS_W && newTextBoxContent != nil {
+				t.Content = newTextBoxContent()
+				if err := t.Content.UnmarshalXML(d, elem); err != nil {
+					return err
+				}
+			} else {
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			if elem.Name == start.Name {
+				break loop
+			}
+		}
+	}
+	return nil
+}
+
 // ==========================================================
 // <wps:cNvPr> (Non-Visual Shape Properties)
 // ==========================================================
@@ -362,11 +484,16 @@ func (p *WPSNonVisualShapeProps) UnmarshalXML(d *xml.Decoder, start xml.StartEle
 // <wps:cNvSpPr> (Non-Visual Shape Drawing Properties)
 // ==========================================================
 type WPSNonVisualShapeDrawingProps struct {
+	// TxBox is "1" when the shape is a text box.
+	TxBox   string        `xml:"txBox,attr,omitempty"`
 	SpLocks *ShapeLocking `xml:"spLocks"`
 }
 
 func (p *WPSNonVisualShapeDrawingProps) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	start.Name.Local = "wps:cNvSpPr"
+	if p.TxBox != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "txBox"}, Value: p.TxBox})
+	}
 	if err := e.EncodeToken(start); err != nil {
 		return err
 	}
@@ -378,6 +505,11 @@ func (p *WPSNonVisualShapeDrawingProps) MarshalXML(e *xml.Encoder, start xml.Sta
 	return e.EncodeToken(xml.EndElement{Name: start.Name})
 }
 func (p *WPSNonVisualShapeDrawingProps) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "txBox" {
+			p.TxBox = attr.Value
+		}
+	}
 loop:
 	for {
 		token, err := d.Token()
@@ -448,8 +580,12 @@ type WPSShapeProperties struct {
 	BwMode   *string                `xml:"bwMode,attr,omitempty"`
 	Xfrm     *dmlpic.TransformGroup `xml:"xfrm,omitempty"`
 	PrstGeom *dmlpic.PresetGeometry `xml:"prstGeom,omitempty"`
-	GradFill *GradientFill          `xml:"gradFill,omitempty"`
-	Ln       *LineProperties        `xml:"ln,omitempty"`
+
+	// Fill: at most one of NoFill, SolidFill and GradFill is set.
+	NoFill    *dmlpic.NoFill  `xml:"noFill,omitempty"`
+	SolidFill *SolidFill      `xml:"solidFill,omitempty"`
+	GradFill  *GradientFill   `xml:"gradFill,omitempty"`
+	Ln        *LineProperties `xml:"ln,omitempty"`
 }
 
 func (p *WPSShapeProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
@@ -471,6 +607,16 @@ func (p *WPSShapeProperties) MarshalXML(e *xml.Encoder, start xml.StartElement)
 			return err
 		}
 	}
+	if p.NoFill != nil {
+		if err := p.NoFill.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	if p.SolidFill != nil {
+		if err := p.SolidFill.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
 	if p.GradFill != nil {
 		if err := p.GradFill.MarshalXML(e, xml.StartElement{}); err != nil {
 			return err
@@ -514,6 +660,16 @@ loop:
 				if err := p.PrstGeom.UnmarshalXML(d, elem); err != nil {
 					return err
 				}
+			} else if elem.Name.Local == "noFill" && elem.Name.Space == ns {
+				p.NoFill = new(dmlpic.NoFill)
+				if err := p.NoFill.UnmarshalXML(d, elem); err != nil {
+					return err
+				}
+			} else if elem.Name.Local == "solidFill" && elem.Name.Space == ns {
+				p.SolidFill = new(SolidFill)
+				if err := p.SolidFill.UnmarshalXML(d, elem); err != nil {
+					return err
+				}
 			} else if elem.Name.Local == "gradFill" && elem.Name.Space == ns {
 				p.GradFill = new(GradientFill)
 				if err := p.GradFill.UnmarshalXML(d, elem); err != nil {
@@ -779,11 +935,17 @@ func (l *ALinearGradient) UnmarshalXML(d *xml.Decoder, start xml.StartElement) e
 // <a:ln> (Line Properties)
 // ==========================================================
 type LineProperties struct {
-	NoFill *dmlpic.NoFill // 复用
+	// W is the line width in EMUs.
+	W         string         `xml:"w,attr,omitempty"`
+	NoFill    *dmlpic.NoFill // 复用
+	SolidFill *SolidFill
 }
 
 func (l *LineProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	start.Name.Local = "a:ln"
+	if l.W != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w"}, Value: l.W})
+	}
 	if err := e.EncodeToken(start); err != nil {
 		return err
 	}
@@ -792,9 +954,19 @@ func (l *LineProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) erro
 			return err
 		}
 	}
+	if l.SolidFill != nil {
+		if err := l.SolidFill.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
 	return e.EncodeToken(xml.EndElement{Name: start.Name})
 }
 func (l *LineProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "w" {
+			l.W = attr.Value
+		}
+	}
 loop:
 	for {
 		token, err := d.Token()
@@ -811,6 +983,61 @@ loop:
 				if err := l.NoFill.UnmarshalXML(d, elem); err != nil {
 					return err
 				}
+			} else if elem.Name.Local == "solidFill" && elem.Name.Space == constants.DrawingMLMainNS {
+				l.SolidFill = new(SolidFill)
+				if err := l.SolidFill.UnmarshalXML(d, elem); err != nil {
+					return err
+				}
+			} else {
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			if elem.Name == start.Name {
+				break loop
+			}
+		}
+	}
+	return nil
+}
+
+// ==========================================================
+// <a:solidFill> (Solid Fill)
+// ==========================================================
+type SolidFill struct {
+	SrgbClr *ASrgbColor
+}
+
+func (f *SolidFill) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "a:solidFill"
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if f.SrgbClr != nil {
+		if err := f.SrgbClr.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+func (f *SolidFill) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+loop:
+	for {
+		token, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				break loop
+			}
+			return err
+		}
+		switch elem := token.(type) {
+		case xml.StartElement:
+			if elem.Name.Local == "srgbClr" && elem.Name.Space == constants.DrawingMLMainNS {
+				f.SrgbClr = new(ASrgbColor)
+				if err := f.SrgbClr.UnmarshalXML(d, elem); err != nil {
+					return err
+				}
 			} else {
 				if err := d.Skip(); err != nil {
 					return err
@@ -0,0 +1,703 @@
+package docx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/common/constants"
+	"github.com/iEvan-lhr/docx-agent/common/units"
+	"github.com/iEvan-lhr/docx-agent/dml"
+	"github.com/iEvan-lhr/docx-agent/dml/dmlchart"
+	"github.com/iEvan-lhr/docx-agent/dml/dmlpic"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+)
+
+// ChartType is the kind of a chart.
+type ChartType string
+
+const (
+	ChartBar     ChartType = "bar"     // Horizontal bars
+	ChartColumn  ChartType = "column"  // Vertical bars
+	ChartLine    ChartType = "line"    // Lines through the values of each series
+	ChartPie     ChartType = "pie"     // Slices of a single series
+	ChartScatter ChartType = "scatter" // Markers at x and y values
+	ChartArea    ChartType = "area"    // Filled areas below the values of each series
+)
+
+const (
+	chartContentType    = "application/vnd.openxmlformats-officedocument.drawingml.chart+xml"
+	workbookContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	// Default size of a new chart
+	defaultChartWidth  units.Inch = 6
+	defaultChartHeight units.Inch = 3.5
+
+	// IDs of the axes of a new chart
+	chartAxisX = "500000001"
+	chartAxisY = "500000002"
+)
+
+// ChartSeries is a data series of a chart.
+type ChartSeries struct {
+	Name string
+
+	// Values holds a value per category, or the y values of a scatter series. NaN values
+	// are left empty.
+	Values []float64
+
+	// XValues are the x values of a scatter series and default to 1, 2, 3...
+	XValues []float64
+
+	// Color is the color of the series as a hex code such as "4472C4"; empty uses the
+	// colors of the theme.
+	Color string
+}
+
+// ChartOptions describes a new chart.
+type ChartOptions struct {
+	Type  ChartType
+	Title string
+
+	// Categories label the values of category charts and default to 1, 2, 3...
+	Categories []string
+	Series     []ChartSeries
+
+	// Stacked stacks the series of bar, column, line and area charts.
+	Stacked bool
+
+	// Width and Height default to 6 by 3.5 inches.
+	Width  units.Inch
+	Height units.Inch
+
+	// LegendPosition is "r" (the default), "l", "t", "b" or "tr"; NoLegend hides the legend.
+	LegendPosition string
+	NoLegend       bool
+
+	AltText string
+}
+
+// Chart is a chart of the document. Charts are placed like pictures, so the embedded
+// PicMeta floats them, orders them and turns them inline.
+type Chart struct {
+	PicMeta
+
+	// Part is the path of the chart part, such as "word/charts/chart1.xml".
+	Part string
+
+	Type       ChartType
+	Title      string
+	Categories []string
+	Series     []ChartSeries
+	Stacked    bool
+}
+
+// AddChart adds a native chart to the paragraph. The chart data is also stored in an
+// embedded workbook, so the chart stays editable in Word.
+func (p *Paragraph) AddChart(opts *ChartOptions) (*Chart, error) {
+	if opts == nil {
+		return nil, errors.New("chart options are required")
+	}
+	if err := validateChart(opts); err != nil {
+		return nil, err
+	}
+
+	width, height := opts.Width, opts.Height
+	if width == 0 {
+		width = defaultChartWidth
+	}
+	if height == 0 {
+		height = defaultChartHeight
+	}
+	if width < 0 || height < 0 {
+		return nil, errors.New("chart size must not be negative")
+	}
+
+	rID, part, err := p.root.addChartPart(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	pm := p.addInlineGraphic(*dml.NewChartGraphic(rID), width, height, "", "Chart", opts.AltText, "")
+	chart := chartFromOptions(opts)
+	chart.PicMeta = *pm
+	chart.Part = part
+	return chart, nil
+}
+
+// AddChart adds a native chart to the document in a new paragraph. See
+// Paragraph.AddChart.
+func (rd *RootDoc) AddChart(opts *ChartOptions) (*Chart, error) {
+	p := newParagraph(rd)
+
+	chart, err := p.AddChart(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rd.Document.Body.Children = append(rd.Document.Body.Children, DocumentChild{Para: p})
+	return chart, nil
+}
+
+// Charts returns the charts of the document body, including those in tables, in document
+// order, with the series data cached in their chart parts.
+func (rd *RootDoc) Charts() ([]*Chart, error) {
+	if rd.Document == nil || rd.Document.Body == nil {
+		return nil, nil
+	}
+
+	var (
+		charts []*Chart
+		err    error
+	)
+	collect := func(p *Paragraph) {
+		if err != nil {
+			return
+		}
+		for _, pm := range p.graphics() {
+			g := &pm.Inline.Graphic
+			if pm.Anchor != nil {
+				g = &pm.Anchor.Graphic
+			}
+			if g.Data == nil || g.Data.Chart == nil {
+				continue
+			}
+			var chart *Chart
+			if chart, err = rd.readChart(g.Data.Chart.RID); err != nil {
+				return
+			}
+			chart.PicMeta = *pm
+			charts = append(charts, chart)
+		}
+	}
+
+	for _, child := range rd.Document.Body.Children {
+		switch {
+		case child.Para != nil:
+			collect(child.Para)
+		case child.Table != nil:
+			wrapTableParagraphs(rd, &child.Table.ct, hasGraphics, collect)
+		}
+	}
+	return charts, err
+}
+
+// graphics returns the inline and floating drawings of the paragraph, including the
+// preferred branch of mc:AlternateContent, in document order.
+func (p *Paragraph) graphics() []*PicMeta {
+	var metas []*PicMeta
+	add := func(d *dml.Drawing) {
+		for i := range d.Inline {
+			metas = append(metas, &PicMeta{Para: p, Inline: &d.Inline[i], drawing: d})
+		}
+		for _, a := range d.Anchor {
+			if a != nil {
+				metas = append(metas, &PicMeta{Para: p, Anchor: a, drawing: d})
+			}
+		}
+	}
+	addAlternate := func(ac *ctypes.AlternateContent) {
+		if ac.Choice != nil && ac.Choice.Drawing != nil {
+			add(ac.Choice.Drawing)
+		}
+	}
+
+	for _, run := range paragraphRuns(&p.ct) {
+		if run.AlternateContent != nil {
+			addAlternate(run.AlternateContent)
+		}
+		for _, rc := range run.Children {
+			switch {
+			case rc.Drawing != nil:
+				add(rc.Drawing)
+			case rc.AlternateContent != nil:
+				addAlternate(rc.AlternateContent)
+			}
+		}
+	}
+	return metas
+}
+
+// hasGraphics reports whether the paragraph holds a drawing.
+func hasGraphics(p *ctypes.Paragraph) bool {
+	for _, run := range paragraphRuns(p) {
+		if run.AlternateContent != nil {
+			return true
+		}
+		for _, rc := range run.Children {
+			if rc.Drawing != nil || rc.AlternateContent != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readChart parses the chart part of the document relationship rID.
+func (rd *RootDoc) readChart(rID string) (*Chart, error) {
+	var target string
+	for _, rel := range rd.Document.DocRels.Relationships {
+		if rel.ID == rID && rel.TargetMode != "External" {
+			target = partTarget(rd.Document.relativePath, rel.Target)
+			break
+		}
+	}
+	if target == "" {
+		return nil, fmt.Errorf("chart relationship %s not found", rID)
+	}
+	content, ok := rd.FileMap.Load(target)
+	if !ok {
+		return nil, fmt.Errorf("chart part %s not found", target)
+	}
+
+	cs := &dmlchart.ChartSpace{}
+	if err := xml.Unmarshal(content.([]byte), cs); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", target, err)
+	}
+	chart := chartFromSpace(cs)
+	chart.Part = target
+	return chart, nil
+}
+
+// partTarget resolves the target of a relationship of part to a package path.
+func partTarget(part, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Join(path.Dir(part), target)
+}
+
+// validateChart checks the options of a new chart.
+func validateChart(opts *ChartOptions) error {
+	switch opts.Type {
+	case ChartBar, ChartColumn, ChartLine, ChartPie, ChartScatter, ChartArea:
+	default:
+		return fmt.Errorf("unsupported chart type %q", opts.Type)
+	}
+	if len(opts.Series) == 0 {
+		return errors.New("a chart needs at least one series")
+	}
+	if opts.Type == ChartPie && len(opts.Series) > 1 {
+		return errors.New("a pie chart takes a single series")
+	}
+	if opts.Stacked && (opts.Type == ChartPie || opts.Type == ChartScatter) {
+		return fmt.Errorf("%s charts cannot be stacked", opts.Type)
+	}
+	switch opts.LegendPosition {
+	case "", "r", "l", "t", "b", "tr":
+	default:
+		return fmt.Errorf("invalid legend position %q", opts.LegendPosition)
+	}
+
+	for i, s := range opts.Series {
+		if len(s.Values) == 0 {
+			return fmt.Errorf("series %d has no values", i+1)
+		}
+		if opts.Type == ChartScatter {
+			if s.XValues != nil && len(s.XValues) != len(s.Values) {
+				return fmt.Errorf("series %d has %d x values for %d y values", i+1, len(s.XValues), len(s.Values))
+			}
+		} else if len(opts.Categories) > 0 && len(s.Values) > len(opts.Categories) {
+			return fmt.Errorf("series %d has more values than there are categories", i+1)
+		}
+		if s.Color != "" {
+			if _, err := solidFill(s.Color); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// chartFromOptions returns the chart described by the options of a new chart, with the
+// defaults filled in.
+func chartFromOptions(opts *ChartOptions) *Chart {
+	chart := &Chart{
+		Type:       opts.Type,
+		Title:      opts.Title,
+		Categories: chartCategories(opts),
+		Stacked:    opts.Stacked,
+	}
+	for _, s := range opts.Series {
+		s.Values = append([]float64(nil), s.Values...)
+		if opts.Type == ChartScatter {
+			s.XValues = scatterXValues(s)
+		} else {
+			s.XValues = nil
+		}
+		chart.Series = append(chart.Series, s)
+	}
+	return chart
+}
+
+// chartCategories returns the categories of a category chart, numbering them when none
+// are given.
+func chartCategories(opts *ChartOptions) []string {
+	if opts.Type == ChartScatter {
+		return nil
+	}
+	if len(opts.Categories) > 0 {
+		return append([]string(nil), opts.Categories...)
+	}
+	n := 0
+	for _, s := range opts.Series {
+		n = max(n, len(s.Values))
+	}
+	categories := make([]string, n)
+	for i := range categories {
+		categories[i] = strconv.Itoa(i + 1)
+	}
+	return categories
+}
+
+// scatterXValues returns the x values of a scatter series, numbering the points when none
+// are given.
+func scatterXValues(s ChartSeries) []float64 {
+	if s.XValues != nil {
+		return append([]float64(nil), s.XValues...)
+	}
+	xs := make([]float64, len(s.Values))
+	for i := range xs {
+		xs[i] = float64(i + 1)
+	}
+	return xs
+}
+
+// addChartPart stores the chart part described by opts with its workbook and returns the
+// ID of the document relationship to the chart and the path of the chart part.
+func (rd *RootDoc) addChartPart(opts *ChartOptions) (string, string, error) {
+	// The chart and its workbook go next to the main document part, wherever it is.
+	docDir := path.Dir(rd.Document.relativePath)
+	n := 1
+	for {
+		_, chartExists := rd.FileMap.Load(path.Join(docDir, fmt.Sprintf("charts/chart%d.xml", n)))
+		_, workbookExists := rd.FileMap.Load(path.Join(docDir, fmt.Sprintf("embeddings/Microsoft_Excel_Worksheet%d.xlsx", n)))
+		if !chartExists && !workbookExists {
+			break
+		}
+		n++
+	}
+	chartTarget := fmt.Sprintf("charts/chart%d.xml", n)
+	chartPath := path.Join(docDir, chartTarget)
+	workbookName := fmt.Sprintf("Microsoft_Excel_Worksheet%d.xlsx", n)
+
+	chart := chartFromOptions(opts)
+	cs, rows := chartSpace(chart, opts)
+	cs.ExternalData = &dmlchart.ExternalData{RID: "rId1", AutoUpdate: dmlchart.NewVal("0")}
+
+	workbook, err := chartWorkbook(rows)
+	if err != nil {
+		return "", "", err
+	}
+	chartXML, err := marshal(cs)
+	if err != nil {
+		return "", "", err
+	}
+	rels := Relationships{
+		Xmlns: constants.XMLNS,
+		Relationships: []*Relationship{{
+			ID:     "rId1",
+			Type:   constants.SourceRelationshipPackage,
+			Target: "../embeddings/" + workbookName,
+		}},
+	}
+	relsXML, err := marshal(rels)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := rd.ContentType.AddOverride("/"+chartPath, chartContentType); err != nil {
+		return "", "", err
+	}
+	if err := rd.ContentType.AddExtension("xlsx", workbookContentType); err != nil {
+		return "", "", err
+	}
+	rd.FileMap.Store(chartPath, chartXML)
+	rd.FileMap.Store(partRelsPath(chartPath), relsXML)
+	rd.FileMap.Store(path.Join(docDir, "embeddings", workbookName), workbook)
+
+	rID := rd.Document.addRelation(constants.SourceRelationshipChart, chartTarget)
+	return rID, chartPath, nil
+}
+
+// chartSpace returns the chart part of a chart and the worksheet rows holding its data.
+//
+// Category charts put the categories in column A and a series per column from B on, with
+// the series names in row 1. Scatter charts put each series in a pair of columns, x values
+// first.
+func chartSpace(chart *Chart, opts *ChartOptions) (*dmlchart.ChartSpace, [][]*sheetCell) {
+	scatter := chart.Type == ChartScatter
+
+	rowCount := len(chart.Categories) + 1
+	for _, s := range chart.Series {
+		rowCount = max(rowCount, len(s.Values)+1)
+	}
+	cols := len(chart.Series) + 1
+	if scatter {
+		cols = 2 * len(chart.Series)
+	}
+	rows := make([][]*sheetCell, rowCount)
+	for i := range rows {
+		rows[i] = make([]*sheetCell, cols)
+	}
+	for i, c := range chart.Categories {
+		rows[i+1][0] = &sheetCell{IsText: true, Text: c}
+	}
+
+	group := &dmlchart.PlotGroup{VaryColors: dmlchart.NewVal("0")}
+	grouping := "standard"
+	if chart.Stacked {
+		grouping = "stacked"
+	}
+	switch chart.Type {
+	case ChartBar, ChartColumn:
+		group.Kind = "barChart"
+		group.BarDir = dmlchart.NewVal("col")
+		if chart.Type == ChartBar {
+			group.BarDir = dmlchart.NewVal("bar")
+		}
+		group.Grouping = dmlchart.NewVal("clustered")
+		group.GapWidth = dmlchart.NewVal("150")
+		if chart.Stacked {
+			group.Grouping = dmlchart.NewVal("stacked")
+			group.Overlap = dmlchart.NewVal("100")
+		}
+	case ChartLine:
+		group.Kind = "lineChart"
+		group.Grouping = dmlchart.NewVal(grouping)
+		group.Marker = dmlchart.NewVal("1")
+	case ChartArea:
+		group.Kind = "areaChart"
+		group.Grouping = dmlchart.NewVal(grouping)
+	case ChartPie:
+		group.Kind = "pieChart"
+		group.VaryColors = dmlchart.NewVal("1")
+		group.FirstSliceAng = dmlchart.NewVal("0")
+	case ChartScatter:
+		group.Kind = "scatterChart"
+		group.ScatterStyle = dmlchart.NewVal("lineMarker")
+	}
+
+	lastCategory := len(chart.Categories) + 1
+	for i, s := range chart.Series {
+		ser := &dmlchart.Series{
+			Idx:   dmlchart.NewVal(strconv.Itoa(i)),
+			Order: dmlchart.NewVal(strconv.Itoa(i)),
+		}
+
+		valueCol := i + 1
+		if scatter {
+			valueCol = 2*i + 1
+			rows[0][valueCol-1] = &sheetCell{IsText: true, Text: "X"}
+			for j, x := range s.XValues {
+				rows[j+1][valueCol-1] = &sheetCell{Number: x}
+			}
+		}
+		rows[0][valueCol] = &sheetCell{IsText: true, Text: s.Name}
+		for j, v := range s.Values {
+			rows[j+1][valueCol] = &sheetCell{Number: v}
+		}
+
+		ser.Tx = &dmlchart.SeriesText{StrRef: &dmlchart.StrRef{
+			F:        sheetRange(valueCol, 1, 1),
+			StrCache: dmlchart.NewStrData([]string{s.Name}),
+		}}
+		values := &dmlchart.NumDataSource{NumRef: &dmlchart.NumRef{
+			F:        sheetRange(valueCol, 2, len(s.Values)+1),
+			NumCache: dmlchart.NewNumData(s.Values),
+		}}
+
+		switch chart.Type {
+		case ChartScatter:
+			ser.XVal = &dmlchart.AxDataSource{NumRef: &dmlchart.NumRef{
+				F:        sheetRange(valueCol-1, 2, len(s.XValues)+1),
+				NumCache: dmlchart.NewNumData(s.XValues),
+			}}
+			ser.YVal = values
+			ser.Smooth = dmlchart.NewVal("0")
+		default:
+			ser.Cat = &dmlchart.AxDataSource{StrRef: &dmlchart.StrRef{
+				F:        sheetRange(0, 2, lastCategory),
+				StrCache: dmlchart.NewStrData(chart.Categories),
+			}}
+			ser.Val = values
+		}
+
+		switch chart.Type {
+		case ChartBar, ChartColumn:
+			ser.InvertIfNegative = dmlchart.NewVal("0")
+		case ChartLine:
+			ser.Marker = &dmlchart.Marker{Symbol: dmlchart.NewVal("none")}
+			ser.Smooth = dmlchart.NewVal("0")
+		case ChartScatter:
+			// Markers only
+			ser.SpPr = &dmlchart.ShapeProperties{Ln: &dml.LineProperties{W: "19050", NoFill: &dmlpic.NoFill{}}}
+		}
+
+		if s.Color != "" {
+			fill, _ := solidFill(s.Color)
+			switch chart.Type {
+			case ChartLine:
+				ser.SpPr = &dmlchart.ShapeProperties{Ln: &dml.LineProperties{W: "28575", SolidFill: fill}}
+			case ChartScatter:
+				ser.Marker = &dmlchart.Marker{
+					Symbol: dmlchart.NewVal("circle"),
+					SpPr:   &dmlchart.ShapeProperties{SolidFill: fill},
+				}
+			case ChartBar, ChartColumn, ChartArea:
+				ser.SpPr = &dmlchart.ShapeProperties{SolidFill: fill}
+			}
+		}
+		group.Series = append(group.Series, ser)
+	}
+
+	cs := &dmlchart.ChartSpace{
+		Date1904:       dmlchart.NewVal("0"),
+		Lang:           dmlchart.NewVal("en-US"),
+		RoundedCorners: dmlchart.NewVal("0"),
+		Chart: dmlchart.Chart{
+			AutoTitleDeleted: dmlchart.NewVal("1"),
+			PlotArea:         dmlchart.PlotArea{Groups: []*dmlchart.PlotGroup{group}},
+			PlotVisOnly:      dmlchart.NewVal("1"),
+			DispBlanksAs:     dmlchart.NewVal("gap"),
+		},
+	}
+	if chart.Title != "" {
+		cs.Chart.Title = dmlchart.NewTitle(chart.Title)
+		cs.Chart.AutoTitleDeleted = dmlchart.NewVal("0")
+	}
+	if !opts.NoLegend {
+		pos := opts.LegendPosition
+		if pos == "" {
+			pos = "r"
+		}
+		cs.Chart.Legend = &dmlchart.Legend{LegendPos: dmlchart.NewVal(pos), Overlay: dmlchart.NewVal("0")}
+	}
+
+	if chart.Type != ChartPie {
+		group.AxIDs = []*dmlchart.Val{dmlchart.NewVal(chartAxisX), dmlchart.NewVal(chartAxisY)}
+		cs.Chart.PlotArea.Axes = chartAxes(chart.Type)
+	}
+	return cs, rows
+}
+
+// chartAxes returns the x and y axes of a new chart.
+func chartAxes(chartType ChartType) []*dmlchart.Axis {
+	axis := func(kind, id, pos, crossAx string) *dmlchart.Axis {
+		return &dmlchart.Axis{
+			Kind:          kind,
+			AxID:          dmlchart.NewVal(id),
+			Scaling:       &dmlchart.Scaling{Orientation: dmlchart.NewVal("minMax")},
+			Delete:        dmlchart.NewVal("0"),
+			AxPos:         dmlchart.NewVal(pos),
+			MajorTickMark: dmlchart.NewVal("out"),
+			MinorTickMark: dmlchart.NewVal("none"),
+			TickLblPos:    dmlchart.NewVal("nextTo"),
+			CrossAx:       dmlchart.NewVal(crossAx),
+			Crosses:       dmlchart.NewVal("autoZero"),
+		}
+	}
+	valueAxis := func(id, pos, crossAx, crossBetween string) *dmlchart.Axis {
+		ax := axis("valAx", id, pos, crossAx)
+		ax.NumFmt = &dmlchart.NumFmt{FormatCode: "General", SourceLinked: "1"}
+		ax.CrossBetween = dmlchart.NewVal(crossBetween)
+		return ax
+	}
+
+	if chartType == ChartScatter {
+		x := valueAxis(chartAxisX, "b", chartAxisY, "midCat")
+		y := valueAxis(chartAxisY, "l", chartAxisX, "midCat")
+		y.MajorGridlines = &dmlchart.Gridlines{}
+		return []*dmlchart.Axis{x, y}
+	}
+
+	catPos, valPos := "b", "l"
+	if chartType == ChartBar {
+		catPos, valPos = "l", "b"
+	}
+	crossBetween := "between"
+	if chartType == ChartArea {
+		crossBetween = "midCat"
+	}
+	cat := axis("catAx", chartAxisX, catPos, chartAxisY)
+	cat.Auto = dmlchart.NewVal("1")
+	cat.LblAlgn = dmlchart.NewVal("ctr")
+	cat.LblOffset = dmlchart.NewVal("100")
+	val := valueAxis(chartAxisY, valPos, chartAxisX, crossBetween)
+	val.MajorGridlines = &dmlchart.Gridlines{}
+	return []*dmlchart.Axis{cat, val}
+}
+
+// chartFromSpace returns the chart of a parsed chart part. The type is that of the first
+// chart group; the series of all groups are returned.
+func chartFromSpace(cs *dmlchart.ChartSpace) *Chart {
+	chart := &Chart{Title: cs.Chart.Title.Text()}
+
+	for i, g := range cs.Chart.PlotArea.Groups {
+		if i == 0 {
+			chart.Type = chartTypeOf(g)
+			if g.Grouping != nil {
+				chart.Stacked = g.Grouping.Val == "stacked" || g.Grouping.Val == "percentStacked"
+			}
+		}
+		for _, ser := range g.Series {
+			s := ChartSeries{Name: ser.Tx.Text(), Color: seriesColor(ser)}
+			switch {
+			case ser.YVal != nil:
+				s.Values = ser.YVal.Numbers()
+				s.XValues = ser.XVal.Numbers()
+			default:
+				s.Values = ser.Val.Numbers()
+				if chart.Categories == nil {
+					chart.Categories = ser.Cat.Strings()
+				}
+			}
+			chart.Series = append(chart.Series, s)
+		}
+	}
+	return chart
+}
+
+// chartTypeOf returns the chart type of a chart group; types other than the ones this
+// package creates are named after the element, such as "doughnutChart".
+func chartTypeOf(g *dmlchart.PlotGroup) ChartType {
+	switch g.Kind {
+	case "barChart", "bar3DChart":
+		if g.BarDir != nil && g.BarDir.Val == "bar" {
+			return ChartBar
+		}
+		return ChartColumn
+	case "lineChart", "line3DChart":
+		return ChartLine
+	case "pieChart", "pie3DChart":
+		return ChartPie
+	case "scatterChart":
+		return ChartScatter
+	case "areaChart", "area3DChart":
+		return ChartArea
+	}
+	return ChartType(g.Kind)
+}
+
+// seriesColor returns the solid color of a series, if it has one.
+func seriesColor(ser *dmlchart.Series) string {
+	var fills []*dml.SolidFill
+	if ser.SpPr != nil {
+		fills = append(fills, ser.SpPr.SolidFill)
+		if ser.SpPr.Ln != nil {
+			fills = append(fills, ser.SpPr.Ln.SolidFill)
+		}
+	}
+	if ser.Marker != nil && ser.Marker.SpPr != nil {
+		fills = append(fills, ser.Marker.SpPr.SolidFill)
+	}
+	for _, fill := range fills {
+		if fill != nil && fill.SrgbClr != nil {
+			return fill.SrgbClr.Val
+		}
+	}
+	return ""
+}
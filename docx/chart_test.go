@@ -0,0 +1,229 @@
+package docx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCharts(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	column, err := rd.AddChart(&docx.ChartOptions{
+		Type:       docx.ChartColumn,
+		Title:      "Revenue",
+		Categories: []string{"Q1", "Q2", "Q3"},
+		Series: []docx.ChartSeries{
+			{Name: "North", Values: []float64{10, 12.5, 9}, Color: "#4472c4"},
+			{Name: "South", Values: []float64{8, math.NaN(), 11}},
+		},
+		Stacked: true,
+		AltText: "Revenue by quarter",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "word/charts/chart1.xml", column.Part)
+
+	_, err = rd.AddChart(&docx.ChartOptions{
+		Type:   docx.ChartLine,
+		Series: []docx.ChartSeries{{Name: "Trend", Values: []float64{1, 2, 3, 5}}},
+	})
+	require.NoError(t, err)
+	_, err = rd.AddChart(&docx.ChartOptions{
+		Type:       docx.ChartPie,
+		Categories: []string{"A", "B"},
+		Series:     []docx.ChartSeries{{Name: "Share", Values: []float64{70, 30}}},
+		NoLegend:   true,
+	})
+	require.NoError(t, err)
+	_, err = rd.AddChart(&docx.ChartOptions{
+		Type:   docx.ChartScatter,
+		Series: []docx.ChartSeries{{Name: "Samples", XValues: []float64{0.5, 1.5}, Values: []float64{3, 4}, Color: "FF0000"}},
+	})
+	require.NoError(t, err)
+
+	for _, opts := range []*docx.ChartOptions{
+		nil,
+		{Type: "radar", Series: []docx.ChartSeries{{Values: []float64{1}}}},
+		{Type: docx.ChartBar},
+		{Type: docx.ChartPie, Series: []docx.ChartSeries{{Values: []float64{1}}, {Values: []float64{2}}}},
+		{Type: docx.ChartPie, Stacked: true, Series: []docx.ChartSeries{{Values: []float64{1}}}},
+		{Type: docx.ChartBar, Categories: []string{"a"}, Series: []docx.ChartSeries{{Values: []float64{1, 2}}}},
+		{Type: docx.ChartScatter, Series: []docx.ChartSeries{{XValues: []float64{1}, Values: []float64{1, 2}}}},
+		{Type: docx.ChartLine, Series: []docx.ChartSeries{{Values: []float64{1}, Color: "blue"}}},
+	} {
+		_, err := rd.AddChart(opts)
+		assert.Error(t, err)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	content := buf.Bytes()
+
+	doc := documentXML(t, content)
+	assert.Contains(t, doc, `<a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/chart"><c:chart`)
+	assert.Contains(t, doc, `descr="Revenue by quarter"`)
+
+	parts := zipParts(t, content)
+	for _, name := range []string{
+		"word/charts/chart1.xml",
+		"word/charts/_rels/chart1.xml.rels",
+		"word/embeddings/Microsoft_Excel_Worksheet1.xlsx",
+		"word/charts/chart4.xml",
+		"word/embeddings/Microsoft_Excel_Worksheet4.xlsx",
+	} {
+		assert.Contains(t, parts, name)
+	}
+	assert.Contains(t, string(parts["[Content_Types].xml"]), `PartName="/word/charts/chart1.xml" ContentType="application/vnd.openxmlformats-officedocument.drawingml.chart+xml"`)
+	assert.Contains(t, string(parts["[Content_Types].xml"]), `Extension="xlsx"`)
+	assert.Contains(t, string(parts["word/charts/chart1.xml"]), `<c:f>Sheet1!$B$2:$B$4</c:f>`)
+	assert.Contains(t, string(parts["word/charts/_rels/chart1.xml.rels"]), `Target="../embeddings/Microsoft_Excel_Worksheet1.xlsx"`)
+
+	workbook := parts["word/embeddings/Microsoft_Excel_Worksheet1.xlsx"]
+	sheet := string(zipParts(t, workbook)["xl/worksheets/sheet1.xml"])
+	assert.Contains(t, sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">Q1</t></is></c><c r="B2"><v>10</v></c>`)
+
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+	charts, err := reopened.Charts()
+	require.NoError(t, err)
+	require.Len(t, charts, 4)
+
+	assert.Equal(t, docx.ChartColumn, charts[0].Type)
+	assert.Equal(t, "Revenue", charts[0].Title)
+	assert.True(t, charts[0].Stacked)
+	assert.Equal(t, []string{"Q1", "Q2", "Q3"}, charts[0].Categories)
+	require.Len(t, charts[0].Series, 2)
+	assert.Equal(t, "North", charts[0].Series[0].Name)
+	assert.Equal(t, []float64{10, 12.5, 9}, charts[0].Series[0].Values)
+	assert.Equal(t, "4472C4", charts[0].Series[0].Color)
+	assert.True(t, math.IsNaN(charts[0].Series[1].Values[1]))
+	assert.Equal(t, 11.0, charts[0].Series[1].Values[2])
+
+	assert.Equal(t, docx.ChartLine, charts[1].Type)
+	assert.Equal(t, []string{"1", "2", "3", "4"}, charts[1].Categories)
+	assert.Equal(t, docx.ChartPie, charts[2].Type)
+	assert.Equal(t, []float64{70, 30}, charts[2].Series[0].Values)
+
+	assert.Equal(t, docx.ChartScatter, charts[3].Type)
+	assert.Equal(t, []float64{0.5, 1.5}, charts[3].Series[0].XValues)
+	assert.Equal(t, []float64{3, 4}, charts[3].Series[0].Values)
+	assert.Equal(t, "FF0000", charts[3].Series[0].Color)
+
+	// New charts skip the parts already in the package.
+	chart, err := reopened.AddChart(&docx.ChartOptions{Type: docx.ChartBar, Series: []docx.ChartSeries{{Values: []float64{1}}}})
+	require.NoError(t, err)
+	assert.Equal(t, "word/charts/chart5.xml", chart.Part)
+}
+
+func TestChartsMainPartDirectory(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+
+	// Move the main document part and its neighbours from word/ to doc/.
+	content := rewriteZip(t, buf.Bytes(), func(name string, content []byte) (string, []byte) {
+		switch name {
+		case "[Content_Types].xml", "_rels/.rels":
+			content = bytes.ReplaceAll(content, []byte("word/"), []byte("doc/"))
+		}
+		if rest, ok := strings.CutPrefix(name, "word/"); ok {
+			name = "doc/" + rest
+		}
+		return name, content
+	})
+
+	rd, err = packager.Unpack(&content)
+	require.NoError(t, err)
+	chart, err := rd.AddChart(&docx.ChartOptions{Type: docx.ChartBar, Series: []docx.ChartSeries{{Values: []float64{1}}}})
+	require.NoError(t, err)
+	assert.Equal(t, "doc/charts/chart1.xml", chart.Part)
+
+	buf.Reset()
+	require.NoError(t, rd.Write(&buf))
+	parts := zipParts(t, buf.Bytes())
+	assert.Contains(t, parts, "doc/charts/chart1.xml")
+	assert.Contains(t, parts, "doc/embeddings/Microsoft_Excel_Worksheet1.xlsx")
+	assert.Contains(t, string(parts["doc/_rels/document.xml.rels"]), `Target="charts/chart1.xml"`)
+}
+
+func TestChartsCorruptPointCount(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+	_, err = rd.AddChart(&docx.ChartOptions{
+		Type:       docx.ChartColumn,
+		Categories: []string{"Q1", "Q2"},
+		Series:     []docx.ChartSeries{{Name: "North", Values: []float64{1, 2}}},
+	})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+
+	// A negative count for the categories and a huge one and index for the values.
+	content := rewriteZip(t, buf.Bytes(), func(name string, content []byte) (string, []byte) {
+		if name == "word/charts/chart1.xml" {
+			text := string(content)
+			text = strings.Replace(text, `<c:ptCount val="2">`, `<c:ptCount val="-5">`, 1)
+			text = strings.Replace(text, `<c:ptCount val="2">`, `<c:ptCount val="4000000000">`, 1)
+			text = strings.Replace(text, `<c:pt idx="1">`, `<c:pt idx="2000000000">`, 1)
+			content = []byte(text)
+		}
+		return name, content
+	})
+
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+	charts, err := reopened.Charts()
+	require.NoError(t, err)
+	require.Len(t, charts, 1)
+	require.Len(t, charts[0].Series, 1)
+	assert.LessOrEqual(t, len(charts[0].Series[0].Values), 1<<20)
+}
+
+// rewriteZip returns a copy of a zip package with every part passed through fn, which
+// returns its new name and content, or an empty name to leave the part out.
+func rewriteZip(t *testing.T, pkg []byte, fn func(name string, content []byte) (string, []byte)) []byte {
+	t.Helper()
+
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for name, content := range zipParts(t, pkg) {
+		name, content = fn(name, content)
+		if name == "" {
+			continue
+		}
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return out.Bytes()
+}
+
+// zipParts returns the parts of a zip package by name.
+func zipParts(t *testing.T, pkg []byte) map[string][]byte {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(pkg), int64(len(pkg)))
+	require.NoError(t, err)
+	parts := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		parts[f.Name] = content
+	}
+	return parts
+}
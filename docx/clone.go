@@ -0,0 +1,47 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+)
+
+// cloneChildren returns deep copies of the given block-level children, bound to root.
+//
+// The children are round-tripped through their XML representation, so the copies share
+// no pointers with the originals and can be modified or attached to another document freely.
+func cloneChildren(root *RootDoc, children []DocumentChild) ([]DocumentChild, error) {
+	body, err := cloneBody(root, &Body{Children: children})
+	if err != nil {
+		return nil, err
+	}
+	return body.Children, nil
+}
+
+// cloneBody returns a deep copy of the given body, including its section properties, bound to root.
+func cloneBody(root *RootDoc, body *Body) (*Body, error) {
+	content, err := marshalBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := NewBody(root)
+	if err := xml.Unmarshal(content, clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// marshalBody encodes the body as a standalone w:body element that carries the
+// namespace declarations required to decode it again.
+func marshalBody(body *Body) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	start := xml.StartElement{Name: xml.Name{Local: "w:body"}, Attr: docAttrs}
+	if err := enc.EncodeElement(body, start); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,858 @@
+package docx
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/iEvan-lhr/docx-agent/internal"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+)
+
+// DefaultCompareAuthor is the revision author recorded in a redline when none is configured.
+const DefaultCompareAuthor = "Compare"
+
+// pairThreshold is the minimum word similarity for two unmatched paragraphs to be reported
+// as one modified paragraph rather than a deletion followed by an insertion.
+const pairThreshold = 0.5
+
+// objectToken stands for a drawing or picture in a word-level diff.
+const objectToken = "\uFFFC"
+
+// ChangeKind describes how a piece of content differs between two documents.
+type ChangeKind string
+
+const (
+	ChangeInserted ChangeKind = "inserted"
+	ChangeDeleted  ChangeKind = "deleted"
+	ChangeModified ChangeKind = "modified"
+)
+
+// DiffOp is the operation of a single segment of a word-level diff.
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffInsert DiffOp = "insert"
+	DiffDelete DiffOp = "delete"
+)
+
+// TextDiff is a run of text that is kept, inserted or deleted in a modified paragraph.
+type TextDiff struct {
+	Op   DiffOp
+	Text string
+}
+
+// Change describes one difference between the original and the revised document.
+type Change struct {
+	Kind ChangeKind
+
+	// Index of the block (paragraph or table) in the original and revised body;
+	// -1 when the block does not exist on that side.
+	OldIndex int
+	NewIndex int
+
+	// Row and column of the table cell the change belongs to, or -1 for changes
+	// outside of tables. Row refers to the revised table, except for deleted rows.
+	Row int
+	Col int
+
+	OldText string
+	NewText string
+
+	// Word-level diff of a modified paragraph.
+	Diff []TextDiff
+}
+
+// CompareOptions configures how revisions are recorded in the redline document.
+type CompareOptions struct {
+	// Author of the tracked revisions; DefaultCompareAuthor when empty.
+	Author string
+
+	// Date of the tracked revisions; the current time when zero.
+	Date time.Time
+}
+
+// Comparison is the result of comparing two documents.
+type Comparison struct {
+	// Changes lists the differences in document order.
+	Changes []Change
+
+	// Redline is a copy of the revised document in which every difference is recorded as
+	// a tracked insertion (w:ins) or deletion (w:del), ready to be reviewed in Word.
+	Redline *RootDoc
+}
+
+// Compare computes the differences between the original and the revised document.
+//
+// Paragraphs and tables are aligned on their text, unmatched paragraphs that are similar
+// enough are diffed word by word, and tables are compared row by row and cell by cell.
+// Both documents are left untouched; opts may be nil.
+func Compare(original, revised *RootDoc, opts *CompareOptions) (*Comparison, error) {
+	if original == nil || original.Document == nil || original.Document.Body == nil {
+		return nil, errors.New("original document has no body")
+	}
+	if revised == nil || revised.Document == nil || revised.Document.Body == nil {
+		return nil, errors.New("revised document has no body")
+	}
+
+	c := newComparer(opts)
+
+	oldBlocks := bodyBlocks(original.Document.Body.Children)
+	newBlocks := bodyBlocks(revised.Document.Body.Children)
+	merged := c.compareBlocks(oldBlocks, newBlocks, nil)
+
+	children := make([]DocumentChild, 0, len(merged))
+	for _, b := range merged {
+		if b.para != nil {
+			children = append(children, DocumentChild{Para: &Paragraph{root: revised, ct: *b.para}})
+		} else {
+			children = append(children, DocumentChild{Table: &Table{root: revised, ct: *b.table}})
+		}
+	}
+
+	redline, err := revised.newPart(children, revised.Document.Body.SectPr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Comparison{Changes: c.changes, Redline: redline}, nil
+}
+
+// block is a paragraph or a table, either at body level or within a table cell.
+type block struct {
+	para  *ctypes.Paragraph
+	table *ctypes.Table
+}
+
+func (b block) text() string {
+	if b.para != nil {
+		return paragraphText(b.para)
+	}
+	return tableText(b.table)
+}
+
+// key identifies the block content for alignment; paragraphs never match tables.
+func (b block) key() string {
+	if b.para != nil {
+		return "p\x00" + paragraphText(b.para)
+	}
+	return "t\x00" + tableText(b.table)
+}
+
+func bodyBlocks(children []DocumentChild) []block {
+	blocks := make([]block, 0, len(children))
+	for _, child := range children {
+		switch {
+		case child.Para != nil:
+			blocks = append(blocks, block{para: &child.Para.ct})
+		case child.Table != nil:
+			blocks = append(blocks, block{table: &child.Table.ct})
+		}
+	}
+	return blocks
+}
+
+func cellBlocks(cell *ctypes.Cell) []block {
+	blocks := make([]block, 0, len(cell.Contents))
+	for _, content := range cell.Contents {
+		switch {
+		case content.Paragraph != nil:
+			blocks = append(blocks, block{para: content.Paragraph})
+		case content.Table != nil:
+			blocks = append(blocks, block{table: content.Table})
+		}
+	}
+	return blocks
+}
+
+func cellContents(blocks []block) []ctypes.TCBlockContent {
+	contents := make([]ctypes.TCBlockContent, 0, len(blocks))
+	for _, b := range blocks {
+		contents = append(contents, ctypes.TCBlockContent{Paragraph: b.para, Table: b.table})
+	}
+	return contents
+}
+
+// cellLocation locates changes found inside a table cell.
+type cellLocation struct {
+	oldIndex, newIndex int
+	row, col           int
+}
+
+type comparer struct {
+	author  string
+	date    string
+	nextID  int
+	changes []Change
+}
+
+func newComparer(opts *CompareOptions) *comparer {
+	c := &comparer{author: DefaultCompareAuthor}
+	date := time.Now()
+	if opts != nil {
+		if opts.Author != "" {
+			c.author = opts.Author
+		}
+		if !opts.Date.IsZero() {
+			date = opts.Date
+		}
+	}
+	c.date = date.UTC().Format("2006-01-02T15:04:05Z")
+	return c
+}
+
+// revision returns a new run-level revision with the next identifier.
+func (c *comparer) revision() *ctypes.RunTrackChange {
+	c.nextID++
+	tc := ctypes.NewRunTrackChange(c.nextID, c.author)
+	tc.Date = internal.ToPtr(c.date)
+	return tc
+}
+
+// markRevision returns a new revision of a table row or paragraph mark with the next identifier.
+func (c *comparer) markRevision() *ctypes.TrackChange {
+	c.nextID++
+	return &ctypes.TrackChange{ID: c.nextID, Author: c.author, Date: internal.ToPtr(c.date)}
+}
+
+// record appends a change; inside a cell the cell location replaces the block indexes.
+func (c *comparer) record(ch Change, at *cellLocation) {
+	if at != nil {
+		ch.OldIndex, ch.NewIndex, ch.Row, ch.Col = at.oldIndex, at.newIndex, at.row, at.col
+	} else {
+		ch.Row, ch.Col = -1, -1
+	}
+	c.changes = append(c.changes, ch)
+}
+
+// compareBlocks aligns two block sequences and returns the merged redline blocks.
+func (c *comparer) compareBlocks(oldBlocks, newBlocks []block, at *cellLocation) []block {
+	oldKeys := make([]string, len(oldBlocks))
+	for i, b := range oldBlocks {
+		oldKeys[i] = b.key()
+	}
+	newKeys := make([]string, len(newBlocks))
+	for j, b := range newBlocks {
+		newKeys[j] = b.key()
+	}
+
+	ops := align(len(oldBlocks), len(newBlocks), func(i, j int) bool { return oldKeys[i] == newKeys[j] })
+
+	var out []block
+	walkAlignment(ops, func(_, j int) {
+		out = append(out, newBlocks[j])
+	}, func(dels, ins []int) {
+		inserted := func(j int) {
+			c.record(Change{Kind: ChangeInserted, OldIndex: -1, NewIndex: j, NewText: newBlocks[j].text()}, at)
+			out = append(out, c.markBlock(newBlocks[j], DiffInsert))
+		}
+
+		// Each deleted block is paired with the first following inserted block it can be
+		// merged with; inserted blocks skipped over are reported before the pair. Within a
+		// cell, a single replaced paragraph is always reported as modified.
+		force := at != nil && len(dels) == 1 && len(ins) == 1
+		next := 0
+		for _, i := range dels {
+			paired := false
+			for k := next; k < len(ins); k++ {
+				merged, ok := c.modifiedBlock(i, ins[k], oldBlocks[i], newBlocks[ins[k]], at, force)
+				if !ok {
+					continue
+				}
+				for _, j := range ins[next:k] {
+					inserted(j)
+				}
+				out = append(out, merged)
+				next, paired = k+1, true
+				break
+			}
+			if !paired {
+				c.record(Change{Kind: ChangeDeleted, OldIndex: i, NewIndex: -1, OldText: oldBlocks[i].text()}, at)
+				out = append(out, c.markBlock(oldBlocks[i], DiffDelete))
+			}
+		}
+		for _, j := range ins[next:] {
+			inserted(j)
+		}
+	})
+	return out
+}
+
+// modifiedBlock merges two unmatched blocks found at the same position. Unless force is
+// set, it reports false when the blocks are too different to be treated as one modified block.
+func (c *comparer) modifiedBlock(i, j int, oldBlock, newBlock block, at *cellLocation, force bool) (block, bool) {
+	switch {
+	case oldBlock.para != nil && newBlock.para != nil:
+		d := diffParagraphs(oldBlock.para, newBlock.para)
+		if !force && d.similarity() < pairThreshold {
+			return block{}, false
+		}
+		c.record(Change{
+			Kind:     ChangeModified,
+			OldIndex: i,
+			NewIndex: j,
+			OldText:  paragraphText(oldBlock.para),
+			NewText:  paragraphText(newBlock.para),
+			Diff:     d.textDiff(),
+		}, at)
+		return block{para: c.redlineParagraph(newBlock.para.Property, d)}, true
+	case oldBlock.table != nil && newBlock.table != nil:
+		loc := cellLocation{oldIndex: i, newIndex: j}
+		if at != nil {
+			loc = *at
+		}
+		return block{table: c.compareTables(oldBlock.table, newBlock.table, loc, at != nil)}, true
+	}
+	return block{}, false
+}
+
+// markBlock marks the whole block as inserted or deleted.
+func (c *comparer) markBlock(b block, op DiffOp) block {
+	if b.para != nil {
+		return block{para: c.markParagraph(b.para, op)}
+	}
+	return block{table: c.markTable(b.table, op)}
+}
+
+func (c *comparer) markParagraph(p *ctypes.Paragraph, op DiffOp) *ctypes.Paragraph {
+	tokens := paragraphTokens(p)
+	d := &paragraphDiff{ops: make([]editOp, len(tokens))}
+	for k := range tokens {
+		if op == DiffDelete {
+			d.ops[k] = editOp{op: op, a: k, b: -1}
+		} else {
+			d.ops[k] = editOp{op: op, a: -1, b: k}
+		}
+	}
+	if op == DiffDelete {
+		d.oldTokens = tokens
+	} else {
+		d.newTokens = tokens
+	}
+	out := c.redlineParagraph(p.Property, d)
+
+	// The paragraph mark is tracked too, so that accepting or rejecting the change does
+	// not leave an empty paragraph behind.
+	prop := &ctypes.ParagraphProp{}
+	if p.Property != nil {
+		copied := *p.Property
+		prop = &copied
+	}
+	rPr := &ctypes.RunProperty{}
+	if prop.RunProperty != nil {
+		copied := *prop.RunProperty
+		rPr = &copied
+	}
+	if op == DiffDelete {
+		rPr.Del = c.markRevision()
+	} else {
+		rPr.Ins = c.markRevision()
+	}
+	prop.RunProperty = rPr
+	out.Property = prop
+	return out
+}
+
+func (c *comparer) markTable(t *ctypes.Table, op DiffOp) *ctypes.Table {
+	out := *t
+	out.RowContents = make([]ctypes.RowContent, 0, len(t.RowContents))
+	for _, rc := range t.RowContents {
+		if rc.Row != nil {
+			out.RowContents = append(out.RowContents, ctypes.RowContent{Row: c.markRow(rc.Row, op)})
+		}
+	}
+	return &out
+}
+
+// markRow marks the row and the content of all its cells as inserted or deleted.
+func (c *comparer) markRow(row *ctypes.Row, op DiffOp) *ctypes.Row {
+	out := *row
+	prop := ctypes.RowProperty{}
+	if row.Property != nil {
+		prop = *row.Property
+	}
+	if op == DiffDelete {
+		prop.Del = c.markRevision()
+	} else {
+		prop.Ins = c.markRevision()
+	}
+	out.Property = &prop
+
+	out.Contents = make([]ctypes.TRCellContent, 0, len(row.Contents))
+	for _, cc := range row.Contents {
+		if cc.Cell == nil {
+			continue
+		}
+		out.Contents = append(out.Contents, ctypes.TRCellContent{Cell: c.markCell(cc.Cell, op)})
+	}
+	return &out
+}
+
+func (c *comparer) markCell(cell *ctypes.Cell, op DiffOp) *ctypes.Cell {
+	out := *cell
+	blocks := cellBlocks(cell)
+	for k, b := range blocks {
+		blocks[k] = c.markBlock(b, op)
+	}
+	out.Contents = cellContents(blocks)
+	return &out
+}
+
+// compareTables merges two tables row by row. Changes are located in the table cells,
+// unless nested is set, in which case they keep the location of the enclosing cell.
+func (c *comparer) compareTables(oldTable, newTable *ctypes.Table, loc cellLocation, nested bool) *ctypes.Table {
+	oldRows, newRows := tableRows(oldTable), tableRows(newTable)
+	oldKeys := make([]string, len(oldRows))
+	for i, row := range oldRows {
+		oldKeys[i] = rowText(row)
+	}
+	newKeys := make([]string, len(newRows))
+	for j, row := range newRows {
+		newKeys[j] = rowText(row)
+	}
+
+	at := func(row, col int) *cellLocation {
+		if nested {
+			return &loc
+		}
+		return &cellLocation{oldIndex: loc.oldIndex, newIndex: loc.newIndex, row: row, col: col}
+	}
+
+	out := *newTable
+	out.RowContents = nil
+	appendRow := func(row *ctypes.Row) {
+		out.RowContents = append(out.RowContents, ctypes.RowContent{Row: row})
+	}
+
+	ops := align(len(oldRows), len(newRows), func(i, j int) bool { return oldKeys[i] == newKeys[j] })
+	walkAlignment(ops, func(_, j int) {
+		appendRow(newRows[j])
+	}, func(dels, ins []int) {
+		k := 0
+		for ; k < len(dels) && k < len(ins); k++ {
+			appendRow(c.compareRows(oldRows[dels[k]], newRows[ins[k]], ins[k], at))
+		}
+		for _, i := range dels[k:] {
+			c.record(Change{Kind: ChangeDeleted, OldText: oldKeys[i]}, at(i, -1))
+			appendRow(c.markRow(oldRows[i], DiffDelete))
+		}
+		for _, j := range ins[k:] {
+			c.record(Change{Kind: ChangeInserted, NewText: newKeys[j]}, at(j, -1))
+			appendRow(c.markRow(newRows[j], DiffInsert))
+		}
+	})
+	return &out
+}
+
+// compareRows merges two rows cell by cell, matching cells by their position.
+func (c *comparer) compareRows(oldRow, newRow *ctypes.Row, rowIndex int, at func(row, col int) *cellLocation) *ctypes.Row {
+	oldCells, newCells := rowCells(oldRow), rowCells(newRow)
+
+	out := *newRow
+	out.Contents = make([]ctypes.TRCellContent, 0, max(len(newCells), len(oldCells)))
+	for col, cell := range newCells {
+		merged := *cell
+		if col < len(oldCells) {
+			merged.Contents = cellContents(c.compareBlocks(cellBlocks(oldCells[col]), cellBlocks(cell), at(rowIndex, col)))
+		} else {
+			c.record(Change{Kind: ChangeInserted, NewText: cellText(cell)}, at(rowIndex, col))
+			merged = *c.markCell(cell, DiffInsert)
+		}
+		out.Contents = append(out.Contents, ctypes.TRCellContent{Cell: &merged})
+	}
+	// Cells the revised row lost stay in the redline with their content deleted.
+	for col := len(newCells); col < len(oldCells); col++ {
+		c.record(Change{Kind: ChangeDeleted, OldText: cellText(oldCells[col])}, at(rowIndex, col))
+		out.Contents = append(out.Contents, ctypes.TRCellContent{Cell: c.markCell(oldCells[col], DiffDelete)})
+	}
+	return &out
+}
+
+func tableRows(t *ctypes.Table) []*ctypes.Row {
+	rows := make([]*ctypes.Row, 0, len(t.RowContents))
+	for _, rc := range t.RowContents {
+		if rc.Row != nil {
+			rows = append(rows, rc.Row)
+		}
+	}
+	return rows
+}
+
+func rowCells(row *ctypes.Row) []*ctypes.Cell {
+	cells := make([]*ctypes.Cell, 0, len(row.Contents))
+	for _, cc := range row.Contents {
+		if cc.Cell != nil {
+			cells = append(cells, cc.Cell)
+		}
+	}
+	return cells
+}
+
+// diffToken is a word, a run of whitespace, a punctuation mark, an ideograph or a
+// non-text run element (tab, break, drawing).
+type diffToken struct {
+	text  string
+	prop  *ctypes.RunProperty
+	child *ctypes.RunChild
+}
+
+// paragraphDiff is the word-level alignment of two paragraphs.
+type paragraphDiff struct {
+	oldTokens []diffToken
+	newTokens []diffToken
+	ops       []editOp
+}
+
+func diffParagraphs(oldPara, newPara *ctypes.Paragraph) *paragraphDiff {
+	d := &paragraphDiff{oldTokens: paragraphTokens(oldPara), newTokens: paragraphTokens(newPara)}
+	d.ops = align(len(d.oldTokens), len(d.newTokens), func(i, j int) bool {
+		return d.oldTokens[i].text == d.newTokens[j].text
+	})
+	return d
+}
+
+func (d *paragraphDiff) token(op editOp) diffToken {
+	if op.op == DiffDelete {
+		return d.oldTokens[op.a]
+	}
+	return d.newTokens[op.b]
+}
+
+// similarity returns the share of words common to both paragraphs, between 0 and 1.
+// Whitespace is ignored so that unrelated sentences do not look alike.
+func (d *paragraphDiff) similarity() float64 {
+	total, equal := 0, 0
+	for _, op := range d.ops {
+		if strings.TrimSpace(d.token(op).text) == "" {
+			continue
+		}
+		if op.op == DiffEqual {
+			equal += 2
+			total += 2
+		} else {
+			total++
+		}
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(equal) / float64(total)
+}
+
+// textDiff returns the diff as text segments, merging consecutive tokens of one operation.
+func (d *paragraphDiff) textDiff() []TextDiff {
+	var segments []TextDiff
+	for _, op := range d.ops {
+		text := d.token(op).text
+		if n := len(segments); n > 0 && segments[n-1].Op == op.op {
+			segments[n-1].Text += text
+			continue
+		}
+		segments = append(segments, TextDiff{Op: op.op, Text: text})
+	}
+	return segments
+}
+
+// redlineParagraph builds a paragraph from the diff, keeping the formatting of each
+// token's source run and wrapping inserted and deleted runs in revisions.
+func (c *comparer) redlineParagraph(prop *ctypes.ParagraphProp, d *paragraphDiff) *ctypes.Paragraph {
+	out := &ctypes.Paragraph{Property: prop}
+
+	var (
+		tokens []diffToken
+		last   DiffOp
+		rev    *ctypes.RunTrackChange
+	)
+	flush := func() {
+		if len(tokens) == 0 {
+			return
+		}
+		run := tokensRun(tokens, last == DiffDelete)
+		tokens = nil
+		if len(run.Children) == 0 {
+			return
+		}
+		switch last {
+		case DiffEqual:
+			out.Children = append(out.Children, ctypes.ParagraphChild{Run: run})
+			rev = nil
+		case DiffInsert:
+			if rev == nil {
+				rev = c.revision()
+				out.Children = append(out.Children, ctypes.ParagraphChild{Ins: rev})
+			}
+			rev.Runs = append(rev.Runs, run)
+		case DiffDelete:
+			if rev == nil {
+				rev = c.revision()
+				out.Children = append(out.Children, ctypes.ParagraphChild{Del: rev})
+			}
+			rev.Runs = append(rev.Runs, run)
+		}
+	}
+
+	for _, op := range d.ops {
+		tok := d.token(op)
+		if len(tokens) > 0 && (op.op != last || tok.prop != tokens[0].prop) {
+			opChanged := op.op != last
+			flush()
+			if opChanged {
+				rev = nil
+			}
+		}
+		last = op.op
+		tokens = append(tokens, tok)
+	}
+	flush()
+
+	return out
+}
+
+// tokensRun joins tokens sharing the same run properties into a single run.
+func tokensRun(tokens []diffToken, deleted bool) *ctypes.Run {
+	run := &ctypes.Run{Property: tokens[0].prop}
+
+	var sb strings.Builder
+	flushText := func() {
+		if sb.Len() == 0 {
+			return
+		}
+		text := ctypes.TextFromString(sb.String())
+		if deleted {
+			run.Children = append(run.Children, ctypes.RunChild{DelText: text})
+		} else {
+			run.Children = append(run.Children, ctypes.RunChild{Text: text})
+		}
+		sb.Reset()
+	}
+
+	for _, tok := range tokens {
+		if tok.child == nil {
+			sb.WriteString(tok.text)
+			continue
+		}
+		flushText()
+		if deleted && tok.text == objectToken {
+			// Deleted drawings are dropped, their relationships are not part of the redline.
+			continue
+		}
+		run.Children = append(run.Children, *tok.child)
+	}
+	flushText()
+
+	return run
+}
+
+// paragraphTokens splits the visible content of the paragraph into diff tokens.
+// Hyperlinks and existing insertions contribute their runs, existing deletions are ignored.
+func paragraphTokens(p *ctypes.Paragraph) []diffToken {
+	var tokens []diffToken
+	addRun := func(run *ctypes.Run) {
+		for k := range run.Children {
+			rc := &run.Children[k]
+			switch {
+			case rc.Text != nil:
+				for _, word := range splitWords(rc.Text.Text) {
+					tokens = append(tokens, diffToken{text: word, prop: run.Property})
+				}
+			case rc.Tab != nil:
+				tokens = append(tokens, diffToken{text: "\t", prop: run.Property, child: rc})
+			case rc.Break != nil, rc.CarrRtn != nil:
+				tokens = append(tokens, diffToken{text: "\n", prop: run.Property, child: rc})
+			case rc.Drawing != nil, rc.Pict != nil, rc.AlternateContent != nil:
+				tokens = append(tokens, diffToken{text: objectToken, prop: run.Property, child: rc})
+			}
+		}
+	}
+
+	for _, child := range p.Children {
+		switch {
+		case child.Run != nil:
+			addRun(child.Run)
+		case child.Link != nil && child.Link.Run != nil:
+			addRun(child.Link.Run)
+		case child.Ins != nil:
+			for _, run := range child.Ins.Runs {
+				addRun(run)
+			}
+		}
+	}
+	return tokens
+}
+
+const (
+	wordRune = iota
+	spaceRune
+	singleRune
+)
+
+func runeClass(r rune) int {
+	switch {
+	case unicode.IsSpace(r):
+		return spaceRune
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+		// Ideographic scripts do not separate words with spaces.
+		return singleRune
+	case unicode.IsLetter(r), unicode.IsDigit(r), unicode.IsMark(r):
+		return wordRune
+	}
+	return singleRune
+}
+
+// splitWords splits s into words, whitespace runs and single punctuation marks or ideographs.
+func splitWords(s string) []string {
+	var words []string
+	start, class := 0, -1
+	for i, r := range s {
+		rc := runeClass(r)
+		if i > start && (rc != class || rc == singleRune) {
+			words = append(words, s[start:i])
+			start = i
+		}
+		class = rc
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return words
+}
+
+// editOp is one step of an edit script; a and b index the old and new sequences,
+// -1 when the step does not consume an element from that side.
+type editOp struct {
+	op   DiffOp
+	a, b int
+}
+
+// align returns the shortest edit script turning a sequence of n elements into one of m
+// elements, based on their longest common subsequence under eq. It follows the linear
+// space refinement of Myers' O(ND) difference algorithm, so long documents do not need a
+// table of n×m entries.
+func align(n, m int, eq func(i, j int) bool) []editOp {
+	a := &aligner{eq: eq, ops: make([]editOp, 0, n+m)}
+	a.diff(0, n, 0, m)
+	return a.ops
+}
+
+// aligner accumulates the edit script of align.
+type aligner struct {
+	eq  func(i, j int) bool
+	ops []editOp
+}
+
+// diff appends the edit script turning the old elements a0 to a1 into the new elements
+// b0 to b1.
+func (a *aligner) diff(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && a.eq(a0, b0) {
+		a.ops = append(a.ops, editOp{op: DiffEqual, a: a0, b: b0})
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a1-suffix > a0 && b1-suffix > b0 && a.eq(a1-1-suffix, b1-1-suffix) {
+		suffix++
+	}
+	a1 -= suffix
+	b1 -= suffix
+
+	switch {
+	case a0 == a1:
+		for j := b0; j < b1; j++ {
+			a.ops = append(a.ops, editOp{op: DiffInsert, a: -1, b: j})
+		}
+	case b0 == b1:
+		for i := a0; i < a1; i++ {
+			a.ops = append(a.ops, editOp{op: DiffDelete, a: i, b: -1})
+		}
+	default:
+		// Both ranges are non-empty and differ at both ends, so the script has at least
+		// two edits and the middle snake splits it into two smaller problems.
+		x, y, u, v := a.middleSnake(a0, a1, b0, b1)
+		a.diff(a0, x, b0, y)
+		for ; x < u; x, y = x+1, y+1 {
+			a.ops = append(a.ops, editOp{op: DiffEqual, a: x, b: y})
+		}
+		a.diff(u, a1, v, b1)
+	}
+
+	for k := suffix; k > 0; k-- {
+		a.ops = append(a.ops, editOp{op: DiffEqual, a: a1 + suffix - k, b: b1 + suffix - k})
+	}
+}
+
+// middleSnake finds the diagonal run of equal elements, from (x, y) to (u, v), in the
+// middle of a shortest edit script of the ranges, by searching from both ends at once.
+func (a *aligner) middleSnake(a0, a1, b0, b1 int) (x, y, u, v int) {
+	n, m := a1-a0, b1-b0
+	delta := n - m
+	odd := delta%2 != 0
+	limit := (n + m + 1) / 2
+	offset := limit + 1
+
+	// forward[k] is the furthest old index reached on diagonal k = x-y from the start;
+	// backward[k] the furthest distance from the end on diagonal k = (n-x)-(m-y).
+	forward := make([]int, 2*limit+3)
+	backward := make([]int, 2*limit+3)
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			i := forward[offset+k-1] + 1
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				i = forward[offset+k+1]
+			}
+			j := i - k
+			si, sj := i, j
+			for i < n && j < m && a.eq(a0+i, b0+j) {
+				i++
+				j++
+			}
+			forward[offset+k] = i
+			if back := delta - k; odd && back >= -(d-1) && back <= d-1 && i+backward[offset+back] >= n {
+				return a0 + si, b0 + sj, a0 + i, b0 + j
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			i := backward[offset+k-1] + 1
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				i = backward[offset+k+1]
+			}
+			j := i - k
+			si, sj := i, j
+			for i < n && j < m && a.eq(a1-1-i, b1-1-j) {
+				i++
+				j++
+			}
+			backward[offset+k] = i
+			if front := delta - k; !odd && front >= -d && front <= d && i+forward[offset+front] >= n {
+				return a1 - i, b1 - j, a1 - si, b1 - sj
+			}
+		}
+	}
+	// Unreachable: the searches always meet within limit steps.
+	return a0, b0, a0, b0
+}
+
+// walkAlignment calls equal for every aligned pair and gap for every maximal stretch of
+// unaligned elements, with the deleted and inserted indexes of that stretch.
+func walkAlignment(ops []editOp, equal func(i, j int), gap func(dels, ins []int)) {
+	var dels, ins []int
+	flush := func() {
+		if len(dels) > 0 || len(ins) > 0 {
+			gap(dels, ins)
+		}
+		dels, ins = nil, nil
+	}
+	for _, op := range ops {
+		switch op.op {
+		case DiffEqual:
+			flush()
+			equal(op.a, op.b)
+		case DiffDelete:
+			dels = append(dels, op.a)
+		case DiffInsert:
+			ins = append(ins, op.b)
+		}
+	}
+	flush()
+}
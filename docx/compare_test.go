@@ -0,0 +1,212 @@
+package docx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addTestTable(rd *docx.RootDoc, rows ...[]string) {
+	tbl := rd.AddTable()
+	for _, cells := range rows {
+		row := tbl.AddRow()
+		for _, text := range cells {
+			row.AddCell().AddParagraph(text)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	original, err := godocx.NewDocument()
+	require.NoError(t, err)
+	original.AddParagraph("Title")
+	original.AddParagraph("The quick brown fox jumps.")
+	original.AddParagraph("This paragraph goes away entirely.")
+	addTestTable(original, []string{"Name", "Qty"}, []string{"Apple", "1"})
+
+	revised, err := godocx.NewDocument()
+	require.NoError(t, err)
+	revised.AddParagraph("Title")
+	revised.AddParagraph("The quick red fox jumps.")
+	addTestTable(revised, []string{"Name", "Qty"}, []string{"Apple", "2"}, []string{"Pear", "5"})
+	revised.AddParagraph("A brand new closing line.")
+
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	result, err := docx.Compare(original, revised, &docx.CompareOptions{Date: date})
+	require.NoError(t, err)
+
+	require.Len(t, result.Changes, 5)
+
+	modified := result.Changes[0]
+	assert.Equal(t, docx.ChangeModified, modified.Kind)
+	assert.Equal(t, 1, modified.OldIndex)
+	assert.Equal(t, 1, modified.NewIndex)
+	assert.Equal(t, -1, modified.Row)
+	assert.Equal(t, []docx.TextDiff{
+		{Op: docx.DiffEqual, Text: "The quick "},
+		{Op: docx.DiffDelete, Text: "brown"},
+		{Op: docx.DiffInsert, Text: "red"},
+		{Op: docx.DiffEqual, Text: " fox jumps."},
+	}, modified.Diff)
+
+	deleted := result.Changes[1]
+	assert.Equal(t, docx.ChangeDeleted, deleted.Kind)
+	assert.Equal(t, "This paragraph goes away entirely.", deleted.OldText)
+	assert.Equal(t, -1, deleted.NewIndex)
+
+	cell := result.Changes[2]
+	assert.Equal(t, docx.ChangeModified, cell.Kind)
+	assert.Equal(t, 1, cell.Row)
+	assert.Equal(t, 1, cell.Col)
+	assert.Equal(t, "1", cell.OldText)
+	assert.Equal(t, "2", cell.NewText)
+
+	row := result.Changes[3]
+	assert.Equal(t, docx.ChangeInserted, row.Kind)
+	assert.Equal(t, 2, row.Row)
+	assert.Equal(t, -1, row.Col)
+	assert.Equal(t, "Pear\t5", row.NewText)
+
+	assert.Equal(t, docx.ChangeInserted, result.Changes[4].Kind)
+	assert.Equal(t, 3, result.Changes[4].NewIndex)
+
+	var buf bytes.Buffer
+	require.NoError(t, result.Redline.Write(&buf))
+	content := buf.Bytes()
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	f, err := zr.Open("word/document.xml")
+	require.NoError(t, err)
+	docXML, err := io.ReadAll(f)
+	require.NoError(t, err)
+	xmlText := string(docXML)
+	assert.Contains(t, xmlText, `<w:del w:id="1" w:author="Compare" w:date="2024-01-02T03:04:05Z"><w:r><w:delText>brown</w:delText></w:r></w:del>`)
+	assert.Contains(t, xmlText, `<w:ins w:id="2" w:author="Compare" w:date="2024-01-02T03:04:05Z"><w:r><w:t>red</w:t></w:r></w:ins>`)
+	assert.Contains(t, xmlText, `<w:delText>This paragraph goes away entirely.</w:delText>`)
+	assert.Contains(t, xmlText, `<w:trPr><w:ins w:id="`)
+
+	// Unchanged content and the visible text of the revised document are kept.
+	var texts []string
+	for _, child := range reopened.Document.Body.Children {
+		if child.Para != nil {
+			texts = append(texts, child.Para.Text())
+		}
+	}
+	assert.Equal(t, []string{"Title", "The quick red fox jumps.", "", "A brand new closing line."}, texts)
+
+	// The paragraph marks of whole inserted and deleted paragraphs are tracked too.
+	children := reopened.Document.Body.Children
+	require.Len(t, children, 5)
+	mark := children[2].Para.GetCT().Property
+	require.NotNil(t, mark)
+	require.NotNil(t, mark.RunProperty)
+	require.NotNil(t, mark.RunProperty.Del)
+	assert.Equal(t, "Compare", mark.RunProperty.Del.Author)
+	mark = children[4].Para.GetCT().Property
+	require.NotNil(t, mark)
+	require.NotNil(t, mark.RunProperty)
+	assert.NotNil(t, mark.RunProperty.Ins)
+	assert.Nil(t, children[1].Para.GetCT().Property)
+	assert.Regexp(t, `<w:pPr><w:rPr><w:del w:id="\d+" w:author="Compare" w:date="2024-01-02T03:04:05Z"></w:del></w:rPr></w:pPr>`, xmlText)
+
+	// The inputs are untouched.
+	assert.Len(t, original.Document.Body.Children, 4)
+	assert.Len(t, revised.Document.Body.Children, 4)
+}
+
+func TestCompareIdeographs(t *testing.T) {
+	original, err := godocx.NewDocument()
+	require.NoError(t, err)
+	original.AddParagraph("今天天气很好")
+
+	revised, err := godocx.NewDocument()
+	require.NoError(t, err)
+	revised.AddParagraph("今天天气不好")
+
+	result, err := docx.Compare(original, revised, &docx.CompareOptions{Author: "Reviewer"})
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, []docx.TextDiff{
+		{Op: docx.DiffEqual, Text: "今天天气"},
+		{Op: docx.DiffDelete, Text: "很"},
+		{Op: docx.DiffInsert, Text: "不"},
+		{Op: docx.DiffEqual, Text: "好"},
+	}, result.Changes[0].Diff)
+}
+
+func TestCompareLongDocument(t *testing.T) {
+	original, err := godocx.NewDocument()
+	require.NoError(t, err)
+	revised, err := godocx.NewDocument()
+	require.NoError(t, err)
+	for i := 0; i < 2000; i++ {
+		text := fmt.Sprintf("Clause %d applies.", i)
+		original.AddParagraph(text)
+		switch i {
+		case 500:
+			revised.AddParagraph("An inserted clause.")
+			revised.AddParagraph(text)
+		case 1500:
+		default:
+			revised.AddParagraph(text)
+		}
+	}
+
+	result, err := docx.Compare(original, revised, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 2)
+	assert.Equal(t, docx.ChangeInserted, result.Changes[0].Kind)
+	assert.Equal(t, 500, result.Changes[0].NewIndex)
+	assert.Equal(t, docx.ChangeDeleted, result.Changes[1].Kind)
+	assert.Equal(t, 1500, result.Changes[1].OldIndex)
+}
+
+func TestCompareRemovedCell(t *testing.T) {
+	original, err := godocx.NewDocument()
+	require.NoError(t, err)
+	addTestTable(original, []string{"Name", "Qty", "Note"})
+
+	revised, err := godocx.NewDocument()
+	require.NoError(t, err)
+	addTestTable(revised, []string{"Name", "Qty"})
+	revised.AddParagraph("item").Numbering(revised.NewListInstance(1), 0)
+	numbering, _ := revised.FileMap.Load("word/numbering.xml")
+
+	result, err := docx.Compare(original, revised, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 2)
+	assert.Equal(t, docx.ChangeDeleted, result.Changes[0].Kind)
+	assert.Equal(t, 2, result.Changes[0].Col)
+	assert.Equal(t, "Note", result.Changes[0].OldText)
+
+	// The removed cell stays in the redline with its text deleted.
+	var table *docx.Table
+	for _, child := range result.Redline.Document.Body.Children {
+		if child.Table != nil {
+			table = child.Table
+		}
+	}
+	require.NotNil(t, table)
+	cells := table.Rows()[0].Cells()
+	require.Len(t, cells, 3)
+	del := cells[2].GetCT().Contents[0].Paragraph.Children[0].Del
+	require.NotNil(t, del)
+	require.Len(t, del.Runs, 1)
+	assert.Equal(t, "Note", del.Runs[0].Children[0].DelText.Text)
+
+	// Pending numbering of the revised document is not written to it.
+	after, _ := revised.FileMap.Load("word/numbering.xml")
+	assert.Equal(t, numbering, after)
+}
@@ -25,7 +25,14 @@ type Override struct {
 	ContentType string `xml:"ContentType,attr"`
 }
 
+// AddExtension registers the content type of the parts with the given extension. An
+// extension that is already registered is left unchanged.
 func (c *ContentTypes) AddExtension(extension, contentType string) error {
+	for _, d := range c.Default {
+		if strings.EqualFold(d.Extension, extension) {
+			return nil
+		}
+	}
 	c.Default = append(c.Default, Default{
 		Extension:   extension,
 		ContentType: contentType,
@@ -34,7 +41,14 @@ func (c *ContentTypes) AddExtension(extension, contentType string) error {
 	return nil
 }
 
+// AddOverride sets the content type of a part, replacing any previous override of the part.
 func (c *ContentTypes) AddOverride(partName, contentType string) error {
+	for i := range c.Override {
+		if c.Override[i].PartName == partName {
+			c.Override[i].ContentType = contentType
+			return nil
+		}
+	}
 	c.Override = append(c.Override, Override{
 		PartName:    partName,
 		ContentType: contentType,
@@ -42,6 +56,17 @@ func (c *ContentTypes) AddOverride(partName, contentType string) error {
 	return nil
 }
 
+// RemoveOverride removes the override entry for the given part name, if present.
+func (c *ContentTypes) RemoveOverride(partName string) {
+	overrides := c.Override[:0]
+	for _, o := range c.Override {
+		if o.PartName != partName {
+			overrides = append(overrides, o)
+		}
+	}
+	c.Override = overrides
+}
+
 func MIMEFromExt(extension string) (string, error) {
 	if strings.HasPrefix(extension, ".") {
 		extension = strings.TrimPrefix(extension, ".")
@@ -0,0 +1,221 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iEvan-lhr/docx-agent/common/constants"
+)
+
+const (
+	customPropsContentType = "application/vnd.openxmlformats-officedocument.custom-properties+xml"
+
+	// customPropsFmtID is the format identifier Word gives every custom property.
+	customPropsFmtID = "{D5CDD505-2E9C-101B-9397-08002B2CF9AE}"
+)
+
+// CustomProperty is a user defined property of the document.
+//
+// Value is a string, int, float64, bool or time.Time according to the variant type of
+// the property. Properties of other variant types read as their text.
+type CustomProperty struct {
+	Name  string
+	Value any
+}
+
+// ctCustomProperties is the structure of the custom properties part.
+type ctCustomProperties struct {
+	XMLName    xml.Name           `xml:"http://schemas.openxmlformats.org/officeDocument/2006/custom-properties Properties"`
+	VT         string             `xml:"xmlns:vt,attr"`
+	Properties []ctCustomProperty `xml:"property"`
+}
+
+type ctCustomProperty struct {
+	FmtID      string    `xml:"fmtid,attr"`
+	PID        int       `xml:"pid,attr"`
+	Name       string    `xml:"name,attr"`
+	LinkTarget string    `xml:"linkTarget,attr,omitempty"`
+	Variant    ctVariant `xml:",any"`
+}
+
+// ctVariant is the value of a custom property. Its content is kept as raw XML so that
+// variants which are not modelled, such as vectors, are written back unchanged.
+type ctVariant struct {
+	XMLName xml.Name
+	Text    string `xml:",chardata"`
+	Inner   string `xml:",innerxml"`
+}
+
+// kind returns the variant type, such as "lpwstr" or "i4".
+func (v ctVariant) kind() string {
+	return strings.TrimPrefix(v.XMLName.Local, "vt:")
+}
+
+// value returns the Go value of the variant.
+func (v ctVariant) value() (any, error) {
+	text := strings.TrimSpace(v.Text)
+	switch v.kind() {
+	case "i1", "i2", "i4", "i8", "int", "ui1", "ui2", "ui4", "ui8", "uint":
+		return strconv.Atoi(text)
+	case "r4", "r8", "decimal":
+		return strconv.ParseFloat(text, 64)
+	case "bool":
+		return strconv.ParseBool(text)
+	case "filetime", "date":
+		return parseW3CDTF(text)
+	}
+	return v.Text, nil
+}
+
+// newVariant returns the variant of a Go value.
+func newVariant(value any) (ctVariant, error) {
+	var kind, text string
+	switch v := value.(type) {
+	case string:
+		kind, text = "lpwstr", v
+	case int:
+		kind, text = "i4", strconv.Itoa(v)
+		if v < math.MinInt32 || v > math.MaxInt32 {
+			kind = "i8"
+		}
+	case float64:
+		kind, text = "r8", strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		kind, text = "bool", strconv.FormatBool(v)
+	case time.Time:
+		kind, text = "filetime", v.UTC().Format(w3cdtf)
+	default:
+		return ctVariant{}, fmt.Errorf("unsupported custom property value of type %T", value)
+	}
+
+	var buf bytes.Buffer
+	if err := xml.EscapeText(&buf, []byte(text)); err != nil {
+		return ctVariant{}, err
+	}
+	return ctVariant{XMLName: xml.Name{Local: kind}, Inner: buf.String()}, nil
+}
+
+// MarshalXML writes the variant in the vt namespace.
+func (v ctVariant) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.Encode(struct {
+		XMLName xml.Name
+		Inner   string `xml:",innerxml"`
+	}{xml.Name{Local: "vt:" + v.kind()}, v.Inner})
+}
+
+// loadCustomProps decodes the custom properties part of the document, which is empty
+// when the document has none.
+func (rd *RootDoc) loadCustomProps() (*ctCustomProperties, error) {
+	props := new(ctCustomProperties)
+	content := rd.loadRootPart(constants.CUSTOM_PROP_TYPE)
+	if content == nil {
+		return props, nil
+	}
+	if err := xmlNewDecoder(bytes.NewReader(constants.TranslateNamespace(content))).
+		Decode(props); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return props, nil
+}
+
+// storeCustomProps writes the custom properties part of the document, adding it when the
+// document has none.
+func (rd *RootDoc) storeCustomProps(props *ctCustomProperties) error {
+	props.XMLName = xml.Name{}
+	props.VT = constants.NameSpaceDocumentPropertiesVariantTypes.Value
+	content, err := marshal(props)
+	if err != nil {
+		return err
+	}
+	path, err := rd.ensureRootPart(constants.CUSTOM_PROP_TYPE, "docProps/custom.xml", customPropsContentType)
+	if err != nil {
+		return err
+	}
+	rd.FileMap.Store(path, content)
+	return nil
+}
+
+// CustomProps returns the custom properties of the document in the order they are stored.
+func (rd *RootDoc) CustomProps() ([]CustomProperty, error) {
+	props, err := rd.loadCustomProps()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]CustomProperty, 0, len(props.Properties))
+	for _, p := range props.Properties {
+		value, err := p.Variant.value()
+		if err != nil {
+			return nil, fmt.Errorf("custom property %q: %w", p.Name, err)
+		}
+		result = append(result, CustomProperty{Name: p.Name, Value: value})
+	}
+	return result, nil
+}
+
+// SetCustomProp sets a custom property of the document, replacing the value of an
+// existing property of the same name. The value must be a string, int, float64, bool or
+// time.Time.
+//
+// Example:
+//
+//	document.SetCustomProp("Client", "Contoso")
+//	document.SetCustomProp("Approved", true)
+func (rd *RootDoc) SetCustomProp(name string, value any) error {
+	if name == "" {
+		return fmt.Errorf("custom property name is empty")
+	}
+	variant, err := newVariant(value)
+	if err != nil {
+		return err
+	}
+	props, err := rd.loadCustomProps()
+	if err != nil {
+		return err
+	}
+
+	pid := 1
+	for i, p := range props.Properties {
+		if p.Name == name {
+			props.Properties[i].Variant = variant
+			return rd.storeCustomProps(props)
+		}
+		pid = max(pid, p.PID)
+	}
+
+	// Identifiers 0 and 1 are reserved, so custom properties are numbered from 2.
+	props.Properties = append(props.Properties, ctCustomProperty{
+		FmtID:   customPropsFmtID,
+		PID:     pid + 1,
+		Name:    name,
+		Variant: variant,
+	})
+	return rd.storeCustomProps(props)
+}
+
+// DeleteCustomProp removes the custom property of the given name. It does nothing when
+// the document has no such property.
+func (rd *RootDoc) DeleteCustomProp(name string) error {
+	props, err := rd.loadCustomProps()
+	if err != nil {
+		return err
+	}
+
+	kept := props.Properties[:0]
+	for _, p := range props.Properties {
+		if p.Name != name {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == len(props.Properties) {
+		return nil
+	}
+	props.Properties = kept
+	return rd.storeCustomProps(props)
+}
@@ -0,0 +1,474 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/iEvan-lhr/docx-agent/common/units"
+	"github.com/iEvan-lhr/docx-agent/dml"
+	"github.com/iEvan-lhr/docx-agent/dml/dmlct"
+	"github.com/iEvan-lhr/docx-agent/dml/dmlst"
+	"github.com/iEvan-lhr/docx-agent/internal"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+)
+
+// WrapMode is how text flows around a floating picture.
+type WrapMode string
+
+const (
+	WrapSquare        WrapMode = "square"        // Around the bounding box
+	WrapTight         WrapMode = "tight"         // Tight around the picture outline
+	WrapThrough       WrapMode = "through"       // Through transparent areas of the picture
+	WrapTopAndBottom  WrapMode = "topAndBottom"  // Above and below only
+	WrapBehindText    WrapMode = "behindText"    // No wrapping, picture behind the text
+	WrapInFrontOfText WrapMode = "inFrontOfText" // No wrapping, picture in front of the text
+)
+
+// baseRelativeHeight is the z-order Word gives the first floating object of a document.
+const baseRelativeHeight = 251658240
+
+// AnchorOptions positions a floating picture.
+//
+// The picture is placed at OffsetX and OffsetY from the left and top of the base given by
+// RelativeFromH and RelativeFromV, unless AlignH or AlignV is set, in which case it is
+// aligned within that base instead.
+type AnchorOptions struct {
+	// RelativeFromH defaults to column; RelativeFromV to paragraph.
+	RelativeFromH dmlst.RelFromH
+	RelativeFromV dmlst.RelFromV
+
+	OffsetX units.Inch
+	OffsetY units.Inch
+
+	AlignH dmlst.AlignH
+	AlignV dmlst.AlignV
+
+	// Wrap defaults to WrapSquare. WrapText chooses the sides text flows along for the
+	// square, tight and through modes and defaults to both sides.
+	Wrap     WrapMode
+	WrapText dmlst.WrapText
+
+	// Minimum distance between the picture and the surrounding text.
+	DistTop    units.Inch
+	DistBottom units.Inch
+	DistLeft   units.Inch
+	DistRight  units.Inch
+
+	// ZOrder is the relative height of the picture among the floating objects of the
+	// document; higher values are drawn on top. Zero keeps the current order of an already
+	// floating picture, or places a new one above all others.
+	ZOrder int
+
+	// Locked prevents the anchor from moving to another paragraph when the picture is
+	// moved in an editor.
+	Locked bool
+}
+
+// AddFloatingPictureFromBytes adds image data to the paragraph as a floating picture. The
+// image is sized as by AddPictureFromBytes and positioned by anchor, which may be nil for a
+// picture with square wrapping at the start of the paragraph.
+func (p *Paragraph) AddFloatingPictureFromBytes(data []byte, opts *PictureOptions, anchor *AnchorOptions) (*PicMeta, error) {
+	pm, err := p.AddPictureFromBytes(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := pm.Float(anchor); err != nil {
+		pm.Para.removeDrawing(pm.drawing)
+		return nil, err
+	}
+	return pm, nil
+}
+
+// AddFloatingPictureFromBytes adds image data to the document as a floating picture
+// anchored to a new paragraph. See Paragraph.AddFloatingPictureFromBytes.
+func (rd *RootDoc) AddFloatingPictureFromBytes(data []byte, opts *PictureOptions, anchor *AnchorOptions) (*PicMeta, error) {
+	p := newParagraph(rd)
+
+	pic, err := p.AddFloatingPictureFromBytes(data, opts, anchor)
+	if err != nil {
+		return nil, err
+	}
+
+	rd.Document.Body.Children = append(rd.Document.Body.Children, DocumentChild{Para: p})
+	return pic, nil
+}
+
+// Pictures returns the pictures of the paragraph, inline and floating, in document order.
+func (p *Paragraph) Pictures() []*PicMeta {
+	var pics []*PicMeta
+	for _, run := range paragraphRuns(&p.ct) {
+		for _, rc := range run.Children {
+			d := rc.Drawing
+			if d == nil {
+				continue
+			}
+			for i := range d.Inline {
+				if isPicture(&d.Inline[i].Graphic) {
+					pics = append(pics, &PicMeta{Para: p, Inline: &d.Inline[i], drawing: d})
+				}
+			}
+			for _, a := range d.Anchor {
+				if a != nil && isPicture(&a.Graphic) {
+					pics = append(pics, &PicMeta{Para: p, Anchor: a, drawing: d})
+				}
+			}
+		}
+	}
+	return pics
+}
+
+// IsFloating reports whether the picture is anchored rather than inline.
+func (pm *PicMeta) IsFloating() bool {
+	return pm.Anchor != nil
+}
+
+// Float turns an inline picture into a floating one positioned by opts, or repositions a
+// picture that is already floating. opts may be nil for square wrapping at the start of
+// the paragraph.
+func (pm *PicMeta) Float(opts *AnchorOptions) error {
+	if opts == nil {
+		opts = &AnchorOptions{}
+	}
+	if pm.drawing == nil || (pm.Inline == nil && pm.Anchor == nil) {
+		return errors.New("picture is not part of a drawing")
+	}
+
+	if pm.Anchor != nil {
+		return pm.Para.root.applyAnchorOptions(pm.Anchor, opts)
+	}
+
+	inline := pm.Inline
+	anchor := &dml.Anchor{
+		SimplePosAttr:     internal.ToPtr(0),
+		LayoutInCell:      1,
+		AllowOverlap:      1,
+		Extent:            inline.Extent,
+		EffectExtent:      inline.EffectExtent,
+		DocProp:           inline.DocProp,
+		CNvGraphicFramePr: inline.CNvGraphicFramePr,
+		Graphic:           inline.Graphic,
+	}
+	if anchor.EffectExtent == nil {
+		anchor.EffectExtent = dml.NewEffectExtent(0, 0, 0, 0)
+	}
+	if err := pm.Para.root.applyAnchorOptions(anchor, opts); err != nil {
+		return err
+	}
+
+	if !removeInline(pm.drawing, inline) {
+		return errors.New("picture is not part of its drawing")
+	}
+	pm.drawing.Anchor = append(pm.drawing.Anchor, anchor)
+	pm.Inline = nil
+	pm.Anchor = anchor
+	return nil
+}
+
+// MakeInline turns a floating picture back into an inline one, keeping its size,
+// description and image. It does nothing for a picture that is already inline.
+func (pm *PicMeta) MakeInline() error {
+	if pm.Anchor == nil {
+		return nil
+	}
+	if pm.drawing == nil {
+		return errors.New("picture is not part of a drawing")
+	}
+
+	anchor := pm.Anchor
+	index := -1
+	for i, a := range pm.drawing.Anchor {
+		if a == anchor {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return errors.New("picture is not part of its drawing")
+	}
+
+	inline := dml.NewInline(anchor.Extent, anchor.DocProp, anchor.Graphic)
+	if anchor.CNvGraphicFramePr != nil {
+		inline.CNvGraphicFramePr = anchor.CNvGraphicFramePr
+	}
+	if ee := anchor.EffectExtent; ee != nil && *ee != (dml.EffectExtent{}) {
+		inline.EffectExtent = ee
+	}
+
+	pm.drawing.Anchor = append(pm.drawing.Anchor[:index], pm.drawing.Anchor[index+1:]...)
+	pm.drawing.Inline = append(pm.drawing.Inline, inline)
+	pm.Anchor = nil
+	pm.Inline = &pm.drawing.Inline[len(pm.drawing.Inline)-1]
+	return nil
+}
+
+// SetZOrder sets the relative height of a floating picture; higher values are drawn on
+// top of lower ones.
+func (pm *PicMeta) SetZOrder(z int) error {
+	if pm.Anchor == nil {
+		return errors.New("only floating pictures have a z-order")
+	}
+	if z < 0 {
+		return errors.New("z-order must not be negative")
+	}
+	pm.Anchor.RelativeHeight = z
+	return nil
+}
+
+// BringToFront places a floating picture above every other floating object of the
+// document body.
+func (pm *PicMeta) BringToFront() error {
+	if pm.Anchor == nil {
+		return errors.New("only floating pictures have a z-order")
+	}
+	pm.Anchor.RelativeHeight = pm.Para.root.nextRelativeHeight(pm.Anchor)
+	return nil
+}
+
+// SendToBack places a floating picture below every other floating object of the document
+// body.
+func (pm *PicMeta) SendToBack() error {
+	if pm.Anchor == nil {
+		return errors.New("only floating pictures have a z-order")
+	}
+
+	anchors := pm.Para.root.bodyAnchors()
+	lowest := -1
+	for _, a := range anchors {
+		if a != pm.Anchor && (lowest < 0 || a.RelativeHeight < lowest) {
+			lowest = a.RelativeHeight
+		}
+	}
+	switch {
+	case lowest < 0:
+		// The only floating object
+	case lowest > 0:
+		pm.Anchor.RelativeHeight = lowest - 1
+	default:
+		// Make room below the lowest object.
+		for _, a := range anchors {
+			if a != pm.Anchor {
+				a.RelativeHeight++
+			}
+		}
+		pm.Anchor.RelativeHeight = 0
+	}
+	return nil
+}
+
+// applyAnchorOptions sets the position, wrapping and z-order of an anchor.
+func (rd *RootDoc) applyAnchorOptions(a *dml.Anchor, opts *AnchorOptions) error {
+	relH, relV := opts.RelativeFromH, opts.RelativeFromV
+	if relH == "" {
+		relH = dmlst.RelFromHColumn
+	} else if _, err := dmlst.RelFromHFromStr(string(relH)); err != nil {
+		return err
+	}
+	if relV == "" {
+		relV = dmlst.RelFromVParagraph
+	} else if _, err := dmlst.RelFromVFromStr(string(relV)); err != nil {
+		return err
+	}
+
+	posH := dml.PoistionH{RelativeFrom: relH, PosOffset: int(opts.OffsetX.ToEmu())}
+	if opts.AlignH != "" {
+		align, err := dmlst.AlignHFromStr(string(opts.AlignH))
+		if err != nil {
+			return err
+		}
+		posH.Align = &align
+	}
+	posV := dml.PoistionV{RelativeFrom: relV, PosOffset: int(opts.OffsetY.ToEmu())}
+	if opts.AlignV != "" {
+		align, err := dmlst.AlignVFromStr(string(opts.AlignV))
+		if err != nil {
+			return err
+		}
+		posV.Align = &align
+	}
+
+	if opts.DistTop < 0 || opts.DistBottom < 0 || opts.DistLeft < 0 || opts.DistRight < 0 {
+		return errors.New("distance from text must not be negative")
+	}
+	if opts.ZOrder < 0 {
+		return errors.New("z-order must not be negative")
+	}
+
+	switch opts.Wrap {
+	case "", WrapSquare, WrapTight, WrapThrough, WrapTopAndBottom, WrapBehindText, WrapInFrontOfText:
+	default:
+		return fmt.Errorf("invalid wrap mode %q", opts.Wrap)
+	}
+	wrapText := opts.WrapText
+	if wrapText == "" {
+		wrapText = dmlst.WrapTextBothSides
+	} else if _, err := dmlst.WrapTextFromStr(string(wrapText)); err != nil {
+		return err
+	}
+
+	a.WrapNone, a.WrapSquare, a.WrapTight, a.WrapThrough, a.WrapTopBtm = nil, nil, nil, nil, nil
+	a.BehindDoc = 0
+	switch opts.Wrap {
+	case "", WrapSquare:
+		a.WrapSquare = &dml.WrapSquare{WrapText: wrapText}
+	case WrapTight:
+		a.WrapTight = &dml.WrapTight{WrapText: wrapText, WrapPolygon: boundingPolygon()}
+	case WrapThrough:
+		a.WrapThrough = &dml.WrapThrough{WrapText: wrapText, WrapPolygon: boundingPolygon()}
+	case WrapTopAndBottom:
+		a.WrapTopBtm = &dml.WrapTopBtm{}
+	case WrapBehindText:
+		a.WrapNone = &dml.WrapNone{}
+		a.BehindDoc = 1
+	case WrapInFrontOfText:
+		a.WrapNone = &dml.WrapNone{}
+	}
+
+	a.PositionH = posH
+	a.PositionV = posV
+	a.DistT = uint(opts.DistTop.ToEmu())
+	a.DistB = uint(opts.DistBottom.ToEmu())
+	a.DistL = uint(opts.DistLeft.ToEmu())
+	a.DistR = uint(opts.DistRight.ToEmu())
+	a.Locked = 0
+	if opts.Locked {
+		a.Locked = 1
+	}
+
+	switch {
+	case opts.ZOrder > 0:
+		a.RelativeHeight = opts.ZOrder
+	case a.RelativeHeight == 0:
+		a.RelativeHeight = rd.nextRelativeHeight(a)
+	}
+	return nil
+}
+
+// nextRelativeHeight returns a z-order above every floating object of the document body
+// other than self.
+func (rd *RootDoc) nextRelativeHeight(self *dml.Anchor) int {
+	next := baseRelativeHeight
+	for _, a := range rd.bodyAnchors() {
+		if a != self && a.RelativeHeight >= next {
+			next = a.RelativeHeight + 1
+		}
+	}
+	return next
+}
+
+// bodyAnchors returns the floating drawings of the document body, including those in
+// tables.
+func (rd *RootDoc) bodyAnchors() []*dml.Anchor {
+	var anchors []*dml.Anchor
+	collect := func(p *ctypes.Paragraph) {
+		for _, run := range paragraphRuns(p) {
+			for _, rc := range run.Children {
+				if rc.Drawing == nil {
+					continue
+				}
+				for _, a := range rc.Drawing.Anchor {
+					if a != nil {
+						anchors = append(anchors, a)
+					}
+				}
+			}
+		}
+	}
+
+	var walkTable func(t *ctypes.Table)
+	walkTable = func(t *ctypes.Table) {
+		for _, rc := range t.RowContents {
+			if rc.Row == nil {
+				continue
+			}
+			for _, cc := range rc.Row.Contents {
+				if cc.Cell == nil {
+					continue
+				}
+				for _, block := range cc.Cell.Contents {
+					switch {
+					case block.Paragraph != nil:
+						collect(block.Paragraph)
+					case block.Table != nil:
+						walkTable(block.Table)
+					}
+				}
+			}
+		}
+	}
+
+	if rd.Document == nil || rd.Document.Body == nil {
+		return nil
+	}
+	for _, child := range rd.Document.Body.Children {
+		switch {
+		case child.Para != nil:
+			collect(&child.Para.ct)
+		case child.Table != nil:
+			walkTable(&child.Table.ct)
+		}
+	}
+	return anchors
+}
+
+// paragraphRuns returns the runs of a paragraph, including those of hyperlinks and
+// tracked insertions, in document order.
+func paragraphRuns(p *ctypes.Paragraph) []*ctypes.Run {
+	var runs []*ctypes.Run
+	for _, child := range p.Children {
+		switch {
+		case child.Run != nil:
+			runs = append(runs, child.Run)
+		case child.Link != nil && child.Link.Run != nil:
+			runs = append(runs, child.Link.Run)
+		case child.Ins != nil:
+			runs = append(runs, child.Ins.Runs...)
+		}
+	}
+	return runs
+}
+
+// isPicture reports whether a graphic holds a picture.
+func isPicture(g *dml.Graphic) bool {
+	return g.Data != nil && g.Data.Pic != nil
+}
+
+// removeInline removes an inline from its drawing and reports whether it was found.
+func removeInline(d *dml.Drawing, inline *dml.Inline) bool {
+	for i := range d.Inline {
+		if &d.Inline[i] == inline {
+			d.Inline = append(d.Inline[:i], d.Inline[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// removeDrawing removes the run holding drawing from the paragraph.
+func (p *Paragraph) removeDrawing(drawing *dml.Drawing) {
+	for i, child := range p.ct.Children {
+		if child.Run == nil {
+			continue
+		}
+		for _, rc := range child.Run.Children {
+			if rc.Drawing == drawing {
+				p.ct.Children = append(p.ct.Children[:i], p.ct.Children[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// boundingPolygon returns a wrapping polygon following the bounding box of the picture, in
+// the 21600-unit coordinate space of wrap polygons.
+func boundingPolygon() dml.WrapPolygon {
+	return dml.WrapPolygon{
+		Edited: internal.ToPtr(false),
+		Start:  dmlct.NewPoint2D(0, 0),
+		LineTo: []dmlct.Point2D{
+			dmlct.NewPoint2D(0, 21600),
+			dmlct.NewPoint2D(21600, 21600),
+			dmlct.NewPoint2D(21600, 0),
+			dmlct.NewPoint2D(0, 0),
+		},
+	}
+}
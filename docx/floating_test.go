@@ -0,0 +1,103 @@
+package docx_test
+
+import (
+	"bytes"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/common/units"
+	"github.com/iEvan-lhr/docx-agent/dml/dmlst"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloatingPictures(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+	data := testPNG(t, 20, 10, 0)
+
+	p := rd.AddParagraph("Text flowing around the logo.")
+	logo, err := p.AddFloatingPictureFromBytes(data, &docx.PictureOptions{Width: 2}, &docx.AnchorOptions{
+		RelativeFromH: dmlst.RelFromHMargin,
+		AlignH:        dmlst.AlignHRight,
+		RelativeFromV: dmlst.RelFromVParagraph,
+		OffsetY:       0.5,
+		WrapText:      dmlst.WrapTextLeft,
+		DistLeft:      0.125,
+	})
+	require.NoError(t, err)
+	require.True(t, logo.IsFloating())
+	assert.Nil(t, logo.Inline)
+	assert.Equal(t, dmlst.AlignHRight, *logo.Anchor.PositionH.Align)
+	assert.Equal(t, int(units.Inch(0.5).ToEmu()), logo.Anchor.PositionV.PosOffset)
+	assert.Equal(t, dmlst.WrapTextLeft, logo.Anchor.WrapSquare.WrapText)
+	assert.Equal(t, units.Inch(1).ToEmu(), units.Emu(logo.Anchor.Extent.Height))
+
+	watermark, err := rd.AddFloatingPictureFromBytes(data, nil, &docx.AnchorOptions{
+		RelativeFromH: dmlst.RelFromHPage,
+		RelativeFromV: dmlst.RelFromVPage,
+		AlignH:        dmlst.AlignHCenter,
+		AlignV:        dmlst.AlignVCenter,
+		Wrap:          docx.WrapBehindText,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, watermark.Anchor.BehindDoc)
+	assert.NotNil(t, watermark.Anchor.WrapNone)
+	assert.Greater(t, watermark.Anchor.RelativeHeight, logo.Anchor.RelativeHeight)
+
+	// Z-order
+	require.NoError(t, watermark.SendToBack())
+	assert.Less(t, watermark.Anchor.RelativeHeight, logo.Anchor.RelativeHeight)
+	require.NoError(t, watermark.BringToFront())
+	assert.Greater(t, watermark.Anchor.RelativeHeight, logo.Anchor.RelativeHeight)
+
+	// Reposition a floating picture.
+	require.NoError(t, logo.Float(&docx.AnchorOptions{Wrap: docx.WrapTight, OffsetX: 1}))
+	assert.Nil(t, logo.Anchor.WrapSquare)
+	require.NotNil(t, logo.Anchor.WrapTight)
+	assert.Nil(t, logo.Anchor.PositionH.Align)
+	assert.Equal(t, dmlst.RelFromHColumn, logo.Anchor.PositionH.RelativeFrom)
+
+	// Inline and back.
+	inline, err := rd.AddPictureFromBytes(data, &docx.PictureOptions{Width: 1, AltText: "chart"})
+	require.NoError(t, err)
+	assert.False(t, inline.IsFloating())
+	require.NoError(t, inline.Float(&docx.AnchorOptions{Wrap: docx.WrapTopAndBottom}))
+	assert.Equal(t, "chart", inline.Anchor.DocProp.Description)
+	assert.Len(t, inline.Para.Pictures(), 1)
+	require.NoError(t, inline.MakeInline())
+	assert.False(t, inline.IsFloating())
+	assert.Equal(t, units.Inch(1).ToEmu(), units.Emu(inline.Inline.Extent.Width))
+	assert.Error(t, inline.SetZOrder(5))
+
+	_, err = p.AddFloatingPictureFromBytes(data, nil, &docx.AnchorOptions{Wrap: "diagonal"})
+	assert.Error(t, err)
+	assert.Len(t, p.Pictures(), 1)
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	content := buf.Bytes()
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+
+	var pics []*docx.PicMeta
+	for _, child := range reopened.Document.Body.Children {
+		if child.Para != nil {
+			pics = append(pics, child.Para.Pictures()...)
+		}
+	}
+	require.Len(t, pics, 3)
+	require.True(t, pics[0].IsFloating())
+	assert.Equal(t, dmlst.RelFromHColumn, pics[0].Anchor.PositionH.RelativeFrom)
+	assert.Equal(t, int(units.Inch(1).ToEmu()), pics[0].Anchor.PositionH.PosOffset)
+	require.True(t, pics[1].IsFloating())
+	assert.Equal(t, dmlst.AlignVCenter, *pics[1].Anchor.PositionV.Align)
+	assert.Equal(t, 1, pics[1].Anchor.BehindDoc)
+	assert.False(t, pics[2].IsFloating())
+
+	// Pictures of a loaded document can be converted too.
+	require.NoError(t, pics[2].Float(nil))
+	assert.Greater(t, pics[2].Anchor.RelativeHeight, pics[1].Anchor.RelativeHeight)
+}
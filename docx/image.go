@@ -0,0 +1,239 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/common/constants"
+	"github.com/iEvan-lhr/docx-agent/common/units"
+	"github.com/iEvan-lhr/docx-agent/dml/dmlct"
+)
+
+// PictureOptions controls the size and description of a picture added from image data.
+//
+// When neither Width nor Height is set the picture is shown at its native size, computed
+// from its pixel size and resolution. When only one of them is set the other follows the
+// aspect ratio of the image.
+type PictureOptions struct {
+	Width  units.Inch
+	Height units.Inch
+
+	// MaxWidth scales the picture down, keeping its aspect ratio, so that it is at most
+	// this wide.
+	MaxWidth units.Inch
+
+	// FitToPage scales the picture down, keeping its aspect ratio, so that it fits
+	// between the margins of the document's final section.
+	FitToPage bool
+
+	// AltText is the alternative text of the picture; Title its title.
+	AltText string
+	Title   string
+
+	// Fallback is a raster image shown by applications that do not support SVG. It is
+	// required for SVG pictures.
+	Fallback []byte
+}
+
+// AddPictureFromReader reads image data and adds it to the paragraph as an inline picture.
+// See AddPictureFromBytes.
+func (p *Paragraph) AddPictureFromReader(r io.Reader, opts *PictureOptions) (*PicMeta, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return p.AddPictureFromBytes(data, opts)
+}
+
+// AddPictureFromBytes adds image data to the paragraph as an inline picture.
+//
+// The format is detected from the data; PNG, JPEG, GIF, BMP, TIFF and SVG are supported.
+// Image data already present in the package is reused rather than stored again. opts may
+// be nil to add the picture at its native size.
+func (p *Paragraph) AddPictureFromBytes(data []byte, opts *PictureOptions) (*PicMeta, error) {
+	if opts == nil {
+		opts = &PictureOptions{}
+	}
+
+	info, err := DecodeImageInfo(data)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height, err := p.root.pictureSize(info, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var rID, svgRID string
+	if info.Format == "svg" {
+		if len(opts.Fallback) == 0 {
+			return nil, errors.New("an SVG picture requires a raster fallback image")
+		}
+		fallback, err := DecodeImageInfo(opts.Fallback)
+		if err != nil {
+			return nil, fmt.Errorf("fallback: %w", err)
+		}
+		if fallback.Format == "svg" {
+			return nil, errors.New("fallback: image must not be an SVG")
+		}
+		if rID, err = p.root.addImagePart(opts.Fallback, fallback.Format); err != nil {
+			return nil, err
+		}
+		if svgRID, err = p.root.addImagePart(data, info.Format); err != nil {
+			return nil, err
+		}
+	} else if rID, err = p.root.addImagePart(data, info.Format); err != nil {
+		return nil, err
+	}
+
+	p.root.ImageCount += 1
+	pm := p.addDrawing(rID, p.root.ImageCount, width, height)
+	inline := pm.Inline
+	inline.DocProp.Description = opts.AltText
+	inline.DocProp.Title = opts.Title
+
+	if pic := inline.Graphic.Data.Pic; pic != nil {
+		if cNvPr := pic.NonVisualPicProp.CNvPr; cNvPr != nil {
+			cNvPr.Description = opts.AltText
+		}
+		if svgRID != "" && pic.BlipFill.Blip != nil {
+			pic.BlipFill.Blip.ExtLst = &dmlct.ExtLst{Exts: []dmlct.Ext{{
+				URI:     dmlct.SVGBlipExtURI,
+				SVGBlip: &dmlct.SVGBlip{EmbedID: svgRID},
+			}}}
+		}
+	}
+
+	return pm, nil
+}
+
+// pictureSize computes the displayed size of an image from the options.
+func (rd *RootDoc) pictureSize(info *ImageInfo, opts *PictureOptions) (units.Inch, units.Inch, error) {
+	if opts.Width < 0 || opts.Height < 0 || opts.MaxWidth < 0 {
+		return 0, 0, errors.New("picture dimensions must not be negative")
+	}
+
+	width, height := opts.Width, opts.Height
+	ratio := float64(info.Height) / float64(info.Width)
+	switch {
+	case width == 0 && height == 0:
+		width, height = info.Size()
+	case height == 0:
+		height = units.Inch(float64(width) * ratio)
+	case width == 0:
+		width = units.Inch(float64(height) / ratio)
+	}
+
+	limit := opts.MaxWidth
+	if opts.FitToPage {
+		if text := rd.textWidth(); text > 0 && (limit == 0 || text < limit) {
+			limit = text
+		}
+	}
+	if limit > 0 && width > limit {
+		height = units.Inch(float64(height) * float64(limit) / float64(width))
+		width = limit
+	}
+	return width, height, nil
+}
+
+// textWidth returns the width between the margins of the final section, or 0 if the
+// document does not specify a page size.
+func (rd *RootDoc) textWidth() units.Inch {
+	if rd.Document == nil || rd.Document.Body == nil || rd.Document.Body.SectPr == nil {
+		return 0
+	}
+	sectPr := rd.Document.Body.SectPr
+	if sectPr.PageSize == nil || sectPr.PageSize.Width == nil {
+		return 0
+	}
+
+	twips := int(*sectPr.PageSize.Width)
+	if margin := sectPr.PageMargin; margin != nil {
+		if margin.Left != nil {
+			twips -= *margin.Left
+		}
+		if margin.Right != nil {
+			twips -= *margin.Right
+		}
+		if margin.Gutter != nil {
+			twips -= *margin.Gutter
+		}
+	}
+	if twips <= 0 {
+		return 0
+	}
+	return units.Inch(float64(twips) / 1440)
+}
+
+// addImagePart stores image data as a media part of the document and returns the ID of the
+// document relationship targeting it. Data identical to an existing media part reuses that
+// part, and its relationship when the document already has one.
+func (rd *RootDoc) addImagePart(data []byte, ext string) (string, error) {
+	mediaPath, err := rd.storeMedia(data, ext)
+	if err != nil {
+		return "", err
+	}
+
+	target := strings.TrimPrefix(mediaPath, "word/")
+	for _, rel := range rd.Document.DocRels.Relationships {
+		if rel.Type == constants.SourceRelationshipImage && rel.Target == target && rel.TargetMode == "" {
+			return rel.ID, nil
+		}
+	}
+	return rd.Document.addRelation(constants.SourceRelationshipImage, target), nil
+}
+
+// storeMedia stores image data under the media folder, unless identical data is already
+// there, registers its content type and returns its package path.
+func (rd *RootDoc) storeMedia(data []byte, ext string) (string, error) {
+	path := rd.findMedia(data)
+	if path != "" {
+		ext = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+
+	mime, err := MIMEFromExt(ext)
+	if err != nil {
+		return "", err
+	}
+	if err := rd.ContentType.AddExtension(ext, mime); err != nil {
+		return "", err
+	}
+
+	if path == "" {
+		for i := rd.ImageCount + 1; ; i++ {
+			candidate := fmt.Sprintf("%simage%d.%s", constants.MediaPath, i, ext)
+			if _, taken := rd.FileMap.Load(candidate); !taken {
+				path = candidate
+				break
+			}
+		}
+		rd.FileMap.Store(path, data)
+	}
+	return path, nil
+}
+
+// findMedia returns the path of a media part holding exactly data, or "" if there is none.
+func (rd *RootDoc) findMedia(data []byte) string {
+	var found string
+	rd.FileMap.Range(func(key, value any) bool {
+		path, ok := key.(string)
+		if !ok || !strings.HasPrefix(path, constants.MediaPath) {
+			return true
+		}
+		content, ok := value.([]byte)
+		if ok && bytes.Equal(content, data) {
+			// Prefer the lowest path so that the choice does not depend on map order.
+			if found == "" || path < found {
+				found = path
+			}
+		}
+		return true
+	})
+	return found
+}
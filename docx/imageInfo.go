@@ -0,0 +1,360 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/common/units"
+)
+
+// defaultImageDPI is the resolution assumed for images that do not state one.
+const defaultImageDPI = 96
+
+// ErrUnknownImageFormat is returned for data that is not a supported image.
+var ErrUnknownImageFormat = errors.New("unknown image format")
+
+// ImageInfo describes an image as read from its header.
+type ImageInfo struct {
+	// Format is the file extension of the format: "png", "jpeg", "gif", "bmp", "tiff" or "svg".
+	Format string
+
+	// Width and Height are the size in pixels. SVG sizes are converted to pixels at 96 DPI.
+	Width  int
+	Height int
+
+	// DPIX and DPIY are the horizontal and vertical resolution, 96 when the image does not
+	// state one.
+	DPIX float64
+	DPIY float64
+}
+
+// Size returns the native printed size of the image.
+func (i *ImageInfo) Size() (width, height units.Inch) {
+	return units.Inch(float64(i.Width) / i.DPIX), units.Inch(float64(i.Height) / i.DPIY)
+}
+
+// MIME returns the content type of the image format.
+func (i *ImageInfo) MIME() string {
+	mime, _ := MIMEFromExt(i.Format)
+	return mime
+}
+
+// DecodeImageInfo detects the format of an image and reads its size and resolution from
+// its header.
+func DecodeImageInfo(data []byte) (*ImageInfo, error) {
+	info := &ImageInfo{DPIX: defaultImageDPI, DPIY: defaultImageDPI}
+
+	var err error
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		info.Format = "png"
+		err = decodePNGInfo(data, info)
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		info.Format = "jpeg"
+		err = decodeJPEGInfo(data, info)
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		info.Format = "gif"
+		if len(data) < 10 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		info.Width = int(binary.LittleEndian.Uint16(data[6:]))
+		info.Height = int(binary.LittleEndian.Uint16(data[8:]))
+	case bytes.HasPrefix(data, []byte("BM")):
+		info.Format = "bmp"
+		err = decodeBMPInfo(data, info)
+	case bytes.HasPrefix(data, []byte("II*\x00")), bytes.HasPrefix(data, []byte("MM\x00*")):
+		info.Format = "tiff"
+		err = decodeTIFFInfo(data, info)
+	case isSVG(data):
+		info.Format = "svg"
+		err = decodeSVGInfo(data, info)
+	default:
+		return nil, ErrUnknownImageFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s image: %w", info.Format, err)
+	}
+	if info.Width <= 0 || info.Height <= 0 {
+		return nil, fmt.Errorf("%s image: invalid size %dx%d", info.Format, info.Width, info.Height)
+	}
+	return info, nil
+}
+
+// decodePNGInfo reads the IHDR chunk and the optional pHYs chunk.
+func decodePNGInfo(data []byte, info *ImageInfo) error {
+	pos := 8
+	for pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		chunk := string(data[pos+4 : pos+8])
+		body := pos + 8
+		if length < 0 || body+length > len(data) {
+			return io.ErrUnexpectedEOF
+		}
+		switch chunk {
+		case "IHDR":
+			if length < 8 {
+				return io.ErrUnexpectedEOF
+			}
+			info.Width = int(binary.BigEndian.Uint32(data[body:]))
+			info.Height = int(binary.BigEndian.Uint32(data[body+4:]))
+		case "pHYs":
+			// Pixels per unit; unit 1 is the meter.
+			if length >= 9 && data[body+8] == 1 {
+				x := float64(binary.BigEndian.Uint32(data[body:])) * 0.0254
+				y := float64(binary.BigEndian.Uint32(data[body+4:])) * 0.0254
+				if x > 0 && y > 0 {
+					info.DPIX, info.DPIY = x, y
+				}
+			}
+		case "IDAT", "IEND":
+			// pHYs must precede the image data.
+			return nil
+		}
+		pos = body + length + 4 // skip the CRC
+	}
+	if info.Width == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// decodeJPEGInfo reads the JFIF density and the frame header.
+func decodeJPEGInfo(data []byte, info *ImageInfo) error {
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return errors.New("invalid marker")
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			pos++ // fill byte
+			continue
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			pos += 2
+			continue
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		seg := pos + 4
+		if length < 2 || pos+2+length > len(data) {
+			return io.ErrUnexpectedEOF
+		}
+
+		switch {
+		case marker == 0xE0 && length >= 14 && bytes.HasPrefix(data[seg:], []byte("JFIF\x00")):
+			unit := data[seg+7]
+			x := float64(binary.BigEndian.Uint16(data[seg+8:]))
+			y := float64(binary.BigEndian.Uint16(data[seg+10:]))
+			if x > 0 && y > 0 {
+				switch unit {
+				case 1: // dots per inch
+					info.DPIX, info.DPIY = x, y
+				case 2: // dots per centimeter
+					info.DPIX, info.DPIY = x*2.54, y*2.54
+				}
+			}
+		case marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC:
+			if length < 7 {
+				return io.ErrUnexpectedEOF
+			}
+			info.Height = int(binary.BigEndian.Uint16(data[seg+1:]))
+			info.Width = int(binary.BigEndian.Uint16(data[seg+3:]))
+			return nil
+		case marker == 0xDA:
+			return errors.New("no frame header before the image data")
+		}
+		pos += 2 + length
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// decodeBMPInfo reads the DIB header.
+func decodeBMPInfo(data []byte, info *ImageInfo) error {
+	if len(data) < 26 {
+		return io.ErrUnexpectedEOF
+	}
+	headerSize := binary.LittleEndian.Uint32(data[14:])
+	if headerSize == 12 {
+		// BITMAPCOREHEADER
+		info.Width = int(binary.LittleEndian.Uint16(data[18:]))
+		info.Height = int(binary.LittleEndian.Uint16(data[20:]))
+		return nil
+	}
+	if headerSize < 40 || len(data) < 46 {
+		return io.ErrUnexpectedEOF
+	}
+	info.Width = int(int32(binary.LittleEndian.Uint32(data[18:])))
+	info.Height = int(int32(binary.LittleEndian.Uint32(data[22:])))
+	if info.Height < 0 {
+		// Top-down bitmap
+		info.Height = -info.Height
+	}
+	// Pixels per meter
+	if x, y := int32(binary.LittleEndian.Uint32(data[38:])), int32(binary.LittleEndian.Uint32(data[42:])); x > 0 && y > 0 {
+		info.DPIX = float64(x) * 0.0254
+		info.DPIY = float64(y) * 0.0254
+	}
+	return nil
+}
+
+// decodeTIFFInfo reads the size and resolution tags of the first image file directory.
+func decodeTIFFInfo(data []byte, info *ImageInfo) error {
+	var order binary.ByteOrder = binary.LittleEndian
+	if data[0] == 'M' {
+		order = binary.BigEndian
+	}
+	if len(data) < 8 {
+		return io.ErrUnexpectedEOF
+	}
+	ifd := int(order.Uint32(data[4:]))
+	if ifd < 8 || ifd+2 > len(data) {
+		return io.ErrUnexpectedEOF
+	}
+
+	rational := func(offset int) float64 {
+		if offset < 0 || offset+8 > len(data) {
+			return 0
+		}
+		num, den := order.Uint32(data[offset:]), order.Uint32(data[offset+4:])
+		if den == 0 {
+			return 0
+		}
+		return float64(num) / float64(den)
+	}
+
+	var xRes, yRes float64
+	unit := uint32(2) // inches
+	count := int(order.Uint16(data[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(data) {
+			return io.ErrUnexpectedEOF
+		}
+		tag := order.Uint16(data[entry:])
+		typ := order.Uint16(data[entry+2:])
+		value := order.Uint32(data[entry+8:])
+		if typ == 3 { // SHORT values are left-justified in the value field
+			value = uint32(order.Uint16(data[entry+8:]))
+		}
+		switch tag {
+		case 256:
+			info.Width = int(value)
+		case 257:
+			info.Height = int(value)
+		case 282:
+			xRes = rational(int(order.Uint32(data[entry+8:])))
+		case 283:
+			yRes = rational(int(order.Uint32(data[entry+8:])))
+		case 296:
+			unit = value
+		}
+	}
+
+	if xRes > 0 && yRes > 0 {
+		switch unit {
+		case 2:
+			info.DPIX, info.DPIY = xRes, yRes
+		case 3:
+			info.DPIX, info.DPIY = xRes*2.54, yRes*2.54
+		}
+	}
+	return nil
+}
+
+// isSVG reports whether data looks like an SVG document.
+func isSVG(data []byte) bool {
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	head = bytes.TrimPrefix(head, []byte("\xEF\xBB\xBF"))
+	head = bytes.TrimSpace(head)
+	return bytes.HasPrefix(head, []byte("<")) && bytes.Contains(head, []byte("<svg"))
+}
+
+// decodeSVGInfo reads the size of the root svg element from its width and height, or from
+// its viewBox.
+func decodeSVGInfo(data []byte, info *ImageInfo) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "svg" {
+			return errors.New("root element is not svg")
+		}
+
+		var width, height float64
+		var viewBox []float64
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "width":
+				width = svgLength(attr.Value)
+			case "height":
+				height = svgLength(attr.Value)
+			case "viewBox":
+				for _, f := range strings.FieldsFunc(attr.Value, func(r rune) bool { return r == ' ' || r == ',' }) {
+					v, err := strconv.ParseFloat(f, 64)
+					if err != nil {
+						break
+					}
+					viewBox = append(viewBox, v)
+				}
+			}
+		}
+
+		if len(viewBox) == 4 && viewBox[2] > 0 && viewBox[3] > 0 {
+			// A missing dimension follows the aspect ratio of the view box.
+			switch {
+			case width == 0 && height == 0:
+				width, height = viewBox[2], viewBox[3]
+			case width == 0:
+				width = height * viewBox[2] / viewBox[3]
+			case height == 0:
+				height = width * viewBox[3] / viewBox[2]
+			}
+		}
+		info.Width = int(width + 0.5)
+		info.Height = int(height + 0.5)
+		return nil
+	}
+}
+
+// svgLength converts an SVG length to pixels at 96 DPI. Relative lengths, such as
+// percentages, yield 0.
+func svgLength(value string) float64 {
+	value = strings.TrimSpace(value)
+	units := map[string]float64{
+		"px": 1,
+		"pt": 96.0 / 72,
+		"pc": 16,
+		"in": 96,
+		"cm": 96 / 2.54,
+		"mm": 96 / 25.4,
+	}
+	scale := 1.0
+	for suffix, s := range units {
+		if strings.HasSuffix(value, suffix) {
+			value = strings.TrimSuffix(value, suffix)
+			scale = s
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return v * scale
+}
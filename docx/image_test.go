@@ -0,0 +1,185 @@
+package docx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/common/units"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPNG encodes a w×h PNG; a non-zero dpi adds a pHYs chunk.
+func testPNG(t *testing.T, w, h int, dpi float64) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	data := buf.Bytes()
+	if dpi == 0 {
+		return data
+	}
+
+	body := make([]byte, 9)
+	ppm := uint32(dpi/0.0254 + 0.5)
+	binary.BigEndian.PutUint32(body, ppm)
+	binary.BigEndian.PutUint32(body[4:], ppm)
+	body[8] = 1
+
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(body)))
+	chunk = append(chunk, "pHYs"...)
+	chunk = append(chunk, body...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	// Insert after the signature and the IHDR chunk.
+	at := 8 + 8 + 13 + 4
+	return append(append(append([]byte{}, data[:at]...), chunk...), data[at:]...)
+}
+
+func TestDecodeImageInfo(t *testing.T) {
+	var jpg bytes.Buffer
+	require.NoError(t, jpeg.Encode(&jpg, image.NewRGBA(image.Rect(0, 0, 30, 20)), nil))
+	// JFIF header stating 300x300 dpi.
+	app0 := []byte{0xFF, 0xE0, 0, 16, 'J', 'F', 'I', 'F', 0, 1, 1, 1, 0x01, 0x2C, 0x01, 0x2C, 0, 0}
+	jfif := append(append([]byte{0xFF, 0xD8}, app0...), jpg.Bytes()[2:]...)
+
+	var gifBuf bytes.Buffer
+	require.NoError(t, gif.Encode(&gifBuf, image.NewPaletted(image.Rect(0, 0, 7, 3), color.Palette{color.Black}), nil))
+
+	bmp := make([]byte, 54)
+	copy(bmp, "BM")
+	binary.LittleEndian.PutUint32(bmp[14:], 40)
+	binary.LittleEndian.PutUint32(bmp[18:], 12)
+	binary.LittleEndian.PutUint32(bmp[22:], uint32(0xFFFFFFF6)) // -10, top-down
+	binary.LittleEndian.PutUint32(bmp[38:], 2835)               // 72 dpi
+	binary.LittleEndian.PutUint32(bmp[42:], 2835)
+
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)
+	entry := func(tag, typ uint16, value uint32) {
+		tiff = binary.LittleEndian.AppendUint16(tiff, tag)
+		tiff = binary.LittleEndian.AppendUint16(tiff, typ)
+		tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+		tiff = binary.LittleEndian.AppendUint32(tiff, value)
+	}
+	entry(256, 3, 50)
+	entry(257, 4, 25)
+	entry(282, 5, 8+2+3*12+4) // rational right after the directory
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 200)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		w, h   int
+		dpi    float64
+	}{
+		{"png", testPNG(t, 4, 2, 0), "png", 4, 2, 96},
+		{"png with pHYs", testPNG(t, 300, 150, 150), "png", 300, 150, 150},
+		{"png with zero pHYs", testPNG(t, 4, 2, 0.01), "png", 4, 2, 96},
+		{"jpeg", jfif, "jpeg", 30, 20, 300},
+		{"gif", gifBuf.Bytes(), "gif", 7, 3, 96},
+		{"bmp", bmp, "bmp", 12, 10, 72},
+		{"tiff", tiff, "tiff", 50, 25, 96},
+		{"svg", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg" width="2in" viewBox="0 0 100 50"/>`), "svg", 192, 96, 96},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := docx.DecodeImageInfo(tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.format, info.Format)
+			assert.Equal(t, tt.w, info.Width)
+			assert.Equal(t, tt.h, info.Height)
+			assert.InDelta(t, tt.dpi, info.DPIX, 0.1)
+			assert.InDelta(t, tt.dpi, info.DPIY, 0.1)
+		})
+	}
+
+	_, err := docx.DecodeImageInfo([]byte("plain text"))
+	assert.ErrorIs(t, err, docx.ErrUnknownImageFormat)
+	_, err = docx.DecodeImageInfo([]byte("\x89PNG\r\n\x1a\n"))
+	assert.Error(t, err)
+}
+
+func TestAddPictureFromBytes(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	data := testPNG(t, 300, 150, 150)
+
+	// Native size: 300px at 150 dpi is 2 inches.
+	pic, err := rd.AddPictureFromBytes(data, &docx.PictureOptions{AltText: "A chart", Title: "Chart"})
+	require.NoError(t, err)
+	assert.InDelta(t, int64(units.Inch(2).ToEmu()), int64(pic.Inline.Extent.Width), 500)
+	assert.InDelta(t, int64(units.Inch(1).ToEmu()), int64(pic.Inline.Extent.Height), 500)
+	assert.Equal(t, "A chart", pic.Inline.DocProp.Description)
+	assert.Equal(t, "Chart", pic.Inline.DocProp.Title)
+
+	// Height follows the aspect ratio; identical bytes are stored once.
+	again, err := rd.AddPictureFromReader(bytes.NewReader(data), &docx.PictureOptions{Width: 4})
+	require.NoError(t, err)
+	assert.Equal(t, units.Inch(2).ToEmu(), units.Emu(again.Inline.Extent.Height))
+	assert.Equal(t, pic.Inline.Graphic.Data.Pic.BlipFill.Blip.EmbedID, again.Inline.Graphic.Data.Pic.BlipFill.Blip.EmbedID)
+	assert.NotEqual(t, pic.Inline.DocProp.ID, again.Inline.DocProp.ID)
+
+	// Fit to the page of the default template.
+	wide, err := rd.AddPictureFromBytes(data, &docx.PictureOptions{Width: 20, FitToPage: true})
+	require.NoError(t, err)
+	assert.Equal(t, units.Inch(6).ToEmu(), units.Emu(wide.Inline.Extent.Width))
+	assert.InDelta(t, float64(wide.Inline.Extent.Width)/2, float64(wide.Inline.Extent.Height), 1)
+
+	capped, err := rd.AddPictureFromBytes(testPNG(t, 10, 10, 0), &docx.PictureOptions{Width: 3, MaxWidth: 1.5})
+	require.NoError(t, err)
+	assert.Equal(t, units.Inch(1.5).ToEmu(), units.Emu(capped.Inline.Extent.Width))
+	assert.Equal(t, units.Inch(1.5).ToEmu(), units.Emu(capped.Inline.Extent.Height))
+
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="96" height="48"></svg>`)
+	_, err = rd.AddPictureFromBytes(svg, nil)
+	assert.Error(t, err)
+	vector, err := rd.AddPictureFromBytes(svg, &docx.PictureOptions{Fallback: data})
+	require.NoError(t, err)
+	blip := vector.Inline.Graphic.Data.Pic.BlipFill.Blip
+	assert.Equal(t, pic.Inline.Graphic.Data.Pic.BlipFill.Blip.EmbedID, blip.EmbedID)
+	require.NotNil(t, blip.ExtLst)
+	require.NotNil(t, blip.ExtLst.Exts[0].SVGBlip)
+
+	var media []string
+	rd.FileMap.Range(func(key, _ any) bool {
+		if strings.HasPrefix(key.(string), "word/media/") {
+			media = append(media, key.(string))
+		}
+		return true
+	})
+	assert.ElementsMatch(t, []string{"word/media/image1.png", "word/media/image4.png", "word/media/image5.svg"}, media)
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var doc string
+	for _, f := range archive.File {
+		if f.Name == "word/document.xml" {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			doc = string(content)
+		}
+	}
+	assert.Contains(t, doc, `descr="A chart" title="Chart"`)
+	assert.Contains(t, doc, `<asvg:svgBlip`)
+}
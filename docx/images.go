@@ -0,0 +1,626 @@
+package docx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/common/constants"
+	"github.com/iEvan-lhr/docx-agent/common/units"
+	"github.com/iEvan-lhr/docx-agent/dml"
+	"github.com/iEvan-lhr/docx-agent/dml/dmlpic"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+)
+
+// ImageLocation is the kind of part an image appears in.
+type ImageLocation string
+
+const (
+	ImageInBody   ImageLocation = "body"
+	ImageInHeader ImageLocation = "header"
+	ImageInFooter ImageLocation = "footer"
+)
+
+// ImageKind is how an image is embedded.
+type ImageKind string
+
+const (
+	ImageKindPicture ImageKind = "picture" // DrawingML picture, inline or floating
+	ImageKindGroup   ImageKind = "group"   // Picture inside a drawing group
+	ImageKindVML     ImageKind = "vml"     // Legacy VML image data
+)
+
+// Image describes a picture of the document, as returned by Images.
+type Image struct {
+	// ID identifies the image in calls to ReplaceImage and SetImageDescription. It is the
+	// 1-based position of the image in Images and changes when pictures are added or removed.
+	ID int
+
+	Kind     ImageKind
+	Location ImageLocation
+
+	// Part is the package path of the part referencing the image, such as
+	// word/document.xml or word/header1.xml.
+	Part string
+
+	// Block is the index of the top-level paragraph or table of the part holding the
+	// image; InTable reports whether it is inside a table.
+	Block   int
+	InTable bool
+
+	// Floating reports an anchored picture; Fallback a picture of an mc:Fallback branch,
+	// shown only by applications that do not support the preferred content.
+	Floating bool
+	Fallback bool
+
+	// RelID is the relationship ID referencing the media, and MediaPath the package path of
+	// the media part; MediaPath is empty when the relationship is missing or external.
+	RelID     string
+	MediaPath string
+
+	// Format is the image format detected from Data, or "" if unknown.
+	Format string
+	Data   []byte
+
+	// Width and Height are the displayed size, 0 when the document does not state it.
+	Width  units.Emu
+	Height units.Emu
+
+	AltText string
+	Title   string
+
+	// ref updates the markup of the image
+	ref *imageRef
+}
+
+// imageRef points into the markup of an image so that it can be updated.
+type imageRef struct {
+	blip    *dmlpic.Blip
+	docProp *dml.DocProp
+	cNvPr   func(description string)
+	vml     *string // r:id of VML image data
+	vmlAlt  *string
+	vmlName *string
+}
+
+// imagePart is a part that may reference images.
+type imagePart struct {
+	path     string
+	location ImageLocation
+	children []DocumentChild
+	rels     []*Relationship
+}
+
+// Images returns every picture of the document body, headers and footers in document order:
+// inline and floating DrawingML pictures, pictures inside drawing groups, both branches of
+// mc:AlternateContent and legacy VML images.
+func (rd *RootDoc) Images() ([]*Image, error) {
+	parts, err := rd.imageParts()
+	if err != nil {
+		return nil, err
+	}
+
+	var images []*Image
+	for _, part := range parts {
+		for block, child := range part.children {
+			scan := func(p *ctypes.Paragraph, inTable bool) {
+				for _, img := range paragraphImages(p) {
+					img.Location = part.location
+					img.Part = part.path
+					img.Block = block
+					img.InTable = inTable
+					rd.resolveImage(img, part)
+					img.ID = len(images) + 1
+					images = append(images, img)
+				}
+			}
+
+			switch {
+			case child.Para != nil:
+				scan(&child.Para.ct, false)
+			case child.Table != nil:
+				walkTableParagraphs(&child.Table.ct, func(p *ctypes.Paragraph) { scan(p, true) })
+			}
+		}
+	}
+	return images, nil
+}
+
+// ReplaceImage swaps the media of an image for data, keeping its size, position and
+// description. Media shared with other images is left untouched for them.
+func (rd *RootDoc) ReplaceImage(id int, data []byte) error {
+	images, err := rd.Images()
+	if err != nil {
+		return err
+	}
+	img, err := imageByID(images, id)
+	if err != nil {
+		return err
+	}
+
+	info, err := DecodeImageInfo(data)
+	if err != nil {
+		return err
+	}
+	if info.Format == "svg" {
+		return errors.New("an SVG image requires a raster fallback; add it with AddPictureFromBytes")
+	}
+
+	// Media used by this image alone is replaced in place when its extension still fits.
+	if img.MediaPath != "" && strings.EqualFold(strings.TrimPrefix(path.Ext(img.MediaPath), "."), info.Format) {
+		shared := false
+		for _, other := range images {
+			if other != img && other.MediaPath == img.MediaPath {
+				shared = true
+				break
+			}
+		}
+		if !shared {
+			rd.FileMap.Store(img.MediaPath, data)
+			img.ref.dropSVG()
+			return nil
+		}
+	}
+
+	mediaPath, err := rd.storeMedia(data, info.Format)
+	if err != nil {
+		return err
+	}
+	relID, err := rd.partImageRelation(img.Part, mediaPath)
+	if err != nil {
+		return err
+	}
+	img.ref.setRelID(relID)
+	return nil
+}
+
+// SetImageDescription sets the alternative text and title of an image.
+func (rd *RootDoc) SetImageDescription(id int, altText, title string) error {
+	images, err := rd.Images()
+	if err != nil {
+		return err
+	}
+	img, err := imageByID(images, id)
+	if err != nil {
+		return err
+	}
+
+	ref := img.ref
+	if ref.docProp != nil {
+		ref.docProp.Description = altText
+		ref.docProp.Title = title
+	}
+	if ref.cNvPr != nil {
+		ref.cNvPr(altText)
+	}
+	if ref.vmlAlt != nil {
+		*ref.vmlAlt = altText
+	}
+	if ref.vmlName != nil {
+		*ref.vmlName = title
+	}
+	return nil
+}
+
+// ExtractImages writes every media part referenced by a picture of the document to dir,
+// creating it if needed, and returns the paths of the written files. Media referenced by
+// several pictures is written once.
+func (rd *RootDoc) ExtractImages(dir string) ([]string, error) {
+	images, err := rd.Images()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var written []string
+	seen := make(map[string]bool)
+	for _, img := range images {
+		if img.MediaPath == "" || seen[img.MediaPath] {
+			continue
+		}
+		seen[img.MediaPath] = true
+
+		file := filepath.Join(dir, path.Base(img.MediaPath))
+		if err := os.WriteFile(file, img.Data, 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, file)
+	}
+	return written, nil
+}
+
+// imageByID returns the image with the given ID.
+func imageByID(images []*Image, id int) (*Image, error) {
+	if id < 1 || id > len(images) {
+		return nil, fmt.Errorf("no image with ID %d", id)
+	}
+	return images[id-1], nil
+}
+
+// imageParts returns the document body followed by the headers and footers, in the order
+// of their package paths.
+func (rd *RootDoc) imageParts() ([]imagePart, error) {
+	parts := []imagePart{{
+		path:     rd.Document.relativePath,
+		location: ImageInBody,
+		children: rd.Document.Body.Children,
+		rels:     rd.Document.DocRels.Relationships,
+	}}
+
+	var extra []imagePart
+	for _, h := range rd.Document.Headers {
+		extra = append(extra, imagePart{path: h.RelativePath, location: ImageInHeader, children: h.Children})
+	}
+	for _, f := range rd.Document.Footers {
+		extra = append(extra, imagePart{path: f.RelativePath, location: ImageInFooter, children: f.Children})
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].path < extra[j].path })
+
+	for i := range extra {
+		rels, err := rd.partRels(extra[i].path)
+		if err != nil {
+			return nil, err
+		}
+		extra[i].rels = rels.Relationships
+	}
+	return append(parts, extra...), nil
+}
+
+// partRels returns the relationships of a part other than the main document, stored in
+// FileMap. A part without relationships yields an empty collection.
+func (rd *RootDoc) partRels(part string) (*Relationships, error) {
+	rels := &Relationships{
+		RelativePath: partRelsPath(part),
+		Xmlns:        constants.XMLNS,
+	}
+	content, ok := rd.FileMap.Load(rels.RelativePath)
+	if !ok {
+		return rels, nil
+	}
+	if err := xml.Unmarshal(content.([]byte), rels); err != nil {
+		return nil, fmt.Errorf("%s: %w", rels.RelativePath, err)
+	}
+	return rels, nil
+}
+
+// partRelsPath returns the path of the relationships part of part.
+func partRelsPath(part string) string {
+	return path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+}
+
+// partImageRelation returns the ID of an image relationship from part to the media part,
+// adding the relationship if the part has none.
+func (rd *RootDoc) partImageRelation(part, mediaPath string) (string, error) {
+	target := mediaPath
+	if dir := path.Dir(part) + "/"; strings.HasPrefix(mediaPath, dir) {
+		target = strings.TrimPrefix(mediaPath, dir)
+	} else {
+		target = "/" + mediaPath
+	}
+
+	if part == rd.Document.relativePath {
+		for _, rel := range rd.Document.DocRels.Relationships {
+			if rel.Type == constants.SourceRelationshipImage && rel.Target == target && rel.TargetMode == "" {
+				return rel.ID, nil
+			}
+		}
+		return rd.Document.addRelation(constants.SourceRelationshipImage, target), nil
+	}
+
+	rels, err := rd.partRels(part)
+	if err != nil {
+		return "", err
+	}
+	next := 0
+	for _, rel := range rels.Relationships {
+		if rel.Type == constants.SourceRelationshipImage && rel.Target == target && rel.TargetMode == "" {
+			return rel.ID, nil
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(rel.ID, "rId")); err == nil && n > next {
+			next = n
+		}
+	}
+
+	id := "rId" + strconv.Itoa(next+1)
+	rels.Relationships = append(rels.Relationships, &Relationship{
+		ID:     id,
+		Type:   constants.SourceRelationshipImage,
+		Target: target,
+	})
+	content, err := marshal(rels)
+	if err != nil {
+		return "", err
+	}
+	rd.FileMap.Store(rels.RelativePath, content)
+	return id, nil
+}
+
+// resolveImage fills in the media of an image from the relationships of its part.
+func (rd *RootDoc) resolveImage(img *Image, part imagePart) {
+	for _, rel := range part.rels {
+		if rel.ID != img.RelID || rel.TargetMode == "External" {
+			continue
+		}
+		target := partTarget(part.path, rel.Target)
+		content, ok := rd.FileMap.Load(target)
+		if !ok {
+			return
+		}
+		img.MediaPath = target
+		img.Data, _ = content.([]byte)
+		if info, err := DecodeImageInfo(img.Data); err == nil {
+			img.Format = info.Format
+		}
+		return
+	}
+}
+
+// walkTableParagraphs calls fn for every paragraph of a table, including nested tables.
+func walkTableParagraphs(t *ctypes.Table, fn func(p *ctypes.Paragraph)) {
+	for _, rc := range t.RowContents {
+		if rc.Row == nil {
+			continue
+		}
+		for _, cc := range rc.Row.Contents {
+			if cc.Cell == nil {
+				continue
+			}
+			for _, block := range cc.Cell.Contents {
+				switch {
+				case block.Paragraph != nil:
+					fn(block.Paragraph)
+				case block.Table != nil:
+					walkTableParagraphs(block.Table, fn)
+				}
+			}
+		}
+	}
+}
+
+// wrapTableParagraphs calls fn for the paragraphs of a table, including nested tables, for
+// which want returns true, in document order. As in Shape.Paragraphs, each of them is moved
+// into a wrapper and the cell refers to the wrapped copy, so changes through the wrapper
+// are made in the table.
+func wrapTableParagraphs(rd *RootDoc, t *ctypes.Table, want func(p *ctypes.Paragraph) bool, fn func(p *Paragraph)) {
+	for _, rc := range t.RowContents {
+		if rc.Row == nil {
+			continue
+		}
+		for _, cc := range rc.Row.Contents {
+			if cc.Cell == nil {
+				continue
+			}
+			for i := range cc.Cell.Contents {
+				block := &cc.Cell.Contents[i]
+				switch {
+				case block.Paragraph != nil:
+					if !want(block.Paragraph) {
+						continue
+					}
+					p := &Paragraph{root: rd, ct: *block.Paragraph}
+					block.Paragraph = &p.ct
+					fn(p)
+				case block.Table != nil:
+					wrapTableParagraphs(rd, block.Table, want, fn)
+				}
+			}
+		}
+	}
+}
+
+// paragraphImages returns the images of a paragraph without their part information.
+func paragraphImages(p *ctypes.Paragraph) []*Image {
+	var images []*Image
+	for _, run := range paragraphRuns(p) {
+		if run.AlternateContent != nil {
+			images = append(images, alternateContentImages(run.AlternateContent)...)
+		}
+		for _, rc := range run.Children {
+			switch {
+			case rc.Drawing != nil:
+				images = append(images, drawingImages(rc.Drawing)...)
+			case rc.Pict != nil:
+				images = append(images, pictImages(rc.Pict)...)
+			case rc.AlternateContent != nil:
+				images = append(images, alternateContentImages(rc.AlternateContent)...)
+			}
+		}
+	}
+	return images
+}
+
+// alternateContentImages returns the images of both branches of an mc:AlternateContent.
+func alternateContentImages(ac *ctypes.AlternateContent) []*Image {
+	var images []*Image
+	if ac.Choice != nil {
+		if ac.Choice.Drawing != nil {
+			images = append(images, drawingImages(ac.Choice.Drawing)...)
+		}
+		if ac.Choice.Pict != nil {
+			images = append(images, pictImages(ac.Choice.Pict)...)
+		}
+	}
+	if ac.Fallback != nil {
+		var fallback []*Image
+		if ac.Fallback.Drawing != nil {
+			fallback = append(fallback, drawingImages(ac.Fallback.Drawing)...)
+		}
+		if ac.Fallback.Pict != nil {
+			fallback = append(fallback, pictImages(ac.Fallback.Pict)...)
+		}
+		for _, img := range fallback {
+			img.Fallback = true
+		}
+		images = append(images, fallback...)
+	}
+	return images
+}
+
+// drawingImages returns the pictures of a drawing.
+func drawingImages(d *dml.Drawing) []*Image {
+	var images []*Image
+	for i := range d.Inline {
+		inline := &d.Inline[i]
+		images = append(images, graphicImages(&inline.Graphic, &inline.DocProp, inline.Extent.Width, inline.Extent.Height, false)...)
+	}
+	for _, a := range d.Anchor {
+		if a != nil {
+			images = append(images, graphicImages(&a.Graphic, &a.DocProp, a.Extent.Width, a.Extent.Height, true)...)
+		}
+	}
+	return images
+}
+
+// graphicImages returns the pictures of a graphic, directly or inside a group.
+func graphicImages(g *dml.Graphic, docProp *dml.DocProp, cx, cy uint64, floating bool) []*Image {
+	if g.Data == nil {
+		return nil
+	}
+
+	var images []*Image
+	if pic := g.Data.Pic; pic != nil && pic.BlipFill.Blip != nil {
+		img := picImage(pic, ImageKindPicture, floating)
+		img.Width, img.Height = units.Emu(cx), units.Emu(cy)
+		img.AltText, img.Title = docProp.Description, docProp.Title
+		img.ref.docProp = docProp
+		images = append(images, img)
+	}
+	if group := g.Data.WPGGroup; group != nil {
+		for _, pic := range group.Pictures() {
+			if pic.BlipFill.Blip == nil {
+				continue
+			}
+			img := picImage(pic, ImageKindGroup, floating)
+			if xfrm := pic.PicShapeProp.TransformGroup; xfrm != nil && xfrm.Extent != nil {
+				img.Width, img.Height = units.Emu(xfrm.Extent.Width), units.Emu(xfrm.Extent.Height)
+			}
+			images = append(images, img)
+		}
+	}
+	return images
+}
+
+// picImage returns the image of a picture with a blip.
+func picImage(pic *dmlpic.Pic, kind ImageKind, floating bool) *Image {
+	img := &Image{
+		Kind:     kind,
+		Floating: floating,
+		RelID:    pic.BlipFill.Blip.EmbedID,
+		ref:      &imageRef{blip: pic.BlipFill.Blip},
+	}
+	if cNvPr := pic.NonVisualPicProp.CNvPr; cNvPr != nil {
+		img.AltText = cNvPr.Description
+		img.ref.cNvPr = func(description string) { cNvPr.Description = description }
+	}
+	return img
+}
+
+// pictImages returns the VML images of a w:pict.
+func pictImages(pict *ctypes.Pict) []*Image {
+	var images []*Image
+	if s := pict.Shape; s != nil && s.ImageData != nil && s.ImageData.RId != "" {
+		img := &Image{
+			Kind:     ImageKindVML,
+			RelID:    s.ImageData.RId,
+			AltText:  s.Alt,
+			Title:    s.ImageData.Title,
+			Floating: vmlFloating(s.Style),
+			ref:      &imageRef{vml: &s.ImageData.RId, vmlAlt: &s.Alt, vmlName: &s.ImageData.Title},
+		}
+		img.Width, img.Height = vmlSize(s.Style)
+		images = append(images, img)
+	}
+	if g := pict.Group; g != nil && g.Shape != nil && g.Shape.ImageData != nil && g.Shape.ImageData.RId != "" {
+		s := g.Shape
+		img := &Image{
+			Kind:     ImageKindVML,
+			RelID:    s.ImageData.RId,
+			AltText:  s.Alt,
+			Title:    s.ImageData.Title,
+			Floating: vmlFloating(g.Style),
+			ref:      &imageRef{vml: &s.ImageData.RId, vmlAlt: &s.Alt, vmlName: &s.ImageData.Title},
+		}
+		img.Width, img.Height = vmlSize(s.Style)
+		images = append(images, img)
+	}
+	return images
+}
+
+// vmlFloating reports whether a VML style positions the shape absolutely.
+func vmlFloating(style string) bool {
+	return strings.Contains(strings.ReplaceAll(style, " ", ""), "position:absolute")
+}
+
+// vmlSize reads the width and height of a VML style such as "width:72pt;height:36pt".
+func vmlSize(style string) (units.Emu, units.Emu) {
+	var width, height units.Emu
+	for _, decl := range strings.Split(style, ";") {
+		name, value, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(name) {
+		case "width":
+			width = vmlLength(value)
+		case "height":
+			height = vmlLength(value)
+		}
+	}
+	return width, height
+}
+
+// vmlLength converts a VML length to EMUs; unknown units yield 0.
+func vmlLength(value string) units.Emu {
+	value = strings.TrimSpace(value)
+	scales := []struct {
+		suffix string
+		emu    float64
+	}{{"pt", 12700}, {"in", 914400}, {"cm", 360000}, {"mm", 36000}, {"px", 9525}}
+	for _, s := range scales {
+		if strings.HasSuffix(value, s.suffix) {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(value, s.suffix), 64)
+			if err != nil {
+				return 0
+			}
+			return units.Emu(v * s.emu)
+		}
+	}
+	return 0
+}
+
+// setRelID points the image at another relationship, dropping an SVG alternative that
+// would otherwise still show the old picture.
+func (r *imageRef) setRelID(id string) {
+	switch {
+	case r.blip != nil:
+		r.blip.EmbedID = id
+		r.dropSVG()
+	case r.vml != nil:
+		*r.vml = id
+	}
+}
+
+// dropSVG removes the SVG alternative of a DrawingML picture.
+func (r *imageRef) dropSVG() {
+	if r.blip == nil || r.blip.ExtLst == nil {
+		return
+	}
+	exts := r.blip.ExtLst.Exts[:0]
+	for _, ext := range r.blip.ExtLst.Exts {
+		if ext.SVGBlip == nil {
+			exts = append(exts, ext)
+		}
+	}
+	r.blip.ExtLst.Exts = exts
+	if len(exts) == 0 {
+		r.blip.ExtLst = nil
+	}
+}
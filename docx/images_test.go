@@ -0,0 +1,149 @@
+package docx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/common/units"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHeaderXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" xmlns:v="urn:schemas-microsoft-com:vml" xmlns:o="urn:schemas-microsoft-com:office:office" xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">
+<w:p><w:r><mc:AlternateContent><mc:Choice Requires="wps"><w:drawing><wp:inline><wp:extent cx="914400" cy="457200"/><wp:docPr id="20" name="Logo" descr="Company logo"/><a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture"><pic:pic><pic:nvPicPr><pic:cNvPr id="20" name="Logo"/><pic:cNvPicPr/></pic:nvPicPr><pic:blipFill><a:blip r:embed="rId1"/></pic:blipFill><pic:spPr/></pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></mc:Choice><mc:Fallback><w:pict><v:shape id="_x0000_i1025" type="#_x0000_t75" style="width:72pt;height:36pt"><v:imagedata r:id="rId1" o:title="logo"/></v:shape></w:pict></mc:Fallback></mc:AlternateContent></w:r></w:p>
+</w:hdr>`
+
+// withHeaderImage adds a header holding an image to a written document.
+func withHeaderImage(t *testing.T, doc []byte, img []byte) []byte {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(doc), int64(len(doc)))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	add := func(name string, content []byte) {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		if f.Name == "word/_rels/document.xml.rels" {
+			content = bytes.Replace(content, []byte("</Relationships>"), []byte(`<Relationship Id="rId99" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/></Relationships>`), 1)
+		}
+		add(f.Name, content)
+	}
+	add("word/header1.xml", []byte(testHeaderXML))
+	add("word/_rels/header1.xml.rels", []byte(`<?xml version="1.0" encoding="UTF-8"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/logo.png"/></Relationships>`))
+	add("word/media/logo.png", img)
+	require.NoError(t, zw.Close())
+	return out.Bytes()
+}
+
+func TestImages(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	photo := testPNG(t, 40, 20, 0)
+	logo := testPNG(t, 8, 8, 0)
+
+	_, err = rd.AddPictureFromBytes(photo, &docx.PictureOptions{Width: 2, AltText: "photo"})
+	require.NoError(t, err)
+	_, err = rd.AddFloatingPictureFromBytes(photo, &docx.PictureOptions{Width: 1}, nil)
+	require.NoError(t, err)
+	cell := rd.AddTable().AddRow().AddCell()
+	_, err = cell.AddEmptyPara().AddPictureFromBytes(logo, nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	content := withHeaderImage(t, buf.Bytes(), logo)
+	doc, err := packager.Unpack(&content)
+	require.NoError(t, err)
+
+	images, err := doc.Images()
+	require.NoError(t, err)
+	require.Len(t, images, 5)
+
+	assert.Equal(t, docx.ImageInBody, images[0].Location)
+	assert.Equal(t, "photo", images[0].AltText)
+	assert.Equal(t, "png", images[0].Format)
+	assert.Equal(t, units.Inch(2).ToEmu(), images[0].Width)
+	assert.Equal(t, photo, images[0].Data)
+	assert.False(t, images[0].Floating)
+
+	assert.True(t, images[1].Floating)
+	assert.Equal(t, images[0].MediaPath, images[1].MediaPath)
+	assert.Equal(t, 1, images[1].Block)
+
+	assert.True(t, images[2].InTable)
+	assert.Equal(t, logo, images[2].Data)
+
+	assert.Equal(t, docx.ImageInHeader, images[3].Location)
+	assert.Equal(t, "word/header1.xml", images[3].Part)
+	assert.Equal(t, "Company logo", images[3].AltText)
+	assert.Equal(t, "word/media/logo.png", images[3].MediaPath)
+	assert.False(t, images[3].Fallback)
+
+	assert.Equal(t, docx.ImageKindVML, images[4].Kind)
+	assert.True(t, images[4].Fallback)
+	assert.Equal(t, "logo", images[4].Title)
+	assert.Equal(t, units.Emu(914400), images[4].Width)
+	assert.Equal(t, "word/media/logo.png", images[4].MediaPath)
+
+	dir := t.TempDir()
+	files, err := doc.ExtractImages(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+	extracted, err := os.ReadFile(filepath.Join(dir, "logo.png"))
+	require.NoError(t, err)
+	assert.Equal(t, logo, extracted)
+
+	// Replacing shared media leaves the other references alone.
+	replacement := testPNG(t, 10, 30, 0)
+	require.NoError(t, doc.ReplaceImage(1, replacement))
+	require.NoError(t, doc.ReplaceImage(4, replacement))
+	require.NoError(t, doc.SetImageDescription(5, "Logo (legacy)", "logo"))
+	require.NoError(t, doc.SetImageDescription(3, "Small logo", "Logo"))
+	assert.Error(t, doc.ReplaceImage(9, replacement))
+	assert.Error(t, doc.ReplaceImage(1, []byte("not an image")))
+
+	images, err = doc.Images()
+	require.NoError(t, err)
+	assert.Equal(t, replacement, images[0].Data)
+	assert.Equal(t, units.Inch(2).ToEmu(), images[0].Width)
+	assert.Equal(t, photo, images[1].Data)
+	assert.Equal(t, replacement, images[3].Data)
+	assert.Equal(t, logo, images[4].Data)
+	assert.Equal(t, "Small logo", images[2].AltText)
+
+	buf.Reset()
+	require.NoError(t, doc.Write(&buf))
+	content = buf.Bytes()
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+	images, err = reopened.Images()
+	require.NoError(t, err)
+	require.Len(t, images, 5)
+	assert.Equal(t, replacement, images[0].Data)
+	assert.Equal(t, photo, images[1].Data)
+	assert.Equal(t, "Small logo", images[2].AltText)
+	assert.Equal(t, replacement, images[3].Data)
+	assert.Equal(t, logo, images[4].Data)
+	assert.Equal(t, "Logo (legacy)", images[4].AltText)
+	assert.True(t, strings.HasPrefix(images[3].MediaPath, "word/media/"))
+}
@@ -0,0 +1,368 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/common/constants"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// ListKind is the kind of numbering of a list created by AddList.
+type ListKind string
+
+const (
+	ListBullet           ListKind = "bullet"           // Bullets changing with the level
+	ListDecimal          ListKind = "decimal"          // 1. a. i. A. cycling with the level
+	ListLowerLetter      ListKind = "lowerLetter"      // a. b. c. at every level
+	ListUpperLetter      ListKind = "upperLetter"      // A. B. C. at every level
+	ListLowerRoman       ListKind = "lowerRoman"       // i. ii. iii. at every level
+	ListUpperRoman       ListKind = "upperRoman"       // I. II. III. at every level
+	ListChineseCounting  ListKind = "chineseCounting"  // 一、二、三、 at every level
+	ListIdeographDigital ListKind = "ideographDigital" // 一. 二. 三. at every level
+	ListLegal            ListKind = "legal"            // 1. 1.1. 1.1.1. with decimal numbers of every level
+)
+
+// listParagraphStyle is the built-in style Word gives to list items.
+const listParagraphStyle = "ListParagraph"
+
+// defaultPictureBulletSize is the size of picture bullets in points when none is given.
+const defaultPictureBulletSize = 9
+
+// List adds numbered or bulleted paragraphs at the end of the document. A list owns a
+// numbering definition, created with the first item, so its numbering is independent of
+// any other list; Continue and Restart derive lists that go on with its numbering or
+// restart it.
+//
+// Example:
+//
+//	list := document.AddList(docx.ListDecimal)
+//	list.Item("Prepare")
+//	list.Item("Cook").Sub("Boil water", "Add pasta")
+//	list.Item("Serve")
+type List struct {
+	state *listState
+	level int
+	up    *List
+}
+
+// listState is shared by a list and its sub-lists.
+type listState struct {
+	root *RootDoc
+
+	// def is the definition of a new list; nil once saved, and for lists derived from
+	// existing numbering.
+	def *NumberingBuilder
+
+	abstractID int
+	numID      int
+	overrides  []ctypes.LvlOverride
+	err        error
+}
+
+// ListItem is a paragraph of a list.
+type ListItem struct {
+	*Paragraph
+	list *List
+}
+
+// AddList returns a new list of the given kind. Its levels can be customized with Level,
+// Start, Bullet and PictureBullet until the first item is added.
+func (rd *RootDoc) AddList(kind ListKind) *List {
+	state := &listState{root: rd, def: rd.NewNumberingDefinition("")}
+	b := state.def
+	if kind == ListLegal {
+		b.MultiLevelType("multilevel")
+	}
+	for ilvl := 0; ilvl <= maxNumberingLevel; ilvl++ {
+		left, hanging := 720*(ilvl+1), uint64(360)
+		if kind == ListLegal {
+			// Legal labels widen with the depth; give them room.
+			left, hanging = 432*(ilvl+1)+288, uint64(432+144*ilvl)
+		}
+		lvl := b.Level(ilvl).Start(1).Justification(stypes.JustificationLeft).Indent(left, hanging)
+		label := "%" + strconv.Itoa(ilvl+1)
+		switch kind {
+		case ListBullet:
+			glyph, font := bulletGlyphForLevel(ilvl)
+			lvl.Format(stypes.NumFmtBullet).Text(glyph).Font(font)
+		case ListDecimal:
+			lvl.Format(orderedNumFmtForLevel(ilvl)).Text(label + ".")
+		case ListLegal:
+			var text strings.Builder
+			for i := 1; i <= ilvl+1; i++ {
+				text.WriteString("%" + strconv.Itoa(i) + ".")
+			}
+			lvl.Format(stypes.NumFmtDecimal).Text(text.String()).Legal(ilvl > 0)
+		case ListChineseCounting:
+			lvl.Format(stypes.NumFmtChineseCounting).Text(label + "、")
+		case ListLowerLetter, ListUpperLetter, ListLowerRoman, ListUpperRoman, ListIdeographDigital:
+			lvl.Format(stypes.NumFmt(kind)).Text(label + ".")
+		default:
+			b.err = fmt.Errorf("unknown list kind %q", kind)
+		}
+	}
+	return &List{state: state}
+}
+
+// ListOf returns the list paragraph p belongs to, at the level of p. Items added to it
+// continue its numbering; Restart derives a list starting over.
+func (rd *RootDoc) ListOf(p *Paragraph) (*List, error) {
+	lvl, err := p.NumberingLevel()
+	if err != nil {
+		return nil, err
+	}
+	if lvl == nil {
+		return nil, errors.New("paragraph is not numbered")
+	}
+	state := &listState{root: rd, abstractID: lvl.Num.AbstractNumID.Val, numID: lvl.NumID}
+	return &List{state: state, level: lvl.Level}, nil
+}
+
+// Level returns a builder for level ilvl of the definition of a new list, to change the
+// format, label, indentation or font of its numbers.
+//
+// Example:
+//
+//	list := document.AddList(docx.ListDecimal)
+//	list.Level(0).Format(stypes.NumFmtUpperRoman).Text("%1)")
+func (l *List) Level(ilvl int) *NumberingLevelBuilder {
+	def := l.state.def
+	if def == nil {
+		// Changes to a saved definition are dropped; report them on the next item.
+		def = &NumberingBuilder{root: l.state.root}
+		l.state.fail(errors.New("list definition can only change before the first item"))
+	}
+	return def.Level(ilvl)
+}
+
+// Start sets the first number of the list.
+func (l *List) Start(n int) *List {
+	l.Level(l.level).Start(n)
+	return l
+}
+
+// Bullet makes level ilvl a bullet level showing glyph in font, such as "■" in
+// "Wingdings" or "–" in "Arial".
+func (l *List) Bullet(ilvl int, glyph, font string) *List {
+	lvl := l.Level(ilvl).Format(stypes.NumFmtBullet).Text(glyph)
+	if font != "" {
+		lvl.Font(font)
+	}
+	return l
+}
+
+// PictureBullet makes level ilvl a bullet level showing the image data, sizePt points
+// wide and high (9 when 0). The image is stored with the numbering definitions.
+func (l *List) PictureBullet(ilvl int, data []byte, sizePt float64) *List {
+	if sizePt <= 0 {
+		sizePt = defaultPictureBulletSize
+	}
+	id, err := l.state.root.addPictureBullet(data, sizePt)
+	if err != nil {
+		l.state.fail(err)
+		return l
+	}
+	lvl := l.Level(ilvl).Format(stypes.NumFmtBullet).Text("")
+	lvl.GetCT().LvlPicBulletID = ctypes.NewDecimalNum(id)
+	lvl.GetCT().RPr = nil
+	return l
+}
+
+// Item adds a paragraph with text at the end of the document as the next item of the list.
+func (l *List) Item(text string) *ListItem {
+	p := l.state.root.AddParagraph(text)
+	if err := l.state.ensure(); err != nil {
+		l.state.fail(err)
+		return &ListItem{Paragraph: p, list: l}
+	}
+	if l.state.root.GetStyleByID(listParagraphStyle, stypes.StyleTypeParagraph) != nil {
+		p.Style(listParagraphStyle)
+	}
+	p.Numbering(l.state.numID, l.level)
+	return &ListItem{Paragraph: p, list: l}
+}
+
+// Sub returns the list one level below the item, with the texts added as its first items.
+// Items are added at the end of the document, so the sub-items of an item must be added
+// before the next item of its level.
+//
+// Example:
+//
+//	item := list.Item("Fruit")
+//	item.Sub("Apples", "Pears").Item("Plums").Sub("Damson")
+func (i *ListItem) Sub(texts ...string) *List {
+	sub := &List{state: i.list.state, level: i.list.level + 1, up: i.list}
+	if sub.level > maxNumberingLevel {
+		sub.level = maxNumberingLevel
+	}
+	for _, text := range texts {
+		sub.Item(text)
+	}
+	return sub
+}
+
+// Parent returns the list one level above, or the list itself at the top level.
+func (l *List) Parent() *List {
+	if l.up == nil {
+		return l
+	}
+	return l.up
+}
+
+// Continue returns a list at the top level that goes on with the numbering of l, for items
+// following other content.
+func (l *List) Continue() *List {
+	return &List{state: l.state}
+}
+
+// Restart returns a list with the levels of l whose numbering starts over at start.
+//
+// Example:
+//
+//	steps := document.AddList(docx.ListDecimal)
+//	steps.Item("One")
+//	document.AddParagraph("Second part")
+//	steps.Restart(1).Item("One again")
+func (l *List) Restart(start int) *List {
+	state := &listState{root: l.state.root}
+	if err := l.state.ensure(); err != nil {
+		state.err = err
+	} else {
+		state.abstractID = l.state.abstractID
+		state.overrides = []ctypes.LvlOverride{{Ilvl: 0, StartOverride: ctypes.NewDecimalNum(start)}}
+	}
+	return &List{state: state}
+}
+
+// NumID returns the numbering instance of the list, 0 before the first item.
+func (l *List) NumID() int {
+	return l.state.numID
+}
+
+// Err returns the first error met while building the list.
+func (l *List) Err() error {
+	return l.state.err
+}
+
+func (s *listState) fail(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// ensure creates the numbering definition and instance of the list.
+func (s *listState) ensure() error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.numID != 0 {
+		return nil
+	}
+	if s.def != nil {
+		id, err := s.def.Save()
+		if err != nil {
+			return err
+		}
+		s.abstractID = id
+		s.def = nil
+	}
+	numID, err := s.root.NewNumberingInstance(s.abstractID, s.overrides...)
+	if err != nil {
+		return err
+	}
+	s.numID = numID
+	return nil
+}
+
+// addPictureBullet stores an image as a picture bullet of the numbering part and returns
+// its numPicBulletId.
+func (rd *RootDoc) addPictureBullet(data []byte, sizePt float64) (int, error) {
+	if rd.Numbering == nil {
+		return 0, errors.New("document has no numbering manager")
+	}
+	info, err := DecodeImageInfo(data)
+	if err != nil {
+		return 0, err
+	}
+	if info.Format == "svg" {
+		return 0, errors.New("picture bullets cannot be SVG images")
+	}
+	mediaPath, err := rd.storeMedia(data, info.Format)
+	if err != nil {
+		return 0, err
+	}
+	// Flush pending instances so that the numbering part exists.
+	if err := rd.Numbering.applyToFileMap(); err != nil {
+		return 0, err
+	}
+	relID, err := rd.partImageRelation(numberingPartPath, mediaPath)
+	if err != nil {
+		return 0, err
+	}
+	return rd.Numbering.addPicBullet(relID, sizePt)
+}
+
+// addPicBullet adds a w:numPicBullet showing the image relID to the numbering part, ahead
+// of the numbering definitions, and returns its ID.
+func (nm *NumberingManager) addPicBullet(relID string, sizePt float64) (int, error) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	content := nm.partContent()
+	id, err := nextPicBulletID(content)
+	if err != nil {
+		return 0, err
+	}
+	spans, err := numberingSpans(content)
+	if err != nil {
+		return 0, err
+	}
+
+	pos := bytes.LastIndex(content, numberingCloseTag)
+	if pos < 0 {
+		return 0, errors.New("numbering part is malformed")
+	}
+	if len(spans) > 0 {
+		pos = int(spans[0].start)
+	}
+
+	size := strconv.FormatFloat(sizePt, 'f', -1, 64) + "pt"
+	frag := fmt.Sprintf(`<w:numPicBullet w:numPicBulletId="%d"><w:pict>`+
+		`<v:shape xmlns:v="%s" xmlns:o="%s" id="_x0000_i%d" type="#_x0000_t75" style="width:%s;height:%s" o:bullet="t">`+
+		`<v:imagedata xmlns:r="%s" r:id="%s" o:title=""/></v:shape></w:pict></w:numPicBullet>`,
+		id, constants.XMLNS_V, constants.XMLNS_O, 1025+id, size, size, constants.SourceRelationship.Value, relID)
+
+	updated := make([]byte, 0, len(content)+len(frag))
+	updated = append(updated, content[:pos]...)
+	updated = append(updated, frag...)
+	updated = append(updated, content[pos:]...)
+	nm.rootDoc.FileMap.Store(numberingPartPath, updated)
+	return id, nil
+}
+
+// nextPicBulletID returns an unused numPicBulletId of the numbering part.
+func nextPicBulletID(content []byte) (int, error) {
+	next := 0
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return next, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if elem, ok := tok.(xml.StartElement); ok && elem.Name.Local == "numPicBullet" {
+			if id, err := strconv.Atoi(attrValue(elem, "numPicBulletId")); err == nil && id >= next {
+				next = id + 1
+			}
+		}
+	}
+}
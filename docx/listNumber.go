@@ -0,0 +1,239 @@
+package docx
+
+import (
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// ListNumber is the number Word displays in front of a numbered paragraph.
+type ListNumber struct {
+	Paragraph *ctypes.Paragraph
+
+	NumID         int
+	Level         int
+	AbstractNumID int
+
+	// Value is the counter of the level of the paragraph.
+	Value int
+
+	// Format is the numbering format of the level, bullet for bullets.
+	Format stypes.NumFmt
+
+	// Text is the level text with the counters of the levels filled in, such as "3.2.a"
+	// or a bullet glyph. It is empty for picture bullets.
+	Text string
+
+	// Suffix separates the number from the paragraph text: "tab", "space" or "nothing".
+	Suffix string
+}
+
+// listCounters holds the counters of the levels of one abstract numbering definition.
+type listCounters struct {
+	values  [maxNumberingLevel + 1]int
+	started [maxNumberingLevel + 1]bool
+}
+
+// NumberingEvaluator computes list numbers by following numbered paragraphs in document
+// order. Paragraphs whose numbering instances share an abstract definition continue each
+// other's numbering; an instance with start overrides starts it over at its first
+// paragraph.
+//
+// Example:
+//
+//	eval := document.NewNumberingEvaluator()
+//	for _, p := range paragraphs {
+//		if num := eval.Next(p.GetCT()); num != nil {
+//			fmt.Println(num.Text, p.Text())
+//		}
+//	}
+type NumberingEvaluator struct {
+	sr       *StyleResolver
+	counters map[int]*listCounters
+	seen     map[int]bool
+}
+
+// NewNumberingEvaluator returns an evaluator bound to the current styles and numbering of
+// the document.
+func (rd *RootDoc) NewNumberingEvaluator() *NumberingEvaluator {
+	return &NumberingEvaluator{
+		sr:       rd.NewStyleResolver(),
+		counters: make(map[int]*listCounters),
+		seen:     make(map[int]bool),
+	}
+}
+
+// Next counts p, the next paragraph in document order, and returns its list number. It
+// returns nil when the paragraph is not numbered, whether directly or through its style.
+func (e *NumberingEvaluator) Next(p *ctypes.Paragraph) *ListNumber {
+	if e.sr.numbering == nil || p == nil {
+		return nil
+	}
+	numPr := e.sr.Paragraph(p, nil).NumProp
+	if numPr == nil || numPr.NumID == nil || numPr.NumID.Val == 0 {
+		return nil
+	}
+	numID := numPr.NumID.Val
+
+	ilvl, ok := e.paragraphLevel(p, numPr)
+	if !ok || ilvl < 0 || ilvl > maxNumberingLevel {
+		return nil
+	}
+	lvl := e.level(numID, ilvl)
+	if lvl == nil || lvl.Definition == nil {
+		return nil
+	}
+
+	counters := e.counters[lvl.AbstractNumID]
+	if counters == nil {
+		counters = &listCounters{}
+		e.counters[lvl.AbstractNumID] = counters
+	}
+	if !e.seen[numID] {
+		// An instance overriding the start of a level starts its numbering over.
+		e.seen[numID] = true
+		for _, o := range lvl.Num.Overrides {
+			if o.Ilvl >= 0 && o.Ilvl <= maxNumberingLevel && (o.StartOverride != nil || (o.Lvl != nil && o.Lvl.Start != nil)) {
+				counters.started[o.Ilvl] = false
+			}
+		}
+	}
+
+	if counters.started[ilvl] {
+		counters.values[ilvl]++
+	} else {
+		counters.values[ilvl] = lvl.Start
+		counters.started[ilvl] = true
+	}
+	for deeper := ilvl + 1; deeper <= maxNumberingLevel; deeper++ {
+		if restartsAfter(e.level(numID, deeper), ilvl) {
+			counters.started[deeper] = false
+		}
+	}
+
+	num := &ListNumber{
+		Paragraph:     p,
+		NumID:         numID,
+		Level:         ilvl,
+		AbstractNumID: lvl.AbstractNumID,
+		Value:         counters.values[ilvl],
+		Format:        stypes.NumFmtDecimal,
+		Suffix:        "tab",
+	}
+	def := lvl.Definition
+	if def.NumFmt != nil {
+		num.Format = def.NumFmt.Val
+	}
+	if def.Suff != nil {
+		num.Suffix = def.Suff.Val
+	}
+	if def.LvlText != nil {
+		num.Text = e.levelText(def.LvlText.Val, numID, counters, onOffValue(def.IsLgl))
+	}
+	return num
+}
+
+// paragraphLevel returns the numbering level of a paragraph. Without an explicit level, a
+// paragraph style linked to a level of the list (w:pStyle), as outline numbered headings
+// are, selects that level.
+func (e *NumberingEvaluator) paragraphLevel(p *ctypes.Paragraph, numPr *ctypes.NumProp) (int, bool) {
+	if numPr.ILvl != nil {
+		return numPr.ILvl.Val, true
+	}
+	lvl := e.level(numPr.NumID.Val, 0)
+	if lvl == nil {
+		return 0, false
+	}
+	if style := paragraphStyleID(p); style != "" {
+		for _, def := range lvl.Abstract.Levels {
+			if def.PStyle != nil && def.PStyle.Val == style {
+				return def.Ilvl, true
+			}
+		}
+	}
+	return 0, true
+}
+
+// level resolves a level of a numbering instance.
+func (e *NumberingEvaluator) level(numID, ilvl int) *NumberingLevel {
+	return resolveNumberingLevel(e.sr.numbering, func(id string) *ctypes.Style {
+		return e.sr.styles[stypes.StyleTypeNumbering][id]
+	}, numID, ilvl)
+}
+
+// levelText fills in the %1 to %9 placeholders of a level text with the counters of the
+// levels, in their own formats or in decimal for a legal numbering level.
+func (e *NumberingEvaluator) levelText(text string, numID int, counters *listCounters, legal bool) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '%' || i+1 == len(text) || text[i+1] < '1' || text[i+1] > '9' {
+			b.WriteByte(text[i])
+			continue
+		}
+		ilvl := int(text[i+1] - '1')
+		i++
+
+		lvl := e.level(numID, ilvl)
+		value := counters.values[ilvl]
+		if !counters.started[ilvl] && lvl != nil {
+			value = lvl.Start
+		}
+		format := stypes.NumFmtDecimal
+		if !legal && lvl != nil && lvl.Definition != nil && lvl.Definition.NumFmt != nil {
+			format = lvl.Definition.NumFmt.Val
+		}
+		b.WriteString(FormatNumber(value, format))
+	}
+	return b.String()
+}
+
+// restartsAfter reports whether a level starts over when a paragraph of level ilvl, a
+// higher level, is numbered. Levels restart after any higher level unless lvlRestart
+// limits it to the levels up to its value, or turns restarting off with 0.
+func restartsAfter(lvl *NumberingLevel, ilvl int) bool {
+	if lvl == nil || lvl.Definition == nil || lvl.Definition.LvlRestart == nil {
+		return true
+	}
+	restart := lvl.Definition.LvlRestart.Val
+	return restart != 0 && ilvl < restart
+}
+
+// paragraphStyleID returns the ID of the style of a paragraph, or an empty string.
+func paragraphStyleID(p *ctypes.Paragraph) string {
+	if p.Property == nil || p.Property.Style == nil {
+		return ""
+	}
+	return p.Property.Style.Val
+}
+
+// ListNumbers returns the list numbers of the numbered paragraphs of the document body, in
+// document order, including the paragraphs of tables.
+//
+// Example:
+//
+//	for _, num := range document.ListNumbers() {
+//		fmt.Printf("%*s%s\n", num.Level*2, "", num.Text)
+//	}
+func (rd *RootDoc) ListNumbers() []ListNumber {
+	if rd.Document == nil || rd.Document.Body == nil {
+		return nil
+	}
+
+	eval := rd.NewNumberingEvaluator()
+	var numbers []ListNumber
+	add := func(p *ctypes.Paragraph) {
+		if num := eval.Next(p); num != nil {
+			numbers = append(numbers, *num)
+		}
+	}
+	for _, child := range rd.Document.Body.Children {
+		switch {
+		case child.Para != nil:
+			add(child.Para.GetCT())
+		case child.Table != nil:
+			walkTableParagraphs(&child.Table.ct, add)
+		}
+	}
+	return numbers
+}
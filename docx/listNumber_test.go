@@ -0,0 +1,139 @@
+package docx_test
+
+import (
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListNumbers(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	// Outline numbered headings find their level through the styles linked to the levels.
+	outline := rd.NewNumberingDefinition("Chapters").MultiLevelType("multilevel")
+	outline.Level(0).Format(stypes.NumFmtChineseCounting).Text("第%1章").Style("Chapter")
+	outline.Level(1).Format(stypes.NumFmtDecimal).Text("%1.%2").Style("Section").Start(1).Legal(true)
+	outline.Level(0).Start(1)
+	abstractID, err := outline.Save()
+	require.NoError(t, err)
+	outlineID, err := rd.NewNumberingInstance(abstractID)
+	require.NoError(t, err)
+	for _, id := range []string{"Chapter", "Section"} {
+		_, err = rd.NewParagraphStyle(id, id).ParagraphProperty(&ctypes.ParagraphProp{
+			NumProp: &ctypes.NumProp{NumID: ctypes.NewDecimalNum(outlineID)},
+		}).Save()
+		require.NoError(t, err)
+	}
+	heading := func(style, text string) {
+		rd.AddParagraph(text).Style(style)
+	}
+
+	heading("Chapter", "Introduction")
+	steps := rd.AddList(docx.ListDecimal)
+	steps.Level(1).Format(stypes.NumFmtLowerLetter).Text("%1.%2")
+	steps.Level(2).Format(stypes.NumFmtLowerRoman).Text("(%3)").Restart(1)
+	steps.Item("One")
+	two := steps.Item("Two").Sub("Two a")
+	two.Item("Two b").Sub("Two b i", "Two b ii")
+	// Level 3 only restarts after level 1, so it goes on after "Two c".
+	two.Item("Two c").Sub("Two c iii")
+	steps.Item("Three").Sub("Three a")
+	heading("Section", "Scope")
+	heading("Section", "Terms")
+
+	heading("Chapter", "Method")
+	rd.AddParagraph("Not numbered")
+	steps.Restart(5).Item("Five again")
+	steps.Continue().Item("Six")
+
+	legal := rd.AddList(docx.ListUpperRoman)
+	legal.Level(1).Text("%1.%2").Legal(true)
+	legal.Item("Part").Sub("Clause")
+
+	bullets := rd.AddList(docx.ListBullet)
+	bullets.Item("Point")
+	heading("Section", "Data")
+
+	table := addGridTable(rd, 1, 1)
+	table.Rows()[0].Cells()[0].GetCT().Contents[0].Paragraph.Property = &ctypes.ParagraphProp{
+		Style: ctypes.NewParagraphStyle("Section"),
+	}
+	for _, list := range []*docx.List{steps, legal, bullets} {
+		require.NoError(t, list.Err())
+	}
+
+	var texts []string
+	numbers := rd.ListNumbers()
+	for _, num := range numbers {
+		texts = append(texts, num.Text)
+	}
+	assert.Equal(t, []string{
+		"第一章",
+		"1.", "2.", "2.a", "2.b", "(i)", "(ii)", "2.c", "(iii)", "3.", "3.a",
+		"1.1", "1.2",
+		"第二章",
+		"5.", "6.",
+		"I.", "1.1",
+		"\uf0b7", // the Symbol font bullet
+		"2.1", "2.2",
+	}, texts)
+
+	assert.Equal(t, 1, numbers[4].Level)
+	assert.Equal(t, 2, numbers[4].Value)
+	assert.Equal(t, stypes.NumFmtLowerLetter, numbers[4].Format)
+	assert.Equal(t, "tab", numbers[4].Suffix)
+	assert.Equal(t, outlineID, numbers[0].NumID)
+	assert.Equal(t, abstractID, numbers[0].AbstractNumID)
+	assert.Equal(t, stypes.NumFmtBullet, numbers[18].Format)
+	assert.Equal(t, 6, numbers[15].Value, "continuing goes on after the restart")
+
+	eval := rd.NewNumberingEvaluator()
+	assert.Nil(t, eval.Next(rd.AddParagraph("Plain").GetCT()))
+}
+
+func TestFormatNumber(t *testing.T) {
+	for _, tc := range []struct {
+		n      int
+		format stypes.NumFmt
+		want   string
+	}{
+		{7, stypes.NumFmtDecimal, "7"},
+		{7, stypes.NumFmtDecimalZero, "07"},
+		{1994, stypes.NumFmtUpperRoman, "MCMXCIV"},
+		{4, stypes.NumFmtLowerRoman, "iv"},
+		{3, stypes.NumFmtLowerLetter, "c"},
+		{28, stypes.NumFmtUpperLetter, "BB"},
+		{12, stypes.NumFmtOrdinal, "12th"},
+		{22, stypes.NumFmtOrdinal, "22nd"},
+		{121, stypes.NumFmtCardinalText, "One hundred twenty-one"},
+		{20, stypes.NumFmtOrdinalText, "Twentieth"},
+		{12, stypes.NumFmtOrdinalText, "Twelfth"},
+		{255, stypes.NumFmtHex, "FF"},
+		{6, stypes.NumFmtChicago, "††"},
+		{3, stypes.NumFmtDecimalEnclosedCircle, "③"},
+		{21, stypes.NumFmtDecimalEnclosedCircle, "21"},
+		{12, stypes.NumFmtDecimalFullWidth, "１２"},
+		{2024, stypes.NumFmtIdeographDigital, "二〇二四"},
+		{12, stypes.NumFmtChineseCounting, "十二"},
+		{101, stypes.NumFmtChineseCounting, "一百零一"},
+		{110, stypes.NumFmtChineseCounting, "一百一十"},
+		{10010, stypes.NumFmtChineseCounting, "一万零一十"},
+		{115, stypes.NumFmtJapaneseCounting, "百十五"},
+		{12, stypes.NumFmtChineseLegalSimplified, "壹拾贰"},
+		{11, stypes.NumFmtIdeographTraditional, "甲"},
+		{3, stypes.NumFmtIdeographZodiac, "寅"},
+		{6, stypes.NumFmtRussianLower, "е"},
+		{2, stypes.NumFmtNumberInDash, "- 2 -"},
+		{2, stypes.NumFmtBullet, ""},
+		{2, stypes.NumFmtNone, ""},
+		{2, stypes.NumFmtHebrew1, "2"},
+	} {
+		assert.Equal(t, tc.want, docx.FormatNumber(tc.n, tc.format), "%d as %s", tc.n, tc.format)
+	}
+}
@@ -0,0 +1,101 @@
+package docx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	list := rd.AddList(docx.ListDecimal).Start(3)
+	list.Level(1).Format(stypes.NumFmtUpperRoman).Text("%2)")
+	first := list.Item("Prepare")
+	sub := list.Item("Cook").Sub("Boil water", "Add pasta")
+	deep := sub.Item("Stir").Sub("Gently")
+	assert.Same(t, sub, deep.Parent())
+	list.Item("Serve")
+	require.NoError(t, list.Err())
+
+	lvl, err := first.NumberingLevel()
+	require.NoError(t, err)
+	assert.Equal(t, 3, lvl.Start)
+	assert.Equal(t, stypes.NumFmtDecimal, lvl.Definition.NumFmt.Val)
+	assert.Equal(t, "%1.", lvl.Definition.LvlText.Val)
+
+	var levels []int
+	for _, child := range rd.Document.Body.Children {
+		if p := child.Para; p != nil && p.GetCT().Property != nil && p.GetCT().Property.NumProp != nil {
+			assert.Equal(t, list.NumID(), p.GetCT().Property.NumProp.NumID.Val)
+			levels = append(levels, p.GetCT().Property.NumProp.ILvl.Val)
+		}
+	}
+	assert.Equal(t, []int{0, 0, 1, 1, 1, 2, 0}, levels)
+
+	lvl, err = rd.NumberingLevel(list.NumID(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, stypes.NumFmtUpperRoman, lvl.Definition.NumFmt.Val)
+
+	// Continuing shares the numbering instance; restarting overrides the first number.
+	rd.AddParagraph("Interlude")
+	assert.Equal(t, list.NumID(), list.Continue().Item("Clean up").GetCT().Property.NumProp.NumID.Val)
+	restarted := list.Restart(1)
+	item := restarted.Item("Again")
+	assert.NotEqual(t, list.NumID(), restarted.NumID())
+	lvl, err = item.NumberingLevel()
+	require.NoError(t, err)
+	assert.Equal(t, 1, lvl.Start)
+	assert.Equal(t, "%1.", lvl.Definition.LvlText.Val)
+
+	resumed, err := rd.ListOf(first.Paragraph)
+	require.NoError(t, err)
+	assert.Equal(t, list.NumID(), resumed.NumID())
+
+	legal := rd.AddList(docx.ListLegal)
+	legal.Item("Scope").Sub("Terms").Item("Parties").Sub("Buyer")
+	require.NoError(t, legal.Err())
+	lvl, err = rd.NumberingLevel(legal.NumID(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, "%1.%2.%3.", lvl.Definition.LvlText.Val)
+	assert.NotNil(t, lvl.Definition.IsLgl)
+	legal.Level(0).Start(5)
+	assert.Error(t, legal.Err(), "the definition is saved")
+
+	chinese := rd.AddList(docx.ListChineseCounting)
+	chinese.Item("第一")
+	lvl, err = rd.NumberingLevel(chinese.NumID(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, stypes.NumFmtChineseCounting, lvl.Definition.NumFmt.Val)
+	assert.Equal(t, "%1、", lvl.Definition.LvlText.Val)
+
+	bullets := rd.AddList(docx.ListBullet).
+		Bullet(0, "–", "Arial").
+		PictureBullet(1, testPNG(t, 12, 12, 96), 0)
+	bullets.Item("Dash").Sub("Picture")
+	require.NoError(t, bullets.Err())
+	lvl, err = rd.NumberingLevel(bullets.NumID(), 1)
+	require.NoError(t, err)
+	require.NotNil(t, lvl.Definition.LvlPicBulletID)
+	assert.Equal(t, 0, lvl.Definition.LvlPicBulletID.Val)
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	parts := zipParts(t, buf.Bytes())
+	numbering := string(parts["word/numbering.xml"])
+	assert.Contains(t, numbering, `<w:numPicBullet w:numPicBulletId="0">`)
+	assert.Less(t, strings.Index(numbering, "<w:numPicBullet"), strings.Index(numbering, "<w:abstractNum"))
+	assert.Contains(t, numbering, `<w:lvlText w:val="–"></w:lvlText>`)
+	assert.Contains(t, string(parts["word/_rels/numbering.xml.rels"]), `Target="media/image1.png"`)
+
+	unknown := rd.AddList(docx.ListKind("stars"))
+	unknown.Item("x")
+	assert.Error(t, unknown.Err())
+}
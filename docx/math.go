@@ -0,0 +1,98 @@
+package docx
+
+import (
+	"github.com/iEvan-lhr/docx-agent/omml"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+)
+
+// Equation is an equation of the document. The embedded Math gives its content and its
+// LaTeX, MathML and linear text forms.
+type Equation struct {
+	*omml.Math
+
+	// Para is the paragraph holding the equation.
+	Para *Paragraph
+
+	// Display reports whether the equation is displayed on its own line, inside a math
+	// paragraph, rather than inline with the text.
+	Display bool
+}
+
+// AddMath converts LaTeX math to an equation and appends it inline to the paragraph.
+//
+// Example:
+//
+//	p := document.AddParagraph("The area is ")
+//	p.AddMath(`A = \pi r^2`)
+func (p *Paragraph) AddMath(latex string) (*omml.Math, error) {
+	m, err := omml.FromLaTeX(latex)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ct.Children = append(p.ct.Children, ctypes.ParagraphChild{Math: m})
+	return m, nil
+}
+
+// AddEquation converts LaTeX math to an equation and adds it to the document, displayed
+// in a paragraph of its own.
+func (rd *RootDoc) AddEquation(latex string) (*Paragraph, error) {
+	m, err := omml.FromLaTeX(latex)
+	if err != nil {
+		return nil, err
+	}
+
+	p := newParagraph(rd)
+	p.ct.Children = append(p.ct.Children, ctypes.ParagraphChild{
+		MathPara: &omml.MathPara{Equations: []*omml.Math{m}},
+	})
+	rd.Document.Body.Children = append(rd.Document.Body.Children, DocumentChild{Para: p})
+	return p, nil
+}
+
+// Equations returns the equations of the paragraph in document order.
+func (p *Paragraph) Equations() []*Equation {
+	var equations []*Equation
+	for _, child := range p.ct.Children {
+		switch {
+		case child.Math != nil:
+			equations = append(equations, &Equation{Math: child.Math, Para: p})
+		case child.MathPara != nil:
+			for _, m := range child.MathPara.Equations {
+				equations = append(equations, &Equation{Math: m, Para: p, Display: true})
+			}
+		}
+	}
+	return equations
+}
+
+// Equations returns the equations of the document body, including those in tables, in
+// document order.
+func (rd *RootDoc) Equations() []*Equation {
+	if rd.Document == nil || rd.Document.Body == nil {
+		return nil
+	}
+
+	var equations []*Equation
+	for _, child := range rd.Document.Body.Children {
+		switch {
+		case child.Para != nil:
+			equations = append(equations, child.Para.Equations()...)
+		case child.Table != nil:
+			wrapTableParagraphs(rd, &child.Table.ct, hasMath, func(p *Paragraph) {
+				equations = append(equations, p.Equations()...)
+			})
+		}
+	}
+	return equations
+}
+
+// hasMath reports whether the paragraph holds an equation.
+func hasMath(p *ctypes.Paragraph) bool {
+	for _, child := range p.Children {
+		if child.Math != nil || child.MathPara != nil {
+			return true
+		}
+	}
+	return false
+}
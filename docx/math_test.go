@@ -0,0 +1,65 @@
+package docx_test
+
+import (
+	"bytes"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMathXML is a paragraph with an inline equation as Word writes it.
+const testMathXML = `<w:p><w:r><w:t xml:space="preserve">Euler: </w:t></w:r><m:oMath><m:sSup><m:sSupPr><m:ctrlPr><w:rPr><w:i/></w:rPr></m:ctrlPr></m:sSupPr><m:e><m:r><m:t>e</m:t></m:r></m:e><m:sup><m:r><m:t>iπ</m:t></m:r></m:sup></m:sSup><m:r><m:t>+1=0</m:t></m:r></m:oMath></w:p>`
+
+func TestEquations(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	p := rd.AddParagraph("Area: ")
+	_, err = p.AddMath(`A = \pi r^2`)
+	require.NoError(t, err)
+	_, err = rd.AddEquation(`x = \frac{-b \pm \sqrt{b^2-4ac}}{2a}`)
+	require.NoError(t, err)
+	_, err = rd.AddEquation(`\frac{1}{`)
+	assert.Error(t, err)
+
+	cell := rd.AddTable().AddRow().AddCell()
+	_, err = cell.AddParagraph("").AddMath(`\sum_{i=1}^{n} i`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Area: A=πr^2", p.Text())
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	content := withBodyXML(t, buf.Bytes(), testMathXML)
+	assert.Contains(t, documentXML(t, content), `<m:oMathPara xmlns:m="http://schemas.openxmlformats.org/officeDocument/2006/math"><m:oMath><m:r>`)
+
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+	equations := reopened.Equations()
+	require.Len(t, equations, 4)
+
+	assert.Equal(t, `e^{i\pi}+1=0`, equations[0].LaTeX())
+	assert.False(t, equations[0].Display)
+	assert.Equal(t, "Euler: e^(iπ)+1=0", equations[0].Para.Text())
+	assert.Equal(t, `A=\pi r^{2}`, equations[1].LaTeX())
+	assert.Equal(t, `x=\frac{-b\pm\sqrt{b^{2}-4ac}}{2a}`, equations[2].LaTeX())
+	assert.True(t, equations[2].Display)
+	assert.Equal(t, `\sum_{i=1}^{n}{i}`, equations[3].LaTeX())
+	assert.Contains(t, equations[3].MathML(), `<munderover><mo>∑</mo>`)
+
+	// The paragraphs of equations in tables are those of the document.
+	equations[3].Para.AddText(" over all rows")
+	assert.Equal(t, "∑_(i=1)^n i over all rows", reopened.Equations()[3].Para.Text())
+
+	// Equations survive a second round trip.
+	buf.Reset()
+	require.NoError(t, reopened.Write(&buf))
+	content = buf.Bytes()
+	again, err := packager.Unpack(&content)
+	require.NoError(t, err)
+	require.Len(t, again.Equations(), 4)
+	assert.Equal(t, `e^{i\pi}+1=0`, again.Equations()[0].LaTeX())
+}
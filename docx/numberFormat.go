@@ -0,0 +1,295 @@
+package docx
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// countingNumerals describes how an East Asian counting format writes numbers.
+type countingNumerals struct {
+	digits      [10]string
+	units       [4]string // ones, tens, hundreds, thousands
+	tenThousand string
+	zero        string // written for skipped digits, empty when they are left out
+	omitOne     bool   // leave out "one" before every unit
+	omitTenOne  bool   // leave out "one" before the tens of a number below twenty
+}
+
+var (
+	chineseCounting = countingNumerals{
+		digits:      [10]string{"〇", "一", "二", "三", "四", "五", "六", "七", "八", "九"},
+		units:       [4]string{"", "十", "百", "千"},
+		tenThousand: "万",
+		zero:        "零",
+		omitTenOne:  true,
+	}
+	japaneseCounting = countingNumerals{
+		digits:      [10]string{"〇", "一", "二", "三", "四", "五", "六", "七", "八", "九"},
+		units:       [4]string{"", "十", "百", "千"},
+		tenThousand: "万",
+		omitOne:     true,
+	}
+	chineseLegalSimplified = countingNumerals{
+		digits:      [10]string{"零", "壹", "贰", "叁", "肆", "伍", "陆", "柒", "捌", "玖"},
+		units:       [4]string{"", "拾", "佰", "仟"},
+		tenThousand: "万",
+		zero:        "零",
+	}
+	ideographLegalTraditional = countingNumerals{
+		digits:      [10]string{"零", "壹", "貳", "參", "肆", "伍", "陸", "柒", "捌", "玖"},
+		units:       [4]string{"", "拾", "佰", "仟"},
+		tenThousand: "萬",
+		zero:        "零",
+	}
+)
+
+var (
+	romanNumerals = []struct {
+		value int
+		text  string
+	}{
+		{1000, "m"}, {900, "cm"}, {500, "d"}, {400, "cd"}, {100, "c"}, {90, "xc"},
+		{50, "l"}, {40, "xl"}, {10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"},
+	}
+	chicagoSymbols    = []string{"*", "†", "‡", "§"}
+	ideographDigits   = []rune("〇一二三四五六七八九")
+	ideographStems    = []rune("甲乙丙丁戊己庚辛壬癸")
+	ideographBranches = []rune("子丑寅卯辰巳午未申酉戌亥")
+	// Word leaves out ё, й, ъ, ы and ь when numbering with Russian letters.
+	russianLetters = []rune("абвгдежзиклмнопрстуфхцчшщэюя")
+	englishOnes    = []string{"", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	englishTens        = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	englishOrdinalWord = map[string]string{
+		"one": "first", "two": "second", "three": "third", "five": "fifth",
+		"eight": "eighth", "nine": "ninth", "twelve": "twelfth",
+	}
+)
+
+// FormatNumber writes n in the numbering format, as Word displays it in list numbers.
+// Bullet and none formats have no number and return an empty string; formats that are
+// not supported fall back to decimal.
+//
+// Example:
+//
+//	docx.FormatNumber(4, stypes.NumFmtLowerRoman)       // "iv"
+//	docx.FormatNumber(28, stypes.NumFmtUpperLetter)     // "BB"
+//	docx.FormatNumber(12, stypes.NumFmtChineseCounting) // "十二"
+func FormatNumber(n int, format stypes.NumFmt) string {
+	switch format {
+	case stypes.NumFmtBullet, stypes.NumFmtNone:
+		return ""
+	}
+	if n < 0 {
+		return strconv.Itoa(n)
+	}
+
+	switch format {
+	case stypes.NumFmtDecimalZero:
+		if n < 10 {
+			return "0" + strconv.Itoa(n)
+		}
+	case stypes.NumFmtUpperRoman:
+		return strings.ToUpper(roman(n))
+	case stypes.NumFmtLowerRoman:
+		return roman(n)
+	case stypes.NumFmtUpperLetter:
+		return strings.ToUpper(repeatedLetter(n, []rune("abcdefghijklmnopqrstuvwxyz")))
+	case stypes.NumFmtLowerLetter:
+		return repeatedLetter(n, []rune("abcdefghijklmnopqrstuvwxyz"))
+	case stypes.NumFmtRussianUpper:
+		return strings.ToUpper(repeatedLetter(n, russianLetters))
+	case stypes.NumFmtRussianLower:
+		return repeatedLetter(n, russianLetters)
+	case stypes.NumFmtOrdinal:
+		return strconv.Itoa(n) + ordinalSuffix(n)
+	case stypes.NumFmtCardinalText:
+		return capitalize(englishWords(n))
+	case stypes.NumFmtOrdinalText:
+		return capitalize(englishOrdinal(englishWords(n)))
+	case stypes.NumFmtHex:
+		return strings.ToUpper(strconv.FormatInt(int64(n), 16))
+	case stypes.NumFmtChicago:
+		if n > 0 {
+			return strings.Repeat(chicagoSymbols[(n-1)%len(chicagoSymbols)], (n-1)/len(chicagoSymbols)+1)
+		}
+	case stypes.NumFmtNumberInDash:
+		return "- " + strconv.Itoa(n) + " -"
+	case stypes.NumFmtDecimalFullWidth, stypes.NumFmtDecimalFullWidth2:
+		return strings.Map(func(r rune) rune { return r - '0' + '０' }, strconv.Itoa(n))
+	case stypes.NumFmtDecimalEnclosedCircle, stypes.NumFmtDecimalEnclosedCircleChinese:
+		return enclosed(n, '①', 20)
+	case stypes.NumFmtDecimalEnclosedFullstop:
+		return enclosed(n, '⒈', 20)
+	case stypes.NumFmtDecimalEnclosedParen:
+		return enclosed(n, '⑴', 20)
+	case stypes.NumFmtIdeographEnclosedCircle:
+		return enclosed(n, '㊀', 10)
+	case stypes.NumFmtIdeographDigital, stypes.NumFmtTaiwaneseDigital:
+		return strings.Map(func(r rune) rune { return ideographDigits[r-'0'] }, strconv.Itoa(n))
+	case stypes.NumFmtIdeographTraditional:
+		return cyclic(n, ideographStems)
+	case stypes.NumFmtIdeographZodiac:
+		return cyclic(n, ideographBranches)
+	case stypes.NumFmtChineseCounting, stypes.NumFmtChineseCountingThousand,
+		stypes.NumFmtTaiwaneseCounting, stypes.NumFmtTaiwaneseCountingThousand:
+		return counting(n, chineseCounting)
+	case stypes.NumFmtJapaneseCounting:
+		return counting(n, japaneseCounting)
+	case stypes.NumFmtChineseLegalSimplified:
+		return counting(n, chineseLegalSimplified)
+	case stypes.NumFmtIdeographLegalTraditional:
+		return counting(n, ideographLegalTraditional)
+	}
+	return strconv.Itoa(n)
+}
+
+// roman writes n in lowercase Roman numerals.
+func roman(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	var b strings.Builder
+	for _, r := range romanNumerals {
+		for ; n >= r.value; n -= r.value {
+			b.WriteString(r.text)
+		}
+	}
+	return b.String()
+}
+
+// repeatedLetter writes n the way Word numbers with letters: a to z, then aa to zz and so on.
+func repeatedLetter(n int, letters []rune) string {
+	if n == 0 {
+		return "0"
+	}
+	return strings.Repeat(string(letters[(n-1)%len(letters)]), (n-1)/len(letters)+1)
+}
+
+// cyclic writes n as the symbol at its position in a cycle of symbols.
+func cyclic(n int, symbols []rune) string {
+	if n == 0 {
+		return "0"
+	}
+	return string(symbols[(n-1)%len(symbols)])
+}
+
+// enclosed writes n as one of the count symbols starting at first, or in decimal beyond them.
+func enclosed(n int, first rune, count int) string {
+	if n < 1 || n > count {
+		return strconv.Itoa(n)
+	}
+	return string(first + rune(n-1))
+}
+
+// ordinalSuffix returns the English suffix of the ordinal of n.
+func ordinalSuffix(n int) string {
+	if n%100 >= 11 && n%100 <= 13 {
+		return "th"
+	}
+	switch n % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+// englishWords writes n in English words, such as "one hundred twenty-one".
+func englishWords(n int) string {
+	switch {
+	case n == 0:
+		return "zero"
+	case n >= 1000000:
+		return joinWords(englishWords(n/1000000)+" million", n%1000000)
+	case n >= 1000:
+		return joinWords(englishWords(n/1000)+" thousand", n%1000)
+	case n >= 100:
+		return joinWords(englishOnes[n/100]+" hundred", n%100)
+	case n >= 20:
+		if n%10 == 0 {
+			return englishTens[n/10]
+		}
+		return englishTens[n/10] + "-" + englishOnes[n%10]
+	}
+	return englishOnes[n]
+}
+
+// joinWords appends the words of rest to text, unless rest is zero.
+func joinWords(text string, rest int) string {
+	if rest == 0 {
+		return text
+	}
+	return text + " " + englishWords(rest)
+}
+
+// englishOrdinal turns the last word of a cardinal number into an ordinal.
+func englishOrdinal(words string) string {
+	cut := strings.LastIndexAny(words, " -") + 1
+	last := words[cut:]
+	switch {
+	case englishOrdinalWord[last] != "":
+		last = englishOrdinalWord[last]
+	case strings.HasSuffix(last, "y"):
+		last = strings.TrimSuffix(last, "y") + "ieth"
+	default:
+		last += "th"
+	}
+	return words[:cut] + last
+}
+
+// capitalize upper-cases the first letter of text.
+func capitalize(text string) string {
+	r, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(r)) + text[size:]
+}
+
+// counting writes n with the numerals of an East Asian counting format, such as 一百零一.
+func counting(n int, numerals countingNumerals) string {
+	if n == 0 {
+		return numerals.digits[0]
+	}
+	if n < 10000 {
+		return countingGroup(n, numerals, false)
+	}
+	text := counting(n/10000, numerals) + numerals.tenThousand
+	low := n % 10000
+	if low == 0 {
+		return text
+	}
+	if low < 1000 {
+		text += numerals.zero
+	}
+	return text + countingGroup(low, numerals, true)
+}
+
+// countingGroup writes n, below ten thousand, with the numerals of a counting format.
+// inner reports whether the group follows higher digits.
+func countingGroup(n int, numerals countingNumerals, inner bool) string {
+	var b strings.Builder
+	skipped := false
+	for i, pow := 3, 1000; i >= 0; i, pow = i-1, pow/10 {
+		d := n / pow % 10
+		if d == 0 {
+			skipped = b.Len() > 0
+			continue
+		}
+		if skipped {
+			b.WriteString(numerals.zero)
+			skipped = false
+		}
+		omit := d == 1 && i > 0 && (numerals.omitOne || (i == 1 && numerals.omitTenOne && !inner && b.Len() == 0))
+		if !omit {
+			b.WriteString(numerals.digits[d])
+		}
+		b.WriteString(numerals.units[i])
+	}
+	return b.String()
+}
@@ -1,12 +1,17 @@
 package docx
 
 import (
+	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
-	"regexp"
+	"io"
 	"strconv"
 	"strings"
 	"sync"
+
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
 )
 
 // NumInstance represents a w:num element that creates an instance of abstract numbering
@@ -120,75 +125,175 @@ func (ni NumInstance) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	return e.EncodeToken(xml.EndElement{Name: start.Name})
 }
 
-// applyToFileMap injects generated numbering instances into the existing numbering.xml
-// within the root document's file map. It preserves existing abstract numbering definitions
-// from the template and appends only the new w:num instance elements.
+// Abstract numbering definitions built into the library. NewListInstance maps the simple
+// IDs 1 and 2 onto them.
+const (
+	decimalAbstractID = 201
+	bulletAbstractID  = 202
+)
+
+// minimalNumberingPart is the numbering part created for documents that have none.
+const minimalNumberingPart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+	`<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"></w:numbering>`
+
+var numberingCloseTag = []byte("</w:numbering>")
+
+// applyToFileMap writes the instances created by NewListInstance, and the built-in
+// multilevel definitions they refer to, into numbering.xml within the root document's
+// file map. Definitions already present in the part are kept as they are.
 func (nm *NumberingManager) applyToFileMap() error {
 	nm.mu.Lock()
 	defer nm.mu.Unlock()
 
-	if len(nm.numbering.Instances) == 0 || nm.rootDoc == nil {
-		return nil
+	return nm.flush()
+}
+
+// flush implements applyToFileMap; nm.mu must be held.
+func (nm *NumberingManager) flush() error {
+	updated, changed, err := nm.pending()
+	if err != nil || !changed {
+		return err
 	}
+	nm.rootDoc.FileMap.Store(numberingPartPath, updated)
+	return nil
+}
 
-	// Determine which instances are already present to avoid duplicates
-	// Collect existing numIds from current numbering.xml content if present
-	existingIDs := make(map[int]struct{})
-	const numberingPath = "word/numbering.xml"
-	if existing, ok := nm.rootDoc.FileMap.Load(numberingPath); ok {
-		content := string(existing.([]byte))
-		idRe := regexp.MustCompile(`w:numId=\"(\d+)\"`)
-		for _, m := range idRe.FindAllStringSubmatch(content, -1) {
-			if len(m) == 2 {
-				if v, err := strconv.Atoi(m[1]); err == nil {
-					existingIDs[v] = struct{}{}
-				}
-			}
+// pending returns the numbering part with the instances created by NewListInstance, and
+// the built-in definitions they refer to, added, and whether that changes the part. The
+// part is nil when the document has none and nothing is pending. nm.mu must be held.
+func (nm *NumberingManager) pending() ([]byte, bool, error) {
+	if nm.rootDoc == nil {
+		return nil, false, nil
+	}
+	if len(nm.numbering.Instances) == 0 {
+		if existing, ok := nm.rootDoc.FileMap.Load(numberingPartPath); ok {
+			return existing.([]byte), false, nil
 		}
+		return nil, false, nil
+	}
+
+	content := nm.partContent()
+	spans, err := numberingSpans(content)
+	if err != nil {
+		return nil, false, err
+	}
+	present := make(map[numberingKey]bool, len(spans))
+	for _, s := range spans {
+		present[s.key()] = true
 	}
 
-	// Build the XML snippet only for missing instances to keep operation idempotent
-	var sb strings.Builder
+	// Written only for missing elements, which keeps the operation idempotent.
+	var abstracts, nums bytes.Buffer
+	for _, id := range []int{decimalAbstractID, bulletAbstractID} {
+		if !present[numberingKey{id: id}] {
+			abstracts.WriteString(builtinAbstractNumXML(id))
+		}
+	}
 	for _, inst := range nm.numbering.Instances {
-		if _, found := existingIDs[inst.NumId]; found {
-			continue
+		if !present[numberingKey{num: true, id: inst.NumId}] {
+			nums.WriteString(numInstanceXML(inst))
 		}
-		// <w:num w:numId="X"><w:abstractNumId w:val="Y"/><w:lvlOverride w:ilvl="0"><w:startOverride w:val="1"/></w:lvlOverride></w:num>
-		sb.WriteString("<w:num w:numId=\"")
-		sb.WriteString(fmt.Sprintf("%d", inst.NumId))
-		sb.WriteString("\"><w:abstractNumId w:val=\"")
-		sb.WriteString(fmt.Sprintf("%d", inst.AbstractNumId))
-		sb.WriteString("\"/>")
-		sb.WriteString("<w:lvlOverride w:ilvl=\"0\"><w:startOverride w:val=\"1\"/></w:lvlOverride>")
-		sb.WriteString("</w:num>")
-	}
-	instancesXML := sb.String()
-
-	if existing, ok := nm.rootDoc.FileMap.Load(numberingPath); ok {
-		// Insert before closing tag of w:numbering
-		content := string(existing.([]byte))
-		// Ensure our multilevel abstract definitions exist
-		content = nm.ensureMultilevelAbstracts(content)
-		if instancesXML == "" {
-			// Nothing new to add
-			nm.rootDoc.FileMap.Store(numberingPath, []byte(content))
-			return nil
+	}
+	if abstracts.Len() == 0 && nums.Len() == 0 {
+		return content, false, nil
+	}
+
+	updated, err := spliceNumbering(content, spans, nil, abstracts.Bytes(), nums.Bytes())
+	if err != nil {
+		return nil, false, err
+	}
+	return updated, true, nil
+}
+
+// pendingPart returns the numbering part as applyToFileMap would write it, without
+// writing it, or nil when the document has no numbering part.
+func (nm *NumberingManager) pendingPart() ([]byte, error) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	content, _, err := nm.pending()
+	return content, err
+}
+
+// partContent returns the current numbering part, or an empty one.
+func (nm *NumberingManager) partContent() []byte {
+	if existing, ok := nm.rootDoc.FileMap.Load(numberingPartPath); ok {
+		return existing.([]byte)
+	}
+	return []byte(minimalNumberingPart)
+}
+
+// definitions parses the numbering part, including the instances not yet written to it.
+// The part itself is left as it is.
+func (nm *NumberingManager) definitions() (*ctypes.Numbering, error) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	content, _, err := nm.pending()
+	if err != nil {
+		return nil, err
+	}
+
+	defs := &ctypes.Numbering{}
+	if content == nil {
+		return defs, nil
+	}
+	if err := xml.Unmarshal(content, defs); err != nil {
+		return nil, fmt.Errorf("parse numbering part: %w", err)
+	}
+	return defs, nil
+}
+
+// save writes the abstract definitions and instances into the numbering part. An element
+// replaces the existing element with the same ID; other elements are added.
+func (nm *NumberingManager) save(abstracts []ctypes.AbstractNum, nums []ctypes.Num) error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	if err := nm.flush(); err != nil {
+		return err
+	}
+
+	content := nm.partContent()
+	spans, err := numberingSpans(content)
+	if err != nil {
+		return err
+	}
+	present := make(map[numberingKey]bool, len(spans))
+	for _, s := range spans {
+		present[s.key()] = true
+	}
+
+	replace := make(map[numberingKey][]byte)
+	var newAbstracts, newNums bytes.Buffer
+	add := func(key numberingKey, v any, buf *bytes.Buffer) error {
+		frag, err := xml.Marshal(v)
+		if err != nil {
+			return err
 		}
-		if strings.Contains(content, "</w:numbering>") {
-			updated := strings.Replace(content, "</w:numbering>", instancesXML+"</w:numbering>", 1)
-			nm.rootDoc.FileMap.Store(numberingPath, []byte(updated))
-			return nil
+		if present[key] {
+			replace[key] = frag
+		} else {
+			buf.Write(frag)
 		}
-		// Fallback: if unexpected structure, append instances at end
-		nm.rootDoc.FileMap.Store(numberingPath, []byte(content+instancesXML))
 		return nil
 	}
+	for _, an := range abstracts {
+		if err := add(numberingKey{id: an.ID}, an, &newAbstracts); err != nil {
+			return err
+		}
+	}
+	for _, num := range nums {
+		if err := add(numberingKey{num: true, id: num.ID}, num, &newNums); err != nil {
+			return err
+		}
+	}
 
-	// If numbering.xml doesn't exist (unlikely with the default template), create a minimal one
-	minimal := `<?xml version="1.0" encoding="UTF-8"?>` +
-		`<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
-		nm.multilevelAbstractsXML() + instancesXML + `</w:numbering>`
-	nm.rootDoc.FileMap.Store(numberingPath, []byte(minimal))
+	updated, err := spliceNumbering(content, spans, replace, newAbstracts.Bytes(), newNums.Bytes())
+	if err != nil {
+		return err
+	}
+	nm.rootDoc.FileMap.Store(numberingPartPath, updated)
 	return nil
 }
 
@@ -197,9 +302,9 @@ func (nm *NumberingManager) applyToFileMap() error {
 func (nm *NumberingManager) normalizeAbstract(abstractNumId int) int {
 	switch abstractNumId {
 	case 1:
-		return 201
+		return decimalAbstractID
 	case 2:
-		return 202
+		return bulletAbstractID
 	default:
 		return abstractNumId
 	}
@@ -207,77 +312,64 @@ func (nm *NumberingManager) normalizeAbstract(abstractNumId int) int {
 
 // ensureNextNumIdFromTemplate raises nextNumId above any existing numIds in the template
 func (nm *NumberingManager) ensureNextNumIdFromTemplate() {
-	const numberingPath = "word/numbering.xml"
-	existing, ok := nm.rootDoc.FileMap.Load(numberingPath)
+	existing, ok := nm.rootDoc.FileMap.Load(numberingPartPath)
 	if !ok {
 		return
 	}
-	content := string(existing.([]byte))
-	re := regexp.MustCompile(`w:numId=\"(\d+)\"`)
-	maxID := 0
-	for _, m := range re.FindAllStringSubmatch(content, -1) {
-		if len(m) == 2 {
-			if v, err := strconv.Atoi(m[1]); err == nil && v > maxID {
-				maxID = v
-			}
-		}
+	spans, err := numberingSpans(existing.([]byte))
+	if err != nil {
+		return
 	}
-	if nm.nextNumId <= maxID {
-		nm.nextNumId = maxID + 1
+	for _, s := range spans {
+		if s.num && nm.nextNumId <= s.id {
+			nm.nextNumId = s.id + 1
+		}
 	}
 }
 
-// ensureMultilevelAbstracts injects multilevel abstract definitions used by normalizeAbstract
-func (nm *NumberingManager) ensureMultilevelAbstracts(content string) string {
-	if strings.Contains(content, `w:abstractNumId="201"`) && strings.Contains(content, `w:abstractNumId="202"`) {
-		return content
-	}
-
-	insert := nm.multilevelAbstractsXML()
-	// Place right after opening <w:numbering ...>
-	start := strings.Index(content, "<w:numbering")
-	if start >= 0 {
-		end := strings.Index(content[start:], ">")
-		if end >= 0 {
-			pos := start + end + 1
-			return content[:pos] + insert + content[pos:]
-		}
-	}
-	// Fallback: append
-	return content + insert
+// numInstanceXML returns the w:num element of an instance created by NewListInstance,
+// restarting the numbering of its first level.
+func numInstanceXML(inst *NumInstance) string {
+	return `<w:num w:numId="` + strconv.Itoa(inst.NumId) + `"><w:abstractNumId w:val="` + strconv.Itoa(inst.AbstractNumId) + `"/>` +
+		`<w:lvlOverride w:ilvl="0"><w:startOverride w:val="1"/></w:lvlOverride></w:num>`
 }
 
-func (nm *NumberingManager) multilevelAbstractsXML() string {
-	// Build 9 levels for decimal and bullet schemes
-	var dec, bul strings.Builder
-	dec.WriteString(`<w:abstractNum w:abstractNumId="201"><w:multiLevelType w:val="hybridMultilevel"/>`)
-	bul.WriteString(`<w:abstractNum w:abstractNumId="202"><w:multiLevelType w:val="hybridMultilevel"/>`)
+// builtinAbstractNumXML returns the nine-level definition behind decimalAbstractID or
+// bulletAbstractID.
+func builtinAbstractNumXML(id int) string {
+	var b strings.Builder
+	b.WriteString(`<w:abstractNum w:abstractNumId="` + strconv.Itoa(id) + `"><w:multiLevelType w:val="hybridMultilevel"/>`)
 	for lvl := 0; lvl < 9; lvl++ {
-		pos := 360 * (lvl + 1)
+		pos := strconv.Itoa(360 * (lvl + 1))
+		b.WriteString(`<w:lvl w:ilvl="` + strconv.Itoa(lvl) + `"><w:start w:val="1"/>`)
+		if id == bulletAbstractID {
+			// Bullets: cycle glyphs (•, o, square) and fonts (Symbol, Symbol, Wingdings)
+			glyph, font := bulletGlyphForLevel(lvl)
+			b.WriteString(`<w:numFmt w:val="bullet"/><w:lvlText w:val="` + glyph + `"/><w:lvlJc w:val="left"/>` +
+				`<w:pPr><w:tabs><w:tab w:val="num" w:pos="` + pos + `"/></w:tabs><w:ind w:left="` + pos + `" w:hanging="360"/></w:pPr>` +
+				`<w:rPr><w:rFonts w:ascii="` + font + `" w:hAnsi="` + font + `" w:hint="default"/></w:rPr></w:lvl>`)
+			continue
+		}
 		// Ordered: cycle formats per level: decimal, lowerLetter, lowerRoman
-		fmtVal := orderedNumFmtForLevel(lvl)
-		dec.WriteString(`<w:lvl w:ilvl="` + strconv.Itoa(lvl) + `"><w:start w:val="1"/><w:numFmt w:val="` + fmtVal + `"/><w:lvlText w:val="%` + strconv.Itoa(lvl+1) + `."/><w:lvlJc w:val="left"/><w:pPr><w:tabs><w:tab w:val="num" w:pos="` + strconv.Itoa(pos) + `"/></w:tabs><w:ind w:left="` + strconv.Itoa(pos) + `" w:hanging="360"/></w:pPr></w:lvl>`)
-		// Bullets: cycle glyphs (•, o, square) and fonts (Symbol, Symbol, Wingdings)
-		glyph, font := bulletGlyphForLevel(lvl)
-		bul.WriteString(`<w:lvl w:ilvl="` + strconv.Itoa(lvl) + `"><w:start w:val="1"/><w:numFmt w:val="bullet"/><w:lvlText w:val="` + glyph + `"/><w:lvlJc w:val="left"/><w:pPr><w:tabs><w:tab w:val="num" w:pos="` + strconv.Itoa(pos) + `"/></w:tabs><w:ind w:left="` + strconv.Itoa(pos) + `" w:hanging="360"/></w:pPr><w:rPr><w:rFonts w:ascii="` + font + `" w:hAnsi="` + font + `" w:hint="default"/></w:rPr></w:lvl>`)
-	}
-	dec.WriteString(`</w:abstractNum>`)
-	bul.WriteString(`</w:abstractNum>`)
-	return dec.String() + bul.String()
+		b.WriteString(`<w:numFmt w:val="` + string(orderedNumFmtForLevel(lvl)) + `"/><w:lvlText w:val="%` + strconv.Itoa(lvl+1) + `."/><w:lvlJc w:val="left"/>` +
+			`<w:pPr><w:tabs><w:tab w:val="num" w:pos="` + pos + `"/></w:tabs><w:ind w:left="` + pos + `" w:hanging="360"/></w:pPr></w:lvl>`)
+	}
+	b.WriteString(`</w:abstractNum>`)
+	return b.String()
 }
 
 // orderedNumFmtForLevel returns the WordprocessingML numFmt for a given level,
-// cycling through decimal, lowerLetter, and lowerRoman.
-func orderedNumFmtForLevel(level int) string {
+// cycling through decimal, lowerLetter, lowerRoman and upperLetter.
+func orderedNumFmtForLevel(level int) stypes.NumFmt {
 	switch level % 4 {
 	case 0:
-		return "decimal"
+		return stypes.NumFmtDecimal
 	case 1:
-		return "lowerLetter"
+		return stypes.NumFmtLowerLetter
 	case 2:
-		return "lowerRoman"
+		return stypes.NumFmtLowerRoman
 	default:
-		return "upperLetter"
+		return stypes.NumFmtUpperLetter
 	}
 }
 
@@ -298,3 +390,210 @@ func bulletGlyphForLevel(level int) (glyph string, font string) {
 		return "♦", "Symbol" // diamond
 	}
 }
+
+// numberingSpan is a top-level w:num or w:abstractNum element of a numbering part.
+type numberingSpan struct {
+	start    int64
+	content  []byte
+	num      bool
+	id       int // numId or abstractNumId
+	abstract int // abstractNumId referenced by a w:num
+}
+
+// numberingSpans returns the top-level numbering instances and abstract definitions of the
+// numbering part, with their raw content.
+func numberingSpans(content []byte) ([]numberingSpan, error) {
+	var spans []numberingSpan
+	depth := 0
+
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return spans, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch elem := tok.(type) {
+		case xml.StartElement:
+			if depth != 1 {
+				depth++
+				continue
+			}
+			switch elem.Name.Local {
+			case "num":
+				num := struct {
+					Abstract ctypes.DecimalNum `xml:"abstractNumId"`
+				}{}
+				if err := dec.DecodeElement(&num, &elem); err != nil {
+					return nil, err
+				}
+				id, _ := strconv.Atoi(attrValue(elem, "numId"))
+				spans = append(spans, numberingSpan{
+					start:    offset,
+					content:  content[offset:dec.InputOffset()],
+					num:      true,
+					id:       id,
+					abstract: num.Abstract.Val,
+				})
+			case "abstractNum":
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+				id, _ := strconv.Atoi(attrValue(elem, "abstractNumId"))
+				spans = append(spans, numberingSpan{start: offset, content: content[offset:dec.InputOffset()], id: id})
+			default:
+				depth++
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// numberingKey identifies a top-level element of a numbering part.
+type numberingKey struct {
+	num bool
+	id  int
+}
+
+func (s numberingSpan) key() numberingKey {
+	return numberingKey{num: s.num, id: s.id}
+}
+
+// spliceNumbering returns content with the elements listed in replace substituted, the
+// abstracts added after the existing abstract definitions and the nums added at the end
+// of the part. Abstract definitions must precede every w:num instance.
+func spliceNumbering(content []byte, spans []numberingSpan, replace map[numberingKey][]byte, abstracts, nums []byte) ([]byte, error) {
+	closePos := bytes.LastIndex(content, numberingCloseTag)
+	if closePos < 0 {
+		return nil, errors.New("numbering part is malformed")
+	}
+	abstractPos := closePos
+	for _, s := range spans {
+		if s.num {
+			abstractPos = int(s.start)
+			break
+		}
+	}
+
+	var out bytes.Buffer
+	pos := 0
+	for _, s := range spans {
+		start := int(s.start)
+		if start == abstractPos {
+			out.Write(content[pos:start])
+			out.Write(abstracts)
+			pos = start
+		}
+		if frag, ok := replace[s.key()]; ok {
+			out.Write(content[pos:start])
+			out.Write(frag)
+			pos = start + len(s.content)
+		}
+	}
+	if abstractPos == closePos {
+		out.Write(content[pos:closePos])
+		out.Write(abstracts)
+		pos = closePos
+	}
+	out.Write(content[pos:closePos])
+	out.Write(nums)
+	out.Write(content[closePos:])
+	return out.Bytes(), nil
+}
+
+// NumberingLevel is the effective definition of one level of a numbering instance.
+type NumberingLevel struct {
+	NumID int
+	Level int
+
+	// AbstractNumID identifies the abstract definition of the level, after following
+	// numbering style references.
+	AbstractNumID int
+	Abstract      *ctypes.AbstractNum
+	Num           *ctypes.Num
+
+	// Definition is the level with the overrides of the instance applied. It is nil when
+	// the abstract definition does not define the level.
+	Definition *ctypes.NumLevel
+
+	// Start is the first number of the level, after any start override.
+	Start int
+}
+
+// NumberingLevel returns the effective definition of level ilvl of the numbering
+// instance numID.
+func (rd *RootDoc) NumberingLevel(numID, ilvl int) (*NumberingLevel, error) {
+	defs, err := rd.NumberingDefinitions()
+	if err != nil {
+		return nil, err
+	}
+	lvl := resolveNumberingLevel(defs, func(id string) *ctypes.Style {
+		return rd.GetStyleByID(id, stypes.StyleTypeNumbering)
+	}, numID, ilvl)
+	if lvl == nil {
+		return nil, fmt.Errorf("numbering instance %d not found", numID)
+	}
+	return lvl, nil
+}
+
+// resolveNumberingLevel resolves level ilvl of instance numID in defs. numberingStyle
+// looks up numbering styles, which abstract definitions may refer to through
+// numStyleLink. It returns nil when the instance or its abstract definition is missing.
+func resolveNumberingLevel(defs *ctypes.Numbering, numberingStyle func(string) *ctypes.Style, numID, ilvl int) *NumberingLevel {
+	num := defs.Num(numID)
+	if num == nil {
+		return nil
+	}
+	abstract := defs.AbstractNum(num.AbstractNumID.Val)
+
+	// A numbering style reference points to the instance holding the actual levels.
+	for hops := 0; abstract != nil && abstract.NumStyleLink != nil && hops < 8; hops++ {
+		style := numberingStyle(abstract.NumStyleLink.Val)
+		if style == nil || style.ParaProp == nil || style.ParaProp.NumProp == nil || style.ParaProp.NumProp.NumID == nil {
+			break
+		}
+		linked := defs.Num(style.ParaProp.NumProp.NumID.Val)
+		if linked == nil {
+			break
+		}
+		abstract = defs.AbstractNum(linked.AbstractNumID.Val)
+	}
+	if abstract == nil {
+		return nil
+	}
+
+	lvl := &NumberingLevel{
+		NumID:         numID,
+		Level:         ilvl,
+		AbstractNumID: abstract.ID,
+		Abstract:      abstract,
+		Num:           num,
+	}
+	if def := abstract.Level(ilvl); def != nil {
+		copied := *def
+		lvl.Definition = &copied
+	}
+	if o := num.Override(ilvl); o != nil {
+		if o.Lvl != nil {
+			copied := *o.Lvl
+			copied.Ilvl = ilvl
+			lvl.Definition = &copied
+		}
+		if o.StartOverride != nil {
+			start := *o.StartOverride
+			if lvl.Definition == nil {
+				lvl.Definition = &ctypes.NumLevel{Ilvl: ilvl}
+			}
+			lvl.Definition.Start = &start
+		}
+	}
+	if lvl.Definition != nil && lvl.Definition.Start != nil {
+		lvl.Start = lvl.Definition.Start.Val
+	}
+	return lvl
+}
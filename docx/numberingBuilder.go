@@ -0,0 +1,390 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// maxNumberingLevel is the deepest level of a numbering definition.
+const maxNumberingLevel = 8
+
+// NumberingDefinitions parses the abstract numbering definitions and numbering instances of
+// the document, including the instances created by NewListInstance. The result is a copy:
+// changes to it are only applied through SaveAbstractNum and SaveNum.
+func (rd *RootDoc) NumberingDefinitions() (*ctypes.Numbering, error) {
+	if rd.Numbering == nil {
+		return nil, errors.New("document has no numbering manager")
+	}
+	return rd.Numbering.definitions()
+}
+
+// SaveAbstractNum adds the abstract numbering definition to the document, or replaces the
+// definition with the same ID.
+func (rd *RootDoc) SaveAbstractNum(an *ctypes.AbstractNum) error {
+	if rd.Numbering == nil {
+		return errors.New("document has no numbering manager")
+	}
+	if err := validateAbstractNum(an); err != nil {
+		return err
+	}
+	return rd.Numbering.save([]ctypes.AbstractNum{*an}, nil)
+}
+
+// SaveNum adds the numbering instance to the document, or replaces the instance with the
+// same ID. The abstract definition it refers to must exist.
+func (rd *RootDoc) SaveNum(num *ctypes.Num) error {
+	defs, err := rd.NumberingDefinitions()
+	if err != nil {
+		return err
+	}
+	if defs.AbstractNum(num.AbstractNumID.Val) == nil {
+		return fmt.Errorf("abstract numbering definition %d not found", num.AbstractNumID.Val)
+	}
+	for _, o := range num.Overrides {
+		if o.Ilvl < 0 || o.Ilvl > maxNumberingLevel {
+			return fmt.Errorf("invalid override level %d", o.Ilvl)
+		}
+	}
+	return rd.Numbering.save(nil, []ctypes.Num{*num})
+}
+
+// NewNumberingInstance creates a numbering instance of the abstract definition
+// abstractNumID with the given level overrides, and returns its numId. Unlike
+// NewListInstance, the ID is used as is and the instance does not restart the first level
+// unless an override says so.
+func (rd *RootDoc) NewNumberingInstance(abstractNumID int, overrides ...ctypes.LvlOverride) (int, error) {
+	if rd.Numbering == nil {
+		return 0, errors.New("document has no numbering manager")
+	}
+	num := ctypes.Num{
+		ID:            rd.Numbering.allocateNumID(),
+		AbstractNumID: ctypes.DecimalNum{Val: abstractNumID},
+		Overrides:     overrides,
+	}
+	if err := rd.SaveNum(&num); err != nil {
+		return 0, err
+	}
+	return num.ID, nil
+}
+
+// SetLevelOverride adds the override to the numbering instance numID, replacing any
+// existing override of the same level.
+//
+// Example:
+//
+//	// Continue the second level of the list at 5.
+//	err := document.SetLevelOverride(numID, ctypes.LvlOverride{Ilvl: 1, StartOverride: ctypes.NewDecimalNum(5)})
+func (rd *RootDoc) SetLevelOverride(numID int, override ctypes.LvlOverride) error {
+	defs, err := rd.NumberingDefinitions()
+	if err != nil {
+		return err
+	}
+	num := defs.Num(numID)
+	if num == nil {
+		return fmt.Errorf("numbering instance %d not found", numID)
+	}
+	if existing := num.Override(override.Ilvl); existing != nil {
+		*existing = override
+	} else {
+		num.Overrides = append(num.Overrides, override)
+		sort.SliceStable(num.Overrides, func(i, j int) bool { return num.Overrides[i].Ilvl < num.Overrides[j].Ilvl })
+	}
+	return rd.SaveNum(num)
+}
+
+// NumberingLevel returns the effective numbering definition of the paragraph, whether the
+// numbering is set directly or through its style. It returns nil when the paragraph is not
+// numbered.
+func (p *Paragraph) NumberingLevel() (*NumberingLevel, error) {
+	numPr := p.root.NewStyleResolver().Paragraph(p.GetCT(), nil).NumProp
+	if numPr == nil || numPr.NumID == nil || numPr.NumID.Val == 0 {
+		return nil, nil
+	}
+	ilvl := 0
+	if numPr.ILvl != nil {
+		ilvl = numPr.ILvl.Val
+	}
+	return p.root.NumberingLevel(numPr.NumID.Val, ilvl)
+}
+
+// allocateNumID reserves an unused numId.
+func (nm *NumberingManager) allocateNumID() int {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	nm.ensureNextNumIdFromTemplate()
+	id := nm.nextNumId
+	nm.nextNumId++
+	return id
+}
+
+// NumberingBuilder creates an abstract numbering definition, the formats and layout of the
+// levels of a list, or modifies an existing one. Changes are only applied to the document
+// when Save is called.
+//
+// Example:
+//
+//	b := document.NewNumberingDefinition("Legal")
+//	b.Level(0).Format(stypes.NumFmtDecimal).Text("%1.").Start(1).Indent(360, 360)
+//	b.Level(1).Format(stypes.NumFmtDecimal).Text("%1.%2.").Legal(true).Indent(792, 432)
+//	abstractID, err := b.Save()
+//	...
+//	numID, err := document.NewNumberingInstance(abstractID)
+type NumberingBuilder struct {
+	root *RootDoc
+	def  ctypes.AbstractNum
+
+	edit bool
+	err  error
+}
+
+// NumberingLevelBuilder sets the properties of one level of a numbering definition.
+type NumberingLevelBuilder struct {
+	b    *NumberingBuilder
+	ilvl int
+}
+
+// NewNumberingDefinition returns a builder for a new abstract numbering definition. The ID
+// is assigned by Save.
+func (rd *RootDoc) NewNumberingDefinition(name string) *NumberingBuilder {
+	return &NumberingBuilder{
+		root: rd,
+		def: ctypes.AbstractNum{
+			MultiLevelType: ctypes.NewCTString("hybridMultilevel"),
+			Name:           optionalCTString(name),
+		},
+	}
+}
+
+// EditNumberingDefinition returns a builder initialized with a copy of the abstract
+// numbering definition abstractNumID.
+func (rd *RootDoc) EditNumberingDefinition(abstractNumID int) (*NumberingBuilder, error) {
+	defs, err := rd.NumberingDefinitions()
+	if err != nil {
+		return nil, err
+	}
+	an := defs.AbstractNum(abstractNumID)
+	if an == nil {
+		return nil, fmt.Errorf("abstract numbering definition %d not found", abstractNumID)
+	}
+	return &NumberingBuilder{root: rd, def: *an, edit: true}, nil
+}
+
+// GetCT returns the definition being built.
+func (b *NumberingBuilder) GetCT() *ctypes.AbstractNum {
+	return &b.def
+}
+
+// Name sets the name of the definition.
+func (b *NumberingBuilder) Name(name string) *NumberingBuilder {
+	b.def.Name = optionalCTString(name)
+	return b
+}
+
+// MultiLevelType sets the kind of definition: "singleLevel", "multilevel" or
+// "hybridMultilevel".
+func (b *NumberingBuilder) MultiLevelType(value string) *NumberingBuilder {
+	b.def.MultiLevelType = optionalCTString(value)
+	return b
+}
+
+// StyleLink marks the definition as the one of the numbering style id.
+func (b *NumberingBuilder) StyleLink(id string) *NumberingBuilder {
+	b.def.StyleLink = optionalCTString(id)
+	return b
+}
+
+// NumStyleLink makes the definition take its levels from the numbering style id.
+func (b *NumberingBuilder) NumStyleLink(id string) *NumberingBuilder {
+	b.def.NumStyleLink = optionalCTString(id)
+	return b
+}
+
+// Level returns a builder for level ilvl (0 to 8), adding the level when it is not defined.
+func (b *NumberingBuilder) Level(ilvl int) *NumberingLevelBuilder {
+	if ilvl < 0 || ilvl > maxNumberingLevel {
+		b.err = fmt.Errorf("invalid numbering level %d", ilvl)
+		ilvl = 0
+	}
+	if b.def.Level(ilvl) == nil {
+		b.def.Levels = append(b.def.Levels, ctypes.NumLevel{Ilvl: ilvl})
+		sort.SliceStable(b.def.Levels, func(i, j int) bool { return b.def.Levels[i].Ilvl < b.def.Levels[j].Ilvl })
+	}
+	return &NumberingLevelBuilder{b: b, ilvl: ilvl}
+}
+
+// Save validates the definition and adds it to the document, or replaces the edited
+// definition. It returns the abstractNumId of the definition.
+func (b *NumberingBuilder) Save() (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	rd := b.root
+	if !b.edit {
+		defs, err := rd.NumberingDefinitions()
+		if err != nil {
+			return 0, err
+		}
+		b.def.ID = nextAbstractNumID(defs)
+	}
+	if err := rd.SaveAbstractNum(&b.def); err != nil {
+		return 0, err
+	}
+	b.edit = true
+	return b.def.ID, nil
+}
+
+// nextAbstractNumID returns an unused abstractNumId that does not collide with the
+// built-in definitions.
+func nextAbstractNumID(defs *ctypes.Numbering) int {
+	id := 0
+	for _, an := range defs.AbstractNums {
+		if an.ID >= id {
+			id = an.ID + 1
+		}
+	}
+	if id == decimalAbstractID || id == bulletAbstractID {
+		id = bulletAbstractID + 1
+	}
+	return id
+}
+
+// validateAbstractNum checks the levels of the definition.
+func validateAbstractNum(an *ctypes.AbstractNum) error {
+	if an.ID < 0 {
+		return fmt.Errorf("invalid abstract numbering ID %d", an.ID)
+	}
+	if len(an.Levels) == 0 && an.NumStyleLink == nil {
+		return errors.New("numbering definition has no levels")
+	}
+	seen := make(map[int]bool, len(an.Levels))
+	for _, lvl := range an.Levels {
+		if lvl.Ilvl < 0 || lvl.Ilvl > maxNumberingLevel {
+			return fmt.Errorf("invalid numbering level %d", lvl.Ilvl)
+		}
+		if seen[lvl.Ilvl] {
+			return fmt.Errorf("numbering level %d is defined twice", lvl.Ilvl)
+		}
+		seen[lvl.Ilvl] = true
+		// lvlRestart names a 1-based level above this one; 0 never restarts.
+		if lvl.LvlRestart != nil && (lvl.LvlRestart.Val < 0 || lvl.LvlRestart.Val > lvl.Ilvl) {
+			return fmt.Errorf("level %d cannot restart after level %d", lvl.Ilvl, lvl.LvlRestart.Val)
+		}
+	}
+	return nil
+}
+
+// GetCT returns the level being built.
+func (l *NumberingLevelBuilder) GetCT() *ctypes.NumLevel {
+	return l.b.def.Level(l.ilvl)
+}
+
+func (l *NumberingLevelBuilder) paraProp() *ctypes.ParagraphProp {
+	lvl := l.GetCT()
+	if lvl.PPr == nil {
+		lvl.PPr = &ctypes.ParagraphProp{}
+	}
+	return lvl.PPr
+}
+
+func (l *NumberingLevelBuilder) runProp() *ctypes.RunProperty {
+	lvl := l.GetCT()
+	if lvl.RPr == nil {
+		lvl.RPr = &ctypes.RunProperty{}
+	}
+	return lvl.RPr
+}
+
+// Format sets the number format, such as stypes.NumFmtDecimal or stypes.NumFmtBullet.
+func (l *NumberingLevelBuilder) Format(value stypes.NumFmt) *NumberingLevelBuilder {
+	l.GetCT().NumFmt = ctypes.NewNumFmt(value)
+	return l
+}
+
+// Text sets the label template, where %1 to %9 stand for the current numbers of levels
+// 0 to 8, for example "%1.%2.". For bullets it is the bullet character.
+func (l *NumberingLevelBuilder) Text(value string) *NumberingLevelBuilder {
+	l.GetCT().LvlText = ctypes.NewCTString(value)
+	return l
+}
+
+// Start sets the first number of the level.
+func (l *NumberingLevelBuilder) Start(value int) *NumberingLevelBuilder {
+	l.GetCT().Start = ctypes.NewDecimalNum(value)
+	return l
+}
+
+// Restart sets the level after which the numbering restarts, as a 1-based level number;
+// 0 never restarts. By default a level restarts after any higher level.
+func (l *NumberingLevelBuilder) Restart(level int) *NumberingLevelBuilder {
+	l.GetCT().LvlRestart = ctypes.NewDecimalNum(level)
+	return l
+}
+
+// Legal displays the numbers of all levels of the label as decimal numbers.
+func (l *NumberingLevelBuilder) Legal(value bool) *NumberingLevelBuilder {
+	l.GetCT().IsLgl = optionalOnOff(value)
+	return l
+}
+
+// Suffix sets what follows the label: "tab", "space" or "nothing".
+func (l *NumberingLevelBuilder) Suffix(value string) *NumberingLevelBuilder {
+	l.GetCT().Suff = optionalCTString(value)
+	return l
+}
+
+// Style links the level to the paragraph style id.
+func (l *NumberingLevelBuilder) Style(id string) *NumberingLevelBuilder {
+	l.GetCT().PStyle = optionalCTString(id)
+	return l
+}
+
+// Justification sets the alignment of the label.
+func (l *NumberingLevelBuilder) Justification(value stypes.Justification) *NumberingLevelBuilder {
+	l.GetCT().LvlJc = ctypes.NewCTString(string(value))
+	return l
+}
+
+// Indent sets the left indentation of the paragraphs and the hanging indentation of the
+// label, in twips, with a tab stop at the text position.
+func (l *NumberingLevelBuilder) Indent(left int, hanging uint64) *NumberingLevelBuilder {
+	pPr := l.paraProp()
+	pPr.Indent = &ctypes.Indent{Left: &left, Hanging: &hanging}
+	pPr.Tabs = ctypes.Tabs{Tab: []ctypes.Tab{{Val: stypes.CustTabStopNum, Position: left}}}
+	return l
+}
+
+// Font sets the font of the label, as needed by symbol bullets.
+func (l *NumberingLevelBuilder) Font(font string) *NumberingLevelBuilder {
+	rPr := l.runProp()
+	rPr.Fonts = &ctypes.RunFonts{Ascii: font, HAnsi: font, Hint: stypes.FontTypeHintDefault}
+	return l
+}
+
+// Bold enables or disables bold labels.
+func (l *NumberingLevelBuilder) Bold(value bool) *NumberingLevelBuilder {
+	l.runProp().Bold = ctypes.OnOffFromBool(value)
+	return l
+}
+
+// Color sets the label color as a hex code such as "FF0000".
+func (l *NumberingLevelBuilder) Color(colorCode string) *NumberingLevelBuilder {
+	l.runProp().Color = ctypes.NewColor(colorCode)
+	return l
+}
+
+// RunProperty sets the run properties of the label.
+func (l *NumberingLevelBuilder) RunProperty(rPr *ctypes.RunProperty) *NumberingLevelBuilder {
+	l.GetCT().RPr = rPr
+	return l
+}
+
+// ParagraphProperty sets the paragraph properties of the level.
+func (l *NumberingLevelBuilder) ParagraphProperty(pPr *ctypes.ParagraphProp) *NumberingLevelBuilder {
+	l.GetCT().PPr = pPr
+	return l
+}
@@ -0,0 +1,163 @@
+package docx_test
+
+import (
+	"bytes"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumberingBuilder(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	b := rd.NewNumberingDefinition("Legal")
+	b.Level(0).Format(stypes.NumFmtUpperRoman).Text("%1.").Start(1).Indent(360, 360)
+	b.Level(1).Format(stypes.NumFmtDecimal).Text("%1.%2").Start(1).Legal(true).Restart(1).Indent(792, 432).Bold(true)
+	abstractID, err := b.Save()
+	require.NoError(t, err)
+
+	numID, err := rd.NewNumberingInstance(abstractID)
+	require.NoError(t, err)
+	assert.NotEqual(t, numID, rd.NewListInstance(1))
+
+	p := rd.AddParagraph("clause")
+	p.Numbering(numID, 1)
+
+	lvl, err := p.NumberingLevel()
+	require.NoError(t, err)
+	require.NotNil(t, lvl)
+	assert.Equal(t, abstractID, lvl.AbstractNumID)
+	assert.Equal(t, stypes.NumFmtDecimal, lvl.Definition.NumFmt.Val)
+	assert.Equal(t, "%1.%2", lvl.Definition.LvlText.Val)
+	assert.NotNil(t, lvl.Definition.IsLgl)
+	assert.Equal(t, 1, lvl.Definition.LvlRestart.Val)
+	assert.Equal(t, 1, lvl.Start)
+	assert.Equal(t, 792, *rd.NewStyleResolver().Paragraph(p.GetCT(), nil).Indent.Left)
+
+	unnumbered, err := rd.AddParagraph("plain").NumberingLevel()
+	require.NoError(t, err)
+	assert.Nil(t, unnumbered)
+
+	// Overrides apply to one instance only.
+	require.NoError(t, rd.SetLevelOverride(numID, ctypes.LvlOverride{Ilvl: 1, StartOverride: ctypes.NewDecimalNum(5)}))
+	lvl, err = p.NumberingLevel()
+	require.NoError(t, err)
+	assert.Equal(t, 5, lvl.Start)
+	assert.Equal(t, "%1.%2", lvl.Definition.LvlText.Val)
+
+	require.NoError(t, rd.SetLevelOverride(numID, ctypes.LvlOverride{Ilvl: 0, Lvl: &ctypes.NumLevel{
+		NumFmt:  ctypes.NewNumFmt(stypes.NumFmtLowerLetter),
+		LvlText: ctypes.NewCTString("(%1)"),
+	}}))
+	lvl, err = rd.NumberingLevel(numID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "(%1)", lvl.Definition.LvlText.Val)
+
+	other, err := rd.NewNumberingInstance(abstractID)
+	require.NoError(t, err)
+	lvl, err = rd.NumberingLevel(other, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "%1.", lvl.Definition.LvlText.Val)
+
+	// Editing the definition changes every instance.
+	edit, err := rd.EditNumberingDefinition(abstractID)
+	require.NoError(t, err)
+	edit.Level(1).Text("%1-%2")
+	_, err = edit.Save()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	content := buf.Bytes()
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+
+	lvl, err = reopened.NumberingLevel(other, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "%1-%2", lvl.Definition.LvlText.Val)
+	lvl, err = reopened.NumberingLevel(numID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 5, lvl.Start)
+
+	_, err = rd.NewNumberingInstance(9999)
+	assert.Error(t, err)
+	deep := rd.NewNumberingDefinition("Deep")
+	deep.Level(9)
+	_, err = deep.Save()
+	assert.Error(t, err)
+	_, err = rd.NumberingLevel(9999, 0)
+	assert.Error(t, err)
+}
+
+func TestNumberingDefinitions_StyleLink(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	defs, err := rd.NumberingDefinitions()
+	require.NoError(t, err)
+	templateAbstracts := len(defs.AbstractNums)
+	assert.NotZero(t, templateAbstracts)
+
+	// A list style: the style refers to an instance of a definition linked back to it, and
+	// other definitions refer to the style.
+	base := rd.NewNumberingDefinition("Outline")
+	base.StyleLink("OutlineList")
+	base.Level(0).Format(stypes.NumFmtUpperLetter).Text("%1)")
+	baseID, err := base.Save()
+	require.NoError(t, err)
+	baseNum, err := rd.NewNumberingInstance(baseID)
+	require.NoError(t, err)
+	_, err = rd.NewNumberingStyle("OutlineList", "Outline List").Numbering(baseNum, 0).Save()
+	require.NoError(t, err)
+
+	ref := rd.NewNumberingDefinition("").NumStyleLink("OutlineList")
+	refID, err := ref.Save()
+	require.NoError(t, err)
+	refNum, err := rd.NewNumberingInstance(refID)
+	require.NoError(t, err)
+
+	lvl, err := rd.NumberingLevel(refNum, 0)
+	require.NoError(t, err)
+	assert.Equal(t, baseID, lvl.AbstractNumID)
+	assert.Equal(t, "%1)", lvl.Definition.LvlText.Val)
+
+	// Numbering through a paragraph style.
+	_, err = rd.NewParagraphStyle("Clause", "Clause").Numbering(refNum, 0).Save()
+	require.NoError(t, err)
+	p := rd.AddParagraph("styled")
+	p.Style("Clause")
+	lvl, err = p.NumberingLevel()
+	require.NoError(t, err)
+	require.NotNil(t, lvl)
+	assert.Equal(t, stypes.NumFmtUpperLetter, lvl.Definition.NumFmt.Val)
+
+	defs, err = rd.NumberingDefinitions()
+	require.NoError(t, err)
+	assert.Len(t, defs.AbstractNums, templateAbstracts+2)
+}
+
+func TestNumberingReadsLeavePartAlone(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+	before, _ := rd.FileMap.Load("word/numbering.xml")
+
+	numID := rd.NewListInstance(1)
+	p := rd.AddParagraph("item")
+	p.Numbering(numID, 0)
+
+	lvl, err := p.NumberingLevel()
+	require.NoError(t, err)
+	require.NotNil(t, lvl)
+	assert.Equal(t, stypes.NumFmtDecimal, lvl.Definition.NumFmt.Val)
+	require.Len(t, rd.ListNumbers(), 1)
+	rd.Outline()
+
+	after, _ := rd.FileMap.Load("word/numbering.xml")
+	assert.Equal(t, before, after)
+}
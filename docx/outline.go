@@ -0,0 +1,266 @@
+package docx
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// bodyTextOutlineLevel is the outline level of paragraphs that are not headings.
+const bodyTextOutlineLevel = 9
+
+// OutlineNode is a heading of the document body together with the body content it owns:
+// the paragraphs and tables up to the next heading of the same or a higher level,
+// including its subheadings.
+type OutlineNode struct {
+	Paragraph *Paragraph
+
+	// Level is 0 for the title and 1 to 9 for the heading levels, as in AddHeading.
+	Level int
+
+	// Number is the list number of the heading, such as "2.1", or empty when the heading
+	// is not numbered.
+	Number string
+
+	Text string
+
+	// Start and End delimit the body children of the section, Start being the index of
+	// the heading. They reflect the body when the outline was built.
+	Start int
+	End   int
+
+	Parent   *OutlineNode
+	Children []*OutlineNode
+}
+
+// Outline returns the tree of the headings of the document body. A paragraph is a heading
+// when it has an outline level, set directly or through its style, or when its style or a
+// style it is based on is the built-in Title or Heading 1 to 9 style. Headings inside
+// tables are not part of the outline.
+//
+// Start and End are a snapshot of the body. Moving and deleting sections through the nodes
+// locates them in the current body, as long as their headings are still in it.
+//
+// Example:
+//
+//	var walk func(nodes []*docx.OutlineNode)
+//	walk = func(nodes []*docx.OutlineNode) {
+//		for _, node := range nodes {
+//			fmt.Printf("%*s%s %s\n", node.Level*2, "", node.Number, node.Text)
+//			walk(node.Children)
+//		}
+//	}
+//	walk(document.Outline())
+func (rd *RootDoc) Outline() []*OutlineNode {
+	if rd.Document == nil || rd.Document.Body == nil {
+		return nil
+	}
+
+	eval := rd.NewNumberingEvaluator()
+	children := rd.Document.Body.Children
+	var (
+		roots []*OutlineNode
+		open  []*OutlineNode // headings whose section is not closed yet, outermost first
+	)
+	for i, child := range children {
+		if child.Table != nil {
+			// Numbered paragraphs inside tables still count in their lists.
+			walkTableParagraphs(&child.Table.ct, func(p *ctypes.Paragraph) { eval.Next(p) })
+			continue
+		}
+		if child.Para == nil {
+			continue
+		}
+		num := eval.Next(child.Para.GetCT())
+		level, ok := eval.sr.headingLevel(child.Para.GetCT())
+		if !ok {
+			continue
+		}
+
+		node := &OutlineNode{
+			Paragraph: child.Para,
+			Level:     level,
+			Text:      child.Para.Text(),
+			Start:     i,
+			End:       len(children),
+		}
+		if num != nil {
+			node.Number = num.Text
+		}
+		for len(open) > 0 && open[len(open)-1].Level >= level {
+			open[len(open)-1].End = i
+			open = open[:len(open)-1]
+		}
+		if len(open) > 0 {
+			node.Parent = open[len(open)-1]
+			node.Parent.Children = append(node.Parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+		open = append(open, node)
+	}
+	return roots
+}
+
+// Content returns the body children of the section: the heading, its content and its
+// subsections.
+func (n *OutlineNode) Content() []DocumentChild {
+	start, end, err := n.span()
+	if err != nil {
+		return nil
+	}
+	return append([]DocumentChild(nil), n.Paragraph.root.Document.Body.Children[start:end]...)
+}
+
+// Delete removes the section, its heading, content and subsections, from the body.
+func (n *OutlineNode) Delete() error {
+	start, end, err := n.span()
+	if err != nil {
+		return err
+	}
+	body := n.Paragraph.root.Document.Body
+	body.Children = append(body.Children[:start:start], body.Children[end:]...)
+	return nil
+}
+
+// MoveBefore moves the section, with its subsections, in front of the section of target,
+// or to the end of the body when target is nil. The section cannot move into itself.
+//
+// Example:
+//
+//	outline := document.Outline()
+//	// Swap the first two chapters.
+//	err := outline[1].MoveBefore(outline[0])
+func (n *OutlineNode) MoveBefore(target *OutlineNode) error {
+	start, end, err := n.span()
+	if err != nil {
+		return err
+	}
+	rd := n.Paragraph.root
+	children := rd.Document.Body.Children
+
+	at := len(children)
+	if target != nil {
+		if target.Paragraph == nil || target.Paragraph.root != rd {
+			return errors.New("target heading is not part of the document")
+		}
+		if at = bodyIndex(children, target.Paragraph); at < 0 {
+			return errors.New("target heading is not in the document body")
+		}
+		if at > start && at < end {
+			return errors.New("cannot move a section into itself")
+		}
+	}
+	if at == start || at == end {
+		return nil
+	}
+
+	section := append([]DocumentChild(nil), children[start:end]...)
+	rest := append(children[:start:start], children[end:]...)
+	if at > start {
+		at -= len(section)
+	}
+	moved := make([]DocumentChild, 0, len(children))
+	moved = append(moved, rest[:at]...)
+	moved = append(moved, section...)
+	moved = append(moved, rest[at:]...)
+	rd.Document.Body.Children = moved
+	return nil
+}
+
+// span locates the section in the current body: the index of its heading and the index
+// of the next heading of the same or a higher level.
+func (n *OutlineNode) span() (int, int, error) {
+	if n.Paragraph == nil || n.Paragraph.root == nil || n.Paragraph.root.Document == nil || n.Paragraph.root.Document.Body == nil {
+		return 0, 0, errors.New("heading is not part of a document")
+	}
+	rd := n.Paragraph.root
+	children := rd.Document.Body.Children
+	start := bodyIndex(children, n.Paragraph)
+	if start < 0 {
+		return 0, 0, errors.New("heading is not in the document body")
+	}
+
+	sr := rd.NewStyleResolver()
+	end := start + 1
+	for ; end < len(children); end++ {
+		if p := children[end].Para; p != nil {
+			if level, ok := sr.headingLevel(p.GetCT()); ok && level <= n.Level {
+				break
+			}
+		}
+	}
+	return start, end, nil
+}
+
+// bodyIndex returns the index of the paragraph among the body children, or -1.
+func bodyIndex(children []DocumentChild, p *Paragraph) int {
+	for i, child := range children {
+		if child.Para == p {
+			return i
+		}
+	}
+	return -1
+}
+
+// headingLevel returns the heading level of a paragraph: 0 for the title and 1 to 9 for
+// headings. A direct outline level comes first, then the styles of the basedOn chain,
+// nearest first, by outline level or as built-in Title and Heading styles.
+func (sr *StyleResolver) headingLevel(p *ctypes.Paragraph) (int, bool) {
+	if p.Property != nil && p.Property.OutlineLvl != nil {
+		return outlineHeadingLevel(p.Property.OutlineLvl.Val)
+	}
+	seen := make(map[string]bool)
+	for id := paragraphStyleID(p); id != "" && !seen[id]; {
+		seen[id] = true
+		style := sr.styles[stypes.StyleTypeParagraph][id]
+		if style == nil {
+			break
+		}
+		if style.ParaProp != nil && style.ParaProp.OutlineLvl != nil {
+			return outlineHeadingLevel(style.ParaProp.OutlineLvl.Val)
+		}
+		if level, ok := builtinHeadingLevel(style); ok {
+			return level, true
+		}
+		id = ""
+		if style.BasedOn != nil {
+			id = style.BasedOn.Val
+		}
+	}
+	return 0, false
+}
+
+// outlineHeadingLevel converts an outline level, 0 for Heading 1, to a heading level.
+func outlineHeadingLevel(lvl int) (int, bool) {
+	if lvl < 0 || lvl >= bodyTextOutlineLevel {
+		return 0, false
+	}
+	return lvl + 1, true
+}
+
+// builtinHeadingLevel recognizes the built-in Title and Heading 1 to 9 styles by ID or name.
+func builtinHeadingLevel(style *ctypes.Style) (int, bool) {
+	names := []string{}
+	if style.ID != nil {
+		names = append(names, *style.ID)
+	}
+	if style.Name != nil {
+		names = append(names, style.Name.Val)
+	}
+	for _, name := range names {
+		name = strings.ToLower(strings.ReplaceAll(name, " ", ""))
+		if name == "title" {
+			return 0, true
+		}
+		if digits, ok := strings.CutPrefix(name, "heading"); ok {
+			if level, err := strconv.Atoi(digits); err == nil && level >= 1 && level <= 9 {
+				return level, true
+			}
+		}
+	}
+	return 0, false
+}
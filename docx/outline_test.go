@@ -0,0 +1,104 @@
+package docx_test
+
+import (
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutline(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	def := rd.NewNumberingDefinition("Headings").MultiLevelType("multilevel")
+	def.Level(0).Format(stypes.NumFmtDecimal).Text("%1.").Start(1)
+	def.Level(1).Format(stypes.NumFmtDecimal).Text("%1.%2").Start(1)
+	abstractID, err := def.Save()
+	require.NoError(t, err)
+	numID, err := rd.NewNumberingInstance(abstractID)
+	require.NoError(t, err)
+	heading := func(text string, level uint) {
+		p, err := rd.AddHeading(text, level)
+		require.NoError(t, err)
+		p.Numbering(numID, int(level-1))
+	}
+	_, err = rd.NewParagraphStyle("Annex", "Annex").BasedOn("Heading1").Save()
+	require.NoError(t, err)
+
+	_, err = rd.AddHeading("Report", 0)
+	require.NoError(t, err)
+	rd.AddParagraph("Summary")
+	heading("Scope", 1)
+	rd.AddParagraph("Scope text")
+	heading("Terms", 2)
+	addGridTable(rd, 1, 1)
+	rd.AddParagraph("Annex A").Style("Annex")
+	rd.AddParagraph("Notes").GetCT().Property = &ctypes.ParagraphProp{OutlineLvl: ctypes.NewDecimalNum(2)}
+	rd.AddParagraph("Not a heading").GetCT().Property = &ctypes.ParagraphProp{OutlineLvl: ctypes.NewDecimalNum(9)}
+	heading("Method", 1)
+	rd.AddParagraph("Method text")
+
+	outline := rd.Outline()
+	require.Len(t, outline, 1)
+	report := outline[0]
+	assert.Equal(t, 0, report.Level)
+	assert.Equal(t, "Report", report.Text)
+	assert.Equal(t, 0, report.Start)
+	assert.Equal(t, 11, report.End)
+	require.Len(t, report.Children, 3)
+
+	scope, annex, method := report.Children[0], report.Children[1], report.Children[2]
+	assert.Same(t, report, scope.Parent)
+	assert.Equal(t, "1.", scope.Number)
+	assert.Equal(t, []int{2, 6}, []int{scope.Start, scope.End})
+	require.Len(t, scope.Children, 1)
+	terms := scope.Children[0]
+	assert.Equal(t, 2, terms.Level)
+	assert.Equal(t, "1.1", terms.Number)
+	assert.Len(t, terms.Content(), 2, "the heading and the table")
+
+	assert.Equal(t, 1, annex.Level)
+	assert.Empty(t, annex.Number)
+	require.Len(t, annex.Children, 1)
+	assert.Equal(t, 3, annex.Children[0].Level)
+	assert.Equal(t, "Notes", annex.Children[0].Text)
+	assert.Len(t, annex.Content(), 3)
+	assert.Equal(t, "2.", method.Number)
+
+	// Moving a chapter takes its subsections along.
+	assert.Error(t, scope.MoveBefore(terms))
+	require.NoError(t, method.MoveBefore(scope))
+	outline = rd.Outline()
+	var titles, numbers []string
+	for _, node := range outline[0].Children {
+		titles = append(titles, node.Text)
+		numbers = append(numbers, node.Number)
+	}
+	assert.Equal(t, []string{"Method", "Scope", "Annex A"}, titles)
+	assert.Equal(t, []string{"1.", "2.", ""}, numbers)
+	assert.Equal(t, "2.1", outline[0].Children[1].Children[0].Number)
+
+	require.NoError(t, scope.MoveBefore(nil))
+	require.NoError(t, annex.Delete())
+	var texts []string
+	for _, child := range rd.Document.Body.Children {
+		if child.Para != nil {
+			texts = append(texts, child.Para.Text())
+		}
+	}
+	assert.Equal(t, []string{"Report", "Summary", "Method", "Method text", "Scope", "Scope text", "Terms"}, texts)
+	assert.Len(t, rd.Document.Body.Children, 8)
+	assert.Error(t, annex.Delete())
+
+	other, err := godocx.NewDocument()
+	require.NoError(t, err)
+	_, err = other.AddHeading("Other", 1)
+	require.NoError(t, err)
+	assert.Error(t, method.MoveBefore(other.Outline()[0]))
+	assert.Empty(t, (&docx.OutlineNode{}).Content())
+}
@@ -282,8 +282,8 @@ func (p *Paragraph) AddLink(text string, link string) *Hyperlink {
 //   - height: The height of the image in inches.
 //
 // Returns:
-//   - *dml.Inline: The created Inline instance representing the added drawing.
-func (p *Paragraph) addDrawing(rID string, imgCount uint, width units.Inch, height units.Inch) *dml.Inline {
+//   - *PicMeta: The paragraph and the created Inline instance representing the added drawing.
+func (p *Paragraph) addDrawing(rID string, imgCount uint, width units.Inch, height units.Inch) *PicMeta {
 	eWidth := width.ToEmu()
 	eHeight := height.ToEmu()
 
@@ -311,7 +311,12 @@ func (p *Paragraph) addDrawing(rID string, imgCount uint, width units.Inch, heig
 
 	p.ct.Children = append(p.ct.Children, ctypes.ParagraphChild{Run: run})
 
-	return &inline
+	// Refer to the inline stored in the drawing so that changes through PicMeta take effect.
+	return &PicMeta{
+		Para:    p,
+		Inline:  &drawing.Inline[len(drawing.Inline)-1],
+		drawing: drawing,
+	}
 }
 
 func (p *Paragraph) AddPicture(path string, width units.Inch, height units.Inch) (*PicMeta, error) {
@@ -321,38 +326,11 @@ func (p *Paragraph) AddPicture(path string, width units.Inch, height units.Inch)
 		return nil, err
 	}
 
-	imgExt := filepath.Ext(path)
-	p.root.ImageCount += 1
-	fileName := fmt.Sprintf("image%d%s", p.root.ImageCount, imgExt)
-	fileIdxPath := fmt.Sprintf("%s%s", constants.MediaPath, fileName)
-
-	imgExtStripDot := strings.TrimPrefix(imgExt, ".")
-	imgMIME, err := MIMEFromExt(imgExtStripDot)
-	if err != nil {
-		return nil, err
-	}
-
-	err = p.root.ContentType.AddExtension(imgExtStripDot, imgMIME)
-	if err != nil {
-		return nil, err
-	}
-
-	overridePart := fmt.Sprintf("/%s%s", constants.MediaPath, fileName)
-	err = p.root.ContentType.AddOverride(overridePart, imgMIME)
+	rID, err := p.root.addImagePart(imgBytes, strings.TrimPrefix(filepath.Ext(path), "."))
 	if err != nil {
 		return nil, err
 	}
 
-	p.root.FileMap.Store(fileIdxPath, imgBytes)
-
-	relName := fmt.Sprintf("media/%s", fileName)
-
-	rID := p.root.Document.addRelation(constants.SourceRelationshipImage, relName)
-
-	inline := p.addDrawing(rID, p.root.ImageCount, width, height)
-
-	return &PicMeta{
-		Para:   p,
-		Inline: inline,
-	}, nil
+	p.root.ImageCount += 1
+	return p.addDrawing(rID, p.root.ImageCount, width, height), nil
 }
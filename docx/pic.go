@@ -1,13 +1,21 @@
 package docx
 
 import (
+	"io"
+
 	"github.com/iEvan-lhr/docx-agent/common/units"
 	"github.com/iEvan-lhr/docx-agent/dml"
 )
 
+// PicMeta refers to a picture of a paragraph. Exactly one of Inline and Anchor is set,
+// depending on whether the picture is inline or floating.
 type PicMeta struct {
 	Para   *Paragraph
 	Inline *dml.Inline
+	Anchor *dml.Anchor
+
+	// drawing holds the picture
+	drawing *dml.Drawing
 }
 
 // AddPicture adds a new image to the document.
@@ -39,3 +47,27 @@ func (rd *RootDoc) AddPicture(path string, width units.Inch, height units.Inch)
 
 	return p.AddPicture(path, width, height)
 }
+
+// AddPictureFromBytes adds image data to the document in a new paragraph. See
+// Paragraph.AddPictureFromBytes.
+func (rd *RootDoc) AddPictureFromBytes(data []byte, opts *PictureOptions) (*PicMeta, error) {
+	p := newParagraph(rd)
+
+	pic, err := p.AddPictureFromBytes(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rd.Document.Body.Children = append(rd.Document.Body.Children, DocumentChild{Para: p})
+	return pic, nil
+}
+
+// AddPictureFromReader reads image data and adds it to the document in a new paragraph.
+// See Paragraph.AddPictureFromBytes.
+func (rd *RootDoc) AddPictureFromReader(r io.Reader, opts *PictureOptions) (*PicMeta, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return rd.AddPictureFromBytes(data, opts)
+}
@@ -3,24 +3,37 @@ package docx
 import (
 	"bytes"
 	"encoding/xml"
+	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/iEvan-lhr/docx-agent/common/constants"
 )
 
+const (
+	corePropsContentType = "application/vnd.openxmlformats-package.core-properties+xml"
+	appPropsContentType  = "application/vnd.openxmlformats-officedocument.extended-properties+xml"
+
+	// w3cdtf is the date and time format of the core properties.
+	w3cdtf = "2006-01-02T15:04:05Z"
+)
+
 // CoreProperties represents the core properties of a document, such as title, creator, and version.
 // It is used to store metadata information about the document.
 type CoreProperties struct {
 	Category       string
 	ContentStatus  string
-	Created        string
+	Created        time.Time
 	Creator        string
 	Description    string
 	Identifier     string
 	Keywords       string
 	LastModifiedBy string
-	Modified       string
-	Revision       string
+	LastPrinted    time.Time
+	Modified       time.Time
+	Revision       int
 	Subject        string
 	Title          string
 	Language       string
@@ -36,6 +49,7 @@ type sourceCoreProps struct {
 	Keywords       string         `xml:"keywords,omitempty"`
 	Description    string         `xml:"http://purl.org/dc/elements/1.1/ description,omitempty"`
 	LastModifiedBy string         `xml:"lastModifiedBy"`
+	LastPrinted    string         `xml:"lastPrinted,omitempty"`
 	Language       string         `xml:"http://purl.org/dc/elements/1.1/ language,omitempty"`
 	Identifier     string         `xml:"http://purl.org/dc/elements/1.1/ identifier,omitempty"`
 	Revision       string         `xml:"revision,omitempty"`
@@ -60,6 +74,7 @@ type finalCoreProps struct {
 	Keywords       string       `xml:"keywords,omitempty"`
 	Description    string       `xml:"dc:description,omitempty"`
 	LastModifiedBy string       `xml:"lastModifiedBy"`
+	LastPrinted    string       `xml:"lastPrinted,omitempty"`
 	Language       string       `xml:"dc:language,omitempty"`
 	Identifier     string       `xml:"dc:identifier,omitempty"`
 	Revision       string       `xml:"revision,omitempty"`
@@ -73,40 +88,57 @@ type finalCoreProps struct {
 // ExtendedProperties represents extended properties of a document, such as application details and statistics.
 // It is used to store additional metadata information about the document.
 type ExtendedProperties struct {
-	Application       string
-	ScaleCrop         bool
-	DocSecurity       int
-	Company           string
-	LinksUpToDate     bool
-	HyperlinksChanged bool
-	AppVersion        string
+	Template             string
+	Application          string
+	AppVersion           string
+	Company              string
+	Manager              string
+	HyperlinkBase        string
+	TotalTime            int // Total editing time in minutes
+	Pages                int
+	Words                int
+	Characters           int
+	CharactersWithSpaces int
+	Lines                int
+	Paragraphs           int
+	DocSecurity          int
+	ScaleCrop            bool
+	SharedDoc            bool
+	LinksUpToDate        bool
+	HyperlinksChanged    bool
 }
 
 // ctExtendedProperties is the structure used for encoding extended properties data to XML.
 type ctExtendedProperties struct {
-	FilePath             string         `xml:"-"`
-	XMLName              xml.Name       `xml:"http://schemas.openxmlformats.org/officeDocument/2006/extended-properties Properties"`
-	VT                   string         `xml:"xmlns:vt,attr"`
-	Template             *string        `xml:"Template,omitempty"`
-	TotalTime            *int           `xml:"TotalTime,omitempty"`
-	Pages                *int           `xml:"Pages,omitempty"`
-	Words                *int           `xml:"Words,omitempty"`
-	Characters           *int           `xml:"Characters,omitempty"`
-	Application          *string        `xml:"Application,omitempty"`
-	DocSecurity          *int           `xml:"DocSecurity,omitempty"`
-	Lines                *int           `xml:"Lines,omitempty"`
-	Paragraphs           *int           `xml:"Paragraphs,omitempty"`
-	ScaleCrop            *bool          `xml:"ScaleCrop,omitempty"`
-	HeadingPairs         *HeadingPairs  `xml:"HeadingPairs,omitempty"`
-	TitlesOfParts        *TitlesOfParts `xml:"TitlesOfParts,omitempty"`
-	Manager              *string        `xml:"Manager,omitempty"`
-	Company              *string        `xml:"Company,omitempty"`
-	LinksUpToDate        *bool          `xml:"LinksUpToDate,omitempty"`
-	CharactersWithSpaces *int           `xml:"CharactersWithSpaces,omitempty"`
-	SharedDoc            *bool          `xml:"SharedDoc,omitempty"`
-	HyperlinkBase        *string        `xml:"HyperlinkBase,omitempty"`
-	HyperlinksChanged    *bool          `xml:"HyperlinksChanged,omitempty"`
-	AppVersion           *string        `xml:"AppVersion,omitempty"`
+	FilePath             string    `xml:"-"`
+	XMLName              xml.Name  `xml:"http://schemas.openxmlformats.org/officeDocument/2006/extended-properties Properties"`
+	VT                   string    `xml:"xmlns:vt,attr"`
+	Template             *string   `xml:"Template,omitempty"`
+	TotalTime            *int      `xml:"TotalTime,omitempty"`
+	Pages                *int      `xml:"Pages,omitempty"`
+	Words                *int      `xml:"Words,omitempty"`
+	Characters           *int      `xml:"Characters,omitempty"`
+	Application          *string   `xml:"Application,omitempty"`
+	DocSecurity          *int      `xml:"DocSecurity,omitempty"`
+	Lines                *int      `xml:"Lines,omitempty"`
+	Paragraphs           *int      `xml:"Paragraphs,omitempty"`
+	ScaleCrop            *bool     `xml:"ScaleCrop,omitempty"`
+	HeadingPairs         *innerXML `xml:"HeadingPairs,omitempty"`
+	TitlesOfParts        *innerXML `xml:"TitlesOfParts,omitempty"`
+	Manager              *string   `xml:"Manager,omitempty"`
+	Company              *string   `xml:"Company,omitempty"`
+	LinksUpToDate        *bool     `xml:"LinksUpToDate,omitempty"`
+	CharactersWithSpaces *int      `xml:"CharactersWithSpaces,omitempty"`
+	SharedDoc            *bool     `xml:"SharedDoc,omitempty"`
+	HyperlinkBase        *string   `xml:"HyperlinkBase,omitempty"`
+	HyperlinksChanged    *bool     `xml:"HyperlinksChanged,omitempty"`
+	AppVersion           *string   `xml:"AppVersion,omitempty"`
+}
+
+// innerXML keeps the content of an element as raw XML, so that parts of the extended
+// properties that are not modelled, such as the heading pairs, are written back unchanged.
+type innerXML struct {
+	XML string `xml:",innerxml"`
 }
 
 // HeadingPairs represents a set of heading pairs used in extended properties.
@@ -156,7 +188,7 @@ func LoadDocProps(fileBytes []byte) (cp *CoreProperties, err error) {
 		Decode(core); err != nil && err != io.EOF {
 		return
 	}
-	cp, err = &CoreProperties{
+	cp = &CoreProperties{
 		Category:       core.Category,
 		ContentStatus:  core.ContentStatus,
 		Creator:        core.Creator,
@@ -164,17 +196,290 @@ func LoadDocProps(fileBytes []byte) (cp *CoreProperties, err error) {
 		Identifier:     core.Identifier,
 		Keywords:       core.Keywords,
 		LastModifiedBy: core.LastModifiedBy,
-		Revision:       core.Revision,
 		Subject:        core.Subject,
 		Title:          core.Title,
 		Language:       core.Language,
 		Version:        core.Version,
-	}, nil
+	}
+	if rev := strings.TrimSpace(core.Revision); rev != "" {
+		if cp.Revision, err = strconv.Atoi(rev); err != nil {
+			return nil, fmt.Errorf("invalid revision %q: %w", core.Revision, err)
+		}
+	}
 	if core.Created != nil {
-		cp.Created = core.Created.Text
+		if cp.Created, err = parseW3CDTF(core.Created.Text); err != nil {
+			return nil, err
+		}
 	}
 	if core.Modified != nil {
-		cp.Modified = core.Modified.Text
+		if cp.Modified, err = parseW3CDTF(core.Modified.Text); err != nil {
+			return nil, err
+		}
 	}
-	return
+	if cp.LastPrinted, err = parseW3CDTF(core.LastPrinted); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// parseW3CDTF parses a date in one of the W3CDTF forms, from a year alone to a full
+// date and time. An empty string gives the zero time.
+func parseW3CDTF(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04Z07:00", "2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid W3CDTF date %q", s)
+}
+
+// formatW3CDTF formats a time in UTC, as Word writes the core property dates. The zero
+// time gives an empty string.
+func formatW3CDTF(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(w3cdtf)
+}
+
+// dcTerms returns the dcterms element of a time, or nil for the zero time.
+func dcTerms(t time.Time) *docxDcTerms {
+	if t.IsZero() {
+		return nil
+	}
+	return &docxDcTerms{Text: formatW3CDTF(t), Type: "dcterms:W3CDTF"}
+}
+
+// encodeCoreProps returns the XML of the core properties part.
+func encodeCoreProps(cp *CoreProperties) ([]byte, error) {
+	out := finalCoreProps{
+		Dc:             "http://purl.org/dc/elements/1.1/",
+		Dcterms:        "http://purl.org/dc/terms/",
+		Dcmitype:       "http://purl.org/dc/dcmitype/",
+		XSI:            "http://www.w3.org/2001/XMLSchema-instance",
+		Title:          cp.Title,
+		Subject:        cp.Subject,
+		Creator:        cp.Creator,
+		Keywords:       cp.Keywords,
+		Description:    cp.Description,
+		LastModifiedBy: cp.LastModifiedBy,
+		LastPrinted:    formatW3CDTF(cp.LastPrinted),
+		Language:       cp.Language,
+		Identifier:     cp.Identifier,
+		Created:        dcTerms(cp.Created),
+		Modified:       dcTerms(cp.Modified),
+		ContentStatus:  cp.ContentStatus,
+		Category:       cp.Category,
+		Version:        cp.Version,
+	}
+	if cp.Revision > 0 {
+		out.Revision = strconv.Itoa(cp.Revision)
+	}
+	return marshal(out)
+}
+
+// rootPart returns the path of the package part with the given root relationship type,
+// or an empty string when the package has none.
+func (rd *RootDoc) rootPart(relType string) string {
+	for _, rel := range rd.RootRels.Relationships {
+		if rel.Type == relType {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+	}
+	return ""
+}
+
+// ensureRootPart returns the path of the package part with the given root relationship
+// type, relating the default path to the package when there is no such part yet.
+func (rd *RootDoc) ensureRootPart(relType, defaultPath, contentType string) (string, error) {
+	if path := rd.rootPart(relType); path != "" {
+		return path, nil
+	}
+
+	ids := make(map[string]bool, len(rd.RootRels.Relationships))
+	for _, rel := range rd.RootRels.Relationships {
+		ids[rel.ID] = true
+	}
+	id := "rId1"
+	for n := 2; ids[id]; n++ {
+		id = "rId" + strconv.Itoa(n)
+	}
+
+	if err := rd.ContentType.AddOverride("/"+defaultPath, contentType); err != nil {
+		return "", err
+	}
+	rd.RootRels.Relationships = append(rd.RootRels.Relationships, &Relationship{
+		ID:     id,
+		Type:   relType,
+		Target: defaultPath,
+	})
+	return defaultPath, nil
+}
+
+// loadRootPart returns the content of the package part with the given root relationship
+// type, or nil when the package has none.
+func (rd *RootDoc) loadRootPart(relType string) []byte {
+	path := rd.rootPart(relType)
+	if path == "" {
+		return nil
+	}
+	content, ok := rd.FileMap.Load(path)
+	if !ok {
+		return nil
+	}
+	return content.([]byte)
+}
+
+// CoreProps returns the core properties of the document, such as its title, author and
+// dates. A document without a core properties part gives empty properties.
+func (rd *RootDoc) CoreProps() (*CoreProperties, error) {
+	content := rd.loadRootPart(constants.CORE_PROP_TYPE)
+	if content == nil {
+		return &CoreProperties{}, nil
+	}
+	return LoadDocProps(content)
+}
+
+// SetCoreProps replaces the core properties of the document, adding the core properties
+// part when the document has none.
+//
+// Example:
+//
+//	props, _ := document.CoreProps()
+//	props.Title = "Quarterly report"
+//	props.Creator = "Finance"
+//	document.SetCoreProps(props)
+func (rd *RootDoc) SetCoreProps(cp *CoreProperties) error {
+	if cp == nil {
+		return fmt.Errorf("core properties are nil")
+	}
+	content, err := encodeCoreProps(cp)
+	if err != nil {
+		return err
+	}
+	path, err := rd.ensureRootPart(constants.CORE_PROP_TYPE, "docProps/core.xml", corePropsContentType)
+	if err != nil {
+		return err
+	}
+	rd.FileMap.Store(path, content)
+	return nil
+}
+
+// touchCoreProps records a save of the document in its core properties: it sets the
+// modification time, bumps the revision and sets the creation time if it is missing.
+// Core properties that cannot be decoded, such as dates written by another tool in a
+// format other than W3CDTF, are left as they are rather than failing the save.
+func (rd *RootDoc) touchCoreProps(now time.Time) error {
+	cp, err := rd.CoreProps()
+	if err != nil {
+		return nil
+	}
+	now = now.UTC().Truncate(time.Second)
+	if cp.Created.IsZero() {
+		cp.Created = now
+	}
+	cp.Modified = now
+	cp.Revision++
+	return rd.SetCoreProps(cp)
+}
+
+// loadAppProps decodes the extended properties part of the document, which is empty when
+// the document has none.
+func (rd *RootDoc) loadAppProps() (*ctExtendedProperties, error) {
+	props := new(ctExtendedProperties)
+	content := rd.loadRootPart(constants.EXTENDED_PROP_TYPE)
+	if content == nil {
+		return props, nil
+	}
+	if err := xmlNewDecoder(bytes.NewReader(constants.TranslateNamespace(content))).
+		Decode(props); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return props, nil
+}
+
+// AppProps returns the extended properties of the document, such as its company and
+// statistics. A document without an extended properties part gives empty properties.
+func (rd *RootDoc) AppProps() (*ExtendedProperties, error) {
+	props, err := rd.loadAppProps()
+	if err != nil {
+		return nil, err
+	}
+	return &ExtendedProperties{
+		Template:             deref(props.Template),
+		Application:          deref(props.Application),
+		AppVersion:           deref(props.AppVersion),
+		Company:              deref(props.Company),
+		Manager:              deref(props.Manager),
+		HyperlinkBase:        deref(props.HyperlinkBase),
+		TotalTime:            deref(props.TotalTime),
+		Pages:                deref(props.Pages),
+		Words:                deref(props.Words),
+		Characters:           deref(props.Characters),
+		CharactersWithSpaces: deref(props.CharactersWithSpaces),
+		Lines:                deref(props.Lines),
+		Paragraphs:           deref(props.Paragraphs),
+		DocSecurity:          deref(props.DocSecurity),
+		ScaleCrop:            deref(props.ScaleCrop),
+		SharedDoc:            deref(props.SharedDoc),
+		LinksUpToDate:        deref(props.LinksUpToDate),
+		HyperlinksChanged:    deref(props.HyperlinksChanged),
+	}, nil
+}
+
+// SetAppProps replaces the extended properties of the document, adding the extended
+// properties part when the document has none. The heading pairs and titles of parts of
+// an existing part are kept.
+func (rd *RootDoc) SetAppProps(ep *ExtendedProperties) error {
+	if ep == nil {
+		return fmt.Errorf("extended properties are nil")
+	}
+	props, err := rd.loadAppProps()
+	if err != nil {
+		return err
+	}
+
+	props.VT = constants.NameSpaceDocumentPropertiesVariantTypes.Value
+	props.Template = &ep.Template
+	props.Application = &ep.Application
+	props.AppVersion = &ep.AppVersion
+	props.Company = &ep.Company
+	props.Manager = &ep.Manager
+	props.HyperlinkBase = &ep.HyperlinkBase
+	props.TotalTime = &ep.TotalTime
+	props.Pages = &ep.Pages
+	props.Words = &ep.Words
+	props.Characters = &ep.Characters
+	props.CharactersWithSpaces = &ep.CharactersWithSpaces
+	props.Lines = &ep.Lines
+	props.Paragraphs = &ep.Paragraphs
+	props.DocSecurity = &ep.DocSecurity
+	props.ScaleCrop = &ep.ScaleCrop
+	props.SharedDoc = &ep.SharedDoc
+	props.LinksUpToDate = &ep.LinksUpToDate
+	props.HyperlinksChanged = &ep.HyperlinksChanged
+
+	content, err := marshal(props)
+	if err != nil {
+		return err
+	}
+	path, err := rd.ensureRootPart(constants.EXTENDED_PROP_TYPE, "docProps/app.xml", appPropsContentType)
+	if err != nil {
+		return err
+	}
+	rd.FileMap.Store(path, content)
+	return nil
+}
+
+// deref returns the value a pointer points to, or the zero value for nil.
+func deref[T any](v *T) T {
+	if v == nil {
+		var zero T
+		return zero
+	}
+	return *v
 }
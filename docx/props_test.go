@@ -0,0 +1,115 @@
+package docx_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentProperties(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	core, err := rd.CoreProps()
+	require.NoError(t, err)
+	assert.Equal(t, "gomutex", core.Creator)
+	assert.Equal(t, 1, core.Revision)
+	assert.Equal(t, time.Date(2013, 12, 23, 23, 15, 0, 0, time.UTC), core.Created)
+
+	core.Title = "Quarterly report"
+	core.Keywords = "finance, q3"
+	core.Creator = "Finance & Co"
+	require.NoError(t, rd.SetCoreProps(core))
+
+	app, err := rd.AppProps()
+	require.NoError(t, err)
+	assert.Equal(t, "Normal.dotm", app.Template)
+	app.Company = "Contoso"
+	app.Pages = 12
+	app.Words = 3400
+	require.NoError(t, rd.SetAppProps(app))
+
+	approved := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	require.NoError(t, rd.SetCustomProp("Client", "Contoso"))
+	require.NoError(t, rd.SetCustomProp("Budget", 125000))
+	require.NoError(t, rd.SetCustomProp("Rate", 0.25))
+	require.NoError(t, rd.SetCustomProp("Approved", true))
+	require.NoError(t, rd.SetCustomProp("ApprovedOn", approved))
+	require.NoError(t, rd.SetCustomProp("Client", "Fabrikam"))
+	require.NoError(t, rd.SetCustomProp("Obsolete", "x"))
+	require.NoError(t, rd.DeleteCustomProp("Obsolete"))
+	assert.Error(t, rd.SetCustomProp("Bad", []string{"x"}))
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	parts := zipParts(t, buf.Bytes())
+	assert.Contains(t, string(parts["[Content_Types].xml"]), `PartName="/docProps/custom.xml"`)
+	assert.Contains(t, string(parts["_rels/.rels"]), `Target="docProps/custom.xml"`)
+	assert.Contains(t, string(parts["docProps/app.xml"]), `<vt:lpstr>Title</vt:lpstr>`)
+	assert.Contains(t, string(parts["docProps/custom.xml"]), `<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="2" name="Client"><vt:lpwstr>Fabrikam</vt:lpwstr></property>`)
+
+	content := buf.Bytes()
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+
+	core, err = reopened.CoreProps()
+	require.NoError(t, err)
+	assert.Equal(t, "Quarterly report", core.Title)
+	assert.Equal(t, "finance, q3", core.Keywords)
+	assert.Equal(t, "Finance & Co", core.Creator)
+
+	app, err = reopened.AppProps()
+	require.NoError(t, err)
+	assert.Equal(t, "Contoso", app.Company)
+	assert.Equal(t, 12, app.Pages)
+	assert.Equal(t, 3400, app.Words)
+
+	custom, err := reopened.CustomProps()
+	require.NoError(t, err)
+	assert.Equal(t, []docx.CustomProperty{
+		{Name: "Client", Value: "Fabrikam"},
+		{Name: "Budget", Value: 125000},
+		{Name: "Rate", Value: 0.25},
+		{Name: "Approved", Value: true},
+		{Name: "ApprovedOn", Value: approved},
+	}, custom)
+
+	// Saving records the modification.
+	path := filepath.Join(t.TempDir(), "props.docx")
+	before := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, reopened.SaveTo(path))
+	saved, err := godocx.OpenDocument(path)
+	require.NoError(t, err)
+	core, err = saved.CoreProps()
+	require.NoError(t, err)
+	assert.Equal(t, 2, core.Revision)
+	assert.False(t, core.Modified.Before(before))
+	assert.Equal(t, time.Date(2013, 12, 23, 23, 15, 0, 0, time.UTC), core.Created)
+}
+
+func TestSaveKeepsUndecodableCoreProps(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	// A modification date written by another tool, not in the W3CDTF format.
+	core := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><dc:title>Legacy</dc:title><dcterms:modified xsi:type="dcterms:W3CDTF">03/15/2021 10:00</dcterms:modified></cp:coreProperties>`)
+	rd.FileMap.Store("docProps/core.xml", core)
+	_, err = rd.CoreProps()
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "legacy.docx")
+	require.NoError(t, rd.SaveTo(path))
+	saved, err := godocx.OpenDocument(path)
+	require.NoError(t, err)
+	content, ok := saved.FileMap.Load("docProps/core.xml")
+	require.True(t, ok)
+	assert.Equal(t, core, content)
+}
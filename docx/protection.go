@@ -0,0 +1,342 @@
+package docx
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"unicode/utf16"
+
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+)
+
+// ProtectionMode is the kind of editing Word still allows in a protected document.
+type ProtectionMode string
+
+const (
+	ProtectReadOnly       ProtectionMode = "readOnly"       // No changes, except in editable ranges
+	ProtectComments       ProtectionMode = "comments"       // Comments only
+	ProtectTrackedChanges ProtectionMode = "trackedChanges" // Changes are tracked as revisions
+	ProtectForms          ProtectionMode = "forms"          // Filling in form fields only
+)
+
+// Editor groups, which may be given to AddEditableRange instead of a user.
+const (
+	EditorsEveryone       = "everyone"
+	EditorsAdministrators = "administrators"
+	EditorsContributors   = "contributors"
+	EditorsEditors        = "editors"
+	EditorsOwners         = "owners"
+	EditorsCurrent        = "current"
+)
+
+var editorGroups = map[string]bool{
+	EditorsEveryone: true, EditorsAdministrators: true, EditorsContributors: true,
+	EditorsEditors: true, EditorsOwners: true, EditorsCurrent: true,
+}
+
+// protectionSpinCount is the number of hash iterations Word uses for passwords.
+const protectionSpinCount = 100000
+
+// Protect restricts editing of the document to the given mode. With a password, Word asks
+// for it before it stops enforcing the protection; the password is stored as a salted
+// SHA-512 hash, as Word does. Protecting for tracked changes also turns tracking on.
+//
+// Example:
+//
+//	document.Protect(docx.ProtectReadOnly, "secret")
+func (rd *RootDoc) Protect(mode ProtectionMode, password string) error {
+	switch mode {
+	case ProtectReadOnly, ProtectComments, ProtectTrackedChanges, ProtectForms:
+	default:
+		return fmt.Errorf("invalid protection mode %q", mode)
+	}
+
+	enforced := true
+	protection := &ctypes.DocProtect{Edit: string(mode), Enforcement: &enforced}
+	if password != "" {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+		spinCount := protectionSpinCount
+		protection.AlgorithmName = "SHA-512"
+		protection.HashValue = base64.StdEncoding.EncodeToString(hashPassword(password, sha512.New, salt, spinCount))
+		protection.SaltValue = base64.StdEncoding.EncodeToString(salt)
+		protection.SpinCount = &spinCount
+	}
+
+	s := rd.settings()
+	s.DocumentProtection = protection
+	if mode == ProtectTrackedChanges {
+		s.TrackRevisions = onOffSetting(true)
+	}
+	return nil
+}
+
+// Unprotect removes the editing restrictions of the document. Editable ranges are kept,
+// and apply again when the document is protected.
+func (rd *RootDoc) Unprotect() {
+	if rd.Settings != nil {
+		rd.Settings.DocumentProtection = nil
+	}
+}
+
+// Protection returns the editing restrictions of the document and whether they are
+// enforced. An unprotected document gives an empty mode.
+func (rd *RootDoc) Protection() (ProtectionMode, bool) {
+	if rd.Settings == nil || rd.Settings.DocumentProtection == nil {
+		return "", false
+	}
+	p := rd.Settings.DocumentProtection
+	return ProtectionMode(p.Edit), p.Enforcement != nil && *p.Enforcement
+}
+
+// CheckProtectionPassword reports whether the password is the one protecting the document.
+// Any password matches a document protected without one.
+func (rd *RootDoc) CheckProtectionPassword(password string) (bool, error) {
+	if rd.Settings == nil || rd.Settings.DocumentProtection == nil {
+		return false, errors.New("document is not protected")
+	}
+	p := rd.Settings.DocumentProtection
+	if p.HashValue == "" {
+		return true, nil
+	}
+
+	var newHash func() hash.Hash
+	switch p.AlgorithmName {
+	case "SHA-1":
+		newHash = sha1.New
+	case "SHA-256":
+		newHash = sha256.New
+	case "SHA-384":
+		newHash = sha512.New384
+	case "SHA-512":
+		newHash = sha512.New
+	default:
+		return false, fmt.Errorf("unsupported protection hash algorithm %q", p.AlgorithmName)
+	}
+
+	expected, err := base64.StdEncoding.DecodeString(p.HashValue)
+	if err != nil {
+		return false, fmt.Errorf("invalid protection hash: %w", err)
+	}
+	salt, err := base64.StdEncoding.DecodeString(p.SaltValue)
+	if err != nil {
+		return false, fmt.Errorf("invalid protection salt: %w", err)
+	}
+	spinCount := 0
+	if p.SpinCount != nil {
+		spinCount = *p.SpinCount
+	}
+
+	actual := hashPassword(password, newHash, salt, spinCount)
+	return subtle.ConstantTimeCompare(actual, expected) == 1, nil
+}
+
+// Word password hashing (ECMA-376 Part 1, §17.15.1.29). The password first goes through
+// the legacy Word key derivation, whose result is hashed with the salt and then rehashed
+// spinCount times together with the iteration number.
+var (
+	passwordInitialCode = [15]uint16{
+		0xE1F0, 0x1D0F, 0xCC9C, 0x84C0, 0x110C, 0x0E10, 0xF1CE,
+		0x313E, 0x1872, 0xE139, 0xD40F, 0x84F9, 0x280C, 0xA96A, 0x4EC3,
+	}
+	passwordEncryptionMatrix = [15][7]uint16{
+		{0xAEFC, 0x4DD9, 0x9BB2, 0x2745, 0x4E8A, 0x9D14, 0x2A09},
+		{0x7B61, 0xF6C2, 0xFDA5, 0xEB6B, 0xC6F7, 0x9DCF, 0x2BBF},
+		{0x4563, 0x8AC6, 0x05AD, 0x0B5A, 0x16B4, 0x2D68, 0x5AD0},
+		{0x0375, 0x06EA, 0x0DD4, 0x1BA8, 0x3750, 0x6EA0, 0xDD40},
+		{0xD849, 0xA0B3, 0x5147, 0xA28E, 0x553D, 0xAA7A, 0x44D5},
+		{0x6F45, 0xDE8A, 0xAD35, 0x4A4B, 0x9496, 0x390D, 0x721A},
+		{0xEB23, 0xC667, 0x9CEF, 0x29FF, 0x53FE, 0xA7FC, 0x5FD9},
+		{0x47D3, 0x8FA6, 0x0F6D, 0x1EDA, 0x3DB4, 0x7B68, 0xF6D0},
+		{0xB861, 0x60E3, 0xC1C6, 0x93AD, 0x377B, 0x6EF6, 0xDDEC},
+		{0x45A0, 0x8B40, 0x06A1, 0x0D42, 0x1A84, 0x3508, 0x6A10},
+		{0xAA51, 0x4483, 0x8906, 0x022D, 0x045A, 0x08B4, 0x1168},
+		{0x76B4, 0xED68, 0xCAF1, 0x85C3, 0x1BA7, 0x374E, 0x6E9C},
+		{0x3730, 0x6E60, 0xDCC0, 0xA9A1, 0x4363, 0x86C6, 0x1DAD},
+		{0x3331, 0x6662, 0xCCC4, 0x89A9, 0x0373, 0x06E6, 0x0DCC},
+		{0x1021, 0x2042, 0x4084, 0x8108, 0x1231, 0x2462, 0x48C4},
+	}
+)
+
+// legacyPasswordKey returns the legacy Word key of a password as the hexadecimal string
+// of its bytes in little-endian order.
+func legacyPasswordKey(password string) string {
+	runes := []rune(password)
+	if len(runes) > len(passwordInitialCode) {
+		runes = runes[:len(passwordInitialCode)]
+	}
+	if len(runes) == 0 {
+		return "00000000"
+	}
+
+	// Each character contributes one byte: its low byte, or its high byte when that is 0.
+	chars := make([]byte, len(runes))
+	for i, r := range runes {
+		chars[i] = byte(r)
+		if chars[i] == 0 {
+			chars[i] = byte(r >> 8)
+		}
+	}
+
+	high := passwordInitialCode[len(chars)-1]
+	for i, c := range chars {
+		row := passwordEncryptionMatrix[len(passwordInitialCode)-len(chars)+i]
+		for bit := 0; bit < 7; bit++ {
+			if c&(1<<bit) != 0 {
+				high ^= row[bit]
+			}
+		}
+	}
+
+	var low uint16
+	for i := len(chars) - 1; i >= 0; i-- {
+		low = rotateLeft15(low) ^ uint16(chars[i])
+	}
+	low = rotateLeft15(low) ^ uint16(len(chars)) ^ 0xCE4B
+
+	key := uint32(high)<<16 | uint32(low)
+	return fmt.Sprintf("%02X%02X%02X%02X", byte(key), byte(key>>8), byte(key>>16), byte(key>>24))
+}
+
+// rotateLeft15 rotates a 15-bit value one bit to the left.
+func rotateLeft15(v uint16) uint16 {
+	return (v>>14)&0x0001 | (v<<1)&0x7FFF
+}
+
+// hashPassword returns the Word hash of a password.
+func hashPassword(password string, newHash func() hash.Hash, salt []byte, spinCount int) []byte {
+	key := legacyPasswordKey(password)
+	keyBytes := make([]byte, 0, 2*len(key))
+	for _, u := range utf16.Encode([]rune(key)) {
+		keyBytes = binary.LittleEndian.AppendUint16(keyBytes, u)
+	}
+
+	h := newHash()
+	h.Write(salt)
+	h.Write(keyBytes)
+	sum := h.Sum(nil)
+
+	iterator := make([]byte, 4)
+	for i := 0; i < spinCount; i++ {
+		binary.LittleEndian.PutUint32(iterator, uint32(i))
+		h.Reset()
+		h.Write(sum)
+		h.Write(iterator)
+		sum = h.Sum(sum[:0])
+	}
+	return sum
+}
+
+// EditableRange is a range of paragraphs which a user or group may edit while the document
+// is protected.
+type EditableRange struct {
+	ID    string
+	User  string // The user who may edit the range, if it is not a group
+	Group string // The group who may edit the range, such as EditorsEveryone
+	Start *Paragraph
+	End   *Paragraph
+}
+
+// AddEditableRange lets the given editors change the paragraphs from first to last, which
+// may be the same, while the rest of the document is protected. An editor is a user, such
+// as "someone@example.com" or `DOMAIN\someone`, or one of the editor groups, such as
+// EditorsEveryone. Without editors the range is editable by everyone.
+//
+// Example:
+//
+//	field := document.AddParagraph("Client name: ")
+//	document.AddEditableRange(field, field)
+//	document.Protect(docx.ProtectReadOnly, "secret")
+func (rd *RootDoc) AddEditableRange(first, last *Paragraph, editors ...string) error {
+	if first == nil || last == nil {
+		return errors.New("editable range needs a first and a last paragraph")
+	}
+	if len(editors) == 0 {
+		editors = []string{EditorsEveryone}
+	}
+
+	next := 0
+	for _, r := range rd.EditableRanges() {
+		if id, err := strconv.Atoi(r.ID); err == nil && id >= next {
+			next = id + 1
+		}
+	}
+
+	// One range per editor, as a range names a single user or group.
+	starts := make([]ctypes.ParagraphChild, 0, len(editors))
+	for _, editor := range editors {
+		id := strconv.Itoa(next)
+		next++
+
+		start := &ctypes.PermStart{ID: id}
+		if editorGroups[editor] {
+			start.EdGrp = editor
+		} else {
+			start.Ed = editor
+		}
+		starts = append(starts, ctypes.ParagraphChild{PermStart: start})
+		last.ct.Children = append(last.ct.Children, ctypes.ParagraphChild{PermEnd: &ctypes.PermEnd{ID: id}})
+	}
+	first.ct.Children = append(starts, first.ct.Children...)
+	return nil
+}
+
+// EditableRanges returns the editable ranges of the document body, including those in
+// tables, in the order they start.
+func (rd *RootDoc) EditableRanges() []*EditableRange {
+	if rd.Document == nil || rd.Document.Body == nil {
+		return nil
+	}
+
+	var ranges []*EditableRange
+	byID := make(map[string]*EditableRange)
+	visit := func(p *Paragraph) {
+		for _, child := range p.ct.Children {
+			switch {
+			case child.PermStart != nil:
+				r := &EditableRange{
+					ID:    child.PermStart.ID,
+					User:  child.PermStart.Ed,
+					Group: child.PermStart.EdGrp,
+					Start: p,
+				}
+				ranges = append(ranges, r)
+				byID[r.ID] = r
+			case child.PermEnd != nil:
+				if r, ok := byID[child.PermEnd.ID]; ok && r.End == nil {
+					r.End = p
+				}
+			}
+		}
+	}
+
+	for _, child := range rd.Document.Body.Children {
+		switch {
+		case child.Para != nil:
+			visit(child.Para)
+		case child.Table != nil:
+			wrapTableParagraphs(rd, &child.Table.ct, hasPermMarks, visit)
+		}
+	}
+	return ranges
+}
+
+// hasPermMarks reports whether a paragraph starts or ends an editable range.
+func hasPermMarks(p *ctypes.Paragraph) bool {
+	for _, child := range p.Children {
+		if child.PermStart != nil || child.PermEnd != nil {
+			return true
+		}
+	}
+	return false
+}
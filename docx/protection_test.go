@@ -0,0 +1,114 @@
+package docx_test
+
+import (
+	"bytes"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtection(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	rd.AddParagraph("This agreement is made between the parties below.")
+	client := rd.AddParagraph("Client: ")
+	cell := rd.AddTable().AddRow().AddCell()
+	signature := cell.AddParagraph("Signature: ")
+	date := cell.AddParagraph("Date: ")
+
+	require.NoError(t, rd.AddEditableRange(client, client))
+	require.NoError(t, rd.AddEditableRange(signature, date, `CONTOSO\jane`, docx.EditorsEditors))
+	assert.Error(t, rd.Protect("everything", ""))
+	require.NoError(t, rd.Protect(docx.ProtectReadOnly, "s3cret"))
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	parts := zipParts(t, buf.Bytes())
+	assert.Contains(t, string(parts["word/settings.xml"]), `<w:documentProtection w:edit="readOnly" w:enforcement="1" w:algorithmName="SHA-512" w:hashValue="`)
+	assert.Contains(t, string(parts["word/document.xml"]), `<w:p><w:permStart w:id="0" w:edGrp="everyone"></w:permStart><w:r>`)
+
+	content := buf.Bytes()
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+
+	mode, enforced := reopened.Protection()
+	assert.Equal(t, docx.ProtectReadOnly, mode)
+	assert.True(t, enforced)
+
+	ok, err := reopened.CheckProtectionPassword("s3cret")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = reopened.CheckProtectionPassword("secret")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ranges := reopened.EditableRanges()
+	require.Len(t, ranges, 3)
+	assert.Equal(t, docx.EditorsEveryone, ranges[0].Group)
+	assert.Equal(t, "Client: ", ranges[0].Start.Text())
+	assert.Equal(t, ranges[0].Start, ranges[0].End)
+	assert.Equal(t, `CONTOSO\jane`, ranges[1].User)
+	assert.Equal(t, docx.EditorsEditors, ranges[2].Group)
+	assert.Equal(t, "Date: ", ranges[2].End.Text())
+	assert.Same(t, ranges[1].Start, ranges[2].Start)
+
+	// The paragraphs of ranges in tables are those of the document.
+	ranges[2].End.AddText("2024-05-01")
+	assert.Equal(t, "Date: 2024-05-01", reopened.EditableRanges()[2].End.Text())
+
+	reopened.Unprotect()
+	mode, _ = reopened.Protection()
+	assert.Empty(t, mode)
+
+	require.NoError(t, reopened.Protect(docx.ProtectTrackedChanges, ""))
+	assert.True(t, reopened.TrackRevisions())
+	ok, err = reopened.CheckProtectionPassword("anything")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCheckProtectionPasswordVectors(t *testing.T) {
+	// Hashes computed apart from this package, following ECMA-376 Part 1, §17.15.1.29.
+	tests := []struct {
+		name      string
+		algorithm string
+		hash      string
+		password  string
+	}{
+		{"sha-1", "SHA-1", "OnI91S87CsHj74Lj9G5lSUGz6s4=", "Example"},
+		{"sha-512", "SHA-512", "K1Kt8GI2R13SGtggtGCvzwKuQJpF7553j4xXgPlrQPMXd2zV2d1Zyvt9TfCDojDVTeQA3b+ZR0eCZk8Yd8h0VA==", "Example"},
+		{"non-ascii", "SHA-512", "I9A5c0JzZRBqGXIy2OdZvAq1Ie5PCeOT14P27IBd85D8+50YLjSK8vmf2loEhSHdZYY5DkSDNDuDIYx84FT51Q==", "Passwörd"},
+	}
+
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protection := `<w:documentProtection w:edit="readOnly" w:enforcement="1" w:algorithmName="` + tt.algorithm +
+				`" w:hashValue="` + tt.hash + `" w:saltValue="ZUdHa+D8F/OAKP3I7ssUnQ==" w:spinCount="100000"/></w:settings>`
+			content := rewriteZip(t, buf.Bytes(), func(name string, content []byte) (string, []byte) {
+				if name == "word/settings.xml" {
+					content = bytes.Replace(content, []byte("</w:settings>"), []byte(protection), 1)
+				}
+				return name, content
+			})
+			doc, err := packager.Unpack(&content)
+			require.NoError(t, err)
+
+			ok, err := doc.CheckProtectionPassword(tt.password)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			ok, err = doc.CheckProtectionPassword(tt.password + "!")
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
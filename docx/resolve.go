@@ -0,0 +1,605 @@
+package docx
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// defaultFontSize is the font size, in half-points, used when no level of the hierarchy sets one.
+const defaultFontSize = 20
+
+// defaultTableLook is the tblLook value Word applies to tables that do not specify one:
+// header row, first column and banded rows.
+const defaultTableLook = "04A0"
+
+// TableCellRef locates a paragraph or run inside a table, so that the table style and its
+// conditional formatting (w:tblStylePr) can take part in the resolution.
+type TableCellRef struct {
+	Table *ctypes.Table
+	Row   int // Zero-based row index
+	Col   int // Zero-based index of the cell within the row
+}
+
+// RunFormat is a flattened view of the effective formatting of a run.
+type RunFormat struct {
+	Font         string  // Latin font (ascii, falling back to hAnsi)
+	EastAsiaFont string  // East Asian font
+	ComplexFont  string  // Complex script font
+	SizePt       float64 // Font size in points
+	Bold         bool
+	Italic       bool
+	Underline    string // Underline type, empty when not underlined
+	Strike       bool
+	DoubleStrike bool
+	Caps         bool
+	SmallCaps    bool
+	Hidden       bool
+	Color        string // Hex color or "auto", empty when unset
+	Highlight    string
+	VertAlign    string // "superscript", "subscript" or empty
+}
+
+// StyleResolver computes the effective formatting of paragraphs and runs by walking the
+// full style hierarchy:
+//
+//	document defaults → table style (incl. conditional formatting) → paragraph style chain →
+//	numbering level → character style chain → direct formatting
+//
+// Bold, italic and the other toggle properties follow the toggle semantics of the
+// specification: each style level that turns them on flips the inherited value, while
+// direct formatting sets them absolutely.
+//
+// A resolver caches style chains, so it should be discarded when the styles change.
+// The returned properties are fresh copies and may be modified by the caller.
+type StyleResolver struct {
+	rd        *RootDoc
+	styles    map[stypes.StyleType]map[string]*ctypes.Style
+	defaults  map[stypes.StyleType]*ctypes.Style
+	chains    map[string]*resolvedStyle
+	numbering *ctypes.Numbering
+	theme     *Theme
+}
+
+// resolvedStyle holds the properties of a style merged with every style it is based on.
+type resolvedStyle struct {
+	pPr         *ctypes.ParagraphProp
+	rPr         *ctypes.RunProperty
+	tblPr       *ctypes.TableProp
+	tcPr        *ctypes.CellProperty
+	conditional map[stypes.TblStyleOverrideType]*ctypes.TableStyleProp
+}
+
+// NewStyleResolver returns a resolver bound to the current styles and numbering of the document.
+func (rd *RootDoc) NewStyleResolver() *StyleResolver {
+	sr := &StyleResolver{
+		rd:       rd,
+		styles:   make(map[stypes.StyleType]map[string]*ctypes.Style),
+		defaults: make(map[stypes.StyleType]*ctypes.Style),
+		chains:   make(map[string]*resolvedStyle),
+	}
+
+	if rd.DocStyles != nil {
+		for i := range rd.DocStyles.StyleList {
+			style := &rd.DocStyles.StyleList[i]
+			if style.ID == nil || style.Type == nil {
+				continue
+			}
+			if sr.styles[*style.Type] == nil {
+				sr.styles[*style.Type] = make(map[string]*ctypes.Style)
+			}
+			sr.styles[*style.Type][*style.ID] = style
+			if style.Default != nil && isOn(*style.Default) {
+				sr.defaults[*style.Type] = style
+			}
+		}
+	}
+
+	if rd.Numbering != nil {
+		// Without readable numbering definitions, numbering levels contribute nothing.
+		sr.numbering, _ = rd.Numbering.definitions()
+	}
+	// Without a readable theme, theme colors and fonts resolve to the explicit values.
+	sr.theme, _ = rd.Theme()
+	return sr
+}
+
+// Paragraph returns the effective paragraph properties of p. cell is nil for paragraphs
+// outside of tables.
+func (sr *StyleResolver) Paragraph(p *ctypes.Paragraph, cell *TableCellRef) *ctypes.ParagraphProp {
+	pPr := &ctypes.ParagraphProp{}
+
+	if defaults := sr.docDefaults(); defaults != nil && defaults.ParaProp != nil {
+		mergeProps(pPr, defaults.ParaProp.ParaProp)
+	}
+
+	if table := sr.tableStyle(cell); table != nil {
+		mergeProps(pPr, table.pPr)
+	}
+
+	if para := sr.paragraphStyle(p); para != nil {
+		mergeProps(pPr, para.pPr)
+	}
+
+	// Numbering may be set directly or through the paragraph style.
+	numPr := pPr.NumProp
+	if p.Property != nil && p.Property.NumProp != nil {
+		numPr = p.Property.NumProp
+	}
+	if lvl := sr.numberingLevel(numPr); lvl != nil {
+		mergeProps(pPr, lvl.PPr)
+	}
+
+	mergeProps(pPr, p.Property)
+
+	return pPr
+}
+
+// Run returns the effective run properties of r, a run of paragraph p.
+func (sr *StyleResolver) Run(p *ctypes.Paragraph, r *ctypes.Run, cell *TableCellRef) *ctypes.RunProperty {
+	var direct *ctypes.RunProperty
+	if r != nil {
+		direct = r.Property
+	}
+	return sr.resolveRun(p, direct, cell, nil)
+}
+
+// NumberingRun returns the effective run properties of the list label of p: the paragraph
+// mark formatting combined with the run properties of the numbering level. It returns nil
+// when the paragraph is not numbered.
+func (sr *StyleResolver) NumberingRun(p *ctypes.Paragraph, cell *TableCellRef) *ctypes.RunProperty {
+	lvl := sr.numberingLevel(sr.Paragraph(p, cell).NumProp)
+	if lvl == nil {
+		return nil
+	}
+
+	var mark *ctypes.RunProperty
+	if p.Property != nil {
+		mark = p.Property.RunProperty
+	}
+	return sr.resolveRun(p, mark, cell, lvl.RPr)
+}
+
+// RunFormat returns the flattened effective formatting of r, a run of paragraph p, with
+// theme fonts and colors resolved against the theme of the document.
+func (sr *StyleResolver) RunFormat(p *ctypes.Paragraph, r *ctypes.Run, cell *TableCellRef) RunFormat {
+	rPr := sr.Run(p, r, cell)
+	f := NewRunFormat(rPr)
+	sr.theme.applyTheme(&f, rPr)
+	return f
+}
+
+func (sr *StyleResolver) resolveRun(p *ctypes.Paragraph, direct *ctypes.RunProperty, cell *TableCellRef, numbering *ctypes.RunProperty) *ctypes.RunProperty {
+	rPr := &ctypes.RunProperty{}
+
+	if defaults := sr.docDefaults(); defaults != nil && defaults.RunProp != nil {
+		mergeProps(rPr, defaults.RunProp.RunProp)
+	}
+
+	if table := sr.tableStyle(cell); table != nil {
+		mergeStyleRun(rPr, table.rPr)
+	}
+
+	if para := sr.paragraphStyle(p); para != nil {
+		mergeStyleRun(rPr, para.rPr)
+	}
+
+	mergeProps(rPr, numbering)
+
+	if char := sr.characterStyle(direct); char != nil {
+		mergeStyleRun(rPr, char.rPr)
+	}
+
+	mergeProps(rPr, direct)
+	rPr.Style = nil
+	if direct != nil {
+		rPr.Style = direct.Style
+	}
+
+	return rPr
+}
+
+func (sr *StyleResolver) docDefaults() *ctypes.DocDefault {
+	if sr.rd.DocStyles == nil {
+		return nil
+	}
+	return sr.rd.DocStyles.DocDefaults
+}
+
+// paragraphStyle resolves the style of the paragraph, or the default paragraph style.
+func (sr *StyleResolver) paragraphStyle(p *ctypes.Paragraph) *resolvedStyle {
+	id := ""
+	if p != nil && p.Property != nil && p.Property.Style != nil {
+		id = p.Property.Style.Val
+	}
+	return sr.styleOrDefault(stypes.StyleTypeParagraph, id)
+}
+
+// characterStyle resolves the character style referenced by the run properties, if any.
+func (sr *StyleResolver) characterStyle(rPr *ctypes.RunProperty) *resolvedStyle {
+	id := ""
+	if rPr != nil && rPr.Style != nil {
+		id = rPr.Style.Val
+	}
+	return sr.styleOrDefault(stypes.StyleTypeCharacter, id)
+}
+
+// tableStyle resolves the table style for the cell, with the conditional formatting that
+// applies to the cell merged in.
+func (sr *StyleResolver) tableStyle(cell *TableCellRef) *resolvedStyle {
+	if cell == nil || cell.Table == nil {
+		return nil
+	}
+
+	style := sr.styleOrDefault(stypes.StyleTypeTable, tableStyleID(cell.Table))
+	if style == nil {
+		return nil
+	}
+
+	merged := &resolvedStyle{pPr: &ctypes.ParagraphProp{}, rPr: &ctypes.RunProperty{}, tcPr: &ctypes.CellProperty{}}
+	mergeProps(merged.pPr, style.pPr)
+	mergeProps(merged.rPr, style.rPr)
+	mergeProps(merged.tcPr, style.tcPr)
+	for _, kind := range cellConditions(cell, style.tblPr) {
+		if cond := style.conditional[kind]; cond != nil {
+			mergeProps(merged.pPr, cond.ParaProp)
+			mergeProps(merged.rPr, cond.RunProp)
+			mergeProps(merged.tcPr, cond.CellProp)
+		}
+	}
+	return merged
+}
+
+func (sr *StyleResolver) styleOrDefault(styleType stypes.StyleType, id string) *resolvedStyle {
+	if id != "" {
+		if _, ok := sr.styles[styleType][id]; ok {
+			return sr.resolve(styleType, id)
+		}
+	}
+	if def := sr.defaults[styleType]; def != nil {
+		return sr.resolve(styleType, *def.ID)
+	}
+	return nil
+}
+
+// resolve merges the style with its basedOn ancestors, the farthest ancestor first.
+func (sr *StyleResolver) resolve(styleType stypes.StyleType, id string) *resolvedStyle {
+	key := string(styleType) + ":" + id
+	if cached, ok := sr.chains[key]; ok {
+		return cached
+	}
+
+	var chain []*ctypes.Style
+	seen := make(map[string]bool)
+	for cur := id; cur != "" && !seen[cur]; {
+		seen[cur] = true
+		style := sr.styles[styleType][cur]
+		if style == nil {
+			break
+		}
+		chain = append(chain, style)
+		cur = ""
+		if style.BasedOn != nil {
+			cur = style.BasedOn.Val
+		}
+	}
+
+	resolved := &resolvedStyle{
+		pPr:         &ctypes.ParagraphProp{},
+		rPr:         &ctypes.RunProperty{},
+		tblPr:       &ctypes.TableProp{},
+		tcPr:        &ctypes.CellProperty{},
+		conditional: make(map[stypes.TblStyleOverrideType]*ctypes.TableStyleProp),
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		style := chain[i]
+		mergeProps(resolved.pPr, style.ParaProp)
+		mergeProps(resolved.rPr, style.RunProp)
+		mergeProps(resolved.tblPr, style.TableProp)
+		mergeProps(resolved.tcPr, style.TableCellProp)
+		for k := range style.TableStylePr {
+			cond := &style.TableStylePr[k]
+			merged := resolved.conditional[cond.Type]
+			if merged == nil {
+				merged = &ctypes.TableStyleProp{
+					Type:     cond.Type,
+					ParaProp: &ctypes.ParagraphProp{},
+					RunProp:  &ctypes.RunProperty{},
+					CellProp: &ctypes.CellProperty{},
+				}
+				resolved.conditional[cond.Type] = merged
+			}
+			mergeProps(merged.ParaProp, cond.ParaProp)
+			mergeProps(merged.RunProp, cond.RunProp)
+			mergeProps(merged.CellProp, cond.CellProp)
+		}
+	}
+	// The style reference itself is not inherited.
+	resolved.pPr.Style = nil
+	resolved.rPr.Style = nil
+
+	sr.chains[key] = resolved
+	return resolved
+}
+
+// cellConditions returns the conditional formatting types that apply to the cell, in
+// increasing order of precedence.
+func cellConditions(cell *TableCellRef, stylePr *ctypes.TableProp) []stypes.TblStyleOverrideType {
+	rows := 0
+	cols := 0
+	for _, rc := range cell.Table.RowContents {
+		if rc.Row == nil {
+			continue
+		}
+		if rows == cell.Row {
+			cols = len(rc.Row.Contents)
+		}
+		rows++
+	}
+
+	look := newTableLook(cell.Table.TableProp.TableLook)
+
+	rowBand, colBand := 1, 1
+	for _, tblPr := range []*ctypes.TableProp{stylePr, &cell.Table.TableProp} {
+		if tblPr == nil {
+			continue
+		}
+		if tblPr.RowCountInRowBand != nil && tblPr.RowCountInRowBand.Val > 0 {
+			rowBand = tblPr.RowCountInRowBand.Val
+		}
+		if tblPr.RowCountInColBand != nil && tblPr.RowCountInColBand.Val > 0 {
+			colBand = tblPr.RowCountInColBand.Val
+		}
+	}
+
+	firstRow := look.firstRow && cell.Row == 0
+	lastRow := look.lastRow && cell.Row == rows-1
+	firstCol := look.firstCol && cell.Col == 0
+	lastCol := look.lastCol && cell.Col == cols-1
+
+	conds := []stypes.TblStyleOverrideType{stypes.TblStyleOverrideWholeTable}
+
+	// Header rows and columns do not take part in the banding.
+	if look.vBand && !firstCol && !lastCol {
+		col := cell.Col
+		if look.firstCol {
+			col--
+		}
+		if (col/colBand)%2 == 0 {
+			conds = append(conds, stypes.TblStyleOverrideBand1Vert)
+		} else {
+			conds = append(conds, stypes.TblStyleOverrideBand2Vert)
+		}
+	}
+	if look.hBand && !firstRow && !lastRow {
+		row := cell.Row
+		if look.firstRow {
+			row--
+		}
+		if (row/rowBand)%2 == 0 {
+			conds = append(conds, stypes.TblStyleOverrideBand1Horz)
+		} else {
+			conds = append(conds, stypes.TblStyleOverrideBand2Horz)
+		}
+	}
+
+	if firstCol {
+		conds = append(conds, stypes.TblStyleOverrideFirstCol)
+	}
+	if lastCol {
+		conds = append(conds, stypes.TblStyleOverrideLastCol)
+	}
+	if firstRow {
+		conds = append(conds, stypes.TblStyleOverrideFirstRow)
+	}
+	if lastRow {
+		conds = append(conds, stypes.TblStyleOverrideLastRow)
+	}
+
+	switch {
+	case firstRow && firstCol:
+		conds = append(conds, stypes.TblStyleOverrideNwCell)
+	case firstRow && lastCol:
+		conds = append(conds, stypes.TblStyleOverrideNeCell)
+	case lastRow && firstCol:
+		conds = append(conds, stypes.TblStyleOverrideSwCell)
+	case lastRow && lastCol:
+		conds = append(conds, stypes.TblStyleOverrideSeCell)
+	}
+
+	return conds
+}
+
+// tableLook holds the conditional formatting switches of a table (w:tblLook).
+type tableLook struct {
+	firstRow, lastRow, firstCol, lastCol bool
+	hBand, vBand                         bool
+}
+
+// newTableLook decodes the tblLook element. The individual attributes take precedence over
+// the legacy hexadecimal bit mask in w:val.
+func newTableLook(look *ctypes.CTString) tableLook {
+	val := defaultTableLook
+	if look != nil {
+		val = look.Val
+	}
+	mask, _ := strconv.ParseUint(val, 16, 16)
+
+	tl := tableLook{
+		firstRow: mask&0x0020 != 0,
+		lastRow:  mask&0x0040 != 0,
+		firstCol: mask&0x0080 != 0,
+		lastCol:  mask&0x0100 != 0,
+		hBand:    mask&0x0200 == 0,
+		vBand:    mask&0x0400 == 0,
+	}
+	if look == nil {
+		return tl
+	}
+
+	attr := func(v *string, dst *bool, invert bool) {
+		if v == nil {
+			return
+		}
+		on := *v == "1" || *v == "true" || *v == "on"
+		*dst = on != invert
+	}
+	attr(look.FirstRow, &tl.firstRow, false)
+	attr(look.LastRow, &tl.lastRow, false)
+	attr(look.FirstColumn, &tl.firstCol, false)
+	attr(look.LastColumn, &tl.lastCol, false)
+	attr(look.NoHBand, &tl.hBand, true)
+	attr(look.NoVBand, &tl.vBand, true)
+	return tl
+}
+
+// NewRunFormat flattens resolved run properties into a RunFormat.
+func NewRunFormat(rPr *ctypes.RunProperty) RunFormat {
+	f := RunFormat{SizePt: defaultFontSize / 2}
+	if rPr == nil {
+		return f
+	}
+
+	if rPr.Fonts != nil {
+		f.Font = rPr.Fonts.Ascii
+		if f.Font == "" {
+			f.Font = rPr.Fonts.HAnsi
+		}
+		f.EastAsiaFont = rPr.Fonts.EastAsia
+		f.ComplexFont = rPr.Fonts.CS
+	}
+	if rPr.Size != nil {
+		f.SizePt = float64(rPr.Size.Value) / 2
+	}
+
+	f.Bold = onOffValue(rPr.Bold)
+	f.Italic = onOffValue(rPr.Italic)
+	f.Strike = onOffValue(rPr.Strike)
+	f.DoubleStrike = onOffValue(rPr.DoubleStrike)
+	f.Caps = onOffValue(rPr.Caps)
+	f.SmallCaps = onOffValue(rPr.SmallCaps)
+	f.Hidden = onOffValue(rPr.Vanish)
+
+	if rPr.Underline != nil && rPr.Underline.Val != stypes.UnderlineNone {
+		f.Underline = string(rPr.Underline.Val)
+	}
+	if rPr.Color != nil {
+		f.Color = rPr.Color.Val
+	}
+	if rPr.Highlight != nil && rPr.Highlight.Val != "none" {
+		f.Highlight = rPr.Highlight.Val
+	}
+	if rPr.VertAlign != nil && rPr.VertAlign.Val != stypes.VerticalAlignRunBaseline {
+		f.VertAlign = string(rPr.VertAlign.Val)
+	}
+
+	return f
+}
+
+// onOffValue reports whether an on/off property is set and on. An element without w:val is on.
+func onOffValue(o *ctypes.OnOff) bool {
+	return o != nil && (o.Val == nil || isOn(*o.Val))
+}
+
+// toggleFields are the run properties with toggle semantics (ECMA-376 §17.7.3).
+var toggleFields = map[string]bool{
+	"Bold": true, "BoldCS": true, "Italic": true, "ItalicCS": true,
+	"Caps": true, "SmallCaps": true, "Strike": true, "Outline": true,
+	"Shadow": true, "Emboss": true, "Imprint": true, "Vanish": true,
+}
+
+// mergeStyleRun applies the run properties of one style level: toggle properties that are
+// on flip the inherited value, every other property overrides it.
+func mergeStyleRun(dst, src *ctypes.RunProperty) {
+	if src == nil {
+		return
+	}
+
+	dv := reflect.ValueOf(dst).Elem()
+	sv := reflect.ValueOf(src).Elem()
+	st := sv.Type()
+
+	overrides := *src
+	ov := reflect.ValueOf(&overrides).Elem()
+	for i := 0; i < st.NumField(); i++ {
+		name := st.Field(i).Name
+		if !toggleFields[name] {
+			continue
+		}
+		ov.Field(i).Set(reflect.Zero(st.Field(i).Type))
+		if onOffValue(sv.Field(i).Interface().(*ctypes.OnOff)) {
+			current := onOffValue(dv.Field(i).Interface().(*ctypes.OnOff))
+			dv.Field(i).Set(reflect.ValueOf(ctypes.OnOffFromBool(!current)))
+		}
+	}
+
+	mergeProps(dst, &overrides)
+}
+
+// mergeProps overrides every property set in src onto dst. Both must be pointers to the same
+// struct type. Fonts, spacing and indentation are merged attribute by attribute, since each
+// level of the hierarchy may set only some of them. Set values are copied, so dst never
+// aliases src at the first level.
+func mergeProps[T any](dst, src *T) {
+	if src == nil {
+		return
+	}
+	mergeValue(reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem())
+}
+
+var attributeMerged = map[reflect.Type]bool{
+	reflect.TypeOf(ctypes.RunFonts{}): true,
+	reflect.TypeOf(ctypes.Spacing{}):  true,
+	reflect.TypeOf(ctypes.Indent{}):   true,
+}
+
+func mergeValue(dv, sv reflect.Value) {
+	for i := 0; i < sv.NumField(); i++ {
+		sf, df := sv.Field(i), dv.Field(i)
+		if !df.CanSet() {
+			continue
+		}
+
+		switch sf.Kind() {
+		case reflect.Ptr:
+			if sf.IsNil() {
+				continue
+			}
+			elem := sf.Elem()
+			if elem.Kind() == reflect.Struct && attributeMerged[elem.Type()] && !df.IsNil() {
+				merged := reflect.New(elem.Type())
+				merged.Elem().Set(df.Elem())
+				mergeValue(merged.Elem(), elem)
+				df.Set(merged)
+				continue
+			}
+			copied := reflect.New(elem.Type())
+			copied.Elem().Set(elem)
+			df.Set(copied)
+		default:
+			if !sf.IsZero() {
+				df.Set(sf)
+			}
+		}
+	}
+}
+
+// numberingLevel returns the effective level referenced by the numbering properties, if any.
+func (sr *StyleResolver) numberingLevel(numPr *ctypes.NumProp) *ctypes.NumLevel {
+	if numPr == nil || numPr.NumID == nil || sr.numbering == nil {
+		return nil
+	}
+	ilvl := 0
+	if numPr.ILvl != nil {
+		ilvl = numPr.ILvl.Val
+	}
+	lvl := resolveNumberingLevel(sr.numbering, func(id string) *ctypes.Style {
+		return sr.styles[stypes.StyleTypeNumbering][id]
+	}, numPr.NumID.Val, ilvl)
+	if lvl == nil {
+		return nil
+	}
+	return lvl.Definition
+}
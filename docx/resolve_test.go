@@ -0,0 +1,145 @@
+package docx_test
+
+import (
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/internal"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStyle(id string, styleType stypes.StyleType, basedOn string, rPr *ctypes.RunProperty) ctypes.Style {
+	style := ctypes.Style{
+		ID:      internal.ToPtr(id),
+		Type:    internal.ToPtr(styleType),
+		Name:    ctypes.NewCTString(id),
+		RunProp: rPr,
+	}
+	if basedOn != "" {
+		style.BasedOn = ctypes.NewCTString(basedOn)
+	}
+	return style
+}
+
+func TestStyleResolver_Run(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	rd.DocStyles.DocDefaults = &ctypes.DocDefault{
+		RunProp: &ctypes.RunPropDefault{RunProp: &ctypes.RunProperty{
+			Fonts: &ctypes.RunFonts{Ascii: "Calibri", EastAsia: "MS Mincho"},
+			Size:  ctypes.NewFontSize(22),
+		}},
+	}
+	rd.DocStyles.StyleList = append(rd.DocStyles.StyleList,
+		testStyle("Base", stypes.StyleTypeParagraph, "", &ctypes.RunProperty{
+			Bold: ctypes.OnOffFromBool(true),
+			Size: ctypes.NewFontSize(28),
+		}),
+		testStyle("Derived", stypes.StyleTypeParagraph, "Base", &ctypes.RunProperty{
+			Italic: &ctypes.OnOff{},
+			Fonts:  &ctypes.RunFonts{EastAsia: "SimSun"},
+		}),
+		testStyle("Emphasis2", stypes.StyleTypeCharacter, "", &ctypes.RunProperty{
+			Bold:  ctypes.OnOffFromBool(true),
+			Color: ctypes.NewColor("FF0000"),
+		}),
+	)
+
+	p := rd.AddParagraph("")
+	p.Style("Derived")
+	plain := p.AddText("plain")
+	styled := p.AddText("styled")
+	styled.GetCT().Property = &ctypes.RunProperty{Style: ctypes.NewCTString("Emphasis2")}
+	direct := p.AddText("direct")
+	direct.GetCT().Property = &ctypes.RunProperty{
+		Italic: ctypes.OnOffFromBool(false),
+		Bold:   ctypes.OnOffFromBool(true),
+	}
+
+	sr := rd.NewStyleResolver()
+
+	f := sr.RunFormat(p.GetCT(), plain.GetCT(), nil)
+	assert.Equal(t, "Calibri", f.Font)
+	assert.Equal(t, "SimSun", f.EastAsiaFont)
+	assert.Equal(t, 14.0, f.SizePt)
+	assert.True(t, f.Bold)
+	assert.True(t, f.Italic)
+
+	// The character style toggles the bold inherited from the paragraph style off.
+	f = sr.RunFormat(p.GetCT(), styled.GetCT(), nil)
+	assert.False(t, f.Bold)
+	assert.Equal(t, "FF0000", f.Color)
+
+	// Direct formatting is absolute.
+	f = sr.RunFormat(p.GetCT(), direct.GetCT(), nil)
+	assert.True(t, f.Bold)
+	assert.False(t, f.Italic)
+
+	// Resolution does not modify the style definitions.
+	assert.Equal(t, "SimSun", rd.GetStyleByID("Derived", stypes.StyleTypeParagraph).RunProp.Fonts.EastAsia)
+	assert.Empty(t, rd.GetStyleByID("Derived", stypes.StyleTypeParagraph).RunProp.Fonts.Ascii)
+}
+
+func TestStyleResolver_TableConditional(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	style := testStyle("Grid2", stypes.StyleTypeTable, "", &ctypes.RunProperty{Size: ctypes.NewFontSize(18)})
+	style.TableStylePr = []ctypes.TableStyleProp{
+		{Type: stypes.TblStyleOverrideFirstRow, RunProp: &ctypes.RunProperty{Bold: &ctypes.OnOff{}}},
+		{Type: stypes.TblStyleOverrideBand1Horz, RunProp: &ctypes.RunProperty{Color: ctypes.NewColor("00FF00")}},
+	}
+	rd.DocStyles.StyleList = append(rd.DocStyles.StyleList, style)
+
+	tbl := rd.AddTable()
+	tbl.Style("Grid2")
+	for r := 0; r < 4; r++ {
+		row := tbl.AddRow()
+		row.AddCell().AddParagraph("a")
+		row.AddCell().AddParagraph("b")
+	}
+
+	sr := rd.NewStyleResolver()
+	format := func(row, col int) docx.RunFormat {
+		cell := tbl.GetCT().RowContents[row].Row.Contents[col].Cell
+		p := cell.Contents[0].Paragraph
+		return sr.RunFormat(p, p.Children[0].Run, &docx.TableCellRef{Table: tbl.GetCT(), Row: row, Col: col})
+	}
+
+	header := format(0, 1)
+	assert.True(t, header.Bold)
+	assert.Equal(t, 9.0, header.SizePt)
+	assert.Empty(t, header.Color)
+
+	assert.False(t, format(1, 1).Bold)
+	assert.Equal(t, "00FF00", format(1, 1).Color)
+	assert.Empty(t, format(2, 1).Color)
+	assert.Equal(t, "00FF00", format(3, 1).Color)
+}
+
+func TestStyleResolver_Numbering(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	list := rd.NewListInstance(2)
+	p := rd.AddParagraph("item")
+	p.Numbering(list, 1)
+
+	sr := rd.NewStyleResolver()
+
+	pPr := sr.Paragraph(p.GetCT(), nil)
+	require.NotNil(t, pPr.Indent)
+	require.NotNil(t, pPr.Indent.Left)
+	assert.Equal(t, 720, *pPr.Indent.Left)
+
+	label := sr.NumberingRun(p.GetCT(), nil)
+	require.NotNil(t, label)
+	assert.Equal(t, "Symbol", label.Fonts.Ascii)
+
+	assert.Nil(t, sr.NumberingRun(rd.AddParagraph("plain").GetCT(), nil))
+}
@@ -10,6 +10,9 @@ import (
 // RootDoc represents the root document of an Office Open XML (OOXML) document.
 // It contains information about the document path, file map, the document structure,
 // and relationships with other parts of the document.
+//
+// The core properties are not decoded when a document is opened: CoreProps reads them
+// from FileMap when asked, so a document with invalid properties still opens.
 type RootDoc struct {
 	Path        string        // Path represents the path of the document.
 	FileMap     sync.Map      // FileMap is a synchronized map for managing files related to the document.
@@ -17,6 +20,7 @@ type RootDoc struct {
 	ContentType ContentTypes
 	Document    *Document         // Document is the main document structure.
 	DocStyles   *ctypes.Styles    // Document styles
+	Settings    *ctypes.Settings  // Document settings, nil when the document has no settings part
 	Numbering   *NumberingManager // Numbering manager for list instances
 	rID         int               // rId is used to generate unique relationship IDs.
 	ImageCount  uint
@@ -14,6 +14,11 @@ func newRun(root *RootDoc, ct *ctypes.Run) *Run {
 	return &Run{root: root, ct: ct}
 }
 
+// GetCT returns a pointer to the underlying Run Complex Type.
+func (r *Run) GetCT() *ctypes.Run {
+	return r.ct
+}
+
 // getProp returns the run properties. If not initialized, it creates and returns a new instance.
 func (r *Run) getProp() *ctypes.RunProperty {
 	if r.ct.Property == nil {
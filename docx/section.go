@@ -0,0 +1,383 @@
+package docx
+
+import (
+	"encoding/xml"
+	"errors"
+	"strconv"
+
+	"github.com/iEvan-lhr/docx-agent/internal"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// Section is a run of body content sharing one page setup. Every section but the last
+// ends with a paragraph carrying the section properties (pPr/sectPr); the last section
+// uses the section properties of the body.
+//
+// A Section is a view of the body when Sections was called; adding or removing body
+// content afterwards does not move its range, but its properties stay live.
+type Section struct {
+	root     *RootDoc
+	prop     *ctypes.SectionProp
+	children []DocumentChild
+	breakPar *Paragraph // Paragraph ending the section, nil for the last section
+}
+
+// PaperSize is a page size in twips, given in portrait orientation.
+type PaperSize struct {
+	Width  uint64
+	Height uint64
+	Code   int // Printer paper code
+}
+
+var (
+	PaperA4     = PaperSize{Width: 11906, Height: 16838, Code: 9} // 210 × 297 mm
+	PaperLetter = PaperSize{Width: 12240, Height: 15840, Code: 1} // 8.5 × 11 inches
+	PaperA3     = PaperSize{Width: 16838, Height: 23811, Code: 8} // 297 × 420 mm
+)
+
+// Margins are the page margins of a section in twips.
+type Margins struct {
+	Top    int
+	Right  int
+	Bottom int
+	Left   int
+	Header int // Distance from the top edge of the page to the header
+	Footer int // Distance from the bottom edge of the page to the footer
+	Gutter int
+}
+
+// MarginsMM returns page margins given in millimeters.
+func MarginsMM(top, right, bottom, left float64) Margins {
+	return Margins{
+		Top:    int(ctypes.MillimetersToTwips(top)),
+		Right:  int(ctypes.MillimetersToTwips(right)),
+		Bottom: int(ctypes.MillimetersToTwips(bottom)),
+		Left:   int(ctypes.MillimetersToTwips(left)),
+	}
+}
+
+// MarginsInches returns page margins given in inches.
+func MarginsInches(top, right, bottom, left float64) Margins {
+	return Margins{
+		Top:    int(ctypes.InchesToTwips(top)),
+		Right:  int(ctypes.InchesToTwips(right)),
+		Bottom: int(ctypes.InchesToTwips(bottom)),
+		Left:   int(ctypes.InchesToTwips(left)),
+	}
+}
+
+// Sections returns the sections of the document in document order.
+//
+// Example:
+//
+//	for _, section := range document.Sections() {
+//		section.SetPageSize(docx.PaperA4, stypes.PageOrientLandscape)
+//	}
+func (rd *RootDoc) Sections() []*Section {
+	if rd.Document == nil || rd.Document.Body == nil {
+		return nil
+	}
+	body := rd.Document.Body
+
+	var (
+		sections []*Section
+		current  []DocumentChild
+	)
+	for _, child := range body.Children {
+		current = append(current, child)
+		if sectPr := childSectPr(child); sectPr != nil {
+			sections = append(sections, &Section{root: rd, prop: sectPr, children: current, breakPar: child.Para})
+			current = nil
+		}
+	}
+
+	if body.SectPr == nil {
+		body.SectPr = ctypes.NewSectionProper()
+	}
+	return append(sections, &Section{root: rd, prop: body.SectPr, children: current})
+}
+
+// childSectPr returns the section properties a body child ends a section with.
+func childSectPr(child DocumentChild) *ctypes.SectionProp {
+	if child.Para == nil || child.Para.ct.Property == nil {
+		return nil
+	}
+	return child.Para.ct.Property.SectPr
+}
+
+// AddSectionBreak ends the current last section of the document and starts a new one,
+// which begins as kind says: on the next page, on the next odd or even page, or
+// continuously on the same page. The new section starts with the page setup of the
+// section before it.
+//
+// The break is attached to the last paragraph of the body, or to a new empty paragraph
+// when the body ends with a table or an earlier section break.
+//
+// Example:
+//
+//	document.AddParagraph("Portrait content")
+//	landscape := document.AddSectionBreak(stypes.SectionMarkNextPage)
+//	landscape.SetOrientation(stypes.PageOrientLandscape)
+func (rd *RootDoc) AddSectionBreak(kind stypes.SectionMark) *Section {
+	children := rd.Document.Body.Children
+	var last *Paragraph
+	if n := len(children); n > 0 && children[n-1].Para != nil && childSectPr(children[n-1]) == nil {
+		last = children[n-1].Para
+	} else {
+		last = rd.AddEmptyParagraph()
+	}
+
+	// The paragraph is a body paragraph without a break, which cannot fail.
+	section, _ := rd.InsertSectionBreak(last, kind)
+	return section
+}
+
+// InsertSectionBreak ends a section at the body paragraph p. The content after p forms a
+// new section, which begins as kind says and keeps the page setup of the section p was
+// in; the content up to p gets a copy of that page setup.
+//
+// Returns:
+//   - *Section: The section starting after p.
+//   - error: An error if p is not a body paragraph or already ends a section.
+func (rd *RootDoc) InsertSectionBreak(p *Paragraph, kind stypes.SectionMark) (*Section, error) {
+	if p.ct.Property != nil && p.ct.Property.SectPr != nil {
+		return nil, errors.New("paragraph already ends a section")
+	}
+
+	for i, section := range rd.Sections() {
+		for _, child := range section.children {
+			if child.Para != p {
+				continue
+			}
+
+			ended, err := cloneSectionProp(section.prop)
+			if err != nil {
+				return nil, err
+			}
+			p.ensureProp()
+			p.ct.Property.SectPr = ended
+			section.prop.Type = &ctypes.GenSingleStrVal[stypes.SectionMark]{Val: kind}
+			return rd.Sections()[i+1], nil
+		}
+	}
+	return nil, errors.New("paragraph is not part of the document body")
+}
+
+// cloneSectionProp returns a deep copy of section properties.
+func cloneSectionProp(sectPr *ctypes.SectionProp) (*ctypes.SectionProp, error) {
+	content, err := xml.Marshal(sectPr)
+	if err != nil {
+		return nil, err
+	}
+	clone := ctypes.NewSectionProper()
+	if err := xml.Unmarshal(content, clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// GetCT returns the underlying section properties.
+func (s *Section) GetCT() *ctypes.SectionProp {
+	return s.prop
+}
+
+// Paragraphs returns the body paragraphs of the section, including the paragraph carrying
+// the section break. Paragraphs inside tables are not included.
+func (s *Section) Paragraphs() []*Paragraph {
+	var paras []*Paragraph
+	for _, child := range s.children {
+		if child.Para != nil {
+			paras = append(paras, child.Para)
+		}
+	}
+	return paras
+}
+
+// Children returns the body content of the section.
+func (s *Section) Children() []DocumentChild {
+	return s.children
+}
+
+// BreakParagraph returns the paragraph ending the section, or nil for the last section.
+func (s *Section) BreakParagraph() *Paragraph {
+	return s.breakPar
+}
+
+// BreakType returns how the section begins. Word starts a section without a type on the
+// next page.
+func (s *Section) BreakType() stypes.SectionMark {
+	if s.prop.Type == nil || s.prop.Type.Val == "" {
+		return stypes.SectionMarkNextPage
+	}
+	return s.prop.Type.Val
+}
+
+// SetBreakType sets how the section begins.
+func (s *Section) SetBreakType(kind stypes.SectionMark) *Section {
+	s.prop.Type = &ctypes.GenSingleStrVal[stypes.SectionMark]{Val: kind}
+	return s
+}
+
+// PageSize returns the page size of the section in twips and its orientation. Sizes the
+// section does not set are zero.
+func (s *Section) PageSize() (width, height uint64, orient stypes.PageOrient) {
+	orient = stypes.PageOrientPortrait
+	if pgSz := s.prop.PageSize; pgSz != nil {
+		if pgSz.Width != nil {
+			width = *pgSz.Width
+		}
+		if pgSz.Height != nil {
+			height = *pgSz.Height
+		}
+		if pgSz.Orient != "" {
+			orient = pgSz.Orient
+		}
+	}
+	return width, height, orient
+}
+
+// SetPageSize sets the paper size and orientation of the section. Landscape pages swap the
+// width and height of the paper.
+func (s *Section) SetPageSize(paper PaperSize, orient stypes.PageOrient) *Section {
+	width, height := paper.Width, paper.Height
+	if orient == stypes.PageOrientLandscape {
+		width, height = height, width
+	}
+	s.prop.PageSize = &ctypes.PageSize{
+		Width:  internal.ToPtr(width),
+		Height: internal.ToPtr(height),
+		Orient: orient,
+		Code:   internal.ToPtr(paper.Code),
+	}
+	return s
+}
+
+// SetOrientation turns the pages of the section, swapping their width and height when the
+// orientation changes.
+func (s *Section) SetOrientation(orient stypes.PageOrient) *Section {
+	width, height, current := s.PageSize()
+	if s.prop.PageSize == nil {
+		s.prop.PageSize = &ctypes.PageSize{}
+	}
+	if current != orient && width != 0 && height != 0 {
+		s.prop.PageSize.Width = internal.ToPtr(height)
+		s.prop.PageSize.Height = internal.ToPtr(width)
+	}
+	s.prop.PageSize.Orient = orient
+	return s
+}
+
+// Margins returns the page margins of the section. Margins the section does not set are
+// zero.
+func (s *Section) Margins() Margins {
+	var m Margins
+	pgMar := s.prop.PageMargin
+	if pgMar == nil {
+		return m
+	}
+
+	for _, field := range []struct {
+		src *int
+		dst *int
+	}{
+		{pgMar.Top, &m.Top}, {pgMar.Right, &m.Right}, {pgMar.Bottom, &m.Bottom}, {pgMar.Left, &m.Left},
+		{pgMar.Header, &m.Header}, {pgMar.Footer, &m.Footer}, {pgMar.Gutter, &m.Gutter},
+	} {
+		if field.src != nil {
+			*field.dst = *field.src
+		}
+	}
+	return m
+}
+
+// SetMargins sets the page margins of the section. A zero Header or Footer keeps the
+// current header or footer distance.
+//
+// Example:
+//
+//	section.SetMargins(docx.MarginsMM(25, 20, 25, 30))
+func (s *Section) SetMargins(m Margins) *Section {
+	current := s.Margins()
+	if m.Header == 0 {
+		m.Header = current.Header
+	}
+	if m.Footer == 0 {
+		m.Footer = current.Footer
+	}
+
+	s.prop.PageMargin = &ctypes.PageMargin{
+		Top:    internal.ToPtr(m.Top),
+		Right:  internal.ToPtr(m.Right),
+		Bottom: internal.ToPtr(m.Bottom),
+		Left:   internal.ToPtr(m.Left),
+		Header: internal.ToPtr(m.Header),
+		Footer: internal.ToPtr(m.Footer),
+		Gutter: internal.ToPtr(m.Gutter),
+	}
+	return s
+}
+
+// Columns returns the number of text columns of the section.
+func (s *Section) Columns() int {
+	cols := s.prop.Cols
+	switch {
+	case cols == nil:
+		return 1
+	case cols.EqualWidth != nil && !*cols.EqualWidth && len(cols.Cols) > 0:
+		return len(cols.Cols)
+	case cols.Num != nil && *cols.Num > 0:
+		return *cols.Num
+	}
+	return 1
+}
+
+// SetColumns lays out the section in count columns of equal width, spacing twips apart.
+// separator draws a line between the columns.
+func (s *Section) SetColumns(count, spacing int, separator bool) *Section {
+	s.prop.Cols = &ctypes.Cols{
+		Num:   internal.ToPtr(count),
+		Space: internal.ToPtr(strconv.Itoa(spacing)),
+	}
+	if separator {
+		s.prop.Cols.Sep = internal.ToPtr(true)
+	}
+	return s
+}
+
+// SetColumnWidths lays out the section in columns of different widths. The space of a
+// column is the gap before the next column, in twips.
+func (s *Section) SetColumnWidths(cols ...ctypes.Col) *Section {
+	s.prop.Cols = &ctypes.Cols{
+		Num:        internal.ToPtr(len(cols)),
+		EqualWidth: internal.ToPtr(false),
+		Cols:       cols,
+	}
+	return s
+}
+
+// LineNumbering returns the line numbering of the section, or nil when its lines are not
+// numbered.
+func (s *Section) LineNumbering() *ctypes.LineNumbering {
+	return s.prop.LnNumType
+}
+
+// SetLineNumbering numbers the lines of the section, showing every countBy-th number at
+// distance twips from the text. A zero distance lets Word choose.
+func (s *Section) SetLineNumbering(countBy, distance int, restart stypes.LineNumberRestart) *Section {
+	lnNum := &ctypes.LineNumbering{
+		CountBy: internal.ToPtr(countBy),
+		Restart: restart,
+	}
+	if distance > 0 {
+		lnNum.Distance = internal.ToPtr(distance)
+	}
+	s.prop.LnNumType = lnNum
+	return s
+}
+
+// ClearLineNumbering removes the line numbering of the section.
+func (s *Section) ClearLineNumbering() *Section {
+	s.prop.LnNumType = nil
+	return s
+}
@@ -0,0 +1,71 @@
+package docx_test
+
+import (
+	"bytes"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSections(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	rd.AddParagraph("Cover")
+	rd.Sections()[0].SetPageSize(docx.PaperA4, stypes.PageOrientPortrait).
+		SetMargins(docx.MarginsMM(25, 20, 25, 30))
+
+	wide := rd.AddSectionBreak(stypes.SectionMarkNextPage)
+	rd.AddParagraph("Wide table")
+	wide.SetOrientation(stypes.PageOrientLandscape).SetLineNumbering(5, 0, stypes.LineNumberRestartNewSection)
+
+	intro := rd.AddParagraph("Intro")
+	rd.AddParagraph("Body")
+	columns, err := rd.InsertSectionBreak(intro, stypes.SectionMarkNextContinuous)
+	require.NoError(t, err)
+	columns.SetColumns(2, 425, true)
+	_, err = rd.InsertSectionBreak(intro, stypes.SectionMarkOddPage)
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	content := buf.Bytes()
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+
+	sections := reopened.Sections()
+	require.Len(t, sections, 3)
+
+	assert.Equal(t, "Cover", sections[0].BreakParagraph().Text())
+	width, height, orient := sections[0].PageSize()
+	assert.Equal(t, []uint64{11906, 16838}, []uint64{width, height})
+	assert.Equal(t, stypes.PageOrientPortrait, orient)
+	assert.Equal(t, 1701, sections[0].Margins().Left)
+
+	require.Len(t, sections[1].Paragraphs(), 2)
+	assert.Equal(t, "Intro", sections[1].BreakParagraph().Text())
+	assert.Equal(t, stypes.SectionMarkNextPage, sections[1].BreakType())
+	width, height, orient = sections[1].PageSize()
+	assert.Equal(t, []uint64{16838, 11906}, []uint64{width, height})
+	assert.Equal(t, stypes.PageOrientLandscape, orient)
+	assert.Equal(t, 1701, sections[1].Margins().Left)
+	require.NotNil(t, sections[1].LineNumbering())
+	assert.Equal(t, 5, *sections[1].LineNumbering().CountBy)
+
+	assert.Nil(t, sections[2].BreakParagraph())
+	assert.Equal(t, "Body", sections[2].Paragraphs()[0].Text())
+	assert.Equal(t, stypes.SectionMarkNextContinuous, sections[2].BreakType())
+	assert.Equal(t, 2, sections[2].Columns())
+	assert.True(t, *sections[2].GetCT().Cols.Sep)
+
+	sections[2].SetColumnWidths(ctypes.Col{Width: 3000, Space: 720}, ctypes.Col{Width: 6000})
+	assert.Equal(t, 2, sections[2].Columns())
+	sections[1].ClearLineNumbering()
+	assert.Nil(t, sections[1].LineNumbering())
+}
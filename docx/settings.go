@@ -0,0 +1,174 @@
+package docx
+
+import (
+	"encoding/xml"
+	"path"
+	"strconv"
+
+	"github.com/iEvan-lhr/docx-agent/common/constants"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+)
+
+const (
+	settingsContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"
+
+	// compatModeURI is the namespace of the compatibility mode setting.
+	compatModeURI = "http://schemas.microsoft.com/office/word"
+)
+
+// Compatibility modes, the Word version whose layout the document follows.
+const (
+	CompatModeWord2003 = 11
+	CompatModeWord2007 = 12
+	CompatModeWord2010 = 14
+	CompatModeWord2013 = 15
+)
+
+// LoadSettings decodes settings.xml into a Settings struct.
+func LoadSettings(fileName string, fileBytes []byte) (*ctypes.Settings, error) {
+	settings := ctypes.Settings{}
+	if err := xml.Unmarshal(fileBytes, &settings); err != nil {
+		return nil, err
+	}
+
+	settings.RelativePath = fileName
+	return &settings, nil
+}
+
+// settings returns the settings of the document, adding a settings part when the
+// document has none.
+func (rd *RootDoc) settings() *ctypes.Settings {
+	if rd.Settings != nil {
+		return rd.Settings
+	}
+
+	settingsPath := path.Join(path.Dir(rd.Document.relativePath), "settings.xml")
+	_ = rd.ContentType.AddOverride("/"+settingsPath, settingsContentType)
+	rd.Document.addRelation(constants.SettingsType, path.Base(settingsPath))
+	rd.Settings = &ctypes.Settings{RelativePath: settingsPath}
+	return rd.Settings
+}
+
+// onOffSetting returns the value of an on/off setting element. Word writes the element
+// without a value to turn a setting on and omits it to turn it off.
+func onOffSetting(on bool) *ctypes.OnOff {
+	if on {
+		return &ctypes.OnOff{}
+	}
+	return nil
+}
+
+// EvenAndOddHeaders reports whether even and odd pages have different headers and footers.
+func (rd *RootDoc) EvenAndOddHeaders() bool {
+	return rd.Settings != nil && onOffValue(rd.Settings.EvenAndOddHeaders)
+}
+
+// SetEvenAndOddHeaders turns different headers and footers for even and odd pages on or off.
+// The even page ones are the headers and footers of type even of each section.
+func (rd *RootDoc) SetEvenAndOddHeaders(on bool) {
+	rd.settings().EvenAndOddHeaders = onOffSetting(on)
+}
+
+// TrackRevisions reports whether Word records changes to the document as revisions.
+func (rd *RootDoc) TrackRevisions() bool {
+	return rd.Settings != nil && onOffValue(rd.Settings.TrackRevisions)
+}
+
+// SetTrackRevisions turns the tracking of changes on or off.
+func (rd *RootDoc) SetTrackRevisions(on bool) {
+	rd.settings().TrackRevisions = onOffSetting(on)
+}
+
+// UpdateFieldsOnOpen reports whether Word recalculates the fields, such as a table of
+// contents, when it opens the document.
+func (rd *RootDoc) UpdateFieldsOnOpen() bool {
+	return rd.Settings != nil && onOffValue(rd.Settings.UpdateFields)
+}
+
+// SetUpdateFieldsOnOpen makes Word recalculate the fields of the document when it opens it,
+// which brings a generated table of contents or page references up to date.
+func (rd *RootDoc) SetUpdateFieldsOnOpen(on bool) {
+	rd.settings().UpdateFields = onOffSetting(on)
+}
+
+// MirrorMargins reports whether the inside and outside margins of facing pages mirror each
+// other.
+func (rd *RootDoc) MirrorMargins() bool {
+	return rd.Settings != nil && onOffValue(rd.Settings.MirrorMargins)
+}
+
+// SetMirrorMargins turns mirrored margins for facing pages on or off.
+func (rd *RootDoc) SetMirrorMargins(on bool) {
+	rd.settings().MirrorMargins = onOffSetting(on)
+}
+
+// DefaultTabStop returns the distance between automatic tab stops in twips, or 0 when the
+// document does not set it.
+func (rd *RootDoc) DefaultTabStop() int {
+	if rd.Settings == nil || rd.Settings.DefaultTabStop == nil {
+		return 0
+	}
+	return rd.Settings.DefaultTabStop.Val
+}
+
+// SetDefaultTabStop sets the distance between automatic tab stops in twips (1440 to the inch).
+func (rd *RootDoc) SetDefaultTabStop(twips int) {
+	rd.settings().DefaultTabStop = ctypes.NewDecimalNum(twips)
+}
+
+// AutoHyphenation reports whether Word hyphenates the document automatically.
+func (rd *RootDoc) AutoHyphenation() bool {
+	return rd.Settings != nil && onOffValue(rd.Settings.AutoHyphenation)
+}
+
+// SetAutoHyphenation turns automatic hyphenation on or off. The hyphenation zone, in twips,
+// and the limit of consecutive hyphenated lines are set when positive.
+//
+// Example:
+//
+//	document.SetAutoHyphenation(true, 360, 2)
+func (rd *RootDoc) SetAutoHyphenation(on bool, zone, consecutiveLimit int) {
+	s := rd.settings()
+	s.AutoHyphenation = onOffSetting(on)
+	if zone > 0 {
+		s.HyphenationZone = ctypes.NewDecimalNum(zone)
+	}
+	if consecutiveLimit > 0 {
+		s.ConsecutiveHyphenLimit = ctypes.NewDecimalNum(consecutiveLimit)
+	}
+}
+
+// CompatibilityMode returns the Word version whose layout the document follows, such as
+// CompatModeWord2013, or 0 when the document does not set it.
+func (rd *RootDoc) CompatibilityMode() int {
+	if rd.Settings == nil || rd.Settings.Compat == nil {
+		return 0
+	}
+	for _, setting := range rd.Settings.Compat.Settings {
+		if setting.Name == "compatibilityMode" {
+			mode, _ := strconv.Atoi(setting.Val)
+			return mode
+		}
+	}
+	return 0
+}
+
+// SetCompatibilityMode sets the Word version whose layout the document follows, such as
+// CompatModeWord2013. A document in an older mode opens in Word in compatibility mode.
+func (rd *RootDoc) SetCompatibilityMode(mode int) {
+	s := rd.settings()
+	if s.Compat == nil {
+		s.Compat = &ctypes.Compat{}
+	}
+	for i, setting := range s.Compat.Settings {
+		if setting.Name == "compatibilityMode" {
+			s.Compat.Settings[i].Val = strconv.Itoa(mode)
+			return
+		}
+	}
+	s.Compat.Settings = append(s.Compat.Settings, ctypes.CompatSetting{
+		Name: "compatibilityMode",
+		URI:  compatModeURI,
+		Val:  strconv.Itoa(mode),
+	})
+}
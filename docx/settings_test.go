@@ -0,0 +1,66 @@
+package docx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettings(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+	require.NotNil(t, rd.Settings)
+
+	assert.Equal(t, 720, rd.DefaultTabStop())
+	assert.Equal(t, docx.CompatModeWord2010, rd.CompatibilityMode())
+	assert.False(t, rd.EvenAndOddHeaders())
+
+	rd.SetEvenAndOddHeaders(true)
+	rd.SetTrackRevisions(true)
+	rd.SetUpdateFieldsOnOpen(true)
+	rd.SetMirrorMargins(true)
+	rd.SetDefaultTabStop(567)
+	rd.SetAutoHyphenation(true, 360, 2)
+	rd.SetCompatibilityMode(docx.CompatModeWord2013)
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	settingsXML := string(zipParts(t, buf.Bytes())["word/settings.xml"])
+	assert.Contains(t, settingsXML, `<w:mirrorMargins></w:mirrorMargins>`)
+	assert.Contains(t, settingsXML, `<w:rsidRoot w:val="00B47730"/>`)
+	assert.Contains(t, settingsXML, `<w14:docId w14:val="24062061"></w14:docId>`)
+	assert.Less(t, strings.Index(settingsXML, "<w:zoom"), strings.Index(settingsXML, "<w:mirrorMargins"))
+
+	content := buf.Bytes()
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+	assert.True(t, reopened.EvenAndOddHeaders())
+	assert.True(t, reopened.TrackRevisions())
+	assert.True(t, reopened.UpdateFieldsOnOpen())
+	assert.True(t, reopened.MirrorMargins())
+	assert.True(t, reopened.AutoHyphenation())
+	assert.Equal(t, 567, reopened.DefaultTabStop())
+	assert.Equal(t, docx.CompatModeWord2013, reopened.CompatibilityMode())
+
+	reopened.SetTrackRevisions(false)
+	assert.False(t, reopened.TrackRevisions())
+	assert.Nil(t, reopened.Settings.TrackRevisions)
+
+	// A settings relationship to a missing part leaves the document without settings.
+	content = rewriteZip(t, content, func(name string, content []byte) (string, []byte) {
+		if name == "word/settings.xml" {
+			return "", nil
+		}
+		return name, content
+	})
+	missing, err := packager.Unpack(&content)
+	require.NoError(t, err)
+	assert.Nil(t, missing.Settings)
+	assert.False(t, missing.TrackRevisions())
+}
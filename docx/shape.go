@@ -0,0 +1,470 @@
+package docx
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/common/units"
+	"github.com/iEvan-lhr/docx-agent/dml"
+	"github.com/iEvan-lhr/docx-agent/dml/dmlct"
+	"github.com/iEvan-lhr/docx-agent/dml/dmlpic"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+)
+
+// ShapePreset is the preset geometry of a shape.
+type ShapePreset string
+
+const (
+	ShapeRect       ShapePreset = "rect"
+	ShapeRoundRect  ShapePreset = "roundRect"
+	ShapeEllipse    ShapePreset = "ellipse"
+	ShapeRightArrow ShapePreset = "rightArrow"
+	ShapeLeftArrow  ShapePreset = "leftArrow"
+	ShapeUpArrow    ShapePreset = "upArrow"
+	ShapeDownArrow  ShapePreset = "downArrow"
+	ShapeLine       ShapePreset = "line"
+)
+
+// emusPerPoint is the number of EMUs in a point, the unit of outline widths.
+const emusPerPoint = 12700
+
+// ShapeOptions sets the look of a shape or text box.
+type ShapeOptions struct {
+	// Fill is the fill color as a hex code such as "4472C4"; empty leaves the shape
+	// transparent.
+	Fill string
+
+	// Outline is the outline color as a hex code and defaults to black. OutlineWidth is in
+	// points and defaults to 0.75. NoOutline removes the outline.
+	Outline      string
+	OutlineWidth float64
+	NoOutline    bool
+
+	// Name, AltText and Title describe the shape for accessibility tools.
+	Name    string
+	AltText string
+	Title   string
+}
+
+// Shape refers to a DrawingML shape of a paragraph, such as a text box. Shapes are placed
+// like pictures, so the embedded PicMeta floats them, orders them and turns them inline.
+type Shape struct {
+	PicMeta
+
+	// Wsp is the shape.
+	Wsp *dml.WPSWordprocessingShape
+}
+
+// AddShape adds a preset shape of the given size to the paragraph. opts may be nil for a
+// transparent shape with a black outline.
+func (p *Paragraph) AddShape(preset ShapePreset, width, height units.Inch, opts *ShapeOptions) (*Shape, error) {
+	switch preset {
+	case ShapeRect, ShapeRoundRect, ShapeEllipse, ShapeRightArrow, ShapeLeftArrow, ShapeUpArrow, ShapeDownArrow, ShapeLine:
+	default:
+		return nil, fmt.Errorf("unsupported shape preset %q", preset)
+	}
+	if width < 0 || height < 0 || (width == 0 && height == 0) {
+		return nil, errors.New("shape size must not be negative or empty")
+	}
+	if preset != ShapeLine && (width == 0 || height == 0) {
+		return nil, errors.New("only lines may have a zero width or height")
+	}
+	if opts == nil {
+		opts = &ShapeOptions{}
+	}
+
+	spPr, err := shapeProperties(preset, width, height, opts)
+	if err != nil {
+		return nil, err
+	}
+	wsp := &dml.WPSWordprocessingShape{
+		CNvSpPr: &dml.WPSNonVisualShapeDrawingProps{},
+		SpPr:    spPr,
+		BodyPr:  &dml.ABodyProperties{Anchor: "ctr"},
+	}
+	return p.addShapeDrawing(wsp, width, height, opts, "Shape"), nil
+}
+
+// AddTextBox adds a text box of the given size to the paragraph. opts may be nil for a
+// white text box with a black outline. Add content with Shape.AddParagraph and
+// Shape.AddTable.
+func (p *Paragraph) AddTextBox(width, height units.Inch, opts *ShapeOptions) (*Shape, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("text box size must be positive")
+	}
+	if opts == nil {
+		opts = &ShapeOptions{Fill: "FFFFFF"}
+	}
+
+	spPr, err := shapeProperties(ShapeRect, width, height, opts)
+	if err != nil {
+		return nil, err
+	}
+	wsp := &dml.WPSWordprocessingShape{
+		CNvSpPr: &dml.WPSNonVisualShapeDrawingProps{TxBox: "1"},
+		SpPr:    spPr,
+		TextBox: &dml.WPSTextBox{Content: &ctypes.TxbxContent{}},
+		BodyPr: &dml.ABodyProperties{
+			Rot:       "0",
+			Vert:      "horz",
+			Wrap:      "square",
+			LIns:      "91440",
+			TIns:      "45720",
+			RIns:      "91440",
+			BIns:      "45720",
+			Anchor:    "t",
+			AnchorCtr: "0",
+		},
+	}
+	return p.addShapeDrawing(wsp, width, height, opts, "Text Box"), nil
+}
+
+// AddShape adds a preset shape to the document in a new paragraph. See
+// Paragraph.AddShape.
+func (rd *RootDoc) AddShape(preset ShapePreset, width, height units.Inch, opts *ShapeOptions) (*Shape, error) {
+	p := newParagraph(rd)
+
+	shape, err := p.AddShape(preset, width, height, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rd.Document.Body.Children = append(rd.Document.Body.Children, DocumentChild{Para: p})
+	return shape, nil
+}
+
+// AddTextBox adds a text box to the document in a new paragraph. See
+// Paragraph.AddTextBox.
+func (rd *RootDoc) AddTextBox(width, height units.Inch, opts *ShapeOptions) (*Shape, error) {
+	p := newParagraph(rd)
+
+	shape, err := p.AddTextBox(width, height, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rd.Document.Body.Children = append(rd.Document.Body.Children, DocumentChild{Para: p})
+	return shape, nil
+}
+
+// Shapes returns the shapes of the paragraph in document order, including the members of
+// grouped shapes and the preferred branch of mc:AlternateContent. Members of a group share
+// the placement of the group.
+func (p *Paragraph) Shapes() []*Shape {
+	var shapes []*Shape
+	add := func(d *dml.Drawing) {
+		for i := range d.Inline {
+			for _, wsp := range graphicShapes(&d.Inline[i].Graphic) {
+				shapes = append(shapes, &Shape{PicMeta: PicMeta{Para: p, Inline: &d.Inline[i], drawing: d}, Wsp: wsp})
+			}
+		}
+		for _, a := range d.Anchor {
+			if a == nil {
+				continue
+			}
+			for _, wsp := range graphicShapes(&a.Graphic) {
+				shapes = append(shapes, &Shape{PicMeta: PicMeta{Para: p, Anchor: a, drawing: d}, Wsp: wsp})
+			}
+		}
+	}
+	addAlternate := func(ac *ctypes.AlternateContent) {
+		if ac.Choice != nil && ac.Choice.Drawing != nil {
+			add(ac.Choice.Drawing)
+		}
+	}
+
+	for _, run := range paragraphRuns(&p.ct) {
+		if run.AlternateContent != nil {
+			addAlternate(run.AlternateContent)
+		}
+		for _, rc := range run.Children {
+			switch {
+			case rc.Drawing != nil:
+				add(rc.Drawing)
+			case rc.AlternateContent != nil:
+				addAlternate(rc.AlternateContent)
+			}
+		}
+	}
+	return shapes
+}
+
+// TextBoxes returns the text boxes of the document body, including those in tables, in
+// document order.
+func (rd *RootDoc) TextBoxes() []*Shape {
+	var boxes []*Shape
+	collect := func(p *Paragraph) {
+		for _, s := range p.Shapes() {
+			if s.IsTextBox() {
+				boxes = append(boxes, s)
+			}
+		}
+	}
+
+	if rd.Document == nil || rd.Document.Body == nil {
+		return nil
+	}
+	for _, child := range rd.Document.Body.Children {
+		switch {
+		case child.Para != nil:
+			collect(child.Para)
+		case child.Table != nil:
+			wrapTableParagraphs(rd, &child.Table.ct, hasGraphics, collect)
+		}
+	}
+	return boxes
+}
+
+// IsTextBox reports whether the shape holds text.
+func (s *Shape) IsTextBox() bool {
+	return s.Wsp.TextBox != nil
+}
+
+// Preset returns the preset geometry of the shape, or "" for custom geometry.
+func (s *Shape) Preset() ShapePreset {
+	if s.Wsp.SpPr == nil || s.Wsp.SpPr.PrstGeom == nil {
+		return ""
+	}
+	return ShapePreset(s.Wsp.SpPr.PrstGeom.Preset)
+}
+
+// AddParagraph appends a paragraph with the given text to the text box, turning the shape
+// into one if it holds no text yet.
+func (s *Shape) AddParagraph(text string) *Paragraph {
+	content := s.content()
+	p := newParagraph(s.Para.root, paraWithText(text))
+	content.Contents = append(content.Contents, ctypes.TCBlockContent{Paragraph: &p.ct})
+	return p
+}
+
+// AddTable appends an empty table to the text box, turning the shape into one if it holds
+// no text yet.
+func (s *Shape) AddTable() *Table {
+	content := s.content()
+	tbl := NewTable(s.Para.root)
+	tbl.ct = *ctypes.DefaultTable()
+	content.Contents = append(content.Contents, ctypes.TCBlockContent{Table: &tbl.ct})
+	return tbl
+}
+
+// Paragraphs returns the top-level paragraphs of the text box. Changes to them are made
+// in the text box.
+func (s *Shape) Paragraphs() []*Paragraph {
+	content, ok := s.textBoxContent()
+	if !ok {
+		return nil
+	}
+
+	var paras []*Paragraph
+	for i := range content.Contents {
+		block := &content.Contents[i]
+		if block.Paragraph == nil {
+			continue
+		}
+		// Move the paragraph into a wrapper and refer to the wrapped copy from the text box.
+		p := &Paragraph{root: s.Para.root, ct: *block.Paragraph}
+		block.Paragraph = &p.ct
+		paras = append(paras, p)
+	}
+	return paras
+}
+
+// Text returns the plain text of the text box, paragraphs separated by newlines. Tables
+// are rendered as by cell text, cells separated by tabs and rows by newlines.
+func (s *Shape) Text() string {
+	content, ok := s.textBoxContent()
+	if !ok {
+		return ""
+	}
+
+	parts := make([]string, 0, len(content.Contents))
+	for _, block := range content.Contents {
+		switch {
+		case block.Paragraph != nil:
+			parts = append(parts, paragraphText(block.Paragraph))
+		case block.Table != nil:
+			parts = append(parts, tableText(block.Table))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// SetFill sets the fill color as a hex code; empty makes the shape transparent.
+func (s *Shape) SetFill(color string) error {
+	spPr := s.shapeProperties()
+	if color == "" {
+		spPr.NoFill, spPr.SolidFill, spPr.GradFill = &dmlpic.NoFill{}, nil, nil
+		return nil
+	}
+	fill, err := solidFill(color)
+	if err != nil {
+		return err
+	}
+	spPr.NoFill, spPr.SolidFill, spPr.GradFill = nil, fill, nil
+	return nil
+}
+
+// SetOutline sets the outline color as a hex code and its width in points; an empty
+// color removes the outline.
+func (s *Shape) SetOutline(color string, width float64) error {
+	ln, err := lineProperties(&ShapeOptions{Outline: color, OutlineWidth: width, NoOutline: color == ""})
+	if err != nil {
+		return err
+	}
+	s.shapeProperties().Ln = ln
+	return nil
+}
+
+// content returns the text box content of the shape, adding an empty one if needed.
+func (s *Shape) content() *ctypes.TxbxContent {
+	if content, ok := s.textBoxContent(); ok {
+		return content
+	}
+
+	content := &ctypes.TxbxContent{}
+	s.Wsp.TextBox = &dml.WPSTextBox{Content: content}
+	if s.Wsp.CNvSpPr == nil {
+		s.Wsp.CNvSpPr = &dml.WPSNonVisualShapeDrawingProps{}
+	}
+	if s.Wsp.BodyPr == nil {
+		s.Wsp.BodyPr = &dml.ABodyProperties{}
+	}
+	return content
+}
+
+// textBoxContent returns the text box content of the shape, if it has any.
+func (s *Shape) textBoxContent() (*ctypes.TxbxContent, bool) {
+	if s.Wsp.TextBox == nil {
+		return nil, false
+	}
+	if s.Wsp.TextBox.Content == nil {
+		content := &ctypes.TxbxContent{}
+		s.Wsp.TextBox.Content = content
+		return content, true
+	}
+	content, ok := s.Wsp.TextBox.Content.(*ctypes.TxbxContent)
+	return content, ok
+}
+
+// shapeProperties returns the shape properties, adding empty ones if needed.
+func (s *Shape) shapeProperties() *dml.WPSShapeProperties {
+	if s.Wsp.SpPr == nil {
+		s.Wsp.SpPr = &dml.WPSShapeProperties{}
+	}
+	return s.Wsp.SpPr
+}
+
+// addShapeDrawing appends a run with an inline drawing of the shape to the paragraph.
+func (p *Paragraph) addShapeDrawing(wsp *dml.WPSWordprocessingShape, width, height units.Inch, opts *ShapeOptions, kind string) *Shape {
+	pm := p.addInlineGraphic(*dml.NewShapeGraphic(wsp), width, height, opts.Name, kind, opts.AltText, opts.Title)
+	return &Shape{PicMeta: *pm, Wsp: wsp}
+}
+
+// addInlineGraphic appends a run with an inline drawing of a graphic that is not a
+// picture to the paragraph. An empty name is replaced by kind and the drawing ID.
+func (p *Paragraph) addInlineGraphic(graphic dml.Graphic, width, height units.Inch, name, kind, altText, title string) *PicMeta {
+	p.root.ImageCount += 1
+	id := p.root.ImageCount
+	if name == "" {
+		name = fmt.Sprintf("%s %d", kind, id)
+	}
+
+	inline := dml.NewInline(
+		*dmlct.NewPostvSz2D(width.ToEmu(), height.ToEmu()),
+		dml.DocProp{
+			ID:          uint64(id),
+			Name:        name,
+			Description: altText,
+			Title:       title,
+		},
+		graphic,
+	)
+	inline.CNvGraphicFramePr = &dml.NonVisualGraphicFrameProp{}
+
+	drawing := &dml.Drawing{Inline: []dml.Inline{inline}}
+	run := &ctypes.Run{Children: []ctypes.RunChild{{Drawing: drawing}}}
+	p.ct.Children = append(p.ct.Children, ctypes.ParagraphChild{Run: run})
+
+	return &PicMeta{Para: p, Inline: &drawing.Inline[0], drawing: drawing}
+}
+
+// shapeProperties returns the geometry, fill and outline of a new shape.
+func shapeProperties(preset ShapePreset, width, height units.Inch, opts *ShapeOptions) (*dml.WPSShapeProperties, error) {
+	spPr := &dml.WPSShapeProperties{
+		Xfrm: &dmlpic.TransformGroup{
+			Offset: &dmlpic.Offset{},
+			Extent: dmlct.NewPostvSz2D(width.ToEmu(), height.ToEmu()),
+		},
+		PrstGeom: dmlpic.NewPresetGeom(string(preset)),
+	}
+
+	if opts.Fill == "" || preset == ShapeLine {
+		spPr.NoFill = &dmlpic.NoFill{}
+	} else {
+		fill, err := solidFill(opts.Fill)
+		if err != nil {
+			return nil, err
+		}
+		spPr.SolidFill = fill
+	}
+
+	ln, err := lineProperties(opts)
+	if err != nil {
+		return nil, err
+	}
+	spPr.Ln = ln
+	return spPr, nil
+}
+
+// lineProperties returns the outline described by opts.
+func lineProperties(opts *ShapeOptions) (*dml.LineProperties, error) {
+	if opts.NoOutline {
+		return &dml.LineProperties{NoFill: &dmlpic.NoFill{}}, nil
+	}
+	if opts.OutlineWidth < 0 {
+		return nil, errors.New("outline width must not be negative")
+	}
+
+	color := opts.Outline
+	if color == "" {
+		color = "000000"
+	}
+	fill, err := solidFill(color)
+	if err != nil {
+		return nil, err
+	}
+	width := opts.OutlineWidth
+	if width == 0 {
+		width = 0.75
+	}
+	return &dml.LineProperties{
+		W:         strconv.Itoa(int(width*emusPerPoint + 0.5)),
+		SolidFill: fill,
+	}, nil
+}
+
+// solidFill returns a solid fill of a hex color such as "4472C4" or "#4472C4".
+func solidFill(color string) (*dml.SolidFill, error) {
+	color = strings.TrimPrefix(color, "#")
+	if b, err := hex.DecodeString(color); err != nil || len(b) != 3 {
+		return nil, fmt.Errorf("invalid color %q", color)
+	}
+	return &dml.SolidFill{SrgbClr: &dml.ASrgbColor{Val: strings.ToUpper(color)}}, nil
+}
+
+// graphicShapes returns the shapes of a graphic, directly or inside a group.
+func graphicShapes(g *dml.Graphic) []*dml.WPSWordprocessingShape {
+	if g.Data == nil {
+		return nil
+	}
+	switch {
+	case g.Data.Wsp != nil:
+		return []*dml.WPSWordprocessingShape{g.Data.Wsp}
+	case g.Data.WPGGroup != nil:
+		return g.Data.WPGGroup.Shapes()
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package docx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGroupXML is a paragraph holding a group of a text box, a nested group with an
+// ellipse, and a picture, written as Word does inside mc:AlternateContent.
+const testGroupXML = `<w:p><w:r><mc:AlternateContent><mc:Choice Requires="wpg"><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0"><wp:extent cx="1828800" cy="914400"/><wp:docPr id="40" name="Group 40"/><a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><a:graphicData uri="http://schemas.microsoft.com/office/word/2010/wordprocessingGroup"><wpg:wgp><wpg:cNvGrpSpPr/><wpg:grpSpPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="1828800" cy="914400"/><a:chOff x="0" y="0"/><a:chExt cx="1828800" cy="914400"/></a:xfrm></wpg:grpSpPr>` +
+	`<wps:wsp><wps:cNvSpPr txBox="1"/><wps:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="914400" cy="914400"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></wps:spPr><wps:txbx><w:txbxContent><w:p><w:r><w:t>Grouped caption</w:t></w:r></w:p></w:txbxContent></wps:txbx><wps:bodyPr/></wps:wsp>` +
+	`<wpg:grpSp><wpg:cNvGrpSpPr/><wpg:grpSpPr/><wps:wsp><wps:cNvSpPr/><wps:spPr><a:prstGeom prst="ellipse"><a:avLst/></a:prstGeom><a:solidFill><a:srgbClr val="FF0000"/></a:solidFill></wps:spPr><wps:bodyPr/></wps:wsp></wpg:grpSp>` +
+	`</wpg:wgp></a:graphicData></a:graphic></wp:inline></w:drawing></mc:Choice><mc:Fallback><w:pict/></mc:Fallback></mc:AlternateContent></w:r></w:p>`
+
+func TestShapes(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	box, err := rd.AddTextBox(2, 1, nil)
+	require.NoError(t, err)
+	require.True(t, box.IsTextBox())
+	box.AddParagraph("First line")
+	box.AddParagraph("Second line").AddText(" continued")
+	tbl := box.AddTable()
+	tbl.AddRow().AddCell().AddParagraph("cell")
+	assert.Equal(t, "First line\nSecond line continued\ncell", box.Text())
+	require.NoError(t, box.Float(&docx.AnchorOptions{Wrap: docx.WrapSquare}))
+
+	arrow, err := rd.AddShape(docx.ShapeRightArrow, 1.5, 0.5, &docx.ShapeOptions{Fill: "#4472c4", NoOutline: true, AltText: "Next step"})
+	require.NoError(t, err)
+	assert.False(t, arrow.IsTextBox())
+	assert.Equal(t, docx.ShapeRightArrow, arrow.Preset())
+	assert.Equal(t, "4472C4", arrow.Wsp.SpPr.SolidFill.SrgbClr.Val)
+	require.NoError(t, arrow.SetOutline("00FF00", 2))
+	assert.Equal(t, "25400", arrow.Wsp.SpPr.Ln.W)
+
+	_, err = rd.AddShape(docx.ShapeLine, 3, 0, nil)
+	require.NoError(t, err)
+	_, err = rd.AddShape(docx.ShapeRect, 0, 1, nil)
+	assert.Error(t, err)
+	_, err = rd.AddShape("star5", 1, 1, nil)
+	assert.Error(t, err)
+	_, err = rd.AddShape(docx.ShapeEllipse, 1, 1, &docx.ShapeOptions{Fill: "red"})
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	content := withBodyXML(t, buf.Bytes(), testGroupXML)
+
+	doc := documentXML(t, content)
+	assert.Contains(t, doc, `<a:graphicData uri="http://schemas.microsoft.com/office/word/2010/wordprocessingShape"><wps:wsp><wps:cNvSpPr txBox="1">`)
+	assert.Contains(t, doc, `<wps:txbx><w:txbxContent><w:p>`)
+	assert.Contains(t, doc, `<a:prstGeom prst="rightArrow">`)
+
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+
+	boxes := reopened.TextBoxes()
+	require.Len(t, boxes, 2)
+	assert.Equal(t, "Grouped caption", boxes[0].Text())
+	assert.Equal(t, "First line\nSecond line continued\ncell", boxes[1].Text())
+	assert.True(t, boxes[1].IsFloating())
+
+	// Edits through the paragraphs of a loaded text box are kept.
+	paras := boxes[1].Paragraphs()
+	require.Len(t, paras, 2)
+	paras[0].AddText(", edited")
+	assert.Equal(t, "First line, edited\nSecond line continued\ncell", boxes[1].Text())
+
+	group := reopened.Document.Body.Children[0].Para.Shapes()
+	require.Len(t, group, 2)
+	assert.Equal(t, docx.ShapeEllipse, group[1].Preset())
+	assert.Equal(t, "FF0000", group[1].Wsp.SpPr.SolidFill.SrgbClr.Val)
+
+	buf.Reset()
+	require.NoError(t, reopened.Write(&buf))
+	content = buf.Bytes()
+	again, err := packager.Unpack(&content)
+	require.NoError(t, err)
+	boxes = again.TextBoxes()
+	require.Len(t, boxes, 2)
+	assert.Equal(t, "Grouped caption", boxes[0].Text())
+	assert.Equal(t, "First line, edited\nSecond line continued\ncell", boxes[1].Text())
+}
+
+// withBodyXML inserts raw paragraph XML at the start of the body of a written document.
+func withBodyXML(t *testing.T, doc []byte, xml string) []byte {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(doc), int64(len(doc)))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		if f.Name == "word/document.xml" {
+			content = bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+xml), 1)
+		}
+		w, err := zw.Create(f.Name)
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return out.Bytes()
+}
+
+// documentXML returns the main document part of a written document.
+func documentXML(t *testing.T, doc []byte) string {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(doc), int64(len(doc)))
+	require.NoError(t, err)
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			return string(content)
+		}
+	}
+	t.Fatal("no document part")
+	return ""
+}
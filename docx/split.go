@@ -0,0 +1,573 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/common/constants"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+const numberingPartPath = "word/numbering.xml"
+
+// prunableRelTypes lists the document relationship types that are only kept in a split part
+// when the part's content actually references them. All other relationships (styles, settings,
+// theme, font table, ...) are document-wide and always kept.
+var prunableRelTypes = map[string]bool{
+	constants.SourceRelationshipImage:     true,
+	constants.SourceRelationshipHyperLink: true,
+	constants.SourceRelationshipChart:     true,
+	constants.HeaderType:                  true,
+	constants.FooterType:                  true,
+}
+
+// relAttrNames lists the attribute local names that carry relationship IDs in document content.
+var relAttrNames = map[string]bool{
+	"id":    true,
+	"embed": true,
+	"link":  true,
+}
+
+// SplitByHeading splits the document before every Heading 1 paragraph and returns one
+// document per chunk. Content that precedes the first heading forms a chunk of its own.
+//
+// Each returned document carries deep copies of its body content and only the styles,
+// numbering definitions, media and relationships that the content references. The final
+// section properties of the source are copied into every part. The source document is
+// not modified, apart from pending numbering instances being flushed to numbering.xml.
+//
+// Returns:
+//   - []*RootDoc: The split documents, in document order.
+//   - error: An error if the document has no body or a part could not be built.
+func (rd *RootDoc) SplitByHeading() ([]*RootDoc, error) {
+	if rd.Document == nil || rd.Document.Body == nil {
+		return nil, errors.New("document has no body")
+	}
+
+	var (
+		parts   []*RootDoc
+		current []DocumentChild
+	)
+
+	flush := func() error {
+		if len(current) == 0 {
+			return nil
+		}
+		part, err := rd.newPart(current, rd.Document.Body.SectPr)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, part)
+		current = nil
+		return nil
+	}
+
+	for _, child := range rd.Document.Body.Children {
+		if child.Para != nil && rd.isHeading1(child.Para) {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+		current = append(current, child)
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return parts, nil
+}
+
+// SplitBySection splits the document at its section breaks and returns one document per section.
+//
+// A section break is a paragraph carrying section properties (pPr/sectPr). The section
+// properties of that paragraph become the final section properties of the part, so every
+// part keeps its own page setup, headers and footers. The last part uses the body's
+// section properties.
+//
+// Returns:
+//   - []*RootDoc: The split documents, in document order.
+//   - error: An error if the document has no body or a part could not be built.
+func (rd *RootDoc) SplitBySection() ([]*RootDoc, error) {
+	if rd.Document == nil || rd.Document.Body == nil {
+		return nil, errors.New("document has no body")
+	}
+
+	var (
+		parts   []*RootDoc
+		current []DocumentChild
+		refs    = newHeaderFooterRefs()
+	)
+
+	for _, child := range rd.Document.Body.Children {
+		current = append(current, child)
+
+		if child.Para == nil || child.Para.ct.Property == nil || child.Para.ct.Property.SectPr == nil {
+			continue
+		}
+
+		part, err := rd.newPart(current, refs.apply(child.Para.ct.Property.SectPr))
+		if err != nil {
+			return nil, err
+		}
+
+		// The break now lives in the part's body, drop it from the cloned paragraph.
+		last := part.Document.Body.Children[len(part.Document.Body.Children)-1]
+		last.Para.ct.Property.SectPr = nil
+
+		parts = append(parts, part)
+		current = nil
+	}
+
+	if len(current) > 0 {
+		part, err := rd.newPart(current, refs.apply(rd.Document.Body.SectPr))
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+
+	return parts, nil
+}
+
+// hdrFtrTypes lists the kinds of header and footer references in the order they are written.
+var hdrFtrTypes = []stypes.HdrFtrType{stypes.HdrFtrDefault, stypes.HdrFtrFirst, stypes.HdrFtrEven}
+
+// headerFooterRefs tracks the header and footer references in effect while walking the
+// sections of a document: a section without a reference of some kind uses the one of the
+// previous section.
+type headerFooterRefs struct {
+	headers map[stypes.HdrFtrType]string
+	footers map[stypes.HdrFtrType]string
+}
+
+func newHeaderFooterRefs() *headerFooterRefs {
+	return &headerFooterRefs{
+		headers: make(map[stypes.HdrFtrType]string),
+		footers: make(map[stypes.HdrFtrType]string),
+	}
+}
+
+// apply records the references of the next section and returns a shallow copy of its
+// section properties referencing every header and footer in effect for it.
+func (r *headerFooterRefs) apply(sectPr *ctypes.SectionProp) *ctypes.SectionProp {
+	if sectPr == nil {
+		return nil
+	}
+	for _, ref := range sectPr.HeaderReferences {
+		r.headers[ref.Type] = ref.ID
+	}
+	for _, ref := range sectPr.FooterReferences {
+		r.footers[ref.Type] = ref.ID
+	}
+
+	effective := *sectPr
+	effective.HeaderReferences = nil
+	effective.FooterReferences = nil
+	for _, kind := range hdrFtrTypes {
+		if id, ok := r.headers[kind]; ok {
+			effective.HeaderReferences = append(effective.HeaderReferences, &ctypes.HeaderReference{Type: kind, ID: id})
+		}
+		if id, ok := r.footers[kind]; ok {
+			effective.FooterReferences = append(effective.FooterReferences, &ctypes.FooterReference{Type: kind, ID: id})
+		}
+	}
+	return &effective
+}
+
+// isHeading1 reports whether the paragraph uses the built-in Heading 1 style.
+func (rd *RootDoc) isHeading1(p *Paragraph) bool {
+	if p.ct.Property == nil || p.ct.Property.Style == nil {
+		return false
+	}
+
+	styleID := p.ct.Property.Style.Val
+	if styleID == "Heading1" {
+		return true
+	}
+
+	style := rd.GetStyleByID(styleID, stypes.StyleTypeParagraph)
+	return style != nil && style.Name != nil && strings.EqualFold(style.Name.Val, "heading 1")
+}
+
+// newPart builds a standalone document from deep copies of the given children and section
+// properties, carrying over only the package parts the content references.
+func (rd *RootDoc) newPart(children []DocumentChild, sectPr *ctypes.SectionProp) (*RootDoc, error) {
+	part := NewRootDoc()
+	part.ImageCount = rd.ImageCount
+	part.rID = rd.rID
+
+	body, err := cloneBody(part, &Body{Children: children, SectPr: sectPr})
+	if err != nil {
+		return nil, err
+	}
+
+	part.Document = &Document{
+		Root:         part,
+		Body:         body,
+		Attrs:        append([]xml.Attr(nil), rd.Document.Attrs...),
+		RID:          rd.Document.RID,
+		relativePath: rd.Document.relativePath,
+		Headers:      make(map[string]*Header),
+		Footers:      make(map[string]*Footer),
+	}
+	if rd.Document.Background != nil {
+		bg := *rd.Document.Background
+		part.Document.Background = &bg
+	}
+
+	part.ContentType = ContentTypes{
+		Default:  append([]Default(nil), rd.ContentType.Default...),
+		Override: append([]Override(nil), rd.ContentType.Override...),
+	}
+	part.RootRels = copyRelationships(rd.RootRels)
+
+	relIDs := make(map[string]bool, len(rd.Document.DocRels.Relationships))
+	for _, rel := range rd.Document.DocRels.Relationships {
+		relIDs[rel.ID] = true
+	}
+
+	refs := newPartRefs()
+	bodyXML, err := marshalBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if err := refs.scan(bodyXML, relIDs); err != nil {
+		return nil, err
+	}
+
+	// Headers and footers are kept when a section of the part points at them.
+	for id, header := range rd.Document.Headers {
+		if !refs.rels[id] {
+			continue
+		}
+		content, err := marshal(header)
+		if err != nil {
+			return nil, err
+		}
+		clone, err := LoadHeaderXml(part, header.RelativePath, content)
+		if err != nil {
+			return nil, err
+		}
+		clone.ID = id
+		part.Document.Headers[id] = clone
+		if err := refs.scan(content, nil); err != nil {
+			return nil, err
+		}
+	}
+	for id, footer := range rd.Document.Footers {
+		if !refs.rels[id] {
+			continue
+		}
+		content, err := marshal(footer)
+		if err != nil {
+			return nil, err
+		}
+		clone, err := LoadFooterXml(part, footer.RelativePath, content)
+		if err != nil {
+			return nil, err
+		}
+		clone.ID = id
+		part.Document.Footers[id] = clone
+		if err := refs.scan(content, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if rd.Settings != nil {
+		content, err := marshal(rd.Settings)
+		if err != nil {
+			return nil, err
+		}
+		if part.Settings, err = LoadSettings(rd.Settings.RelativePath, content); err != nil {
+			return nil, err
+		}
+	}
+
+	if rd.DocStyles != nil {
+		styles, err := pruneStyles(rd.DocStyles, refs.styles)
+		if err != nil {
+			return nil, err
+		}
+		part.DocStyles = styles
+
+		stylesXML, err := marshal(styles)
+		if err != nil {
+			return nil, err
+		}
+		if err := refs.scan(stylesXML, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// Split the document relationships into kept and dropped ones and work out which
+	// internal parts are still reachable from the new document.
+	wordDir := path.Dir(rd.Document.relativePath)
+	kept := make(map[string]bool)
+	dropped := make(map[string]bool)
+
+	part.Document.DocRels = Relationships{
+		RelativePath: rd.Document.DocRels.RelativePath,
+		Xmlns:        rd.Document.DocRels.Xmlns,
+	}
+	for _, rel := range rd.Document.DocRels.Relationships {
+		target := ""
+		if rel.TargetMode != "External" {
+			target = path.Join(wordDir, rel.Target)
+		}
+
+		if prunableRelTypes[rel.Type] && !refs.rels[rel.ID] {
+			if target != "" {
+				dropped[target] = true
+			}
+			continue
+		}
+
+		relCopy := *rel
+		part.Document.DocRels.Relationships = append(part.Document.DocRels.Relationships, &relCopy)
+		if target != "" {
+			kept[target] = true
+		}
+	}
+
+	// Parts referenced from the relationships of kept headers and footers stay as well.
+	for _, hdrPath := range part.headerFooterPaths() {
+		relsURI := path.Join(path.Dir(hdrPath), "_rels", path.Base(hdrPath)+".rels")
+		content, ok := rd.FileMap.Load(relsURI)
+		if !ok {
+			continue
+		}
+		rels := Relationships{}
+		if err := xml.Unmarshal(content.([]byte), &rels); err != nil {
+			return nil, err
+		}
+		for _, rel := range rels.Relationships {
+			if rel.TargetMode != "External" {
+				kept[path.Join(path.Dir(hdrPath), rel.Target)] = true
+			}
+		}
+	}
+
+	for target := range dropped {
+		if kept[target] {
+			continue
+		}
+		part.ContentType.RemoveOverride("/" + target)
+		dropped[path.Join(path.Dir(target), "_rels", path.Base(target)+".rels")] = true
+	}
+
+	rd.FileMap.Range(func(key, value any) bool {
+		fileName := key.(string)
+		if dropped[fileName] && !kept[fileName] {
+			return true
+		}
+		if strings.HasPrefix(fileName, constants.MediaPath) && !kept[fileName] {
+			part.ContentType.RemoveOverride("/" + fileName)
+			return true
+		}
+		part.FileMap.Store(fileName, value)
+		return true
+	})
+
+	// Instances not yet written to the numbering part of rd are included, without
+	// writing them to rd.
+	if rd.Numbering != nil {
+		content, err := rd.Numbering.pendingPart()
+		if err != nil {
+			return nil, err
+		}
+		if content != nil {
+			part.FileMap.Store(numberingPartPath, content)
+		}
+	}
+	if content, ok := part.FileMap.Load(numberingPartPath); ok {
+		pruned, err := pruneNumberingXML(content.([]byte), refs.numIDs)
+		if err != nil {
+			return nil, err
+		}
+		part.FileMap.Store(numberingPartPath, pruned)
+	}
+
+	return part, nil
+}
+
+// headerFooterPaths returns the package paths of the document's headers and footers.
+func (rd *RootDoc) headerFooterPaths() []string {
+	var paths []string
+	for _, h := range rd.Document.Headers {
+		paths = append(paths, h.RelativePath)
+	}
+	for _, f := range rd.Document.Footers {
+		paths = append(paths, f.RelativePath)
+	}
+	return paths
+}
+
+// copyRelationships returns a copy of rels that does not share Relationship pointers with it.
+func copyRelationships(rels Relationships) Relationships {
+	out := Relationships{
+		RelativePath: rels.RelativePath,
+		XMLName:      rels.XMLName,
+		Xmlns:        rels.Xmlns,
+	}
+	for _, rel := range rels.Relationships {
+		relCopy := *rel
+		out.Relationships = append(out.Relationships, &relCopy)
+	}
+	return out
+}
+
+// partRefs collects the styles, numbering instances and relationships referenced by a part.
+type partRefs struct {
+	rels   map[string]bool
+	styles map[string]bool
+	numIDs map[int]bool
+}
+
+func newPartRefs() *partRefs {
+	return &partRefs{
+		rels:   make(map[string]bool),
+		styles: make(map[string]bool),
+		numIDs: make(map[int]bool),
+	}
+}
+
+// scan walks the XML content and records every style, numbering instance and relationship
+// it references. Relationship IDs are only recorded when relIDs is non-nil and contains them.
+func (refs *partRefs) scan(content []byte, relIDs map[string]bool) error {
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		elem, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		for _, attr := range elem.Attr {
+			if relIDs != nil && relAttrNames[attr.Name.Local] && relIDs[attr.Value] {
+				refs.rels[attr.Value] = true
+			}
+		}
+
+		switch elem.Name.Local {
+		case "pStyle", "rStyle", "tblStyle":
+			if val := attrValue(elem, "val"); val != "" {
+				refs.styles[val] = true
+			}
+		case "numId":
+			if id, err := strconv.Atoi(attrValue(elem, "val")); err == nil {
+				refs.numIDs[id] = true
+			}
+		}
+	}
+}
+
+// attrValue returns the value of the first attribute of elem with the given local name.
+func attrValue(elem xml.StartElement, local string) string {
+	for _, attr := range elem.Attr {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// pruneStyles returns a copy of styles holding only the used styles, the default styles
+// and every style they depend on through basedOn, next and link.
+func pruneStyles(styles *ctypes.Styles, used map[string]bool) (*ctypes.Styles, error) {
+	content, err := marshal(styles)
+	if err != nil {
+		return nil, err
+	}
+	clone, err := LoadStyles(styles.RelativePath, content)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make(map[string]bool, len(used))
+	for id := range used {
+		keep[id] = true
+	}
+	for _, style := range clone.StyleList {
+		if style.ID != nil && style.Default != nil && isOn(*style.Default) {
+			keep[*style.ID] = true
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, style := range clone.StyleList {
+			if style.ID == nil || !keep[*style.ID] {
+				continue
+			}
+			for _, dep := range []*ctypes.CTString{style.BasedOn, style.Next, style.Link} {
+				if dep != nil && !keep[dep.Val] {
+					keep[dep.Val] = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	list := clone.StyleList[:0]
+	for _, style := range clone.StyleList {
+		if style.ID != nil && keep[*style.ID] {
+			list = append(list, style)
+		}
+	}
+	clone.StyleList = list
+
+	return clone, nil
+}
+
+// isOn reports whether an ST_OnOff value is switched on.
+func isOn(v stypes.OnOff) bool {
+	switch v {
+	case stypes.OnOffTrue, stypes.OnOffOn, stypes.OnOffOne:
+		return true
+	}
+	return false
+}
+
+// pruneNumberingXML removes the w:num instances whose IDs are not in numIDs, and the
+// w:abstractNum definitions no remaining instance uses. Everything else in the part is
+// copied byte for byte.
+func pruneNumberingXML(content []byte, numIDs map[int]bool) ([]byte, error) {
+	spans, err := numberingSpans(content)
+	if err != nil {
+		return nil, err
+	}
+
+	keptAbstract := make(map[int]bool)
+	for _, s := range spans {
+		if s.num && numIDs[s.id] {
+			keptAbstract[s.abstract] = true
+		}
+	}
+
+	var out bytes.Buffer
+	var last int64
+	for _, s := range spans {
+		if (s.num && numIDs[s.id]) || (!s.num && keptAbstract[s.id]) {
+			continue
+		}
+		out.Write(content[last:s.start])
+		last = s.start + int64(len(s.content))
+	}
+	out.Write(content[last:])
+
+	return out.Bytes(), nil
+}
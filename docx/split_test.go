@@ -0,0 +1,177 @@
+package docx_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/common/units"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestPNG(t *testing.T) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 2))))
+
+	p := filepath.Join(t.TempDir(), "pixel.png")
+	require.NoError(t, os.WriteFile(p, buf.Bytes(), 0o644))
+	return p
+}
+
+func paraTexts(rd *docx.RootDoc) []string {
+	var out []string
+	for _, child := range rd.Document.Body.Children {
+		if child.Para == nil {
+			continue
+		}
+		var sb strings.Builder
+		for _, pc := range child.Para.GetCT().Children {
+			if pc.Run == nil {
+				continue
+			}
+			for _, rc := range pc.Run.Children {
+				if rc.Text != nil {
+					sb.WriteString(rc.Text.Text)
+				}
+			}
+		}
+		out = append(out, sb.String())
+	}
+	return out
+}
+
+func fileNames(rd *docx.RootDoc) map[string]bool {
+	names := make(map[string]bool)
+	rd.FileMap.Range(func(key, _ any) bool {
+		names[key.(string)] = true
+		return true
+	})
+	return names
+}
+
+func TestSplitByHeading(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	rd.AddParagraph("Preface")
+
+	_, err = rd.AddHeading("Chapter 1", 1)
+	require.NoError(t, err)
+	list := rd.NewListInstance(1)
+	rd.AddParagraph("Item").Numbering(list, 0)
+
+	_, err = rd.AddHeading("Chapter 2", 1)
+	require.NoError(t, err)
+	_, err = rd.AddPicture(writeTestPNG(t), units.Inch(1), units.Inch(0.5))
+	require.NoError(t, err)
+	_, err = rd.AddHeading("Section 2.1", 2)
+	require.NoError(t, err)
+
+	parts, err := rd.SplitByHeading()
+	require.NoError(t, err)
+	require.Len(t, parts, 3)
+
+	assert.Equal(t, []string{"Preface"}, paraTexts(parts[0]))
+	assert.Equal(t, []string{"Chapter 1", "Item"}, paraTexts(parts[1]))
+	assert.Equal(t, []string{"Chapter 2", "", "Section 2.1"}, paraTexts(parts[2]))
+
+	// Only the second chapter carries the picture and its relationship.
+	assert.False(t, fileNames(parts[1])["word/media/image1.png"])
+	assert.True(t, fileNames(parts[2])["word/media/image1.png"])
+
+	// Numbering instances are only kept where used.
+	num1, _ := parts[1].FileMap.Load("word/numbering.xml")
+	num2, _ := parts[2].FileMap.Load("word/numbering.xml")
+	listAttr := `w:numId="` + strconv.Itoa(list) + `"`
+	assert.Contains(t, string(num1.([]byte)), listAttr)
+	assert.NotContains(t, string(num2.([]byte)), listAttr)
+	assert.NotContains(t, string(num2.([]byte)), `w:abstractNumId="201"`)
+
+	// Styles are pruned to the used ones and their dependencies.
+	assert.NotNil(t, parts[2].GetStyleByID("Heading2", "paragraph"))
+	assert.Nil(t, parts[1].GetStyleByID("Heading2", "paragraph"))
+	assert.NotNil(t, parts[1].GetStyleByID("Normal", "paragraph"))
+	assert.Less(t, len(parts[1].DocStyles.StyleList), len(rd.DocStyles.StyleList))
+
+	// The source document is untouched.
+	assert.Len(t, rd.Document.Body.Children, 6)
+
+	for _, part := range parts {
+		var buf bytes.Buffer
+		require.NoError(t, part.Write(&buf))
+		content := buf.Bytes()
+		reopened, err := packager.Unpack(&content)
+		require.NoError(t, err)
+		assert.Equal(t, paraTexts(part), paraTexts(reopened))
+	}
+}
+
+func TestSplitBySection(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	rd.AddParagraph("Portrait")
+	brk := rd.AddParagraph("End of first section")
+	brk.GetCT().Property = ctypes.DefaultParaProperty()
+	brk.GetCT().Property.SectPr = ctypes.NewSectionProper()
+	rd.AddParagraph("Landscape")
+
+	parts, err := rd.SplitBySection()
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+
+	assert.Equal(t, []string{"Portrait", "End of first section"}, paraTexts(parts[0]))
+	assert.Equal(t, []string{"Landscape"}, paraTexts(parts[1]))
+
+	assert.NotNil(t, parts[0].Document.Body.SectPr)
+	last := parts[0].Document.Body.Children[1].Para.GetCT()
+	assert.Nil(t, last.Property.SectPr)
+	assert.NotNil(t, brk.GetCT().Property.SectPr)
+}
+
+func TestSplitBySectionInheritsHeaders(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	brk := rd.AddParagraph("First section")
+	brk.GetCT().Property = ctypes.DefaultParaProperty()
+	brk.GetCT().Property.SectPr = ctypes.NewSectionProper()
+	brk.GetCT().Property.SectPr.HeaderReferences = []*ctypes.HeaderReference{{Type: stypes.HdrFtrDefault, ID: "rId99"}}
+	rd.AddParagraph("Second section")
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	content := withHeaderImage(t, buf.Bytes(), testPNG(t, 8, 8, 0))
+	doc, err := packager.Unpack(&content)
+	require.NoError(t, err)
+
+	parts, err := doc.SplitBySection()
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	for _, part := range parts {
+		sectPr := part.Document.Body.SectPr
+		require.Len(t, sectPr.HeaderReferences, 1)
+		assert.Equal(t, "rId99", sectPr.HeaderReferences[0].ID)
+		assert.Contains(t, part.Document.Headers, "rId99")
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, parts[1].Write(&out))
+	written := zipParts(t, out.Bytes())
+	assert.Contains(t, string(written["word/_rels/document.xml.rels"]), `Target="header1.xml"`)
+	assert.Contains(t, written, "word/header1.xml")
+	assert.Contains(t, written, "word/media/logo.png")
+}
@@ -0,0 +1,649 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/internal"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// ErrStyleInUse is returned when deleting a style that is still referenced.
+var ErrStyleInUse = errors.New("style is in use")
+
+// StyleBuilder creates a new style or modifies an existing one. Changes are only applied to
+// the document when Save is called.
+//
+// Example:
+//
+//	_, err := document.NewParagraphStyle("Quote2", "Quote 2").
+//		BasedOn("Normal").Next("Normal").
+//		Italic(true).Color("595959").
+//		Indent(&ctypes.Indent{Left: internal.ToPtr(720)}).
+//		Save()
+type StyleBuilder struct {
+	root  *RootDoc
+	style ctypes.Style
+
+	// ID of the edited style; empty when creating a new style
+	editID string
+}
+
+// NewParagraphStyle returns a builder for a new paragraph style.
+func (rd *RootDoc) NewParagraphStyle(id, name string) *StyleBuilder {
+	return rd.newStyleBuilder(id, name, stypes.StyleTypeParagraph)
+}
+
+// NewCharacterStyle returns a builder for a new character style.
+func (rd *RootDoc) NewCharacterStyle(id, name string) *StyleBuilder {
+	return rd.newStyleBuilder(id, name, stypes.StyleTypeCharacter)
+}
+
+// NewTableStyle returns a builder for a new table style.
+func (rd *RootDoc) NewTableStyle(id, name string) *StyleBuilder {
+	return rd.newStyleBuilder(id, name, stypes.StyleTypeTable)
+}
+
+// NewNumberingStyle returns a builder for a new numbering style. Use Numbering to attach
+// the numbering definition the style stands for.
+func (rd *RootDoc) NewNumberingStyle(id, name string) *StyleBuilder {
+	return rd.newStyleBuilder(id, name, stypes.StyleTypeNumbering)
+}
+
+func (rd *RootDoc) newStyleBuilder(id, name string, styleType stypes.StyleType) *StyleBuilder {
+	return &StyleBuilder{
+		root: rd,
+		style: ctypes.Style{
+			ID:          internal.ToPtr(id),
+			Type:        internal.ToPtr(styleType),
+			Name:        ctypes.NewCTString(name),
+			CustomStyle: internal.ToPtr(stypes.OnOffOne),
+		},
+	}
+}
+
+// EditStyle returns a builder initialized with a copy of the existing style.
+func (rd *RootDoc) EditStyle(id string, styleType stypes.StyleType) (*StyleBuilder, error) {
+	style := rd.GetStyleByID(id, styleType)
+	if style == nil {
+		return nil, fmt.Errorf("style %q of type %s not found", id, styleType)
+	}
+
+	clone, err := cloneStyle(style)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StyleBuilder{root: rd, style: *clone, editID: id}, nil
+}
+
+// GetCT returns a pointer to the style being built, for properties without a dedicated setter.
+func (b *StyleBuilder) GetCT() *ctypes.Style {
+	return &b.style
+}
+
+// Name sets the primary name of the style shown in the user interface.
+func (b *StyleBuilder) Name(name string) *StyleBuilder {
+	b.style.Name = ctypes.NewCTString(name)
+	return b
+}
+
+// BasedOn sets the parent style the style inherits from.
+func (b *StyleBuilder) BasedOn(id string) *StyleBuilder {
+	b.style.BasedOn = optionalCTString(id)
+	return b
+}
+
+// Next sets the style applied to the paragraph following one with this style.
+func (b *StyleBuilder) Next(id string) *StyleBuilder {
+	b.style.Next = optionalCTString(id)
+	return b
+}
+
+// Link sets the linked paragraph or character style.
+func (b *StyleBuilder) Link(id string) *StyleBuilder {
+	b.style.Link = optionalCTString(id)
+	return b
+}
+
+// UIPriority sets the sorting order of the style in the user interface.
+func (b *StyleBuilder) UIPriority(priority int) *StyleBuilder {
+	b.style.UIPriority = ctypes.NewDecimalNum(priority)
+	return b
+}
+
+// QFormat marks the style as a primary style shown in the style gallery.
+func (b *StyleBuilder) QFormat(value bool) *StyleBuilder {
+	b.style.QFormat = optionalOnOff(value)
+	return b
+}
+
+// Hidden hides the style from the user interface.
+func (b *StyleBuilder) Hidden(value bool) *StyleBuilder {
+	b.style.Hidden = optionalOnOff(value)
+	return b
+}
+
+// SemiHidden hides the style from the main user interface.
+func (b *StyleBuilder) SemiHidden(value bool) *StyleBuilder {
+	b.style.SemiHidden = optionalOnOff(value)
+	return b
+}
+
+// UnhideWhenUsed removes the semi-hidden property once the style is used.
+func (b *StyleBuilder) UnhideWhenUsed(value bool) *StyleBuilder {
+	b.style.UnhideWhenUsed = optionalOnOff(value)
+	return b
+}
+
+// Default makes the style the default style of its type. Saving it clears the flag on the
+// previous default style.
+func (b *StyleBuilder) Default(value bool) *StyleBuilder {
+	b.style.Default = nil
+	if value {
+		b.style.Default = internal.ToPtr(stypes.OnOffOne)
+	}
+	return b
+}
+
+// RunProperty replaces the run properties of the style.
+func (b *StyleBuilder) RunProperty(rPr *ctypes.RunProperty) *StyleBuilder {
+	b.style.RunProp = rPr
+	return b
+}
+
+// ParagraphProperty replaces the paragraph properties of the style.
+func (b *StyleBuilder) ParagraphProperty(pPr *ctypes.ParagraphProp) *StyleBuilder {
+	b.style.ParaProp = pPr
+	return b
+}
+
+// TableProperty replaces the table properties of a table style.
+func (b *StyleBuilder) TableProperty(tblPr *ctypes.TableProp) *StyleBuilder {
+	b.style.TableProp = tblPr
+	return b
+}
+
+// Conditional sets the conditional formatting of a table style for prop.Type, replacing any
+// existing formatting of that type.
+func (b *StyleBuilder) Conditional(prop ctypes.TableStyleProp) *StyleBuilder {
+	for i := range b.style.TableStylePr {
+		if b.style.TableStylePr[i].Type == prop.Type {
+			b.style.TableStylePr[i] = prop
+			return b
+		}
+	}
+	b.style.TableStylePr = append(b.style.TableStylePr, prop)
+	return b
+}
+
+// TableStyleFormat is the formatting of a part of a table styled with a table style: the
+// whole table, or one of its conditional formats such as the header row or odd banded rows.
+// Zero fields are left unset and inherit from the formatting below them.
+type TableStyleFormat struct {
+	Bold          bool
+	Italic        bool
+	Color         string               // Text color as a hex code such as "FFFFFF"
+	Fill          string               // Cell background as a hex code such as "4472C4"
+	Justification stypes.Justification // Paragraph alignment
+	Borders       *ctypes.CellBorders  // Cell borders
+}
+
+// TableFormat sets the formatting of a table style for a part of the table. The whole table
+// format goes into the style itself; the other kinds become its conditional formatting,
+// replacing any existing formatting of that kind. Tables choose the conditional formats
+// that apply to them with Table.Look.
+//
+// Example:
+//
+//	_, err := document.NewTableStyle("Report", "Report").
+//		TableFormat(stypes.TblStyleOverrideWholeTable, docx.TableStyleFormat{Borders: borders}).
+//		TableFormat(stypes.TblStyleOverrideFirstRow, docx.TableStyleFormat{Bold: true, Color: "FFFFFF", Fill: "4472C4"}).
+//		TableFormat(stypes.TblStyleOverrideBand1Horz, docx.TableStyleFormat{Fill: "D9E2F3"}).
+//		Save()
+func (b *StyleBuilder) TableFormat(kind stypes.TblStyleOverrideType, format TableStyleFormat) *StyleBuilder {
+	pPr, rPr, tcPr := &ctypes.ParagraphProp{}, &ctypes.RunProperty{}, &ctypes.CellProperty{}
+	if kind == stypes.TblStyleOverrideWholeTable {
+		pPr, rPr = b.paraProp(), b.runProp()
+		if b.style.TableCellProp == nil {
+			b.style.TableCellProp = &ctypes.CellProperty{}
+		}
+		tcPr = b.style.TableCellProp
+	}
+
+	if format.Bold {
+		rPr.Bold = &ctypes.OnOff{}
+	}
+	if format.Italic {
+		rPr.Italic = &ctypes.OnOff{}
+	}
+	if format.Color != "" {
+		rPr.Color = ctypes.NewColor(format.Color)
+	}
+	if format.Justification != "" {
+		pPr.Justification = ctypes.NewGenSingleStrVal(format.Justification)
+	}
+	if format.Fill != "" {
+		tcPr.Shading = &ctypes.Shading{Val: stypes.ShdClear, Color: internal.ToPtr("auto"), Fill: internal.ToPtr(format.Fill)}
+	}
+	if format.Borders != nil {
+		tcPr.Borders = format.Borders
+	}
+
+	if kind == stypes.TblStyleOverrideWholeTable {
+		return b
+	}
+	prop := ctypes.TableStyleProp{Type: kind}
+	if !reflect.ValueOf(*pPr).IsZero() {
+		prop.ParaProp = pPr
+	}
+	if !reflect.ValueOf(*rPr).IsZero() {
+		prop.RunProp = rPr
+	}
+	if !reflect.ValueOf(*tcPr).IsZero() {
+		prop.CellProp = tcPr
+	}
+	return b.Conditional(prop)
+}
+
+// TableBorders sets the borders of a table style.
+func (b *StyleBuilder) TableBorders(borders *ctypes.TableBorders) *StyleBuilder {
+	b.tableProp().Borders = borders
+	return b
+}
+
+// BandSize sets how many rows make a horizontal band and how many columns make a vertical
+// band of a table style. Zero leaves a size unchanged.
+func (b *StyleBuilder) BandSize(rows, cols int) *StyleBuilder {
+	tblPr := b.tableProp()
+	if rows > 0 {
+		tblPr.RowCountInRowBand = ctypes.NewDecimalNum(rows)
+	}
+	if cols > 0 {
+		tblPr.RowCountInColBand = ctypes.NewDecimalNum(cols)
+	}
+	return b
+}
+
+func (b *StyleBuilder) tableProp() *ctypes.TableProp {
+	if b.style.TableProp == nil {
+		b.style.TableProp = &ctypes.TableProp{}
+	}
+	return b.style.TableProp
+}
+
+func (b *StyleBuilder) runProp() *ctypes.RunProperty {
+	if b.style.RunProp == nil {
+		b.style.RunProp = &ctypes.RunProperty{}
+	}
+	return b.style.RunProp
+}
+
+func (b *StyleBuilder) paraProp() *ctypes.ParagraphProp {
+	if b.style.ParaProp == nil {
+		b.style.ParaProp = &ctypes.ParagraphProp{}
+	}
+	return b.style.ParaProp
+}
+
+// Font sets the Latin font of the style.
+func (b *StyleBuilder) Font(font string) *StyleBuilder {
+	rPr := b.runProp()
+	if rPr.Fonts == nil {
+		rPr.Fonts = &ctypes.RunFonts{}
+	}
+	rPr.Fonts.Ascii = font
+	rPr.Fonts.HAnsi = font
+	return b
+}
+
+// EastAsiaFont sets the East Asian font of the style.
+func (b *StyleBuilder) EastAsiaFont(font string) *StyleBuilder {
+	rPr := b.runProp()
+	if rPr.Fonts == nil {
+		rPr.Fonts = &ctypes.RunFonts{}
+	}
+	rPr.Fonts.EastAsia = font
+	return b
+}
+
+// Size sets the font size in points.
+func (b *StyleBuilder) Size(size uint64) *StyleBuilder {
+	b.runProp().Size = ctypes.NewFontSize(size * 2)
+	return b
+}
+
+// Bold enables or disables bold formatting.
+func (b *StyleBuilder) Bold(value bool) *StyleBuilder {
+	b.runProp().Bold = ctypes.OnOffFromBool(value)
+	return b
+}
+
+// Italic enables or disables italic formatting.
+func (b *StyleBuilder) Italic(value bool) *StyleBuilder {
+	b.runProp().Italic = ctypes.OnOffFromBool(value)
+	return b
+}
+
+// Underline sets the underline type.
+func (b *StyleBuilder) Underline(value stypes.Underline) *StyleBuilder {
+	b.runProp().Underline = ctypes.NewGenSingleStrVal(value)
+	return b
+}
+
+// Color sets the text color as a hex code such as "FF0000".
+func (b *StyleBuilder) Color(colorCode string) *StyleBuilder {
+	b.runProp().Color = ctypes.NewColor(colorCode)
+	return b
+}
+
+// Justification sets the paragraph alignment.
+func (b *StyleBuilder) Justification(value stypes.Justification) *StyleBuilder {
+	b.paraProp().Justification = ctypes.NewGenSingleStrVal(value)
+	return b
+}
+
+// Spacing sets the spacing above and below paragraphs, in twips.
+func (b *StyleBuilder) Spacing(before, after uint64) *StyleBuilder {
+	b.paraProp().Spacing = ctypes.NewParagraphSpacing(before, after)
+	return b
+}
+
+// Indent sets the paragraph indentation.
+func (b *StyleBuilder) Indent(indent *ctypes.Indent) *StyleBuilder {
+	b.paraProp().Indent = indent
+	return b
+}
+
+// KeepNext keeps paragraphs with this style on the same page as the next paragraph.
+func (b *StyleBuilder) KeepNext(value bool) *StyleBuilder {
+	b.paraProp().KeepNext = ctypes.OnOffFromBool(value)
+	return b
+}
+
+// OutlineLevel sets the outline level (0 for Heading 1) used by the navigation pane and
+// tables of contents.
+func (b *StyleBuilder) OutlineLevel(level int) *StyleBuilder {
+	b.paraProp().OutlineLvl = ctypes.NewDecimalNum(level)
+	return b
+}
+
+// Numbering attaches a numbering instance and level to the style.
+func (b *StyleBuilder) Numbering(numID, level int) *StyleBuilder {
+	b.paraProp().NumProp = &ctypes.NumProp{
+		NumID: ctypes.NewDecimalNum(numID),
+		ILvl:  ctypes.NewDecimalNum(level),
+	}
+	return b
+}
+
+// Save validates the style and adds it to the document, or replaces the edited style.
+//
+// It fails when a new style's ID is already taken, or when basedOn, next or link refer to
+// missing styles or to the style itself. Changing the ID of an edited style updates the
+// references of other styles, and fails with ErrStyleInUse when the document body,
+// headers and footers or the numbering definitions use the style.
+func (b *StyleBuilder) Save() (*ctypes.Style, error) {
+	rd := b.root
+	if rd.DocStyles == nil {
+		return nil, errors.New("document has no styles part")
+	}
+	if b.style.ID == nil || *b.style.ID == "" {
+		return nil, errors.New("style ID is empty")
+	}
+	id, styleType := *b.style.ID, *b.style.Type
+
+	if b.editID == "" || b.editID != id {
+		if styleIndex(rd.DocStyles, id) >= 0 {
+			return nil, fmt.Errorf("style %q already exists", id)
+		}
+	}
+	if b.editID != "" && b.editID != id && styleIndex(rd.DocStyles, b.editID) >= 0 {
+		// Other styles follow the new ID, content referencing the old one would not.
+		names, err := rd.partsUsingStyle(b.editID)
+		if err != nil {
+			return nil, err
+		}
+		if len(names) > 0 {
+			return nil, fmt.Errorf("%w: cannot change the ID of %q, it is referenced by %s", ErrStyleInUse, b.editID, strings.Join(names, ", "))
+		}
+	}
+
+	for _, dep := range []struct {
+		ref  *ctypes.CTString
+		kind string
+	}{{b.style.BasedOn, "basedOn"}, {b.style.Next, "next"}, {b.style.Link, "link"}} {
+		if dep.ref == nil {
+			continue
+		}
+		if dep.ref.Val == id {
+			return nil, fmt.Errorf("style %q cannot reference itself as %s", id, dep.kind)
+		}
+		if styleIndex(rd.DocStyles, dep.ref.Val) < 0 {
+			return nil, fmt.Errorf("%s style %q of style %q not found", dep.kind, dep.ref.Val, id)
+		}
+	}
+	if b.style.BasedOn != nil && basedOnCycle(rd.DocStyles, id, b.style.BasedOn.Val) {
+		return nil, fmt.Errorf("basedOn chain of style %q is cyclic", id)
+	}
+
+	if b.style.Default != nil && isOn(*b.style.Default) {
+		for i := range rd.DocStyles.StyleList {
+			s := &rd.DocStyles.StyleList[i]
+			if s.Type != nil && *s.Type == styleType {
+				s.Default = nil
+			}
+		}
+	}
+
+	if b.editID != "" {
+		if i := styleIndex(rd.DocStyles, b.editID); i >= 0 {
+			rd.DocStyles.StyleList[i] = b.style
+			if b.editID != id {
+				rd.renameStyleRefs(b.editID, id)
+			}
+			b.editID = id
+			return &rd.DocStyles.StyleList[i], nil
+		}
+	}
+
+	rd.DocStyles.StyleList = append(rd.DocStyles.StyleList, b.style)
+	b.editID = id
+	return &rd.DocStyles.StyleList[len(rd.DocStyles.StyleList)-1], nil
+}
+
+// renameStyleRefs points the basedOn, next and link references of other styles at a renamed style.
+func (rd *RootDoc) renameStyleRefs(oldID, newID string) {
+	for i := range rd.DocStyles.StyleList {
+		s := &rd.DocStyles.StyleList[i]
+		for _, ref := range []*ctypes.CTString{s.BasedOn, s.Next, s.Link} {
+			if ref != nil && ref.Val == oldID {
+				ref.Val = newID
+			}
+		}
+	}
+}
+
+// DeleteStyle removes a style from the document. It fails with ErrStyleInUse when the style
+// is the default style of its type, or is referenced by other styles, by the document body,
+// headers and footers, or by the numbering definitions.
+func (rd *RootDoc) DeleteStyle(id string, styleType stypes.StyleType) error {
+	style := rd.GetStyleByID(id, styleType)
+	if style == nil {
+		return fmt.Errorf("style %q of type %s not found", id, styleType)
+	}
+	if style.Default != nil && isOn(*style.Default) {
+		return fmt.Errorf("%w: %q is the default %s style", ErrStyleInUse, id, styleType)
+	}
+
+	users, err := rd.styleUsers(id)
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("%w: %q is referenced by %s", ErrStyleInUse, id, strings.Join(users, ", "))
+	}
+
+	if i := styleIndex(rd.DocStyles, id); i >= 0 {
+		rd.DocStyles.StyleList = append(rd.DocStyles.StyleList[:i], rd.DocStyles.StyleList[i+1:]...)
+	}
+	return nil
+}
+
+// styleUsers describes every place that references the style ID.
+func (rd *RootDoc) styleUsers(id string) ([]string, error) {
+	var users []string
+
+	for _, s := range rd.DocStyles.StyleList {
+		if s.ID == nil || *s.ID == id {
+			continue
+		}
+		for _, dep := range []struct {
+			ref  *ctypes.CTString
+			kind string
+		}{{s.BasedOn, "basedOn"}, {s.Next, "next"}, {s.Link, "link"}} {
+			if dep.ref != nil && dep.ref.Val == id {
+				users = append(users, fmt.Sprintf("style %q (%s)", *s.ID, dep.kind))
+			}
+		}
+	}
+
+	names, err := rd.partsUsingStyle(id)
+	if err != nil {
+		return nil, err
+	}
+	return append(users, names...), nil
+}
+
+// partsUsingStyle returns the names of the document, header, footer and numbering parts
+// that reference the style ID.
+func (rd *RootDoc) partsUsingStyle(id string) ([]string, error) {
+	parts := make(map[string][]byte)
+	if rd.Document != nil && rd.Document.Body != nil {
+		content, err := marshalBody(rd.Document.Body)
+		if err != nil {
+			return nil, err
+		}
+		parts[rd.Document.relativePath] = content
+		for _, h := range rd.Document.Headers {
+			if parts[h.RelativePath], err = marshal(h); err != nil {
+				return nil, err
+			}
+		}
+		for _, f := range rd.Document.Footers {
+			if parts[f.RelativePath], err = marshal(f); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if rd.Numbering != nil {
+		content, err := rd.Numbering.pendingPart()
+		if err != nil {
+			return nil, err
+		}
+		if content != nil {
+			parts[numberingPartPath] = content
+		}
+	} else if content, ok := rd.FileMap.Load(numberingPartPath); ok {
+		parts[numberingPartPath] = content.([]byte)
+	}
+
+	var names []string
+	for name, content := range parts {
+		used, err := referencesStyle(content, id)
+		if err != nil {
+			return nil, err
+		}
+		if used {
+			names = append(names, name)
+		}
+	}
+	// Map iteration order is random; keep the error message stable.
+	sort.Strings(names)
+	return names, nil
+}
+
+// referencesStyle reports whether the XML content refers to the style ID.
+func referencesStyle(content []byte, id string) (bool, error) {
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		elem, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch elem.Name.Local {
+		case "pStyle", "rStyle", "tblStyle", "numStyleLink", "styleLink":
+			if attrValue(elem, "val") == id {
+				return true, nil
+			}
+		}
+	}
+}
+
+// styleIndex returns the position of the style with the given ID, or -1.
+func styleIndex(styles *ctypes.Styles, id string) int {
+	for i, s := range styles.StyleList {
+		if s.ID != nil && *s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// basedOnCycle reports whether making id based on parent would create a cycle.
+func basedOnCycle(styles *ctypes.Styles, id, parent string) bool {
+	seen := map[string]bool{id: true}
+	for cur := parent; cur != ""; {
+		if seen[cur] {
+			return true
+		}
+		seen[cur] = true
+		i := styleIndex(styles, cur)
+		if i < 0 || styles.StyleList[i].BasedOn == nil {
+			return false
+		}
+		cur = styles.StyleList[i].BasedOn.Val
+	}
+	return false
+}
+
+// cloneStyle returns a deep copy of the style.
+func cloneStyle(style *ctypes.Style) (*ctypes.Style, error) {
+	content, err := xml.Marshal(style)
+	if err != nil {
+		return nil, err
+	}
+	clone := &ctypes.Style{}
+	if err := xml.Unmarshal(content, clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+func optionalCTString(val string) *ctypes.CTString {
+	if val == "" {
+		return nil
+	}
+	return ctypes.NewCTString(val)
+}
+
+func optionalOnOff(value bool) *ctypes.OnOff {
+	if !value {
+		return nil
+	}
+	return &ctypes.OnOff{}
+}
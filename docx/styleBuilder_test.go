@@ -0,0 +1,173 @@
+package docx_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/internal"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStyleBuilder(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	style, err := rd.NewParagraphStyle("Quote2", "Quote 2").
+		BasedOn("Normal").
+		Next("Normal").
+		UIPriority(30).
+		QFormat(true).
+		Italic(true).
+		Color("595959").
+		Spacing(120, 120).
+		Save()
+	require.NoError(t, err)
+	assert.Equal(t, "Normal", style.BasedOn.Val)
+	assert.NotNil(t, rd.GetStyleByID("Quote2", stypes.StyleTypeParagraph))
+
+	_, err = rd.NewParagraphStyle("Quote2", "Duplicate").Save()
+	assert.Error(t, err)
+
+	_, err = rd.NewCharacterStyle("Orphan", "Orphan").BasedOn("Missing").Save()
+	assert.Error(t, err)
+
+	// Modifications only apply on Save.
+	edit, err := rd.EditStyle("Quote2", stypes.StyleTypeParagraph)
+	require.NoError(t, err)
+	edit.Color("C00000").Size(13)
+	assert.Equal(t, "595959", rd.GetStyleByID("Quote2", stypes.StyleTypeParagraph).RunProp.Color.Val)
+	_, err = edit.Save()
+	require.NoError(t, err)
+	updated := rd.GetStyleByID("Quote2", stypes.StyleTypeParagraph)
+	assert.Equal(t, "C00000", updated.RunProp.Color.Val)
+	assert.Equal(t, uint64(26), updated.RunProp.Size.Value)
+	assert.NotNil(t, updated.RunProp.Italic)
+
+	// A basedOn cycle is rejected.
+	normal, err := rd.EditStyle("Normal", stypes.StyleTypeParagraph)
+	require.NoError(t, err)
+	_, err = normal.BasedOn("Quote2").Save()
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	content := buf.Bytes()
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+	assert.Equal(t, "C00000", reopened.GetStyleByID("Quote2", stypes.StyleTypeParagraph).RunProp.Color.Val)
+}
+
+func TestDeleteStyle(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	_, err = rd.NewParagraphStyle("Base2", "Base 2").Save()
+	require.NoError(t, err)
+	_, err = rd.NewParagraphStyle("Child2", "Child 2").BasedOn("Base2").Save()
+	require.NoError(t, err)
+	_, err = rd.NewCharacterStyle("Marker", "Marker").Bold(true).Save()
+	require.NoError(t, err)
+	rd.AddParagraph("").AddText("marked").Style("Marker")
+
+	err = rd.DeleteStyle("Base2", stypes.StyleTypeParagraph)
+	assert.True(t, errors.Is(err, docx.ErrStyleInUse))
+	assert.Contains(t, err.Error(), `"Child2"`)
+
+	err = rd.DeleteStyle("Marker", stypes.StyleTypeCharacter)
+	assert.True(t, errors.Is(err, docx.ErrStyleInUse))
+	assert.Contains(t, err.Error(), "word/document.xml")
+
+	err = rd.DeleteStyle("Normal", stypes.StyleTypeParagraph)
+	assert.True(t, errors.Is(err, docx.ErrStyleInUse))
+
+	require.NoError(t, rd.DeleteStyle("Child2", stypes.StyleTypeParagraph))
+	require.NoError(t, rd.DeleteStyle("Base2", stypes.StyleTypeParagraph))
+	assert.Nil(t, rd.GetStyleByID("Base2", stypes.StyleTypeParagraph))
+}
+
+func TestRenameStyle(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	_, err = rd.NewParagraphStyle("Base3", "Base 3").Save()
+	require.NoError(t, err)
+	_, err = rd.NewParagraphStyle("Child3", "Child 3").BasedOn("Base3").Save()
+	require.NoError(t, err)
+	_, err = rd.NewCharacterStyle("Marker3", "Marker 3").Save()
+	require.NoError(t, err)
+	rd.AddParagraph("").AddText("marked").Style("Marker3")
+
+	// Other styles follow a renamed style.
+	edit, err := rd.EditStyle("Base3", stypes.StyleTypeParagraph)
+	require.NoError(t, err)
+	edit.GetCT().ID = internal.ToPtr("Base4")
+	_, err = edit.Save()
+	require.NoError(t, err)
+	assert.Equal(t, "Base4", rd.GetStyleByID("Child3", stypes.StyleTypeParagraph).BasedOn.Val)
+
+	// Content would keep referring to the old ID.
+	edit, err = rd.EditStyle("Marker3", stypes.StyleTypeCharacter)
+	require.NoError(t, err)
+	edit.GetCT().ID = internal.ToPtr("Marker4")
+	_, err = edit.Save()
+	assert.True(t, errors.Is(err, docx.ErrStyleInUse))
+	assert.Contains(t, err.Error(), "word/document.xml")
+	assert.NotNil(t, rd.GetStyleByID("Marker3", stypes.StyleTypeCharacter))
+	assert.Nil(t, rd.GetStyleByID("Marker4", stypes.StyleTypeCharacter))
+}
+
+func TestImportStyles(t *testing.T) {
+	src, err := godocx.NewDocument()
+	require.NoError(t, err)
+	list := src.NewListInstance(1)
+	_, err = src.NewParagraphStyle("BrandBase", "Brand Base").Font("Arial").Save()
+	require.NoError(t, err)
+	_, err = src.NewParagraphStyle("BrandList", "Brand List").BasedOn("BrandBase").Numbering(list, 0).Save()
+	require.NoError(t, err)
+
+	dst, err := godocx.NewDocument()
+	require.NoError(t, err)
+	existing := dst.NewListInstance(2)
+
+	imported, err := dst.ImportStyles(src, []string{"BrandList"}, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"BrandBase", "BrandList"}, imported)
+
+	brandList := dst.GetStyleByID("BrandList", stypes.StyleTypeParagraph)
+	require.NotNil(t, brandList)
+	newNumID := brandList.ParaProp.NumProp.NumID.Val
+	assert.NotEqual(t, existing, newNumID)
+
+	p := dst.AddParagraph("item")
+	p.Style("BrandList")
+	sr := dst.NewStyleResolver()
+	pPr := sr.Paragraph(p.GetCT(), nil)
+	require.NotNil(t, pPr.Indent)
+	assert.Equal(t, 360, *pPr.Indent.Left)
+	assert.Equal(t, "Arial", docx.NewRunFormat(sr.Run(p.GetCT(), nil, nil)).Font)
+
+	// Existing styles are kept unless overwriting.
+	edit, err := src.EditStyle("BrandBase", stypes.StyleTypeParagraph)
+	require.NoError(t, err)
+	_, err = edit.Font("Verdana").Save()
+	require.NoError(t, err)
+
+	imported, err = dst.ImportStyles(src, []string{"BrandBase"}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, imported)
+	assert.Equal(t, "Arial", dst.GetStyleByID("BrandBase", stypes.StyleTypeParagraph).RunProp.Fonts.Ascii)
+
+	imported, err = dst.ImportStyles(src, []string{"BrandBase"}, &docx.StyleImportOptions{Overwrite: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BrandBase"}, imported)
+	assert.Equal(t, "Verdana", dst.GetStyleByID("BrandBase", stypes.StyleTypeParagraph).RunProp.Fonts.Ascii)
+
+	_, err = dst.ImportStyles(src, []string{"Missing"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,183 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// StyleImportOptions configures ImportStyles.
+type StyleImportOptions struct {
+	// Overwrite replaces styles that already exist in the document. By default existing
+	// styles are kept, and the imported styles refer to them.
+	Overwrite bool
+}
+
+// ImportStyles copies styles from src into the document. ids lists the style IDs to
+// import; when empty, every style of src is imported.
+//
+// The styles they depend on through basedOn, next and link are imported as well, and the
+// numbering definitions used by imported paragraph and numbering styles are copied with
+// new IDs. It returns the IDs of the styles that were added or replaced.
+func (rd *RootDoc) ImportStyles(src *RootDoc, ids []string, opts *StyleImportOptions) ([]string, error) {
+	if rd.DocStyles == nil {
+		return nil, errors.New("document has no styles part")
+	}
+	if src == nil || src.DocStyles == nil {
+		return nil, errors.New("source document has no styles part")
+	}
+	overwrite := opts != nil && opts.Overwrite
+
+	if len(ids) == 0 {
+		for _, s := range src.DocStyles.StyleList {
+			if s.ID != nil {
+				ids = append(ids, *s.ID)
+			}
+		}
+	}
+
+	// Collect the dependency closure in source order.
+	wanted := make(map[string]bool)
+	queue := append([]string(nil), ids...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if wanted[id] {
+			continue
+		}
+		i := styleIndex(src.DocStyles, id)
+		if i < 0 {
+			return nil, fmt.Errorf("style %q not found in source document", id)
+		}
+		wanted[id] = true
+
+		if !overwrite && styleIndex(rd.DocStyles, id) >= 0 {
+			// The existing style is kept, so its dependencies are already in place.
+			continue
+		}
+		s := src.DocStyles.StyleList[i]
+		for _, dep := range []*ctypes.CTString{s.BasedOn, s.Next, s.Link} {
+			if dep != nil && !wanted[dep.Val] && styleIndex(src.DocStyles, dep.Val) >= 0 {
+				queue = append(queue, dep.Val)
+			}
+		}
+	}
+
+	var (
+		imported []*ctypes.Style
+		numIDs   = make(map[int]bool)
+	)
+	for i := range src.DocStyles.StyleList {
+		s := &src.DocStyles.StyleList[i]
+		if s.ID == nil || !wanted[*s.ID] {
+			continue
+		}
+		if !overwrite && styleIndex(rd.DocStyles, *s.ID) >= 0 {
+			continue
+		}
+		clone, err := cloneStyle(s)
+		if err != nil {
+			return nil, err
+		}
+		if clone.ParaProp != nil && clone.ParaProp.NumProp != nil && clone.ParaProp.NumProp.NumID != nil {
+			numIDs[clone.ParaProp.NumProp.NumID.Val] = true
+		}
+		imported = append(imported, clone)
+	}
+
+	numMap := map[int]int{}
+	if len(numIDs) > 0 {
+		var err error
+		if numMap, err = rd.importNumbering(src, numIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	added := make([]string, 0, len(imported))
+	for _, s := range imported {
+		if s.ParaProp != nil && s.ParaProp.NumProp != nil && s.ParaProp.NumProp.NumID != nil {
+			if newID, ok := numMap[s.ParaProp.NumProp.NumID.Val]; ok {
+				s.ParaProp.NumProp.NumID = ctypes.NewDecimalNum(newID)
+			}
+		}
+
+		// Only one default style per type: the document keeps its own.
+		if s.Default != nil && isOn(*s.Default) {
+			if existing := rd.defaultStyle(*s.Type); existing != nil && *existing.ID != *s.ID {
+				s.Default = nil
+			}
+		}
+
+		if i := styleIndex(rd.DocStyles, *s.ID); i >= 0 {
+			rd.DocStyles.StyleList[i] = *s
+		} else {
+			rd.DocStyles.StyleList = append(rd.DocStyles.StyleList, *s)
+		}
+		added = append(added, *s.ID)
+	}
+
+	return added, nil
+}
+
+// defaultStyle returns the default style of the given type, if any.
+func (rd *RootDoc) defaultStyle(styleType stypes.StyleType) *ctypes.Style {
+	for i := range rd.DocStyles.StyleList {
+		s := &rd.DocStyles.StyleList[i]
+		if s.Type != nil && *s.Type == styleType && s.Default != nil && isOn(*s.Default) {
+			return s
+		}
+	}
+	return nil
+}
+
+// importNumbering copies the numbering instances numIDs of src, together with their abstract
+// definitions, into the numbering part of the document. The copies get new IDs; the returned
+// map gives the new ID of every copied instance.
+func (rd *RootDoc) importNumbering(src *RootDoc, numIDs map[int]bool) (map[int]int, error) {
+	srcDefs, err := src.NumberingDefinitions()
+	if err != nil {
+		return nil, err
+	}
+	dstDefs, err := rd.NumberingDefinitions()
+	if err != nil {
+		return nil, err
+	}
+
+	nextAbstract := nextAbstractNumID(dstDefs)
+	numMap := make(map[int]int)
+	abstractMap := make(map[int]int)
+	var (
+		abstracts []ctypes.AbstractNum
+		nums      []ctypes.Num
+	)
+	for _, num := range srcDefs.Nums {
+		if !numIDs[num.ID] {
+			continue
+		}
+		newAbstract, ok := abstractMap[num.AbstractNumID.Val]
+		if !ok {
+			an := srcDefs.AbstractNum(num.AbstractNumID.Val)
+			if an == nil {
+				return nil, fmt.Errorf("abstract numbering definition %d not found in source document", num.AbstractNumID.Val)
+			}
+			newAbstract = nextAbstract
+			nextAbstract++
+			abstractMap[an.ID] = newAbstract
+			copied := *an
+			copied.ID = newAbstract
+			abstracts = append(abstracts, copied)
+		}
+
+		numMap[num.ID] = rd.Numbering.allocateNumID()
+		num.ID = numMap[num.ID]
+		num.AbstractNumID = ctypes.DecimalNum{Val: newAbstract}
+		nums = append(nums, num)
+	}
+
+	if err := rd.Numbering.save(abstracts, nums); err != nil {
+		return nil, err
+	}
+	return numMap, nil
+}
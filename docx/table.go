@@ -119,11 +119,11 @@ func (rd *RootDoc) AddTable() *Table {
 func (t *Table) AddRow() *Row {
 	row := Row{
 		root: t.root,
-		ct:   *ctypes.DefaultRow(),
+		ct:   ctypes.DefaultRow(),
 	}
 
 	t.ct.RowContents = append(t.ct.RowContents, ctypes.RowContent{
-		Row: &row.ct,
+		Row: row.ct,
 	})
 
 	return &row
@@ -194,19 +194,19 @@ type Row struct {
 	// Reverse inheriting the Rootdoc into paragraph to access other elements
 	root *RootDoc
 
-	// Row Complex Type
-	ct ctypes.Row
+	// Row Complex Type, shared with the table
+	ct *ctypes.Row
 }
 
 // Add Cell to row and returns Cell
 func (r *Row) AddCell() *Cell {
 	cell := Cell{
 		root: r.root,
-		ct:   *ctypes.DefaultCell(),
+		ct:   ctypes.DefaultCell(),
 	}
 
 	r.ct.Contents = append(r.ct.Contents, ctypes.TRCellContent{
-		Cell: &cell.ct,
+		Cell: cell.ct,
 	})
 
 	return &cell
@@ -217,8 +217,8 @@ type Cell struct {
 	// Reverse inheriting the Rootdoc into paragraph to access other elements
 	root *RootDoc
 
-	// Cell Complex Type
-	ct ctypes.Cell
+	// Cell Complex Type, shared with the row
+	ct *ctypes.Cell
 }
 
 // Adds paragraph with text and returns Paragraph
@@ -246,45 +246,59 @@ func (c *Cell) AddEmptyPara() *Paragraph {
 }
 
 // ColSpan sets the number of columns a cell should span across in a table.
+//
+// The row keeps its other cells, so the row spans more grid columns afterwards; use
+// Table.Merge to merge existing cells.
 func (c *Cell) ColSpan(cols int) *Cell {
-	if c.ct.Property != nil {
-		c.ct.Property.GridSpan = &ctypes.DecimalNum{Val: cols}
-	}
+	c.ensureProp()
+	c.ct.Property.GridSpan = &ctypes.DecimalNum{Val: cols}
 	return c
 }
 
-// RowSpan sets the cell to span vertically in a table, indicating it is part of a vertically merged group of cells.
+// RowSpan starts a vertically merged group of cells at the cell. The cells below it join the
+// group with vMerge continuation cells; use Table.Merge to write both in one go.
 func (c *Cell) RowSpan() *Cell {
-	if c.ct.Property != nil {
-		vMerge := ctypes.AnnotationVMergeRest
-		c.ct.Property.CellMerge = &ctypes.CellMerge{
-			VMerge: &vMerge,
-		}
-	}
+	c.ensureProp()
+	c.ct.Property.VMerge = &ctypes.GenOptStrVal[stypes.MergeCell]{Val: internal.ToPtr(stypes.MergeCellRestart)}
 	return c
 }
 
+func (c *Cell) ensureProp() {
+	if c.ct.Property == nil {
+		c.ct.Property = &ctypes.CellProperty{}
+	}
+}
+
+// GetCT returns a pointer to the underlying Cell Complex Type.
+func (c *Cell) GetCT() *ctypes.Cell {
+	return c.ct
+}
+
 // VerticalAlign sets the vertical alignment of a cell based on the provided string: "top", "center", "middle", or "bottom".
 func (c *Cell) VerticalAlign(valign string) *Cell {
-	if c.ct.Property != nil {
-		switch valign {
-		case "top":
-			c.ct.Property.VAlign = ctypes.NewGenSingleStrVal(stypes.VerticalJcTop)
-		case "center", "middle":
-			c.ct.Property.VAlign = ctypes.NewGenSingleStrVal(stypes.VerticalJcCenter)
-		case "bottom":
-			c.ct.Property.VAlign = ctypes.NewGenSingleStrVal(stypes.VerticalJcBottom)
-		}
+	c.ensureProp()
+	switch valign {
+	case "top":
+		c.ct.Property.VAlign = ctypes.NewGenSingleStrVal(stypes.VerticalJcTop)
+	case "center", "middle":
+		c.ct.Property.VAlign = ctypes.NewGenSingleStrVal(stypes.VerticalJcCenter)
+	case "bottom":
+		c.ct.Property.VAlign = ctypes.NewGenSingleStrVal(stypes.VerticalJcBottom)
 	}
 	return c
 }
 
 func (c *Cell) BackgroundColor(color string) *Cell {
+	c.ensureProp()
+	if c.ct.Property.Shading == nil {
+		c.ct.Property.Shading = ctypes.DefaultShading()
+	}
 	c.ct.Property.Shading.Fill = &color
 	return c
 }
 
 func (c *Cell) Width(width int, widthType stypes.TableWidth) *Cell {
+	c.ensureProp()
 	c.ct.Property.Width = ctypes.NewTableWidth(width, widthType)
 	return c
 }
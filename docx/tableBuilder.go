@@ -0,0 +1,483 @@
+package docx
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/iEvan-lhr/docx-agent/internal"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+const (
+	// defaultTextWidth is the text width of a Letter page with one inch margins, used when
+	// the document does not give a page size.
+	defaultTextWidth = 9360
+
+	// autofitCharWidth approximates the width of a character of the default body font.
+	autofitCharWidth = 110
+
+	// autofitPadding is the default left and right cell margin of a table.
+	autofitPadding = 2 * 108
+
+	// autofitMinWidth is the narrowest column AutoFit produces.
+	autofitMinWidth = 360
+)
+
+// Rows returns the rows of the table.
+func (t *Table) Rows() []*Row {
+	var rows []*Row
+	for _, rc := range t.ct.RowContents {
+		if rc.Row != nil {
+			rows = append(rows, &Row{root: t.root, ct: rc.Row})
+		}
+	}
+	return rows
+}
+
+// Cells returns the cells of the row, one per tc element. A cell spanning several grid
+// columns is returned once.
+func (r *Row) Cells() []*Cell {
+	var cells []*Cell
+	for _, cc := range r.ct.Contents {
+		if cc.Cell != nil {
+			cells = append(cells, &Cell{root: r.root, ct: cc.Cell})
+		}
+	}
+	return cells
+}
+
+// GetCT returns a pointer to the underlying Row Complex Type.
+func (r *Row) GetCT() *ctypes.Row {
+	return r.ct
+}
+
+func (r *Row) ensureProp() {
+	if r.ct.Property == nil {
+		r.ct.Property = ctypes.DefaultRowProperty()
+	}
+}
+
+// RepeatHeader marks the row as a header row, repeated at the top of every page the
+// table continues on. Only rows at the start of the table repeat.
+func (r *Row) RepeatHeader(value bool) *Row {
+	r.ensureProp()
+	r.ct.Property.Header = nil
+	if value {
+		r.ct.Property.Header = &ctypes.OnOff{}
+	}
+	return r
+}
+
+// CantSplit keeps the row on one page instead of breaking it across pages.
+func (r *Row) CantSplit(value bool) *Row {
+	r.ensureProp()
+	r.ct.Property.CantSplit = nil
+	if value {
+		r.ct.Property.CantSplit = &ctypes.OnOff{}
+	}
+	return r
+}
+
+// Height sets the height of the row in twips. The rule decides whether the height is
+// exact, a minimum, or ignored in favour of the content.
+func (r *Row) Height(twips int, rule stypes.HeightRule) *Row {
+	r.ensureProp()
+	r.ct.Property.Height = ctypes.NewTableRowHeight(twips, rule)
+	return r
+}
+
+// HeaderRows marks the first n rows of the table as header rows, repeated on every page.
+func (t *Table) HeaderRows(n int) *Table {
+	for i, row := range t.Rows() {
+		row.RepeatHeader(i < n)
+	}
+	return t
+}
+
+// TableLook selects which conditional formats of the table style apply to the table
+// (w:tblLook).
+type TableLook struct {
+	FirstRow      bool
+	LastRow       bool
+	FirstColumn   bool
+	LastColumn    bool
+	BandedRows    bool
+	BandedColumns bool
+}
+
+// Look sets which conditional formats of the table style apply to the table.
+func (t *Table) Look(look TableLook) *Table {
+	mask := 0
+	flag := func(on bool, bit int) *string {
+		if on {
+			mask |= bit
+			return internal.ToPtr("1")
+		}
+		return internal.ToPtr("0")
+	}
+
+	tblLook := &ctypes.CTString{}
+	tblLook.FirstRow = flag(look.FirstRow, 0x0020)
+	tblLook.LastRow = flag(look.LastRow, 0x0040)
+	tblLook.FirstColumn = flag(look.FirstColumn, 0x0080)
+	tblLook.LastColumn = flag(look.LastColumn, 0x0100)
+	// The banding bits switch banding off.
+	tblLook.NoHBand = flag(!look.BandedRows, 0x0200)
+	tblLook.NoVBand = flag(!look.BandedColumns, 0x0400)
+	tblLook.Val = fmt.Sprintf("%04X", mask)
+
+	t.ct.TableProp.TableLook = tblLook
+	return t
+}
+
+// gridCell is a cell of a row placed on the table grid.
+type gridCell struct {
+	cell  *ctypes.Cell
+	index int // Index in the contents of the row
+	col   int // First grid column
+	span  int // Number of grid columns
+}
+
+// rowGridCells returns the cells of a row with the grid columns they occupy.
+func rowGridCells(row *ctypes.Row) []gridCell {
+	col := 0
+	if row.Property != nil && row.Property.GridBefore != nil {
+		col = row.Property.GridBefore.Val
+	}
+
+	var cells []gridCell
+	for i, cc := range row.Contents {
+		if cc.Cell == nil {
+			continue
+		}
+		span := cellSpan(cc.Cell)
+		cells = append(cells, gridCell{cell: cc.Cell, index: i, col: col, span: span})
+		col += span
+	}
+	return cells
+}
+
+// cellSpan returns the number of grid columns a cell spans.
+func cellSpan(c *ctypes.Cell) int {
+	if c.Property != nil && c.Property.GridSpan != nil && c.Property.GridSpan.Val > 1 {
+		return c.Property.GridSpan.Val
+	}
+	return 1
+}
+
+// isVMerged reports whether a cell is part of a vertically merged group.
+func isVMerged(c *ctypes.Cell) bool {
+	return c.Property != nil && c.Property.VMerge != nil
+}
+
+// rowCols returns the number of grid columns a row occupies.
+func rowCols(row *ctypes.Row) int {
+	cols := 0
+	if cells := rowGridCells(row); len(cells) > 0 {
+		last := cells[len(cells)-1]
+		cols = last.col + last.span
+	}
+	if row.Property != nil && row.Property.GridAfter != nil {
+		cols += row.Property.GridAfter.Val
+	}
+	return cols
+}
+
+// gridCols returns the number of grid columns of the table: the width of its widest row,
+// or the number of tblGrid columns when that is larger.
+func (t *Table) gridCols() int {
+	cols := len(t.ct.Grid.Col)
+	for _, row := range t.Rows() {
+		cols = max(cols, rowCols(row.ct))
+	}
+	return cols
+}
+
+// Merge merges the cells covering grid rows r1 to r2 and grid columns c1 to c2, all
+// zero-based and inclusive, into one cell. Cells within a row become one cell spanning the
+// columns (gridSpan); rows below the first continue the merged cell (vMerge). The content
+// of the merged cells moves to the top-left cell.
+//
+// Returns an error if the range lies outside the table, cuts through a cell spanning
+// several columns, or overlaps a vertically merged cell.
+//
+// Example:
+//
+//	table.Merge(0, 0, 0, 2) // Title row across three columns
+//	table.Merge(1, 0, 3, 0) // Row label down three rows
+func (t *Table) Merge(r1, c1, r2, c2 int) error {
+	rows := t.Rows()
+	if r1 < 0 || c1 < 0 || r1 > r2 || c1 > c2 || r2 >= len(rows) {
+		return fmt.Errorf("invalid merge range (%d,%d)-(%d,%d)", r1, c1, r2, c2)
+	}
+
+	// Check the whole range before changing anything.
+	ranges := make([][]gridCell, 0, r2-r1+1)
+	for r := r1; r <= r2; r++ {
+		var inRange []gridCell
+		for _, gc := range rowGridCells(rows[r].ct) {
+			if gc.col+gc.span <= c1 || gc.col > c2 {
+				continue
+			}
+			if gc.col < c1 || gc.col+gc.span-1 > c2 {
+				return fmt.Errorf("merge range cuts through a merged cell in row %d", r)
+			}
+			if isVMerged(gc.cell) {
+				return fmt.Errorf("merge range overlaps a vertically merged cell in row %d", r)
+			}
+			inRange = append(inRange, gc)
+		}
+		if len(inRange) == 0 || inRange[0].col != c1 || inRange[len(inRange)-1].col+inRange[len(inRange)-1].span-1 != c2 {
+			return fmt.Errorf("row %d has no cells for every column from %d to %d", r, c1, c2)
+		}
+		ranges = append(ranges, inRange)
+	}
+	if r2+1 < len(rows) {
+		for _, gc := range rowGridCells(rows[r2+1].ct) {
+			if gc.col == c1 && isVMerged(gc.cell) {
+				return fmt.Errorf("merge range overlaps a vertically merged cell in row %d", r2+1)
+			}
+		}
+	}
+
+	var top *ctypes.Cell
+	for i, inRange := range ranges {
+		row := rows[r1+i].ct
+		keep := inRange[0].cell
+		if top == nil {
+			top = keep
+		}
+
+		removed := make(map[int]bool, len(inRange)-1)
+		for _, gc := range inRange[1:] {
+			moveCellContent(top, gc.cell)
+			removed[gc.index] = true
+		}
+		if len(removed) > 0 {
+			contents := row.Contents[:0]
+			for j, cc := range row.Contents {
+				if !removed[j] {
+					contents = append(contents, cc)
+				}
+			}
+			row.Contents = contents
+		}
+
+		if keep.Property == nil {
+			keep.Property = &ctypes.CellProperty{}
+		}
+		keep.Property.GridSpan = nil
+		if c2 > c1 {
+			keep.Property.GridSpan = &ctypes.DecimalNum{Val: c2 - c1 + 1}
+		}
+		if width, ok := t.gridWidth(c1, c2); ok {
+			keep.Property.Width = ctypes.NewTableWidth(width, stypes.TableWidthDxa)
+		}
+
+		if r2 > r1 {
+			if keep == top {
+				keep.Property.VMerge = &ctypes.GenOptStrVal[stypes.MergeCell]{Val: internal.ToPtr(stypes.MergeCellRestart)}
+			} else {
+				moveCellContent(top, keep)
+				keep.Property.VMerge = &ctypes.GenOptStrVal[stypes.MergeCell]{}
+			}
+		}
+	}
+	return nil
+}
+
+// moveCellContent appends the content of src to dst, dropping empty paragraphs. A cell
+// keeps at least one paragraph, so src is left with an empty one.
+func moveCellContent(dst, src *ctypes.Cell) {
+	var moved []ctypes.TCBlockContent
+	for _, block := range src.Contents {
+		if block.Paragraph != nil && isEmptyParagraph(block.Paragraph) {
+			continue
+		}
+		moved = append(moved, block)
+	}
+	src.Contents = []ctypes.TCBlockContent{{Paragraph: &ctypes.Paragraph{}}}
+	if len(moved) == 0 {
+		return
+	}
+
+	if len(dst.Contents) == 1 && dst.Contents[0].Paragraph != nil && isEmptyParagraph(dst.Contents[0].Paragraph) {
+		dst.Contents = nil
+	}
+	dst.Contents = append(dst.Contents, moved...)
+}
+
+// isEmptyParagraph reports whether a paragraph shows neither text nor images.
+func isEmptyParagraph(p *ctypes.Paragraph) bool {
+	return paragraphText(p) == "" && len(paragraphImages(p)) == 0
+}
+
+// gridWidth returns the width in twips of grid columns c1 to c2, if the grid gives all
+// of them.
+func (t *Table) gridWidth(c1, c2 int) (int, bool) {
+	if c2 >= len(t.ct.Grid.Col) {
+		return 0, false
+	}
+	width := 0
+	for _, col := range t.ct.Grid.Col[c1 : c2+1] {
+		if col.Width == nil {
+			return 0, false
+		}
+		width += int(*col.Width)
+	}
+	return width, true
+}
+
+// ColumnWidths sets the widths of the grid columns in twips and sizes every cell to the
+// columns it spans. The table width becomes the sum of the widths.
+func (t *Table) ColumnWidths(widths ...int) *Table {
+	t.ct.Grid.Col = make([]ctypes.Column, len(widths))
+	total := 0
+	for i, w := range widths {
+		t.ct.Grid.Col[i] = ctypes.Column{Width: internal.ToPtr(uint64(w))}
+		total += w
+	}
+	t.Width(total, stypes.TableWidthDxa)
+
+	for _, row := range t.Rows() {
+		for _, gc := range rowGridCells(row.ct) {
+			if width, ok := t.gridWidth(gc.col, gc.col+gc.span-1); ok {
+				if gc.cell.Property == nil {
+					gc.cell.Property = &ctypes.CellProperty{}
+				}
+				gc.cell.Property.Width = ctypes.NewTableWidth(width, stypes.TableWidthDxa)
+			}
+		}
+	}
+	return t
+}
+
+// SetColumnWidth sets the width of grid column col. A dxa width is in twips; a pct width is
+// in fiftieths of a percent of the table width, 5000 being the full width. The cells of
+// the column take the width, and the grid column the width in twips.
+func (t *Table) SetColumnWidth(col, width int, unit stypes.TableWidth) *Table {
+	cols := max(t.gridCols(), col+1)
+	for len(t.ct.Grid.Col) < cols {
+		t.ct.Grid.Col = append(t.ct.Grid.Col, ctypes.Column{})
+	}
+
+	twips := width
+	if unit == stypes.TableWidthPct {
+		twips = t.widthTwips() * width / 5000
+	}
+	t.ct.Grid.Col[col].Width = internal.ToPtr(uint64(twips))
+
+	for _, row := range t.Rows() {
+		for _, gc := range rowGridCells(row.ct) {
+			if gc.col != col || gc.span != 1 {
+				continue
+			}
+			if gc.cell.Property == nil {
+				gc.cell.Property = &ctypes.CellProperty{}
+			}
+			gc.cell.Property.Width = ctypes.NewTableWidth(width, unit)
+		}
+	}
+	return t
+}
+
+// widthTwips returns the width of the table in twips: its preferred width when given in
+// twips or percent, else the text width of the page.
+func (t *Table) widthTwips() int {
+	textWidth := defaultTextWidth
+	if t.root != nil {
+		if w := int(float64(t.root.textWidth()) * 1440); w > 0 {
+			textWidth = w
+		}
+	}
+
+	tblW := t.ct.TableProp.Width
+	if tblW == nil || tblW.Width == nil || tblW.WidthType == nil {
+		return textWidth
+	}
+	value, err := strconv.Atoi(*tblW.Width)
+	if err != nil || value <= 0 {
+		return textWidth
+	}
+	switch *tblW.WidthType {
+	case stypes.TableWidthDxa:
+		return value
+	case stypes.TableWidthPct:
+		return textWidth * value / 5000
+	}
+	return textWidth
+}
+
+// FixedLayout switches the table to the fixed layout algorithm, where columns keep their
+// widths whatever the content. Without widths, the width of the table is shared equally
+// between the grid columns.
+func (t *Table) FixedLayout(widths ...int) *Table {
+	if len(widths) == 0 {
+		cols := t.gridCols()
+		if cols == 0 {
+			return t.Layout(stypes.TableLayoutFixed)
+		}
+		total := t.widthTwips()
+		widths = make([]int, cols)
+		for i := range widths {
+			widths[i] = total / cols
+		}
+	}
+	return t.ColumnWidths(widths...).Layout(stypes.TableLayoutFixed)
+}
+
+// AutoFit switches the table to the autofit layout algorithm, where columns follow their
+// content. The grid is sized from an estimate of the longest line of every column, shrunk
+// to the text width of the page when the content is wider; the table and its cells lose
+// their preferred widths.
+func (t *Table) AutoFit() *Table {
+	cols := t.gridCols()
+	widths := make([]int, cols)
+	for i := range widths {
+		widths[i] = autofitMinWidth
+	}
+
+	for _, row := range t.Rows() {
+		for _, gc := range rowGridCells(row.ct) {
+			if gc.cell.Property != nil {
+				gc.cell.Property.Width = nil
+			}
+			// Cells spanning several columns do not decide the width of any of them.
+			if gc.span != 1 {
+				continue
+			}
+			widths[gc.col] = max(widths[gc.col], cellContentWidth(gc.cell))
+		}
+	}
+
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	if limit := t.widthTwips(); total > limit {
+		for i := range widths {
+			widths[i] = max(autofitMinWidth, widths[i]*limit/total)
+		}
+	}
+
+	t.ct.Grid.Col = make([]ctypes.Column, cols)
+	for i, w := range widths {
+		t.ct.Grid.Col[i] = ctypes.Column{Width: internal.ToPtr(uint64(w))}
+	}
+	t.Width(0, stypes.TableWidthAuto)
+	return t.Layout(stypes.TableLayoutAutoFit)
+}
+
+// cellContentWidth estimates the width in twips the longest paragraph of a cell needs on
+// one line.
+func cellContentWidth(c *ctypes.Cell) int {
+	longest := 0
+	for _, block := range c.Contents {
+		if block.Paragraph != nil {
+			longest = max(longest, utf8.RuneCountInString(paragraphText(block.Paragraph)))
+		}
+	}
+	return longest*autofitCharWidth + autofitPadding
+}
@@ -0,0 +1,86 @@
+package docx_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addGridTable adds a table of rows × cols cells labelled "r,c".
+func addGridTable(rd *docx.RootDoc, rows, cols int) *docx.Table {
+	table := rd.AddTable()
+	for r := 0; r < rows; r++ {
+		row := table.AddRow()
+		for c := 0; c < cols; c++ {
+			row.AddCell().AddParagraph(fmt.Sprintf("%d,%d", r, c))
+		}
+	}
+	return table
+}
+
+func TestTableBuilder(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	table := addGridTable(rd, 4, 3)
+	table.FixedLayout(2000, 3000, 4000)
+	require.NoError(t, table.Merge(0, 0, 0, 2))
+	require.NoError(t, table.Merge(1, 0, 3, 0))
+	table.HeaderRows(1)
+	table.Rows()[1].Height(600, stypes.HeightRuleAtLeast).CantSplit(true)
+
+	assert.Error(t, table.Merge(0, 1, 1, 1), "cuts through the title cell")
+	assert.Error(t, table.Merge(2, 0, 2, 1), "overlaps the row label")
+	assert.Error(t, table.Merge(0, 0, 4, 0), "outside the table")
+
+	rows := table.Rows()
+	require.Len(t, rows[0].Cells(), 1)
+	title := rows[0].Cells()[0].GetCT()
+	assert.Equal(t, 3, title.Property.GridSpan.Val)
+	assert.Equal(t, "9000", *title.Property.Width.Width)
+	require.Len(t, title.Contents, 3)
+
+	label := rows[1].Cells()[0].GetCT()
+	assert.Equal(t, stypes.MergeCellRestart, *label.Property.VMerge.Val)
+	require.Len(t, label.Contents, 3)
+	continued := rows[3].Cells()[0].GetCT()
+	assert.Nil(t, continued.Property.VMerge.Val)
+	require.Len(t, continued.Contents, 1)
+	assert.Empty(t, continued.Contents[0].Paragraph.Children)
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	documentXML := string(zipParts(t, buf.Bytes())["word/document.xml"])
+	assert.Contains(t, documentXML, `<w:tblLayout w:type="fixed">`)
+	assert.Contains(t, documentXML, `<w:gridCol w:w="2000"></w:gridCol><w:gridCol w:w="3000"></w:gridCol><w:gridCol w:w="4000"></w:gridCol>`)
+	assert.Contains(t, documentXML, `<w:gridSpan w:val="3"></w:gridSpan>`)
+	assert.Contains(t, documentXML, `<w:vMerge w:val="restart"></w:vMerge>`)
+	assert.Equal(t, 2, strings.Count(documentXML, `<w:vMerge></w:vMerge>`))
+	assert.Contains(t, documentXML, `<w:tblHeader></w:tblHeader>`)
+	assert.Contains(t, documentXML, `<w:trHeight w:val="600" w:hRule="atLeast">`)
+
+	table.SetColumnWidth(2, 2500, stypes.TableWidthPct)
+	assert.Equal(t, uint64(4500), *table.GetCT().Grid.Col[2].Width)
+
+	fit := addGridTable(rd, 2, 2)
+	fit.Rows()[0].Cells()[1].AddParagraph(strings.Repeat("wide ", 10))
+	fit.AutoFit()
+	grid := fit.GetCT().Grid.Col
+	require.Len(t, grid, 2)
+	assert.Equal(t, uint64(3*110+216), *grid[0].Width)
+	assert.Equal(t, uint64(50*110+216), *grid[1].Width)
+	assert.Equal(t, stypes.TableLayoutAutoFit, *fit.GetCT().TableProp.Layout.LayoutType)
+
+	// Cell setters add the cell properties when the cell has none.
+	cell := fit.Rows()[1].Cells()[0]
+	cell.GetCT().Property = nil
+	cell.VerticalAlign("middle")
+	assert.Equal(t, stypes.VerticalJcCenter, cell.GetCT().Property.VAlign.Val)
+}
@@ -0,0 +1,481 @@
+package docx
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// TableColumn describes a column of a table built from data.
+type TableColumn struct {
+	Title string
+	Width int                  // Width in twips, zero to share the remaining width
+	Align stypes.Justification // Paragraph alignment of the column's cells
+
+	// Format formats numbers: a fmt verb such as "%.1f", or a pattern such as "#,##0.00"
+	// or "$#,##0" giving the decimals and thousands grouping. Time values take a Go time
+	// layout instead.
+	Format string
+}
+
+// TableDataOptions configure a table built from data. The zero value builds a plain
+// table with a repeated header row.
+type TableDataOptions struct {
+	Style string // Table style ID, such as "LightList-Accent1"
+
+	// Columns sets the width, alignment and number format of the columns of
+	// AddTableFromRecords and AddTableFromCSV by position. AddTableFromStructs reads them
+	// from struct tags instead.
+	Columns []TableColumn
+
+	HeaderBold  bool
+	HeaderFill  string // Header cell background color, RRGGBB
+	HeaderColor string // Header text color, RRGGBB
+
+	BandedRows    bool   // Apply the banded rows of the table style
+	BandedColumns bool   // Apply the banded columns of the table style
+	FirstColumn   bool   // Apply the first column format of the table style
+	BandFill      string // Background color of every second data row, RRGGBB, for tables without a style
+
+	// Totals adds a last row holding the sum of every numeric column, labelled
+	// TotalsLabel ("Total" by default) in the first column.
+	Totals      bool
+	TotalsLabel string
+	TotalsBold  bool
+}
+
+// AddTableFromRecords adds a table with a header row of headers and one row per record.
+// Records shorter than the headers leave their last cells empty.
+//
+// Example:
+//
+//	table, err := document.AddTableFromRecords(
+//		[]string{"Item", "Qty", "Price"},
+//		[][]string{{"Widget", "2", "10.50"}, {"Gadget", "1", "99.00"}},
+//		&docx.TableDataOptions{Style: "LightList-Accent1", BandedRows: true, Totals: true},
+//	)
+func (rd *RootDoc) AddTableFromRecords(headers []string, rows [][]string, opts *TableDataOptions) (*Table, error) {
+	if len(headers) == 0 {
+		return nil, errors.New("a table needs at least one column")
+	}
+	if opts == nil {
+		opts = &TableDataOptions{}
+	}
+
+	columns := make([]TableColumn, len(headers))
+	for i, title := range headers {
+		if i < len(opts.Columns) {
+			columns[i] = opts.Columns[i]
+		}
+		columns[i].Title = title
+	}
+
+	cells := make([][]string, len(rows))
+	for r, record := range rows {
+		if len(record) > len(headers) {
+			return nil, fmt.Errorf("record %d has %d fields for %d columns", r, len(record), len(headers))
+		}
+		cells[r] = make([]string, len(headers))
+		copy(cells[r], record)
+	}
+
+	var totals []string
+	if opts.Totals {
+		totals = make([]string, len(columns))
+		for c, col := range columns {
+			totals[c] = sumTextColumn(cells, c, col.Format)
+		}
+	}
+	return rd.addDataTable(columns, cells, totals, opts), nil
+}
+
+// AddTableFromCSV adds a table from CSV data whose first record holds the column titles.
+func (rd *RootDoc) AddTableFromCSV(r io.Reader, opts *TableDataOptions) (*Table, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("CSV data has no header record")
+	}
+	return rd.AddTableFromRecords(records[0], records[1:], opts)
+}
+
+// AddTableFromStructs adds a table with one row per element of rows, a slice of structs or
+// struct pointers, and one column per exported field. The docx struct tag sets the column
+// title and, after semicolons, its width in twips, alignment and format:
+//
+//	type Line struct {
+//		Item  string  `docx:"Item;width=3600"`
+//		Price float64 `docx:"Unit price;align=right;format=#,##0.00"`
+//		SKU   string  `docx:"-"` // Not shown
+//	}
+//
+// Fields without a tag use the field name as title. Totals sums the numeric fields.
+func (rd *RootDoc) AddTableFromStructs(rows any, opts *TableDataOptions) (*Table, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("rows must be a slice of structs, got %T", rows)
+	}
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("rows must be a slice of structs, got %T", rows)
+	}
+	if opts == nil {
+		opts = &TableDataOptions{}
+	}
+
+	columns, fields, err := structColumns(elemType)
+	if err != nil {
+		return nil, err
+	}
+
+	cells := make([][]string, v.Len())
+	sums := make([]float64, len(fields))
+	numeric := make([]bool, len(fields))
+	for c, field := range fields {
+		numeric[c] = isNumericKind(elemType.FieldByIndex(field).Type.Kind())
+	}
+	for r := range cells {
+		elem := v.Index(r)
+		if elem.Kind() == reflect.Pointer {
+			if elem.IsNil() {
+				return nil, fmt.Errorf("row %d is nil", r)
+			}
+			elem = elem.Elem()
+		}
+
+		cells[r] = make([]string, len(fields))
+		for c, field := range fields {
+			// A field promoted through a nil embedded pointer is left empty.
+			value, err := elem.FieldByIndexErr(field)
+			if err != nil {
+				continue
+			}
+			cells[r][c] = formatValue(value, columns[c].Format)
+			if numeric[c] {
+				sums[c] += numericValue(value)
+			}
+		}
+	}
+
+	var totals []string
+	if opts.Totals {
+		totals = make([]string, len(columns))
+		for c := range columns {
+			if numeric[c] {
+				totals[c] = formatNumber(sums[c], columns[c].Format)
+			}
+		}
+	}
+	return rd.addDataTable(columns, cells, totals, opts), nil
+}
+
+// structColumns returns the columns of a struct type and the index of the field behind
+// each.
+func structColumns(t reflect.Type) ([]TableColumn, [][]int, error) {
+	var (
+		columns []TableColumn
+		fields  [][]int
+	)
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		tag, ok := field.Tag.Lookup("docx")
+		if tag == "-" {
+			continue
+		}
+
+		col := TableColumn{Title: field.Name}
+		if ok {
+			parts := strings.Split(tag, ";")
+			if parts[0] != "" {
+				col.Title = parts[0]
+			}
+			for _, part := range parts[1:] {
+				key, value, _ := strings.Cut(part, "=")
+				switch strings.TrimSpace(key) {
+				case "width":
+					width, err := strconv.Atoi(value)
+					if err != nil {
+						return nil, nil, fmt.Errorf("field %s: invalid width %q", field.Name, value)
+					}
+					col.Width = width
+				case "align":
+					align, err := stypes.JustificationFromStr(value)
+					if err != nil {
+						return nil, nil, fmt.Errorf("field %s: invalid alignment %q", field.Name, value)
+					}
+					col.Align = align
+				case "format":
+					col.Format = value
+				default:
+					return nil, nil, fmt.Errorf("field %s: unknown tag option %q", field.Name, key)
+				}
+			}
+		}
+		columns = append(columns, col)
+		fields = append(fields, field.Index)
+	}
+	if len(columns) == 0 {
+		return nil, nil, fmt.Errorf("%s has no exported fields", t)
+	}
+	return columns, fields, nil
+}
+
+// addDataTable adds a table of a header row, the data rows and, if totals is not nil, a
+// totals row.
+func (rd *RootDoc) addDataTable(columns []TableColumn, cells [][]string, totals []string, opts *TableDataOptions) *Table {
+	table := rd.AddTable()
+	if opts.Style != "" {
+		table.Style(opts.Style)
+	}
+	table.Look(TableLook{
+		FirstRow:      true,
+		LastRow:       totals != nil,
+		FirstColumn:   opts.FirstColumn,
+		BandedRows:    opts.BandedRows,
+		BandedColumns: opts.BandedColumns,
+	})
+
+	header := table.AddRow().RepeatHeader(true)
+	for _, col := range columns {
+		cell := header.AddCell()
+		run := dataCellParagraph(cell, col.Align).AddText(col.Title)
+		if opts.HeaderBold {
+			run.Bold(true)
+		}
+		if opts.HeaderColor != "" {
+			run.Color(opts.HeaderColor)
+		}
+		if opts.HeaderFill != "" {
+			cell.BackgroundColor(opts.HeaderFill)
+		}
+	}
+
+	for r, record := range cells {
+		row := table.AddRow()
+		for c, text := range record {
+			cell := row.AddCell()
+			dataCellParagraph(cell, columns[c].Align).AddText(text)
+			if opts.BandFill != "" && opts.Style == "" && r%2 == 1 {
+				cell.BackgroundColor(opts.BandFill)
+			}
+		}
+	}
+
+	if totals != nil {
+		label := opts.TotalsLabel
+		if label == "" {
+			label = "Total"
+		}
+		totals[0] = label
+
+		row := table.AddRow()
+		for c, text := range totals {
+			run := dataCellParagraph(row.AddCell(), columns[c].Align).AddText(text)
+			if opts.TotalsBold {
+				run.Bold(true)
+			}
+		}
+	}
+
+	widths := make([]int, len(columns))
+	fixed, rest, open := false, 0, 0
+	for c, col := range columns {
+		widths[c] = col.Width
+		if col.Width > 0 {
+			fixed = true
+			rest += col.Width
+		} else {
+			open++
+		}
+	}
+	if !fixed {
+		return table.AutoFit()
+	}
+	if open > 0 {
+		share := max(autofitMinWidth, (table.widthTwips()-rest)/open)
+		for c := range widths {
+			if widths[c] == 0 {
+				widths[c] = share
+			}
+		}
+	}
+	return table.ColumnWidths(widths...)
+}
+
+// dataCellParagraph adds the paragraph of a data cell, aligned as its column.
+func dataCellParagraph(cell *Cell, align stypes.Justification) *Paragraph {
+	p := cell.AddEmptyPara()
+	if align != "" {
+		p.Justification(align)
+	}
+	return p
+}
+
+// formatValue formats a struct field for a table cell.
+func formatValue(v reflect.Value, format string) string {
+	if !v.IsValid() {
+		return ""
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		if value.IsZero() {
+			return ""
+		}
+		if format == "" {
+			format = time.DateOnly
+		}
+		return value.Format(format)
+	case fmt.Stringer:
+		return value.String()
+	}
+
+	switch {
+	case isNumericKind(v.Kind()):
+		if format == "" {
+			return fmt.Sprint(v.Interface())
+		}
+		return formatNumber(numericValue(v), format)
+	case v.Kind() == reflect.Bool && format != "":
+		return fmt.Sprintf(format, v.Bool())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// isNumericKind reports whether values of the kind can be summed.
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// numericValue returns the value of a numeric field as float64.
+func numericValue(v reflect.Value) float64 {
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	case v.CanFloat():
+		return v.Float()
+	}
+	return 0
+}
+
+// sumTextColumn returns the formatted sum of column c, or an empty string when a value is
+// not a number. Without a format the sum keeps the most decimals and the thousands
+// grouping of the values.
+func sumTextColumn(cells [][]string, c int, format string) string {
+	var (
+		sum      float64
+		decimals int
+		grouped  bool
+		seen     bool
+	)
+	for _, record := range cells {
+		text := strings.TrimSpace(record[c])
+		if text == "" {
+			continue
+		}
+		plain := strings.ReplaceAll(text, ",", "")
+		value, err := strconv.ParseFloat(plain, 64)
+		if err != nil {
+			return ""
+		}
+		sum += value
+		seen = true
+		grouped = grouped || plain != text
+		if _, frac, ok := strings.Cut(plain, "."); ok {
+			decimals = max(decimals, len(frac))
+		}
+	}
+	if !seen {
+		return ""
+	}
+
+	if format == "" {
+		format = "0"
+		if grouped {
+			format = "#,##0"
+		}
+		if decimals > 0 {
+			format += "." + strings.Repeat("0", decimals)
+		}
+	}
+	return formatNumber(sum, format)
+}
+
+// formatNumber formats v with a fmt verb or a number pattern such as "#,##0.00". The
+// characters before and after the digits of a pattern are kept as they are.
+func formatNumber(v float64, format string) string {
+	if format == "" {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	if strings.HasPrefix(format, "%") {
+		if strings.HasSuffix(format, "d") {
+			return fmt.Sprintf(format, int64(math.Round(v)))
+		}
+		return fmt.Sprintf(format, v)
+	}
+
+	start := strings.IndexAny(format, "#0")
+	end := strings.LastIndexAny(format, "#0")
+	if start < 0 {
+		return format
+	}
+	prefix, pattern, suffix := format[:start], format[start:end+1], format[end+1:]
+
+	decimals := 0
+	if _, frac, ok := strings.Cut(pattern, "."); ok {
+		decimals = strings.Count(frac, "0") + strings.Count(frac, "#")
+	}
+	digits := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+
+	if intPattern, _, _ := strings.Cut(pattern, "."); strings.Contains(intPattern, ",") {
+		var sb strings.Builder
+		for i, d := range intPart {
+			if i > 0 && (len(intPart)-i)%3 == 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteRune(d)
+		}
+		intPart = sb.String()
+	}
+
+	number := intPart
+	if fracPart != "" {
+		number += "." + fracPart
+	}
+	if v < 0 && strings.Trim(digits, "0.") != "" {
+		number = "-" + number
+	}
+	return prefix + number + suffix
+}
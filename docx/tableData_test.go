@@ -0,0 +1,81 @@
+package docx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type invoiceLine struct {
+	Item    string    `docx:"Item;width=3600"`
+	Qty     int       `docx:";align=center"`
+	Price   float64   `docx:"Unit price;align=right;format=#,##0.00"`
+	Shipped time.Time `docx:"Shipped;format=02 Jan 2006"`
+	SKU     string    `docx:"-"`
+	note    string
+}
+
+func TestTableFromData(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	lines := []invoiceLine{
+		{Item: "Widget", Qty: 2, Price: 1250.5, Shipped: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{Item: "Gadget", Qty: 1, Price: 99},
+	}
+	table, err := rd.AddTableFromStructs(lines, &docx.TableDataOptions{
+		Style:      "LightList-Accent1",
+		HeaderBold: true,
+		BandedRows: true,
+		BandFill:   "EEEEEE",
+		Totals:     true,
+		TotalsBold: true,
+	})
+	require.NoError(t, err)
+
+	grid := table.LogicalGrid()
+	assert.Equal(t, [][]string{
+		{"Item", "Qty", "Unit price", "Shipped"},
+		{"Widget", "2", "1,250.50", "01 Mar 2024"},
+		{"Gadget", "1", "99.00", ""},
+		{"Total", "3", "1,349.50", ""},
+	}, grid.Text())
+	assert.Equal(t, "LightList-Accent1", table.GetCT().TableProp.Style.Val)
+	assert.Equal(t, "0460", table.GetCT().TableProp.TableLook.Val)
+	assert.Equal(t, uint64(3600), *table.GetCT().Grid.Col[0].Width)
+	assert.Equal(t, stypes.JustificationRight, table.Rows()[1].Cells()[2].GetCT().Contents[0].Paragraph.Property.Justification.Val)
+
+	// The table style gives the bands, and the header and totals rows are bold each on
+	// their own option.
+	assert.NotEqual(t, "EEEEEE", *table.Rows()[2].Cells()[0].GetCT().Property.Shading.Fill)
+	assert.NotNil(t, table.Rows()[0].Cells()[0].GetCT().Contents[0].Paragraph.Children[0].Run.Property.Bold)
+	assert.NotNil(t, table.Rows()[3].Cells()[0].GetCT().Contents[0].Paragraph.Children[0].Run.Property.Bold)
+
+	_, err = rd.AddTableFromStructs([]int{1}, nil)
+	assert.Error(t, err)
+
+	csvTable, err := rd.AddTableFromCSV(strings.NewReader("Region,Revenue\nNorth,\"1,200.5\"\nSouth,800\n"), &docx.TableDataOptions{
+		BandFill: "EEEEEE",
+		Totals:   true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Total", "2,000.5"}, csvTable.LogicalGrid().Text()[3])
+	assert.Equal(t, "EEEEEE", *csvTable.Rows()[2].Cells()[0].GetCT().Property.Shading.Fill)
+	assert.Nil(t, csvTable.Rows()[3].Cells()[0].GetCT().Contents[0].Paragraph.Children[0].Run.Property)
+	assert.Equal(t, stypes.TableLayoutAutoFit, *csvTable.GetCT().TableProp.Layout.LayoutType)
+
+	_, err = rd.AddTableFromRecords([]string{"A"}, [][]string{{"1", "2"}}, nil)
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	documentXML := string(zipParts(t, buf.Bytes())["word/document.xml"])
+	assert.Contains(t, documentXML, `<w:tblLook w:val="0460" w:firstRow="1" w:lastRow="1" w:firstColumn="0" w:lastColumn="0" w:noHBand="0" w:noVBand="1">`)
+}
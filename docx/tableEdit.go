@@ -0,0 +1,596 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/internal"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// InsertRow inserts an empty row at index at, zero-based, so that it becomes row at; at
+// equal to the number of rows appends the row. The row copies the layout of the row
+// before it, or of the first row when inserted at the top: row and cell properties, cell
+// spans and the paragraph properties of the first paragraph of every cell.
+//
+// Inside a vertically merged cell the new row continues the merge. The row repeats as a
+// header row only when inserted among the leading header rows.
+//
+// Example:
+//
+//	row, _ := table.InsertRow(1)
+//	row.Cells()[0].SetText("Inserted")
+func (t *Table) InsertRow(at int) (*Row, error) {
+	rows := t.rowCTs()
+	if at < 0 || at > len(rows) {
+		return nil, fmt.Errorf("row index %d out of range [0,%d]", at, len(rows))
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("table has no row to copy the layout from")
+	}
+
+	row, err := t.cloneRow(rows[max(at-1, 0)])
+	if err != nil {
+		return nil, err
+	}
+
+	continued := make(map[int]bool)
+	if at > 0 && at < len(rows) {
+		for _, gc := range rowGridCells(rows[at]) {
+			if isVMergeContinue(gc.cell) {
+				continued[gc.col] = true
+			}
+		}
+	}
+	for _, gc := range rowGridCells(row) {
+		blankCell(gc.cell)
+		if gc.cell.Property == nil {
+			continue
+		}
+		gc.cell.Property.VMerge = nil
+		if continued[gc.col] {
+			gc.cell.Property.VMerge = &ctypes.GenOptStrVal[stypes.MergeCell]{}
+		}
+	}
+
+	if row.Property != nil && at >= t.leadingHeaderRows() {
+		row.Property.Header = nil
+	}
+
+	t.setRows(slices.Insert(rows, at, row))
+	return &Row{root: t.root, ct: row}, nil
+}
+
+// CloneRow inserts a deep copy of row index, content and formatting included, right
+// after it and returns the copy. It suits templates with a sample row repeated for every
+// data item: clone the sample row, fill the copy, and delete the sample row at the end.
+//
+// Example:
+//
+//	sample := 1
+//	for i, item := range items {
+//		row, _ := table.CloneRow(sample + i)
+//		row.Cells()[0].SetText(item.Name)
+//	}
+//	table.DeleteRow(sample)
+func (t *Table) CloneRow(index int) (*Row, error) {
+	rows := t.rowCTs()
+	if index < 0 || index >= len(rows) {
+		return nil, fmt.Errorf("row index %d out of range [0,%d)", index, len(rows))
+	}
+
+	row, err := t.cloneRow(rows[index])
+	if err != nil {
+		return nil, err
+	}
+
+	// A copy of a vertically merged cell continues the merge when the cell it was copied
+	// from does not end it, so the merged cell grows by one row.
+	continued := make(map[int]bool)
+	if index+1 < len(rows) {
+		for _, gc := range rowGridCells(rows[index+1]) {
+			if isVMergeContinue(gc.cell) {
+				continued[gc.col] = true
+			}
+		}
+	}
+	for _, gc := range rowGridCells(row) {
+		if isVMerged(gc.cell) && (isVMergeContinue(gc.cell) || continued[gc.col]) {
+			blankCell(gc.cell)
+			gc.cell.Property.VMerge = &ctypes.GenOptStrVal[stypes.MergeCell]{}
+		}
+	}
+
+	t.setRows(slices.Insert(rows, index+1, row))
+	return &Row{root: t.root, ct: row}, nil
+}
+
+// DeleteRow removes row index. A vertically merged cell starting in the row starts in the
+// next row instead, with the content of the removed cell.
+func (t *Table) DeleteRow(index int) error {
+	rows := t.rowCTs()
+	if index < 0 || index >= len(rows) {
+		return fmt.Errorf("row index %d out of range [0,%d)", index, len(rows))
+	}
+
+	if index+1 < len(rows) {
+		for _, gc := range rowGridCells(rows[index]) {
+			if !isVMerged(gc.cell) || isVMergeContinue(gc.cell) {
+				continue
+			}
+			next := cellAt(rows[index+1], gc.col)
+			if next == nil || !isVMergeContinue(next) {
+				continue
+			}
+			next.Property.VMerge = &ctypes.GenOptStrVal[stypes.MergeCell]{Val: internal.ToPtr(stypes.MergeCellRestart)}
+			moveCellContent(next, gc.cell)
+		}
+	}
+
+	t.setRows(slices.Delete(rows, index, index+1))
+	t.tidyVMerge()
+	return nil
+}
+
+// MoveRow moves row from to index to, both zero-based; to is the index of the row after
+// the move.
+//
+// Returns an error if the row has vertically merged cells, or if the move would put it
+// inside a vertically merged cell.
+func (t *Table) MoveRow(from, to int) error {
+	rows := t.rowCTs()
+	if from < 0 || from >= len(rows) || to < 0 || to >= len(rows) {
+		return fmt.Errorf("cannot move row %d to %d in a table of %d rows", from, to, len(rows))
+	}
+	if hasVMerge(rows[from]) {
+		return fmt.Errorf("row %d has vertically merged cells", from)
+	}
+
+	row := rows[from]
+	rows = slices.Delete(rows, from, from+1)
+	if to < len(rows) {
+		for _, gc := range rowGridCells(rows[to]) {
+			if isVMergeContinue(gc.cell) {
+				return fmt.Errorf("moving row %d to %d splits a vertically merged cell", from, to)
+			}
+		}
+	}
+
+	t.setRows(slices.Insert(rows, to, row))
+	return nil
+}
+
+// InsertColumn inserts a grid column at index at, zero-based, so that it becomes grid
+// column at; at equal to the number of grid columns appends the column. The column is
+// width twips wide, or as wide as its left neighbour when width is 0.
+//
+// The table grid and every row are updated: a cell spanning across the position spans one
+// column more, a row skipping the position (gridBefore/gridAfter) skips one column more,
+// and any other row gets a new empty cell with the properties of its neighbour. The
+// table width grows by the column width when given in twips.
+//
+// Example:
+//
+//	table.InsertColumn(1, 1440) // One inch wide column after the first
+func (t *Table) InsertColumn(at, width int) error {
+	cols := t.gridCols()
+	if at < 0 || at > cols {
+		return fmt.Errorf("column index %d out of range [0,%d]", at, cols)
+	}
+
+	if width <= 0 {
+		if neighbour := max(at-1, 0); neighbour < len(t.ct.Grid.Col) && t.ct.Grid.Col[neighbour].Width != nil {
+			width = int(*t.ct.Grid.Col[neighbour].Width)
+		}
+	}
+	column := ctypes.Column{}
+	if width > 0 {
+		column.Width = internal.ToPtr(uint64(width))
+	}
+
+	// Create the new cells first, so a failure leaves the table untouched.
+	rows := t.rowCTs()
+	added := make(map[*ctypes.Row]gridCell)
+	for _, row := range rows {
+		cells := rowGridCells(row)
+		if len(cells) == 0 {
+			continue
+		}
+		// The new cell takes the properties of the cell on its left, or on its right at
+		// the start of the row.
+		var template *gridCell
+		index := -1
+		for i, gc := range cells {
+			if gc.col < at && at < gc.col+gc.span {
+				break
+			}
+			if gc.col+gc.span == at {
+				template, index = &cells[i], gc.index+1
+				break
+			}
+			if gc.col == at {
+				template, index = &cells[i], gc.index
+				break
+			}
+		}
+		if template == nil {
+			continue
+		}
+
+		cell, err := t.cloneCell(template.cell)
+		if err != nil {
+			return err
+		}
+		blankCell(cell)
+		if cell.Property != nil {
+			cell.Property.GridSpan = nil
+			cell.Property.HMerge = nil
+			cell.Property.VMerge = nil
+			cell.Property.Width = nil
+			if width > 0 {
+				cell.Property.Width = ctypes.NewTableWidth(width, stypes.TableWidthDxa)
+			}
+		}
+		added[row] = gridCell{cell: cell, index: index}
+	}
+
+	for len(t.ct.Grid.Col) < at {
+		t.ct.Grid.Col = append(t.ct.Grid.Col, ctypes.Column{})
+	}
+	t.ct.Grid.Col = slices.Insert(t.ct.Grid.Col, at, column)
+	for _, row := range rows {
+		if gc, ok := added[row]; ok {
+			row.Contents = slices.Insert(row.Contents, gc.index, ctypes.TRCellContent{Cell: gc.cell})
+			continue
+		}
+
+		cells := rowGridCells(row)
+		if len(cells) == 0 {
+			continue
+		}
+		switch last := cells[len(cells)-1]; {
+		case at < cells[0].col:
+			if row.Property != nil && row.Property.GridBefore != nil {
+				row.Property.GridBefore.Val++
+				addDxaWidth(row.Property.WidthBefore, width)
+			}
+		case at > last.col+last.span:
+			// A row shorter than the grid without gridAfter stays short.
+			if row.Property != nil && row.Property.GridAfter != nil {
+				row.Property.GridAfter.Val++
+				addDxaWidth(row.Property.WidthAfter, width)
+			}
+		default:
+			for _, gc := range cells {
+				if gc.col < at && at < gc.col+gc.span {
+					gc.cell.Property.GridSpan = &ctypes.DecimalNum{Val: gc.span + 1}
+					addDxaWidth(gc.cell.Property.Width, width)
+				}
+			}
+		}
+	}
+
+	addDxaWidth(t.ct.TableProp.Width, width)
+	return nil
+}
+
+// DeleteColumn removes grid column at, zero-based. A cell spanning several columns spans
+// one column fewer, a row skipping the column (gridBefore/gridAfter) skips one column
+// fewer, and the cells of the column are removed with their content. The table width
+// shrinks by the column width when given in twips.
+//
+// Returns an error if the column is the only column of a row's cells.
+func (t *Table) DeleteColumn(at int) error {
+	cols := t.gridCols()
+	if at < 0 || at >= cols {
+		return fmt.Errorf("column index %d out of range [0,%d)", at, cols)
+	}
+
+	// Find the cell of every row in the column first, so a failure leaves the table
+	// untouched.
+	rows := t.rowCTs()
+	hits := make(map[*ctypes.Row]gridCell)
+	for r, row := range rows {
+		cells := rowGridCells(row)
+		for _, gc := range cells {
+			if at < gc.col || at >= gc.col+gc.span {
+				continue
+			}
+			if len(cells) == 1 && gc.span == 1 {
+				return fmt.Errorf("row %d has no cell outside column %d", r, at)
+			}
+			hits[row] = gc
+			break
+		}
+	}
+
+	width := 0
+	if at < len(t.ct.Grid.Col) {
+		if w := t.ct.Grid.Col[at].Width; w != nil {
+			width = int(*w)
+		}
+		t.ct.Grid.Col = slices.Delete(t.ct.Grid.Col, at, at+1)
+	}
+
+	for _, row := range rows {
+		gc, ok := hits[row]
+		if !ok {
+			cells := rowGridCells(row)
+			// A row shorter than the grid without trPr has no gridAfter to shrink.
+			if len(cells) == 0 || row.Property == nil {
+				continue
+			}
+			if at < cells[0].col {
+				decrementGrid(&row.Property.GridBefore)
+				addDxaWidth(row.Property.WidthBefore, -width)
+			} else {
+				decrementGrid(&row.Property.GridAfter)
+				addDxaWidth(row.Property.WidthAfter, -width)
+			}
+			continue
+		}
+
+		if gc.span == 1 {
+			row.Contents = slices.Delete(row.Contents, gc.index, gc.index+1)
+			continue
+		}
+		gc.cell.Property.GridSpan = nil
+		if gc.span > 2 {
+			gc.cell.Property.GridSpan = &ctypes.DecimalNum{Val: gc.span - 1}
+		}
+		addDxaWidth(gc.cell.Property.Width, -width)
+	}
+
+	addDxaWidth(t.ct.TableProp.Width, -width)
+	return nil
+}
+
+// SortRows sorts the rows below the leading header rows by the text of grid column col.
+// The sort is stable. With a nil cmp, two numbers compare by value and any other texts
+// by string; cmp otherwise returns a negative number when a sorts before b, a positive
+// number when after, and zero when equal.
+//
+// Returns an error if the sorted rows have vertically merged cells.
+//
+// Example:
+//
+//	table.SortRows(2, nil) // By the third column, numerically
+//	table.SortRows(0, func(a, b string) int {
+//		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+//	})
+func (t *Table) SortRows(col int, cmp func(a, b string) int) error {
+	grid := t.LogicalGrid()
+	if col < 0 || col >= grid.Cols {
+		return fmt.Errorf("column index %d out of range [0,%d)", col, grid.Cols)
+	}
+	if cmp == nil {
+		cmp = compareCellText
+	}
+
+	rows := t.rowCTs()
+	headerRows := t.leadingHeaderRows()
+	for r := headerRows; r < len(rows); r++ {
+		if hasVMerge(rows[r]) {
+			return fmt.Errorf("row %d has vertically merged cells", r)
+		}
+	}
+
+	order := make([]int, 0, len(rows)-headerRows)
+	for r := headerRows; r < len(rows); r++ {
+		order = append(order, r)
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp(grid.Cells[a][col].Text, grid.Cells[b][col].Text)
+	})
+
+	sorted := slices.Clone(rows[:headerRows])
+	for _, r := range order {
+		sorted = append(sorted, rows[r])
+	}
+	t.setRows(sorted)
+	return nil
+}
+
+// SetText replaces the content of the cell with one paragraph of text, keeping the
+// paragraph properties of the first paragraph and the run properties of its first run.
+func (c *Cell) SetText(text string) *Cell {
+	var (
+		pPr *ctypes.ParagraphProp
+		rPr *ctypes.RunProperty
+	)
+	for _, block := range c.ct.Contents {
+		if block.Paragraph == nil {
+			continue
+		}
+		pPr = block.Paragraph.Property
+		for _, child := range block.Paragraph.Children {
+			if child.Run != nil && child.Run.Property != nil {
+				rPr = child.Run.Property
+				break
+			}
+		}
+		break
+	}
+
+	p := &ctypes.Paragraph{Property: pPr}
+	p.AddText(text).Property = rPr
+	c.ct.Contents = []ctypes.TCBlockContent{{Paragraph: p}}
+	return c
+}
+
+// rowCTs returns the rows of the table.
+func (t *Table) rowCTs() []*ctypes.Row {
+	var rows []*ctypes.Row
+	for _, rc := range t.ct.RowContents {
+		if rc.Row != nil {
+			rows = append(rows, rc.Row)
+		}
+	}
+	return rows
+}
+
+// setRows replaces the rows of the table.
+func (t *Table) setRows(rows []*ctypes.Row) {
+	t.ct.RowContents = make([]ctypes.RowContent, len(rows))
+	for i, row := range rows {
+		t.ct.RowContents[i] = ctypes.RowContent{Row: row}
+	}
+}
+
+// leadingHeaderRows returns the number of rows at the start of the table marked to repeat
+// as header rows.
+func (t *Table) leadingHeaderRows() int {
+	n := 0
+	for _, row := range t.rowCTs() {
+		if row.Property == nil || !onOffValue(row.Property.Header) {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// cloneRow returns a deep copy of a row of the table. Paragraph identifiers are dropped,
+// as they must be unique within the document.
+func (t *Table) cloneRow(row *ctypes.Row) (*ctypes.Row, error) {
+	tbl := &Table{root: t.root, ct: ctypes.Table{
+		TableProp:   t.ct.TableProp,
+		Grid:        t.ct.Grid,
+		RowContents: []ctypes.RowContent{{Row: row}},
+	}}
+	children, err := cloneChildren(t.root, []DocumentChild{{Table: tbl}})
+	if err != nil {
+		return nil, err
+	}
+	if len(children) != 1 || children[0].Table == nil || len(children[0].Table.ct.RowContents) != 1 {
+		return nil, errors.New("failed to copy table row")
+	}
+
+	clone := children[0].Table.ct.RowContents[0].Row
+	clone.ParaID, clone.TextId = nil, nil
+	for _, gc := range rowGridCells(clone) {
+		for _, block := range gc.cell.Contents {
+			if block.Paragraph != nil {
+				block.Paragraph.ParaID, block.Paragraph.TextId = nil, nil
+			}
+		}
+	}
+	return clone, nil
+}
+
+// cloneCell returns a deep copy of a cell of the table.
+func (t *Table) cloneCell(cell *ctypes.Cell) (*ctypes.Cell, error) {
+	row, err := t.cloneRow(&ctypes.Row{Contents: []ctypes.TRCellContent{{Cell: cell}}})
+	if err != nil {
+		return nil, err
+	}
+	cells := rowGridCells(row)
+	if len(cells) != 1 {
+		return nil, errors.New("failed to copy table cell")
+	}
+	return cells[0].cell, nil
+}
+
+// blankCell replaces the content of a cell with one empty paragraph, which keeps the
+// paragraph properties of the first paragraph.
+func blankCell(c *ctypes.Cell) {
+	p := &ctypes.Paragraph{}
+	for _, block := range c.Contents {
+		if block.Paragraph != nil {
+			p.Property = block.Paragraph.Property
+			break
+		}
+	}
+	c.Contents = []ctypes.TCBlockContent{{Paragraph: p}}
+}
+
+// cellAt returns the cell of a row starting at grid column col, or nil.
+func cellAt(row *ctypes.Row, col int) *ctypes.Cell {
+	for _, gc := range rowGridCells(row) {
+		if gc.col == col {
+			return gc.cell
+		}
+	}
+	return nil
+}
+
+// hasVMerge reports whether a row has vertically merged cells.
+func hasVMerge(row *ctypes.Row) bool {
+	for _, gc := range rowGridCells(row) {
+		if isVMerged(gc.cell) {
+			return true
+		}
+	}
+	return false
+}
+
+// tidyVMerge repairs vertical merges after rows were removed: a continuation cell without a
+// merged cell above it starts the merge, and a merge of one row is no merge.
+func (t *Table) tidyVMerge() {
+	rows := t.rowCTs()
+	for r, row := range rows {
+		for _, gc := range rowGridCells(row) {
+			if !isVMerged(gc.cell) {
+				continue
+			}
+			if isVMergeContinue(gc.cell) {
+				if r > 0 {
+					if above := cellAt(rows[r-1], gc.col); above != nil && isVMerged(above) {
+						continue
+					}
+				}
+				gc.cell.Property.VMerge = &ctypes.GenOptStrVal[stypes.MergeCell]{Val: internal.ToPtr(stypes.MergeCellRestart)}
+			}
+			if r+1 < len(rows) {
+				if below := cellAt(rows[r+1], gc.col); below != nil && isVMergeContinue(below) {
+					continue
+				}
+			}
+			gc.cell.Property.VMerge = nil
+		}
+	}
+}
+
+// decrementGrid lowers a gridBefore or gridAfter count by one, removing it at zero.
+func decrementGrid(n **ctypes.DecimalNum) {
+	if *n == nil {
+		return
+	}
+	(*n).Val--
+	if (*n).Val <= 0 {
+		*n = nil
+	}
+}
+
+// addDxaWidth adds delta twips to a width given in twips; other widths are left alone.
+func addDxaWidth(w *ctypes.TableWidth, delta int) {
+	if w == nil || w.Width == nil || w.WidthType == nil || *w.WidthType != stypes.TableWidthDxa || delta == 0 {
+		return
+	}
+	value, err := strconv.Atoi(*w.Width)
+	if err != nil {
+		return
+	}
+	*w.Width = strconv.Itoa(max(value+delta, 0))
+}
+
+// compareCellText compares two cell texts as numbers when both are numbers, ignoring
+// thousands separators, and as strings otherwise.
+func compareCellText(a, b string) int {
+	x, errA := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(a), ",", ""), 64)
+	y, errB := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(b), ",", ""), 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,166 @@
+package docx_test
+
+import (
+	"bytes"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableRows(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	table := addGridTable(rd, 4, 2)
+	table.HeaderRows(1)
+	require.NoError(t, table.Merge(1, 0, 2, 0))
+
+	// The new row continues the merged cell above it.
+	row, err := table.InsertRow(2)
+	require.NoError(t, err)
+	assert.Nil(t, row.GetCT().Property.Header)
+	assert.Equal(t, [][]string{
+		{"0,0", "0,1"},
+		{"1,0\n2,0", "1,1"},
+		{"1,0\n2,0", ""},
+		{"1,0\n2,0", "2,1"},
+		{"3,0", "3,1"},
+	}, table.LogicalGrid().Text())
+
+	// Removing the first row of a merged cell moves its start and content down.
+	require.NoError(t, table.DeleteRow(1))
+	require.NoError(t, table.DeleteRow(1))
+	assert.Equal(t, [][]string{
+		{"0,0", "0,1"},
+		{"1,0\n2,0", "2,1"},
+		{"3,0", "3,1"},
+	}, table.LogicalGrid().Text())
+	assert.Nil(t, table.Rows()[1].Cells()[0].GetCT().Property.VMerge, "a merge of one row is dropped")
+
+	// The sample row keeps its formatting in every copy.
+	sample := table.Rows()[2]
+	sample.Cells()[0].GetCT().Contents = nil
+	p := sample.Cells()[0].AddEmptyPara()
+	p.Justification(stypes.JustificationRight)
+	p.AddText("sample").Bold(true)
+	for i, item := range []string{"a", "b"} {
+		row, err := table.CloneRow(2 + i)
+		require.NoError(t, err)
+		row.Cells()[0].SetText(item)
+	}
+	require.NoError(t, table.DeleteRow(2))
+	require.NoError(t, table.MoveRow(3, 1))
+	assert.Equal(t, [][]string{
+		{"0,0", "0,1"},
+		{"b", "3,1"},
+		{"1,0\n2,0", "2,1"},
+		{"a", "3,1"},
+	}, table.LogicalGrid().Text())
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	content := buf.Bytes()
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+	tables := reopened.ExtractTables()
+	require.Len(t, tables, 1)
+	copied := tables[0].Table.Rows()[1].Cells()[0].GetCT().Contents[0].Paragraph
+	assert.Equal(t, stypes.JustificationRight, copied.Property.Justification.Val)
+	require.Len(t, copied.Children, 1)
+	assert.NotNil(t, copied.Children[0].Run.Property.Bold)
+
+	require.NoError(t, table.SortRows(1, nil))
+	assert.Equal(t, []string{"0,1", "2,1", "3,1", "3,1"}, columnText(table, 1))
+	require.NoError(t, table.SortRows(0, func(a, b string) int { return len(b) - len(a) }))
+	assert.Equal(t, []string{"0,0", "1,0\n2,0", "b", "a"}, columnText(table, 0))
+
+	_, err = table.InsertRow(5)
+	assert.Error(t, err)
+	assert.Error(t, table.DeleteRow(-1))
+}
+
+func TestTableColumns(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	table := addGridTable(rd, 3, 3)
+	table.ColumnWidths(1000, 2000, 3000)
+	require.NoError(t, table.Merge(0, 0, 0, 1))
+	require.NoError(t, table.Merge(1, 2, 2, 2))
+
+	require.NoError(t, table.InsertColumn(1, 500))
+	assert.Equal(t, [][]string{
+		{"0,0\n0,1", "0,0\n0,1", "0,0\n0,1", "0,2"},
+		{"1,0", "", "1,1", "1,2\n2,2"},
+		{"2,0", "", "2,1", "1,2\n2,2"},
+	}, table.LogicalGrid().Text())
+	title := table.Rows()[0].Cells()[0].GetCT()
+	assert.Equal(t, 3, title.Property.GridSpan.Val)
+	assert.Equal(t, "3500", *title.Property.Width.Width)
+	assert.Equal(t, "500", *table.Rows()[1].Cells()[1].GetCT().Property.Width.Width)
+	assert.Equal(t, "6500", *table.GetCT().TableProp.Width.Width)
+	require.Len(t, table.GetCT().Grid.Col, 4)
+	assert.Equal(t, uint64(500), *table.GetCT().Grid.Col[1].Width)
+
+	// Appending copies the width of the last column and extends the merged cell's rows.
+	require.NoError(t, table.InsertColumn(4, 0))
+	assert.Equal(t, uint64(3000), *table.GetCT().Grid.Col[4].Width)
+	assert.Equal(t, []string{"", "", ""}, columnText(table, 4))
+
+	require.NoError(t, table.DeleteColumn(0))
+	require.NoError(t, table.DeleteColumn(3))
+	assert.Equal(t, [][]string{
+		{"0,0\n0,1", "0,0\n0,1", "0,2"},
+		{"", "1,1", "1,2\n2,2"},
+		{"", "2,1", "1,2\n2,2"},
+	}, table.LogicalGrid().Text())
+	assert.Equal(t, "2500", *title.Property.Width.Width)
+	assert.Equal(t, "5500", *table.GetCT().TableProp.Width.Width)
+
+	single := addGridTable(rd, 1, 1)
+	assert.Error(t, single.DeleteColumn(0))
+	assert.Error(t, table.InsertColumn(4, 0))
+}
+
+func TestTableColumnsShortRow(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	// The second row has two cells for three grid columns and no row properties.
+	table := addGridTable(rd, 2, 3)
+	table.ColumnWidths(1000, 2000, 3000)
+	short := table.Rows()[1].GetCT()
+	short.Contents = short.Contents[:2]
+	short.Property = nil
+
+	require.NoError(t, table.InsertColumn(3, 0))
+	assert.Len(t, table.Rows()[1].Cells(), 2)
+	require.NoError(t, table.DeleteColumn(3))
+	require.NoError(t, table.DeleteColumn(2))
+	assert.Equal(t, [][]string{
+		{"0,0", "0,1"},
+		{"1,0", "1,1"},
+	}, table.LogicalGrid().Text())
+	assert.Len(t, table.GetCT().Grid.Col, 2)
+
+	// A failure leaves the grid and the rows as they were.
+	single := table.AddRow()
+	single.AddCell().AddParagraph("only")
+	assert.Error(t, table.DeleteColumn(0))
+	assert.Len(t, table.GetCT().Grid.Col, 2)
+	assert.Len(t, table.Rows()[0].Cells(), 2)
+}
+
+// columnText returns the text of grid column col of every row.
+func columnText(table *docx.Table, col int) []string {
+	var text []string
+	for _, row := range table.LogicalGrid().Text() {
+		text = append(text, row[col])
+	}
+	return text
+}
@@ -0,0 +1,187 @@
+package docx
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// ToCSV writes the table as CSV, one record per row and one field per grid column. A
+// merged cell is written once, at its top-left position, and the positions it covers are
+// left empty, as spreadsheets store merged cells.
+//
+// Example:
+//
+//	f, _ := os.Create("table.csv")
+//	defer f.Close()
+//	err := table.ToCSV(f)
+func (t *Table) ToCSV(w io.Writer) error {
+	grid := t.LogicalGrid()
+	cw := csv.NewWriter(w)
+	for _, row := range grid.Cells {
+		record := make([]string, grid.Cols)
+		for c, cell := range row {
+			if cell.IsOrigin() {
+				record[c] = cell.Text
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// RecordKeys returns the keys ToRecords uses: per grid column, the texts of the header
+// rows joined by " / ", repeated texts of cells merged across header rows counted once.
+// Columns without header text are named "Column N"; repeated keys get a " (N)" suffix.
+//
+// headerRows is the number of header rows. Zero takes the rows marked to repeat as
+// header rows (tblHeader), or the first row when none is marked.
+func (t *Table) RecordKeys(headerRows int) ([]string, error) {
+	grid := t.LogicalGrid()
+	headerRows = t.headerRowCount(headerRows)
+	if headerRows > grid.Rows {
+		return nil, fmt.Errorf("table has %d rows, fewer than %d header rows", grid.Rows, headerRows)
+	}
+	return recordKeys(grid, headerRows), nil
+}
+
+// ToRecords returns the rows below the header rows as records keyed by RecordKeys. Merged
+// cells give their text to every row and column they cover.
+//
+// Example:
+//
+//	records, _ := table.ToRecords(0)
+//	for _, record := range records {
+//		fmt.Println(record["Item"], record["Price / Net"])
+//	}
+func (t *Table) ToRecords(headerRows int) ([]map[string]string, error) {
+	grid := t.LogicalGrid()
+	headerRows = t.headerRowCount(headerRows)
+	if headerRows > grid.Rows {
+		return nil, fmt.Errorf("table has %d rows, fewer than %d header rows", grid.Rows, headerRows)
+	}
+
+	keys := recordKeys(grid, headerRows)
+	records := make([]map[string]string, 0, grid.Rows-headerRows)
+	for _, row := range grid.Cells[headerRows:] {
+		record := make(map[string]string, len(keys))
+		for c, cell := range row {
+			record[keys[c]] = cell.Text
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// headerRowCount returns the number of header rows to use for a requested count.
+func (t *Table) headerRowCount(headerRows int) int {
+	if headerRows > 0 {
+		return headerRows
+	}
+
+	return max(t.leadingHeaderRows(), 1)
+}
+
+// recordKeys flattens the first headerRows rows of a grid into one key per column.
+func recordKeys(grid *LogicalGrid, headerRows int) []string {
+	keys := make([]string, grid.Cols)
+	seen := make(map[string]int, grid.Cols)
+	for c := range keys {
+		var parts []string
+		var last *GridCell
+		for r := 0; r < headerRows; r++ {
+			cell := &grid.Cells[r][c]
+			text := strings.Join(strings.Fields(cell.Text), " ")
+			// A cell merged down the header rows names the column once.
+			if text == "" || (last != nil && last.OriginRow == cell.OriginRow && last.OriginCol == cell.OriginCol) {
+				continue
+			}
+			parts = append(parts, text)
+			last = cell
+		}
+
+		key := strings.Join(parts, " / ")
+		if key == "" {
+			key = fmt.Sprintf("Column %d", c+1)
+		}
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			key = fmt.Sprintf("%s (%d)", key, n)
+		}
+		keys[c] = key
+	}
+	return keys
+}
+
+// ExtractedTable is a table of the document body with where it stands.
+type ExtractedTable struct {
+	Table     *Table
+	Index     int    // Zero-based position among the tables of the body
+	BodyIndex int    // Index of the table in the body children
+	Section   int    // Zero-based index of the section holding the table
+	Caption   string // Text of the nearest Caption paragraph before the table, if any
+}
+
+// ExtractTables returns the tables of the document body in document order. The caption of
+// a table is the nearest paragraph styled Caption before it, looking back no further than
+// the previous table. Tables nested in cells are part of the text of their cell.
+//
+// Example:
+//
+//	for _, extracted := range document.ExtractTables() {
+//		records, _ := extracted.Table.ToRecords(0)
+//		fmt.Println(extracted.Caption, len(records))
+//	}
+func (rd *RootDoc) ExtractTables() []ExtractedTable {
+	if rd.Document == nil || rd.Document.Body == nil {
+		return nil
+	}
+
+	var (
+		tables  []ExtractedTable
+		caption string
+		section int
+	)
+	for i, child := range rd.Document.Body.Children {
+		switch {
+		case child.Table != nil:
+			tables = append(tables, ExtractedTable{
+				Table:     child.Table,
+				Index:     len(tables),
+				BodyIndex: i,
+				Section:   section,
+				Caption:   caption,
+			})
+			caption = ""
+		case child.Para != nil:
+			if rd.isCaption(child.Para) {
+				caption = child.Para.Text()
+			}
+			if childSectPr(child) != nil {
+				section++
+			}
+		}
+	}
+	return tables
+}
+
+// isCaption reports whether the paragraph uses the built-in Caption style.
+func (rd *RootDoc) isCaption(p *Paragraph) bool {
+	if p.ct.Property == nil || p.ct.Property.Style == nil {
+		return false
+	}
+
+	styleID := p.ct.Property.Style.Val
+	if styleID == "Caption" {
+		return true
+	}
+
+	style := rd.GetStyleByID(styleID, stypes.StyleTypeParagraph)
+	return style != nil && style.Name != nil && strings.EqualFold(style.Name.Val, "caption")
+}
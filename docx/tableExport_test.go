@@ -0,0 +1,59 @@
+package docx_test
+
+import (
+	"bytes"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableExport(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	rd.AddParagraph("Line items").Style("Caption")
+	invoice := rd.AddTable()
+	for _, texts := range [][]string{
+		{"Item", "Price", ""},
+		{"", "Net", "Gross"},
+		{"Widget", "10", "12"},
+		{"Gadget, large", "20", "24"},
+	} {
+		row := invoice.AddRow()
+		for _, text := range texts {
+			row.AddCell().AddParagraph(text)
+		}
+	}
+	require.NoError(t, invoice.Merge(0, 1, 0, 2))
+	require.NoError(t, invoice.Merge(0, 0, 1, 0))
+	invoice.HeaderRows(2)
+
+	rd.AddParagraph("Unrelated text")
+	rd.AddTable().AddRow().AddCell().AddParagraph("Notes")
+
+	keys, err := invoice.RecordKeys(0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Item", "Price / Net", "Price / Gross"}, keys)
+
+	records, err := invoice.ToRecords(0)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, map[string]string{"Item": "Gadget, large", "Price / Net": "20", "Price / Gross": "24"}, records[1])
+
+	_, err = invoice.ToRecords(5)
+	assert.Error(t, err)
+
+	var csvOut bytes.Buffer
+	require.NoError(t, invoice.ToCSV(&csvOut))
+	assert.Equal(t, "Item,Price,\n,Net,Gross\nWidget,10,12\n\"Gadget, large\",20,24\n", csvOut.String())
+
+	tables := rd.ExtractTables()
+	require.Len(t, tables, 2)
+	assert.Equal(t, "Line items", tables[0].Caption)
+	assert.Equal(t, 0, tables[0].Section)
+	assert.Equal(t, 1, tables[1].Index)
+	assert.Empty(t, tables[1].Caption)
+	assert.Same(t, invoice, tables[0].Table)
+}
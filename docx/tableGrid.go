@@ -0,0 +1,154 @@
+package docx
+
+import (
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// LogicalGrid is a table resolved into a rectangular matrix, one GridCell per row and grid
+// column. A merged cell covers several positions, which all refer to the same origin cell.
+type LogicalGrid struct {
+	Rows  int
+	Cols  int
+	Cells [][]GridCell // Cells[row][col]
+}
+
+// GridCell is one position of a logical grid.
+type GridCell struct {
+	Row int // Zero-based row of the position
+	Col int // Zero-based grid column of the position
+
+	// OriginRow and OriginCol locate the top-left position of the merged cell covering the
+	// position. For unmerged cells they equal Row and Col.
+	OriginRow int
+	OriginCol int
+	RowSpan   int // Rows the merged cell covers
+	ColSpan   int // Grid columns the merged cell covers
+
+	// Cell is the origin cell, nil where no cell covers the position: the grid columns a
+	// row skips before its first cell or after its last (gridBefore/gridAfter), and rows
+	// shorter than the grid.
+	Cell *Cell
+	Text string // Text of the origin cell, paragraphs separated by newlines
+}
+
+// IsOrigin reports whether the position is the top-left position of its cell.
+func (g GridCell) IsOrigin() bool {
+	return g.Row == g.OriginRow && g.Col == g.OriginCol
+}
+
+// At returns the position at row and grid column col, or nil outside the grid.
+func (g *LogicalGrid) At(row, col int) *GridCell {
+	if row < 0 || col < 0 || row >= g.Rows || col >= g.Cols {
+		return nil
+	}
+	return &g.Cells[row][col]
+}
+
+// Text returns the text of every position: merged cells repeat their text at each
+// position they cover.
+func (g *LogicalGrid) Text() [][]string {
+	text := make([][]string, g.Rows)
+	for r, row := range g.Cells {
+		text[r] = make([]string, g.Cols)
+		for c, cell := range row {
+			text[r][c] = cell.Text
+		}
+	}
+	return text
+}
+
+// mergedCell is a cell of the table with the grid area it covers.
+type mergedCell struct {
+	row, col         int
+	rowSpan, colSpan int
+	cell             *ctypes.Cell
+}
+
+// LogicalGrid resolves the table into a rectangular matrix. Horizontal merges (gridSpan),
+// vertical merges (vMerge restart and continue) and the grid columns skipped before and
+// after the cells of a row (gridBefore/gridAfter, sized by wBefore/wAfter) are expanded,
+// so every position of the matrix knows the cell covering it.
+//
+// The matrix is as wide as the widest row or the table grid, whichever is wider. A vMerge
+// continuation cell without a cell above it in the same grid column starts a cell of its
+// own.
+//
+// Example:
+//
+//	grid := table.LogicalGrid()
+//	for _, row := range grid.Cells {
+//		for _, cell := range row {
+//			if cell.IsOrigin() {
+//				fmt.Println(cell.Row, cell.Col, cell.RowSpan, cell.ColSpan, cell.Text)
+//			}
+//		}
+//	}
+func (t *Table) LogicalGrid() *LogicalGrid {
+	rows := t.Rows()
+	cols := t.gridCols()
+
+	owners := make([][]*mergedCell, len(rows))
+	for r, row := range rows {
+		owners[r] = make([]*mergedCell, cols)
+		for _, gc := range rowGridCells(row.ct) {
+			var m *mergedCell
+			if r > 0 && isVMergeContinue(gc.cell) {
+				if above := owners[r-1][gc.col]; above != nil && above.col == gc.col && above.row+above.rowSpan == r {
+					above.rowSpan++
+					m = above
+				}
+			}
+			if m == nil {
+				m = &mergedCell{row: r, col: gc.col, rowSpan: 1, colSpan: gc.span, cell: gc.cell}
+			}
+			for c := gc.col; c < gc.col+gc.span && c < cols; c++ {
+				owners[r][c] = m
+			}
+		}
+	}
+
+	grid := &LogicalGrid{Rows: len(rows), Cols: cols, Cells: make([][]GridCell, len(rows))}
+	texts := make(map[*mergedCell]string)
+	for r := range rows {
+		grid.Cells[r] = make([]GridCell, cols)
+		for c := 0; c < cols; c++ {
+			m := owners[r][c]
+			if m == nil {
+				grid.Cells[r][c] = GridCell{Row: r, Col: c, OriginRow: r, OriginCol: c, RowSpan: 1, ColSpan: 1}
+				continue
+			}
+
+			text, ok := texts[m]
+			if !ok {
+				text = cellText(m.cell)
+				texts[m] = text
+			}
+			grid.Cells[r][c] = GridCell{
+				Row:       r,
+				Col:       c,
+				OriginRow: m.row,
+				OriginCol: m.col,
+				RowSpan:   m.rowSpan,
+				ColSpan:   m.colSpan,
+				Cell:      &Cell{root: t.root, ct: m.cell},
+				Text:      text,
+			}
+		}
+	}
+	return grid
+}
+
+// isVMergeContinue reports whether a cell continues the vertically merged cell above it.
+// A vMerge element without a value continues the merge.
+func isVMergeContinue(c *ctypes.Cell) bool {
+	if !isVMerged(c) {
+		return false
+	}
+	return c.Property.VMerge.Val == nil || *c.Property.VMerge.Val == stypes.MergeCellContinue
+}
+
+// Text returns the text of the cell's paragraphs separated by newlines.
+func (c *Cell) Text() string {
+	return cellText(c.ct)
+}
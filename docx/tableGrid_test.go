@@ -0,0 +1,65 @@
+package docx_test
+
+import (
+	"bytes"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogicalGrid(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	table := addGridTable(rd, 3, 3)
+	require.NoError(t, table.Merge(0, 0, 0, 1))
+	require.NoError(t, table.Merge(1, 2, 2, 2))
+
+	// The last row starts one grid column in and has one cell fewer.
+	short := table.AddRow()
+	short.GetCT().Property.GridBefore = &ctypes.DecimalNum{Val: 1}
+	short.GetCT().Property.WidthBefore = ctypes.NewTableWidth(1000, "dxa")
+	short.AddCell().AddParagraph("3,1")
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	content := buf.Bytes()
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+
+	var grid *docx.LogicalGrid
+	for _, child := range reopened.Document.Body.Children {
+		if child.Table != nil {
+			grid = child.Table.LogicalGrid()
+		}
+	}
+	require.NotNil(t, grid)
+	assert.Equal(t, 4, grid.Rows)
+	assert.Equal(t, 3, grid.Cols)
+
+	assert.Equal(t, [][]string{
+		{"0,0\n0,1", "0,0\n0,1", "0,2"},
+		{"1,0", "1,1", "1,2\n2,2"},
+		{"2,0", "2,1", "1,2\n2,2"},
+		{"", "3,1", ""},
+	}, grid.Text())
+
+	title := grid.At(0, 1)
+	assert.False(t, title.IsOrigin())
+	assert.Equal(t, []int{0, 0, 1, 2}, []int{title.OriginRow, title.OriginCol, title.RowSpan, title.ColSpan})
+
+	merged := grid.At(2, 2)
+	assert.False(t, merged.IsOrigin())
+	assert.Equal(t, []int{1, 2, 2, 1}, []int{merged.OriginRow, merged.OriginCol, merged.RowSpan, merged.ColSpan})
+	assert.Equal(t, grid.At(1, 2).Cell.GetCT(), merged.Cell.GetCT())
+
+	assert.Nil(t, grid.At(3, 0).Cell)
+	assert.Nil(t, grid.At(3, 2).Cell)
+	assert.True(t, grid.At(3, 1).IsOrigin())
+	assert.Nil(t, grid.At(4, 0))
+}
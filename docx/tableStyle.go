@@ -0,0 +1,208 @@
+package docx
+
+import (
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// cnfStyleBits lists the conditional formats in the order of the bits of a cnfStyle value.
+var cnfStyleBits = []stypes.TblStyleOverrideType{
+	stypes.TblStyleOverrideFirstRow,
+	stypes.TblStyleOverrideLastRow,
+	stypes.TblStyleOverrideFirstCol,
+	stypes.TblStyleOverrideLastCol,
+	stypes.TblStyleOverrideBand1Vert,
+	stypes.TblStyleOverrideBand2Vert,
+	stypes.TblStyleOverrideBand1Horz,
+	stypes.TblStyleOverrideBand2Horz,
+	stypes.TblStyleOverrideNwCell,
+	stypes.TblStyleOverrideNeCell,
+	stypes.TblStyleOverrideSwCell,
+	stypes.TblStyleOverrideSeCell,
+}
+
+// CnfStyle encodes conditional formats as the value of a cnfStyle element: twelve binary
+// digits, one per conditional format. The whole table format has no digit.
+//
+// Example:
+//
+//	CnfStyle([]stypes.TblStyleOverrideType{stypes.TblStyleOverrideFirstRow}) // "100000000000"
+func CnfStyle(conds []stypes.TblStyleOverrideType) string {
+	bits := []byte(strings.Repeat("0", len(cnfStyleBits)))
+	for _, kind := range conds {
+		for i, bit := range cnfStyleBits {
+			if kind == bit {
+				bits[i] = '1'
+			}
+		}
+	}
+	return string(bits)
+}
+
+// ParseCnfStyle decodes the value of a cnfStyle element into the conditional formats it
+// sets, in the order of their bits.
+func ParseCnfStyle(val string) []stypes.TblStyleOverrideType {
+	var conds []stypes.TblStyleOverrideType
+	for i, bit := range cnfStyleBits {
+		if i < len(val) && val[i] == '1' {
+			conds = append(conds, bit)
+		}
+	}
+	return conds
+}
+
+// CellConditions returns the conditional formats of the table style that apply to the
+// cell, in increasing order of precedence, starting with the whole table format. They
+// follow from the position of the cell, the conditional formats the table enables with
+// its tblLook, and the band sizes of the table and its style.
+func (sr *StyleResolver) CellConditions(cell *TableCellRef) []stypes.TblStyleOverrideType {
+	if cell == nil || cell.Table == nil {
+		return nil
+	}
+	var stylePr *ctypes.TableProp
+	if style := sr.styleOrDefault(stypes.StyleTypeTable, tableStyleID(cell.Table)); style != nil {
+		stylePr = style.tblPr
+	}
+	return cellConditions(cell, stylePr)
+}
+
+// Cell returns the effective cell properties of the cell: the table style with the
+// conditional formats that apply to the cell, then the direct cell properties.
+func (sr *StyleResolver) Cell(cell *TableCellRef) *ctypes.CellProperty {
+	tcPr := &ctypes.CellProperty{}
+	if table := sr.tableStyle(cell); table != nil {
+		mergeProps(tcPr, table.tcPr)
+	}
+	if c := refCell(cell); c != nil {
+		mergeProps(tcPr, c.Property)
+	}
+	return tcPr
+}
+
+// CellFill returns the background color of the cell as a hex code, with theme colors
+// resolved, or an empty string when the cell has no background. Cell shading takes
+// precedence over the shading of the table.
+//
+// Example:
+//
+//	sr := document.NewStyleResolver()
+//	for r, row := range table.Rows() {
+//		for c := range row.Cells() {
+//			fill := sr.CellFill(&docx.TableCellRef{Table: table.GetCT(), Row: r, Col: c})
+//			fmt.Printf("<td style=\"background:#%s\">", fill)
+//		}
+//	}
+func (sr *StyleResolver) CellFill(cell *TableCellRef) string {
+	if fill := sr.shadingFill(sr.Cell(cell).Shading); fill != "" {
+		return fill
+	}
+	if cell == nil || cell.Table == nil {
+		return ""
+	}
+
+	tblPr := &ctypes.TableProp{}
+	if style := sr.styleOrDefault(stypes.StyleTypeTable, tableStyleID(cell.Table)); style != nil {
+		mergeProps(tblPr, style.tblPr)
+	}
+	mergeProps(tblPr, &cell.Table.TableProp)
+	return sr.shadingFill(tblPr.Shading)
+}
+
+// shadingFill returns the fill color of shading, or an empty string for no or automatic fill.
+func (sr *StyleResolver) shadingFill(shd *ctypes.Shading) string {
+	if shd == nil {
+		return ""
+	}
+	fill := ""
+	if shd.Fill != nil {
+		fill = *shd.Fill
+	}
+	fill = sr.theme.ResolveColor(fill, shd.ThemeFill, shd.ThemeFillTint, shd.ThemeFillShade)
+	if strings.EqualFold(fill, "auto") {
+		return ""
+	}
+	return fill
+}
+
+// UpdateCnfStyle records on every row and cell of the table the conditional formats of
+// the table style that apply to it (w:cnfStyle), as Word does. Rows record the row
+// formats: first and last row and horizontal bands.
+func (t *Table) UpdateCnfStyle() *Table {
+	sr := t.root.NewStyleResolver()
+	for r, row := range t.rowCTs() {
+		var rowConds []stypes.TblStyleOverrideType
+		for c, cc := range row.Contents {
+			if cc.Cell == nil {
+				continue
+			}
+			conds := sr.CellConditions(&TableCellRef{Table: &t.ct, Row: r, Col: c})
+			if rowConds == nil {
+				for _, kind := range conds {
+					switch kind {
+					case stypes.TblStyleOverrideFirstRow, stypes.TblStyleOverrideLastRow,
+						stypes.TblStyleOverrideBand1Horz, stypes.TblStyleOverrideBand2Horz:
+						rowConds = append(rowConds, kind)
+					}
+				}
+			}
+
+			cnf := cnfStyleValue(conds)
+			if cc.Cell.Property == nil && cnf != nil {
+				cc.Cell.Property = &ctypes.CellProperty{}
+			}
+			if cc.Cell.Property != nil {
+				cc.Cell.Property.CnfStyle = cnf
+			}
+		}
+
+		cnf := cnfStyleValue(rowConds)
+		if row.Property == nil && cnf != nil {
+			row.Property = ctypes.DefaultRowProperty()
+		}
+		if row.Property != nil {
+			row.Property.Cnf = cnf
+		}
+	}
+	return t
+}
+
+// cnfStyleValue returns the cnfStyle element for conditional formats, or nil when none of
+// them has a bit.
+func cnfStyleValue(conds []stypes.TblStyleOverrideType) *ctypes.CTString {
+	val := CnfStyle(conds)
+	if !strings.Contains(val, "1") {
+		return nil
+	}
+	return ctypes.NewCTString(val)
+}
+
+// tableStyleID returns the ID of the style of the table, or an empty string.
+func tableStyleID(tbl *ctypes.Table) string {
+	if tbl.TableProp.Style == nil {
+		return ""
+	}
+	return tbl.TableProp.Style.Val
+}
+
+// refCell returns the cell a TableCellRef points to, or nil.
+func refCell(cell *TableCellRef) *ctypes.Cell {
+	if cell == nil || cell.Table == nil {
+		return nil
+	}
+	r := 0
+	for _, rc := range cell.Table.RowContents {
+		if rc.Row == nil {
+			continue
+		}
+		if r == cell.Row {
+			if cell.Col < 0 || cell.Col >= len(rc.Row.Contents) {
+				return nil
+			}
+			return rc.Row.Contents[cell.Col].Cell
+		}
+		r++
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package docx_test
+
+import (
+	"bytes"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/docx"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableStyleConditions(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	_, err = rd.NewTableStyle("Report", "Report").
+		TableBorders(&ctypes.TableBorders{InsideH: &ctypes.Border{Val: stypes.BorderStyleSingle}}).
+		TableFormat(stypes.TblStyleOverrideWholeTable, docx.TableStyleFormat{Color: "333333"}).
+		TableFormat(stypes.TblStyleOverrideFirstRow, docx.TableStyleFormat{Bold: true, Color: "FFFFFF", Fill: "4472C4"}).
+		TableFormat(stypes.TblStyleOverrideBand1Horz, docx.TableStyleFormat{Fill: "D9E2F3"}).
+		TableFormat(stypes.TblStyleOverrideFirstCol, docx.TableStyleFormat{Italic: true}).
+		BandSize(2, 0).
+		Save()
+	require.NoError(t, err)
+
+	table := addGridTable(rd, 6, 2)
+	table.Style("Report")
+	table.Look(docx.TableLook{FirstRow: true, FirstColumn: true, BandedRows: true})
+	for _, row := range table.Rows() {
+		for _, cell := range row.Cells() {
+			cell.GetCT().Property.Shading = nil
+		}
+	}
+	table.Rows()[5].Cells()[1].BackgroundColor("FF0000")
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	content := buf.Bytes()
+	reopened, err := packager.Unpack(&content)
+	require.NoError(t, err)
+	tables := reopened.ExtractTables()
+	require.Len(t, tables, 1)
+	tbl := tables[0].Table
+
+	sr := reopened.NewStyleResolver()
+	ref := func(row, col int) *docx.TableCellRef {
+		return &docx.TableCellRef{Table: tbl.GetCT(), Row: row, Col: col}
+	}
+
+	assert.Equal(t, []stypes.TblStyleOverrideType{
+		stypes.TblStyleOverrideWholeTable,
+		stypes.TblStyleOverrideFirstCol,
+		stypes.TblStyleOverrideFirstRow,
+		stypes.TblStyleOverrideNwCell,
+	}, sr.CellConditions(ref(0, 0)))
+
+	// Bands of two rows start below the header row.
+	fills := make([]string, 6)
+	for r := range fills {
+		fills[r] = sr.CellFill(ref(r, 1))
+	}
+	assert.Equal(t, []string{"4472C4", "D9E2F3", "D9E2F3", "", "", "FF0000"}, fills)
+
+	p := tbl.Rows()[0].Cells()[1].GetCT().Contents[0].Paragraph
+	header := sr.RunFormat(p, p.Children[0].Run, ref(0, 1))
+	assert.True(t, header.Bold)
+	assert.Equal(t, "FFFFFF", header.Color)
+	p = tbl.Rows()[3].Cells()[0].GetCT().Contents[0].Paragraph
+	body := sr.RunFormat(p, p.Children[0].Run, ref(3, 0))
+	assert.True(t, body.Italic)
+	assert.Equal(t, "333333", body.Color)
+
+	tbl.UpdateCnfStyle()
+	rows := tbl.Rows()
+	assert.Equal(t, "101000001000", rows[0].Cells()[0].GetCT().Property.CnfStyle.Val)
+	assert.Equal(t, "000000100000", rows[1].Cells()[1].GetCT().Property.CnfStyle.Val)
+	assert.Equal(t, "100000000000", rows[0].GetCT().Property.Cnf.Val)
+	assert.Equal(t, "000000010000", rows[3].Cells()[1].GetCT().Property.CnfStyle.Val)
+	assert.Equal(t, []stypes.TblStyleOverrideType{
+		stypes.TblStyleOverrideFirstCol,
+		stypes.TblStyleOverrideBand2Horz,
+	}, docx.ParseCnfStyle(rows[3].Cells()[0].GetCT().Property.CnfStyle.Val))
+
+	// Rows and cells without conditional formats get no properties for them.
+	plain := rd.AddTable()
+	plain.Look(docx.TableLook{})
+	plain.AddRow().AddCell()
+	plain.Rows()[0].GetCT().Property = nil
+	plain.UpdateCnfStyle()
+	assert.Nil(t, plain.Rows()[0].GetCT().Property)
+	assert.Nil(t, plain.Rows()[0].Cells()[0].GetCT().Property.CnfStyle)
+}
@@ -0,0 +1,93 @@
+package docx
+
+import (
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+)
+
+// Text returns the plain text of the paragraph.
+//
+// Text of runs, hyperlinks and tracked insertions is concatenated in document order;
+// tracked deletions are skipped. Tabs and breaks are rendered as "\t" and "\n", and
+// equations in their linear form, such as "x^2+1".
+func (p *Paragraph) Text() string {
+	return paragraphText(&p.ct)
+}
+
+// paragraphText returns the plain text of a paragraph complex type.
+func paragraphText(p *ctypes.Paragraph) string {
+	var sb strings.Builder
+	for _, child := range p.Children {
+		switch {
+		case child.Run != nil:
+			writeRunText(&sb, child.Run)
+		case child.Link != nil && child.Link.Run != nil:
+			writeRunText(&sb, child.Link.Run)
+		case child.Ins != nil:
+			for _, run := range child.Ins.Runs {
+				writeRunText(&sb, run)
+			}
+		case child.Math != nil:
+			sb.WriteString(child.Math.Text())
+		case child.MathPara != nil:
+			sb.WriteString(child.MathPara.Text())
+		}
+	}
+	return sb.String()
+}
+
+// writeRunText appends the visible text of the run to sb.
+func writeRunText(sb *strings.Builder, run *ctypes.Run) {
+	for _, rc := range run.Children {
+		switch {
+		case rc.Text != nil:
+			sb.WriteString(rc.Text.Text)
+		case rc.Tab != nil:
+			sb.WriteByte('\t')
+		case rc.Break != nil, rc.CarrRtn != nil:
+			sb.WriteByte('\n')
+		}
+	}
+}
+
+// tableText returns the text of every paragraph in the table, cells separated by tabs and
+// rows by newlines. Nested tables are flattened into their parent cell.
+func tableText(t *ctypes.Table) string {
+	var sb strings.Builder
+	for i, rc := range t.RowContents {
+		if rc.Row == nil {
+			continue
+		}
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(rowText(rc.Row))
+	}
+	return sb.String()
+}
+
+// rowText returns the text of the row's cells separated by tabs.
+func rowText(row *ctypes.Row) string {
+	cells := make([]string, 0, len(row.Contents))
+	for _, cc := range row.Contents {
+		if cc.Cell != nil {
+			cells = append(cells, cellText(cc.Cell))
+		}
+	}
+	return strings.Join(cells, "\t")
+}
+
+// cellText returns the text of the cell's paragraphs separated by newlines.
+func cellText(c *ctypes.Cell) string {
+	parts := make([]string, 0, len(c.Contents))
+	for _, block := range c.Contents {
+		switch {
+		case block.Paragraph != nil:
+			parts = append(parts, paragraphText(block.Paragraph))
+		case block.Table != nil:
+			parts = append(parts, tableText(block.Table))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
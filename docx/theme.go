@@ -0,0 +1,539 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/iEvan-lhr/docx-agent/wml/ctypes"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+const (
+	themeRelType     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/theme"
+	themeContentType = "application/vnd.openxmlformats-officedocument.theme+xml"
+)
+
+// Theme is the color scheme and fonts of the theme of a document, which colors, borders,
+// shading and fonts can refer to instead of naming a concrete value.
+type Theme struct {
+	Name      string
+	Colors    ThemeColorScheme
+	MajorFont ThemeFontSet // Headings font
+	MinorFont ThemeFontSet // Body font
+
+	// colorMap maps the text and background colors to the scheme colors, as set by the
+	// w:clrSchemeMapping of the document settings.
+	colorMap map[stypes.ThemeColor]stypes.ThemeColor
+
+	// eastAsiaLang and bidiLang are the languages of the w:themeFontLang of the document
+	// settings, which select the East Asian and complex script theme fonts.
+	eastAsiaLang string
+	bidiLang     string
+}
+
+// ThemeColorScheme holds the colors of a theme as RRGGBB hex strings.
+type ThemeColorScheme struct {
+	Name              string
+	Dark1             string
+	Light1            string
+	Dark2             string
+	Light2            string
+	Accent1           string
+	Accent2           string
+	Accent3           string
+	Accent4           string
+	Accent5           string
+	Accent6           string
+	Hyperlink         string
+	FollowedHyperlink string
+}
+
+// ThemeFontSet is the major or minor font of a theme.
+type ThemeFontSet struct {
+	Latin         string
+	EastAsia      string
+	ComplexScript string
+
+	// Scripts maps script codes, such as "Jpan" or "Arab", to the font of the script.
+	Scripts map[string]string
+}
+
+// xmlTheme is the part of a:theme this package reads.
+type xmlTheme struct {
+	Name     string `xml:"name,attr"`
+	Elements struct {
+		ClrScheme struct {
+			Name   string           `xml:"name,attr"`
+			Colors []xmlSchemeColor `xml:",any"`
+		} `xml:"clrScheme"`
+		FontScheme struct {
+			Major xmlFontCollection `xml:"majorFont"`
+			Minor xmlFontCollection `xml:"minorFont"`
+		} `xml:"fontScheme"`
+	} `xml:"themeElements"`
+}
+
+type xmlSchemeColor struct {
+	XMLName xml.Name
+	SRGB    *struct {
+		Val string `xml:"val,attr"`
+	} `xml:"srgbClr"`
+	Sys *struct {
+		LastClr string `xml:"lastClr,attr"`
+	} `xml:"sysClr"`
+}
+
+type xmlTypeface struct {
+	Typeface string `xml:"typeface,attr"`
+}
+
+type xmlFontCollection struct {
+	Latin xmlTypeface `xml:"latin"`
+	EA    xmlTypeface `xml:"ea"`
+	CS    xmlTypeface `xml:"cs"`
+	Fonts []struct {
+		Script   string `xml:"script,attr"`
+		Typeface string `xml:"typeface,attr"`
+	} `xml:"font"`
+}
+
+// LoadTheme decodes a theme part.
+func LoadTheme(fileBytes []byte) (*Theme, error) {
+	var src xmlTheme
+	if err := xml.Unmarshal(fileBytes, &src); err != nil {
+		return nil, err
+	}
+
+	t := &Theme{Name: src.Name}
+	t.Colors.Name = src.Elements.ClrScheme.Name
+	for _, c := range src.Elements.ClrScheme.Colors {
+		value := ""
+		switch {
+		case c.SRGB != nil:
+			value = strings.ToUpper(c.SRGB.Val)
+		case c.Sys != nil:
+			value = strings.ToUpper(c.Sys.LastClr)
+		}
+		if field := t.Colors.field(c.XMLName.Local); field != nil {
+			*field = value
+		}
+	}
+	t.MajorFont = newThemeFontSet(src.Elements.FontScheme.Major)
+	t.MinorFont = newThemeFontSet(src.Elements.FontScheme.Minor)
+	return t, nil
+}
+
+func newThemeFontSet(src xmlFontCollection) ThemeFontSet {
+	fs := ThemeFontSet{
+		Latin:         src.Latin.Typeface,
+		EastAsia:      src.EA.Typeface,
+		ComplexScript: src.CS.Typeface,
+		Scripts:       make(map[string]string, len(src.Fonts)),
+	}
+	for _, f := range src.Fonts {
+		fs.Scripts[f.Script] = f.Typeface
+	}
+	return fs
+}
+
+// field returns the color of the scheme with the given a:clrScheme element name.
+func (cs *ThemeColorScheme) field(name string) *string {
+	switch name {
+	case "dk1":
+		return &cs.Dark1
+	case "lt1":
+		return &cs.Light1
+	case "dk2":
+		return &cs.Dark2
+	case "lt2":
+		return &cs.Light2
+	case "accent1":
+		return &cs.Accent1
+	case "accent2":
+		return &cs.Accent2
+	case "accent3":
+		return &cs.Accent3
+	case "accent4":
+		return &cs.Accent4
+	case "accent5":
+		return &cs.Accent5
+	case "accent6":
+		return &cs.Accent6
+	case "hlink":
+		return &cs.Hyperlink
+	case "folHlink":
+		return &cs.FollowedHyperlink
+	}
+	return nil
+}
+
+// defaultColorMap is the mapping of the text and background colors Word uses when the
+// settings do not give one.
+var defaultColorMap = map[stypes.ThemeColor]stypes.ThemeColor{
+	stypes.ThemeColorText1:       stypes.ThemeColorDark1,
+	stypes.ThemeColorBackground1: stypes.ThemeColorLight1,
+	stypes.ThemeColorText2:       stypes.ThemeColorDark2,
+	stypes.ThemeColorBackground2: stypes.ThemeColorLight2,
+}
+
+// Color returns the RRGGBB value of a theme color, or an empty string when the theme does
+// not define it. Text and background colors follow the color mapping of the document.
+func (t *Theme) Color(c stypes.ThemeColor) string {
+	colorMap := t.colorMap
+	if colorMap == nil {
+		colorMap = defaultColorMap
+	}
+	if mapped, ok := colorMap[c]; ok {
+		c = mapped
+	}
+
+	switch c {
+	case stypes.ThemeColorDark1:
+		return t.Colors.Dark1
+	case stypes.ThemeColorLight1:
+		return t.Colors.Light1
+	case stypes.ThemeColorDark2:
+		return t.Colors.Dark2
+	case stypes.ThemeColorLight2:
+		return t.Colors.Light2
+	case stypes.ThemeColorAccent1:
+		return t.Colors.Accent1
+	case stypes.ThemeColorAccent2:
+		return t.Colors.Accent2
+	case stypes.ThemeColorAccent3:
+		return t.Colors.Accent3
+	case stypes.ThemeColorAccent4:
+		return t.Colors.Accent4
+	case stypes.ThemeColorAccent5:
+		return t.Colors.Accent5
+	case stypes.ThemeColorAccent6:
+		return t.Colors.Accent6
+	case stypes.ThemeColorHyperlink:
+		return t.Colors.Hyperlink
+	case stypes.ThemeColorFollowedHyperlink:
+		return t.Colors.FollowedHyperlink
+	}
+	return ""
+}
+
+// ResolveColor returns the concrete color of a color attribute set: the theme color with
+// its tint or shade applied when a theme color is given, else val. tint and shade are the
+// hex bytes of w:themeTint and w:themeShade.
+//
+// Example:
+//
+//	c := run.Property.Color
+//	rgb := theme.ResolveColor(c.Val, c.ThemeColor, c.ThemeTint, c.ThemeShade)
+func (t *Theme) ResolveColor(val string, themeColor *stypes.ThemeColor, tint, shade *string) string {
+	if t == nil || themeColor == nil || *themeColor == stypes.ThemeColorNone {
+		return val
+	}
+	base := t.Color(*themeColor)
+	if base == "" {
+		return val
+	}
+
+	r, g, b, ok := parseRGB(base)
+	if !ok {
+		return base
+	}
+	h, s, l := rgbToHSL(r, g, b)
+	if tint != nil {
+		if v, err := strconv.ParseUint(*tint, 16, 8); err == nil {
+			f := float64(v) / 255
+			l = l*f + (1 - f)
+		}
+	}
+	if shade != nil {
+		if v, err := strconv.ParseUint(*shade, 16, 8); err == nil {
+			l *= float64(v) / 255
+		}
+	}
+	r, g, b = hslToRGB(h, s, l)
+	return fmt.Sprintf("%02X%02X%02X", r, g, b)
+}
+
+// Font returns the typeface of a theme font. The East Asian and complex script fonts fall
+// back to the script font for the theme font languages of the document.
+func (t *Theme) Font(f stypes.ThemeFont) string {
+	fs := &t.MinorFont
+	if strings.HasPrefix(string(f), "major") {
+		fs = &t.MajorFont
+	}
+
+	switch f {
+	case stypes.ThemeFontMajorAscii, stypes.ThemeFontMajorHAnsi, stypes.ThemeFontMinorAscii, stypes.ThemeFontMinorHAnsi:
+		return fs.Latin
+	case stypes.ThemeFontMajorEastAsia, stypes.ThemeFontMinorEastAsia:
+		if fs.EastAsia != "" {
+			return fs.EastAsia
+		}
+		return fs.Scripts[langScript(t.eastAsiaLang)]
+	case stypes.ThemeFontMajorBidi, stypes.ThemeFontMinorBidi:
+		if fs.ComplexScript != "" {
+			return fs.ComplexScript
+		}
+		return fs.Scripts[langScript(t.bidiLang)]
+	}
+	return ""
+}
+
+// langScripts maps languages to the script codes of the theme fonts.
+var langScripts = map[string]string{
+	"ja": "Jpan", "ko": "Hang", "zh-CN": "Hans", "zh-SG": "Hans", "zh-TW": "Hant",
+	"zh-HK": "Hant", "zh-MO": "Hant", "ar": "Arab", "fa": "Arab", "ur": "Arab",
+	"he": "Hebr", "th": "Thai", "hi": "Deva",
+}
+
+// langScript returns the script code of a language tag such as "ja-JP".
+func langScript(lang string) string {
+	if script, ok := langScripts[lang]; ok {
+		return script
+	}
+	primary, _, _ := strings.Cut(lang, "-")
+	return langScripts[primary]
+}
+
+// Theme returns the theme of the document, or nil when the document has none.
+func (rd *RootDoc) Theme() (*Theme, error) {
+	themePath := rd.themePath()
+	if themePath == "" {
+		return nil, nil
+	}
+	content, ok := rd.FileMap.Load(themePath)
+	if !ok {
+		return nil, nil
+	}
+
+	t, err := LoadTheme(content.([]byte))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", themePath, err)
+	}
+	if rd.Settings != nil {
+		for _, raw := range rd.Settings.Extra {
+			switch raw.XMLName.Local {
+			case "w:clrSchemeMapping":
+				t.colorMap = make(map[stypes.ThemeColor]stypes.ThemeColor, len(defaultColorMap))
+				for k, v := range defaultColorMap {
+					t.colorMap[k] = v
+				}
+				for _, attr := range raw.Attrs {
+					if from, ok := colorMapAttrs[strings.TrimPrefix(attr.Name.Local, "w:")]; ok {
+						t.colorMap[from] = stypes.ThemeColor(attr.Value)
+					}
+				}
+			case "w:themeFontLang":
+				for _, attr := range raw.Attrs {
+					switch attr.Name.Local {
+					case "w:eastAsia":
+						t.eastAsiaLang = attr.Value
+					case "w:bidi":
+						t.bidiLang = attr.Value
+					}
+				}
+			}
+		}
+	}
+	return t, nil
+}
+
+// colorMapAttrs maps the attributes of w:clrSchemeMapping to the colors they map.
+var colorMapAttrs = map[string]stypes.ThemeColor{
+	"t1":  stypes.ThemeColorText1,
+	"bg1": stypes.ThemeColorBackground1,
+	"t2":  stypes.ThemeColorText2,
+	"bg2": stypes.ThemeColorBackground2,
+}
+
+// themePath returns the path of the theme part, or an empty string when the document has
+// none.
+func (rd *RootDoc) themePath() string {
+	if rd.Document == nil {
+		return ""
+	}
+	for _, rel := range rd.Document.DocRels.Relationships {
+		if rel.Type == themeRelType {
+			return partTarget(rd.Document.relativePath, rel.Target)
+		}
+	}
+	return ""
+}
+
+// ImportTheme replaces the theme of the document with the theme of src, so that theme
+// colors and fonts take the values of src. The pictures the theme of src refers to are
+// copied as well.
+//
+// Example:
+//
+//	branded, _ := godocx.OpenDocument("brand.docx")
+//	document.ImportTheme(branded)
+func (rd *RootDoc) ImportTheme(src *RootDoc) error {
+	srcPath := src.themePath()
+	if srcPath == "" {
+		return fmt.Errorf("source document has no theme")
+	}
+	content, ok := src.FileMap.Load(srcPath)
+	if !ok {
+		return fmt.Errorf("source theme part %s is missing", srcPath)
+	}
+	srcRels, err := src.partRels(srcPath)
+	if err != nil {
+		return err
+	}
+
+	themePath := rd.themePath()
+	if themePath == "" {
+		themePath = path.Join(path.Dir(rd.Document.relativePath), "theme/theme1.xml")
+		if err := rd.ContentType.AddOverride("/"+themePath, themeContentType); err != nil {
+			return err
+		}
+		rd.Document.addRelation(themeRelType, "theme/theme1.xml")
+	}
+
+	// The pictures of the theme keep their paths, unless a different part already uses one.
+	rels := &Relationships{RelativePath: partRelsPath(themePath), Xmlns: srcRels.Xmlns}
+	for _, rel := range srcRels.Relationships {
+		relCopy := *rel
+		if rel.TargetMode != "External" {
+			target := partTarget(srcPath, rel.Target)
+			data, ok := src.FileMap.Load(target)
+			if !ok {
+				continue
+			}
+			if existing, ok := rd.FileMap.Load(target); ok && !bytes.Equal(existing.([]byte), data.([]byte)) {
+				return fmt.Errorf("theme part %s conflicts with a part of the document", target)
+			}
+			rd.FileMap.Store(target, data)
+			if ext := strings.TrimPrefix(path.Ext(target), "."); ext != "" {
+				if mime, err := MIMEFromExt(ext); err == nil {
+					if err := rd.ContentType.AddExtension(ext, mime); err != nil {
+						return err
+					}
+				}
+			}
+			if !strings.HasPrefix(rel.Target, "/") {
+				relCopy.Target = "/" + target
+			}
+		}
+		rels.Relationships = append(rels.Relationships, &relCopy)
+	}
+
+	rd.FileMap.Delete(rels.RelativePath)
+	if len(rels.Relationships) > 0 {
+		relsContent, err := marshal(rels)
+		if err != nil {
+			return err
+		}
+		rd.FileMap.Store(rels.RelativePath, relsContent)
+	}
+	rd.FileMap.Store(themePath, content)
+	return nil
+}
+
+// applyTheme replaces the theme references of a run format with the concrete colors and
+// fonts of the theme.
+func (t *Theme) applyTheme(f *RunFormat, rPr *ctypes.RunProperty) {
+	if t == nil || rPr == nil {
+		return
+	}
+
+	if fonts := rPr.Fonts; fonts != nil {
+		// A theme font takes precedence over the font named next to it.
+		if fonts.AsciiTheme != "" {
+			f.Font = t.Font(fonts.AsciiTheme)
+		} else if fonts.Ascii == "" && fonts.HAnsiTheme != "" {
+			f.Font = t.Font(fonts.HAnsiTheme)
+		}
+		if fonts.EastAsiaTheme != "" {
+			f.EastAsiaFont = t.Font(fonts.EastAsiaTheme)
+		}
+		if fonts.CSTheme != "" {
+			f.ComplexFont = t.Font(fonts.CSTheme)
+		}
+	}
+
+	if c := rPr.Color; c != nil && c.ThemeColor != nil {
+		f.Color = t.ResolveColor(c.Val, c.ThemeColor, c.ThemeTint, c.ThemeShade)
+	}
+}
+
+// parseRGB parses an RRGGBB hex color.
+func parseRGB(s string) (r, g, b uint8, ok bool) {
+	if len(s) != 6 {
+		return 0, 0, 0, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v), true
+}
+
+// rgbToHSL converts a color to hue (0-360), saturation and luminance (0-1).
+func rgbToHSL(r, g, b uint8) (h, s, l float64) {
+	rf, gf, bf := float64(r)/255, float64(g)/255, float64(b)/255
+	maxC := math.Max(rf, math.Max(gf, bf))
+	minC := math.Min(rf, math.Min(gf, bf))
+	l = (maxC + minC) / 2
+	if maxC == minC {
+		return 0, 0, l
+	}
+
+	d := maxC - minC
+	if l > 0.5 {
+		s = d / (2 - maxC - minC)
+	} else {
+		s = d / (maxC + minC)
+	}
+	switch maxC {
+	case rf:
+		h = (gf - bf) / d
+		if gf < bf {
+			h += 6
+		}
+	case gf:
+		h = (bf-rf)/d + 2
+	default:
+		h = (rf-gf)/d + 4
+	}
+	return h * 60, s, l
+}
+
+// hslToRGB converts hue (0-360), saturation and luminance (0-1) to a color.
+func hslToRGB(h, s, l float64) (r, g, b uint8) {
+	l = math.Max(0, math.Min(1, l))
+	if s == 0 {
+		v := uint8(math.Round(l * 255))
+		return v, v, v
+	}
+
+	var q float64
+	if l < 0.5 {
+		q = l * (1 + s)
+	} else {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+	channel := func(t float64) uint8 {
+		t = math.Mod(t+1, 1)
+		var v float64
+		switch {
+		case t < 1.0/6:
+			v = p + (q-p)*6*t
+		case t < 0.5:
+			v = q
+		case t < 2.0/3:
+			v = p + (q-p)*(2.0/3-t)*6
+		default:
+			v = p
+		}
+		return uint8(math.Round(v * 255))
+	}
+	hk := h / 360
+	return channel(hk + 1.0/3), channel(hk), channel(hk - 1.0/3)
+}
@@ -0,0 +1,65 @@
+package docx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	godocx "github.com/iEvan-lhr/docx-agent"
+	"github.com/iEvan-lhr/docx-agent/packager"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTheme(t *testing.T) {
+	rd, err := godocx.NewDocument()
+	require.NoError(t, err)
+
+	theme, err := rd.Theme()
+	require.NoError(t, err)
+	require.NotNil(t, theme)
+	assert.Equal(t, "Office", theme.Colors.Name)
+	assert.Equal(t, "4F81BD", theme.Colors.Accent1)
+	assert.Equal(t, "000000", theme.Color(stypes.ThemeColorText1))
+	assert.Equal(t, "1F497D", theme.Color(stypes.ThemeColorText2))
+	assert.Equal(t, "Cambria", theme.Font(stypes.ThemeFontMinorHAnsi))
+	assert.Equal(t, "ＭＳ 明朝", theme.Font(stypes.ThemeFontMinorEastAsia))
+
+	accent1 := stypes.ThemeColorAccent1
+	tint, shade := "99", "BF"
+	assert.Equal(t, "95B3D7", theme.ResolveColor("", &accent1, &tint, nil))
+	assert.Equal(t, "376092", theme.ResolveColor("", &accent1, nil, &shade))
+	assert.Equal(t, "FF0000", theme.ResolveColor("FF0000", nil, &tint, nil))
+
+	p := rd.AddParagraph("")
+	run := p.AddText("Accent")
+	run.Color("FF0000")
+	run.GetCT().Property.Color.ThemeColor = &accent1
+	run.GetCT().Property.Color.ThemeShade = &shade
+	format := rd.NewStyleResolver().RunFormat(p.GetCT(), run.GetCT(), nil)
+	assert.Equal(t, "376092", format.Color)
+	assert.Equal(t, "Cambria", format.Font)
+	assert.Equal(t, "ＭＳ 明朝", format.EastAsiaFont)
+
+	// A second document with another accent color and body font supplies the theme.
+	src, err := godocx.NewDocument()
+	require.NoError(t, err)
+	content, _ := src.FileMap.Load("word/theme/theme1.xml")
+	themeXML := strings.Replace(string(content.([]byte)), `<a:srgbClr val="4F81BD"/>`, `<a:srgbClr val="E36C0A"/>`, 1)
+	themeXML = strings.Replace(themeXML, `<a:latin typeface="Cambria"/>`, `<a:latin typeface="Georgia"/>`, 1)
+	src.FileMap.Store("word/theme/theme1.xml", []byte(themeXML))
+
+	require.NoError(t, rd.ImportTheme(src))
+
+	var buf bytes.Buffer
+	require.NoError(t, rd.Write(&buf))
+	pkg := buf.Bytes()
+	reopened, err := packager.Unpack(&pkg)
+	require.NoError(t, err)
+
+	theme, err = reopened.Theme()
+	require.NoError(t, err)
+	assert.Equal(t, "E36C0A", theme.Colors.Accent1)
+	assert.Equal(t, "Georgia", theme.MinorFont.Latin)
+}
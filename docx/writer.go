@@ -86,6 +86,14 @@ func (rd *RootDoc) writeToZip(zw *zip.Writer) error {
 	}
 	snapshot[rd.DocStyles.RelativePath] = docStyleBytes
 
+	if rd.Settings != nil {
+		settingsBytes, err := marshal(rd.Settings)
+		if err != nil {
+			return err
+		}
+		snapshot[rd.Settings.RelativePath] = settingsBytes
+	}
+
 	// Serialize headers
 	for _, header := range rd.Document.Headers {
 		headerBytes, err := marshal(header)
@@ -152,11 +160,19 @@ func (rd *RootDoc) Save() error {
 }
 
 // SaveTo method saves the RootDoc to the specified file path.
+//
+// Saving updates the modification time and revision of the core properties, as Word
+// does. Write and WriteTo leave them unchanged. Core properties that cannot be decoded
+// are saved as they are, without being updated.
 func (rd *RootDoc) SaveTo(fileName string) error {
 	if fileName == "" {
 		return errors.New("Destination file path is empty")
 	}
 
+	if err := rd.touchCoreProps(time.Now()); err != nil {
+		return err
+	}
+
 	file, err := os.OpenFile(filepath.Clean(fileName), os.O_WRONLY|os.O_TRUNC|os.O_CREATE, os.ModePerm)
 	if err != nil {
 		return err
@@ -0,0 +1,5 @@
+// Package omml provides Office Math Markup Language (OMML), the equation markup of the Office
+// Open XML (OOXML) standard that WordprocessingML documents embed as <m:oMath> and
+// <m:oMathPara>. Besides the object model it converts equations from LaTeX and to LaTeX
+// and MathML.
+package omml
@@ -0,0 +1,567 @@
+package omml
+
+import (
+	"encoding/xml"
+)
+
+// Fraction is a fraction (<m:f>).
+type Fraction struct {
+	// Type is "bar" (the default), "skw" (skewed), "lin" (linear) or "noBar" (stacked,
+	// as in binomial coefficients).
+	Type string
+
+	Num []Element
+	Den []Element
+}
+
+func (f Fraction) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "m:f"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	var props [][2]string
+	if f.Type != "" && f.Type != "bar" {
+		props = append(props, [2]string{"m:type", f.Type})
+	}
+	if err := encodeProps(e, "m:fPr", props...); err != nil {
+		return err
+	}
+	if err := encodeArg(e, "m:num", f.Num); err != nil {
+		return err
+	}
+	if err := encodeArg(e, "m:den", f.Den); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (f *Fraction) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeChildren(d, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "fPr":
+			var pr properties
+			if err = d.DecodeElement(&pr, &child); err != nil {
+				return err
+			}
+			f.Type = pr.Type.value("")
+		case "num":
+			f.Num, err = decodeElements(d)
+		case "den":
+			f.Den, err = decodeElements(d)
+		default:
+			err = d.Skip()
+		}
+		return err
+	})
+}
+
+// Radical is a square root or, with a degree, an nth root (<m:rad>).
+type Radical struct {
+	// Degree is empty for square roots.
+	Degree []Element
+	Base   []Element
+}
+
+func (r Radical) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "m:rad"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	var props [][2]string
+	if len(r.Degree) == 0 {
+		props = append(props, [2]string{"m:degHide", "1"})
+	}
+	if err := encodeProps(e, "m:radPr", props...); err != nil {
+		return err
+	}
+	if err := encodeArg(e, "m:deg", r.Degree); err != nil {
+		return err
+	}
+	if err := encodeArg(e, "m:e", r.Base); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (r *Radical) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	hidden := false
+	err := decodeChildren(d, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "radPr":
+			var pr properties
+			if err = d.DecodeElement(&pr, &child); err != nil {
+				return err
+			}
+			hidden = pr.DegHide.on()
+		case "deg":
+			r.Degree, err = decodeElements(d)
+		case "e":
+			r.Base, err = decodeElements(d)
+		default:
+			err = d.Skip()
+		}
+		return err
+	})
+	if hidden {
+		r.Degree = nil
+	}
+	return err
+}
+
+// Subscript is a base with a subscript (<m:sSub>).
+type Subscript struct {
+	Base []Element
+	Sub  []Element
+}
+
+func (s Subscript) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeObject(e, "m:sSub", arg{"m:e", s.Base}, arg{"m:sub", s.Sub})
+}
+
+func (s *Subscript) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeObject(d, map[string]*[]Element{"e": &s.Base, "sub": &s.Sub})
+}
+
+// Superscript is a base with a superscript (<m:sSup>).
+type Superscript struct {
+	Base []Element
+	Sup  []Element
+}
+
+func (s Superscript) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeObject(e, "m:sSup", arg{"m:e", s.Base}, arg{"m:sup", s.Sup})
+}
+
+func (s *Superscript) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeObject(d, map[string]*[]Element{"e": &s.Base, "sup": &s.Sup})
+}
+
+// SubSuperscript is a base with a subscript and a superscript (<m:sSubSup>).
+type SubSuperscript struct {
+	Base []Element
+	Sub  []Element
+	Sup  []Element
+}
+
+func (s SubSuperscript) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeObject(e, "m:sSubSup", arg{"m:e", s.Base}, arg{"m:sub", s.Sub}, arg{"m:sup", s.Sup})
+}
+
+func (s *SubSuperscript) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeObject(d, map[string]*[]Element{"e": &s.Base, "sub": &s.Sub, "sup": &s.Sup})
+}
+
+// PreSubSuperscript is a base with a subscript and a superscript before it (<m:sPre>).
+type PreSubSuperscript struct {
+	Sub  []Element
+	Sup  []Element
+	Base []Element
+}
+
+func (s PreSubSuperscript) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeObject(e, "m:sPre", arg{"m:sub", s.Sub}, arg{"m:sup", s.Sup}, arg{"m:e", s.Base})
+}
+
+func (s *PreSubSuperscript) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeObject(d, map[string]*[]Element{"e": &s.Base, "sub": &s.Sub, "sup": &s.Sup})
+}
+
+// Nary is an n-ary operator such as a sum, a product or an integral, with its limits and
+// operand (<m:nary>).
+type Nary struct {
+	// Char is the operator, such as "∑"; empty is an integral.
+	Char string
+
+	// LimLoc places the limits: "undOvr" below and above the operator, or "subSup" as
+	// scripts. Empty leaves the choice to the application.
+	LimLoc string
+
+	// Sub and Sup are the lower and upper limits; empty limits are hidden.
+	Sub  []Element
+	Sup  []Element
+	Base []Element
+}
+
+// Operator returns the operator character.
+func (n *Nary) Operator() string {
+	if n.Char == "" {
+		return "∫"
+	}
+	return n.Char
+}
+
+func (n Nary) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "m:nary"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	var props [][2]string
+	if n.Char != "" && n.Char != "∫" {
+		props = append(props, [2]string{"m:chr", n.Char})
+	}
+	if n.LimLoc != "" {
+		props = append(props, [2]string{"m:limLoc", n.LimLoc})
+	}
+	if len(n.Sub) == 0 {
+		props = append(props, [2]string{"m:subHide", "1"})
+	}
+	if len(n.Sup) == 0 {
+		props = append(props, [2]string{"m:supHide", "1"})
+	}
+	if err := encodeProps(e, "m:naryPr", props...); err != nil {
+		return err
+	}
+	for _, a := range []arg{{"m:sub", n.Sub}, {"m:sup", n.Sup}, {"m:e", n.Base}} {
+		if err := encodeArg(e, a.name, a.elems); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (n *Nary) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var subHidden, supHidden bool
+	err := decodeChildren(d, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "naryPr":
+			var pr properties
+			if err = d.DecodeElement(&pr, &child); err != nil {
+				return err
+			}
+			n.Char = pr.Chr.value("")
+			n.LimLoc = pr.LimLoc.value("")
+			subHidden, supHidden = pr.SubHide.on(), pr.SupHide.on()
+		case "sub":
+			n.Sub, err = decodeElements(d)
+		case "sup":
+			n.Sup, err = decodeElements(d)
+		case "e":
+			n.Base, err = decodeElements(d)
+		default:
+			err = d.Skip()
+		}
+		return err
+	})
+	if subHidden {
+		n.Sub = nil
+	}
+	if supHidden {
+		n.Sup = nil
+	}
+	return err
+}
+
+// Matrix is a matrix (<m:m>); Rows holds the elements of each cell of each row.
+type Matrix struct {
+	Rows [][][]Element
+}
+
+func (m Matrix) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "m:m"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, row := range m.Rows {
+		mr := xml.StartElement{Name: xml.Name{Local: "m:mr"}}
+		if err := e.EncodeToken(mr); err != nil {
+			return err
+		}
+		for _, cell := range row {
+			if err := encodeArg(e, "m:e", cell); err != nil {
+				return err
+			}
+		}
+		if err := e.EncodeToken(mr.End()); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (m *Matrix) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeChildren(d, func(child xml.StartElement) error {
+		if child.Name.Local != "mr" {
+			return d.Skip()
+		}
+		var row [][]Element
+		err := decodeChildren(d, func(cell xml.StartElement) error {
+			if cell.Name.Local != "e" {
+				return d.Skip()
+			}
+			elems, err := decodeElements(d)
+			row = append(row, elems)
+			return err
+		})
+		m.Rows = append(m.Rows, row)
+		return err
+	})
+}
+
+// Delimiter is a bracketed group of one or more elements (<m:d>), such as "(a, b)".
+type Delimiter struct {
+	// Begin, Sep and End are the opening, separating and closing characters. An empty
+	// Begin or End leaves that side open.
+	Begin string
+	Sep   string
+	End   string
+
+	Elems [][]Element
+}
+
+// NewDelimiter returns a delimiter with the given brackets around the elements.
+func NewDelimiter(begin, end string, elems ...[]Element) *Delimiter {
+	return &Delimiter{Begin: begin, Sep: "|", End: end, Elems: elems}
+}
+
+func (dl Delimiter) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "m:d"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	var props [][2]string
+	if dl.Begin != "(" {
+		props = append(props, [2]string{"m:begChr", dl.Begin})
+	}
+	if dl.Sep != "|" && dl.Sep != "" {
+		props = append(props, [2]string{"m:sepChr", dl.Sep})
+	}
+	if dl.End != ")" {
+		props = append(props, [2]string{"m:endChr", dl.End})
+	}
+	if err := encodeProps(e, "m:dPr", props...); err != nil {
+		return err
+	}
+	for _, elems := range dl.Elems {
+		if err := encodeArg(e, "m:e", elems); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (dl *Delimiter) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	dl.Begin, dl.Sep, dl.End = "(", "|", ")"
+	return decodeChildren(d, func(child xml.StartElement) error {
+		switch child.Name.Local {
+		case "dPr":
+			var pr properties
+			if err := d.DecodeElement(&pr, &child); err != nil {
+				return err
+			}
+			dl.Begin = pr.BegChr.value("(")
+			dl.Sep = pr.SepChr.value("|")
+			dl.End = pr.EndChr.value(")")
+		case "e":
+			elems, err := decodeElements(d)
+			if err != nil {
+				return err
+			}
+			dl.Elems = append(dl.Elems, elems)
+		default:
+			return d.Skip()
+		}
+		return nil
+	})
+}
+
+// Function is a function applied to an argument (<m:func>), such as "sin x".
+type Function struct {
+	// Name is the function name, usually a plain run; limits of functions such as lim
+	// are a LimitLower around the name.
+	Name []Element
+	Base []Element
+}
+
+func (f Function) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeObject(e, "m:func", arg{"m:fName", f.Name}, arg{"m:e", f.Base})
+}
+
+func (f *Function) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeObject(d, map[string]*[]Element{"fName": &f.Name, "e": &f.Base})
+}
+
+// Accent is a base with an accent mark above it (<m:acc>), such as a hat or a vector
+// arrow.
+type Accent struct {
+	// Char is the combining accent character; empty is a circumflex (U+0302).
+	Char string
+	Base []Element
+}
+
+// Mark returns the accent character.
+func (a *Accent) Mark() string {
+	if a.Char == "" {
+		return "\u0302"
+	}
+	return a.Char
+}
+
+func (a Accent) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "m:acc"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	var props [][2]string
+	if a.Char != "" && a.Char != "\u0302" {
+		props = append(props, [2]string{"m:chr", a.Char})
+	}
+	if err := encodeProps(e, "m:accPr", props...); err != nil {
+		return err
+	}
+	if err := encodeArg(e, "m:e", a.Base); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (a *Accent) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeChildren(d, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "accPr":
+			var pr properties
+			if err = d.DecodeElement(&pr, &child); err != nil {
+				return err
+			}
+			a.Char = pr.Chr.value("")
+		case "e":
+			a.Base, err = decodeElements(d)
+		default:
+			err = d.Skip()
+		}
+		return err
+	})
+}
+
+// Bar is a base with a line above or below it (<m:bar>).
+type Bar struct {
+	// Top puts the bar above the base; by default it is below.
+	Top  bool
+	Base []Element
+}
+
+func (b Bar) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "m:bar"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	var props [][2]string
+	if b.Top {
+		props = append(props, [2]string{"m:pos", "top"})
+	}
+	if err := encodeProps(e, "m:barPr", props...); err != nil {
+		return err
+	}
+	if err := encodeArg(e, "m:e", b.Base); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (b *Bar) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeChildren(d, func(child xml.StartElement) (err error) {
+		switch child.Name.Local {
+		case "barPr":
+			var pr properties
+			if err = d.DecodeElement(&pr, &child); err != nil {
+				return err
+			}
+			b.Top = pr.Pos.value("bot") == "top"
+		case "e":
+			b.Base, err = decodeElements(d)
+		default:
+			err = d.Skip()
+		}
+		return err
+	})
+}
+
+// LimitLower is a base with a limit below it (<m:limLow>), such as "lim" over "x→0".
+type LimitLower struct {
+	Base []Element
+	Lim  []Element
+}
+
+func (l LimitLower) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeObject(e, "m:limLow", arg{"m:e", l.Base}, arg{"m:lim", l.Lim})
+}
+
+func (l *LimitLower) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeObject(d, map[string]*[]Element{"e": &l.Base, "lim": &l.Lim})
+}
+
+// LimitUpper is a base with a limit above it (<m:limUpp>).
+type LimitUpper struct {
+	Base []Element
+	Lim  []Element
+}
+
+func (l LimitUpper) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeObject(e, "m:limUpp", arg{"m:e", l.Base}, arg{"m:lim", l.Lim})
+}
+
+func (l *LimitUpper) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeObject(d, map[string]*[]Element{"e": &l.Base, "lim": &l.Lim})
+}
+
+// EquationArray is a column of equations (<m:eqArr>), such as the lines of a system of
+// equations. A "&" run inside a row marks an alignment point.
+type EquationArray struct {
+	Rows [][]Element
+}
+
+func (ea EquationArray) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "m:eqArr"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, row := range ea.Rows {
+		if err := encodeArg(e, "m:e", row); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (ea *EquationArray) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeChildren(d, func(child xml.StartElement) error {
+		if child.Name.Local != "e" {
+			return d.Skip()
+		}
+		row, err := decodeElements(d)
+		ea.Rows = append(ea.Rows, row)
+		return err
+	})
+}
+
+// arg is a named argument of a math object.
+type arg struct {
+	name  string
+	elems []Element
+}
+
+// encodeObject writes a math object without properties that consists of its arguments.
+func encodeObject(e *xml.Encoder, name string, args ...arg) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, a := range args {
+		if err := encodeArg(e, a.name, a.elems); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// decodeObject decodes the arguments of a math object into the slices given by local
+// name; properties and unknown children are skipped.
+func decodeObject(d *xml.Decoder, args map[string]*[]Element) error {
+	return decodeChildren(d, func(child xml.StartElement) (err error) {
+		target, ok := args[child.Name.Local]
+		if !ok {
+			return d.Skip()
+		}
+		*target, err = decodeElements(d)
+		return err
+	})
+}
@@ -0,0 +1,352 @@
+package omml
+
+import (
+	"strings"
+	"unicode"
+)
+
+// latexWriter builds LaTeX, separating control words from the letters after them.
+type latexWriter struct {
+	strings.Builder
+	afterWord bool // The last thing written was a control word such as \alpha
+}
+
+// cmd writes a control sequence such as "\frac".
+func (w *latexWriter) cmd(name string) {
+	w.WriteString(`\` + name)
+	w.afterWord = isLetters(name)
+}
+
+// raw writes LaTeX as it is.
+func (w *latexWriter) raw(s string) {
+	if s == "" {
+		return
+	}
+	if w.afterWord && unicode.IsLetter(rune(s[0])) {
+		w.WriteByte(' ')
+	}
+	w.WriteString(s)
+	w.afterWord = false
+}
+
+// group writes the elements enclosed in braces.
+func (w *latexWriter) group(elems []Element) {
+	w.raw("{")
+	writeLaTeXList(w, elems)
+	w.raw("}")
+}
+
+// text writes math text, turning characters into control words where needed.
+func (w *latexWriter) text(s string) {
+	for _, r := range s {
+		switch {
+		case r == '−':
+			w.raw("-")
+		case r == '′':
+			w.raw("'")
+		case strings.ContainsRune(`{}%#$_\`, r):
+			w.cmd(symbolCmds[string(r)])
+		case r < unicode.MaxASCII && r != '~' && r != '^':
+			w.raw(string(r))
+		case symbolCmds[string(r)] != "":
+			w.cmd(symbolCmds[string(r)])
+		case doubleLetters[r] != 0:
+			w.cmd("mathbb")
+			w.raw("{" + string(doubleLetters[r]) + "}")
+		default:
+			w.raw(string(r))
+		}
+	}
+}
+
+func writeLaTeXList(w *latexWriter, elems []Element) {
+	for _, el := range elems {
+		el.writeLaTeX(w)
+	}
+}
+
+func (r *Run) writeLaTeX(w *latexWriter) {
+	switch {
+	case r.Normal:
+		w.cmd("text")
+		w.raw("{" + r.Text + "}")
+	case r.Style == "p" && functionNames[r.Text]:
+		w.cmd(r.Text)
+	case r.Style == "p" && isLetters(r.Text):
+		w.cmd("mathrm")
+		w.raw("{" + r.Text + "}")
+	case (r.Style == "b" || r.Style == "bi") && strings.TrimSpace(r.Text) != "":
+		w.cmd("mathbf")
+		w.raw("{")
+		w.text(r.Text)
+		w.raw("}")
+	default:
+		w.text(r.Text)
+	}
+}
+
+func (r *Raw) writeLaTeX(w *latexWriter) {
+	w.text(r.Text())
+}
+
+func (f *Fraction) writeLaTeX(w *latexWriter) {
+	switch f.Type {
+	case "lin", "skw":
+		w.group(f.Num)
+		w.raw("/")
+		w.group(f.Den)
+	case "noBar":
+		w.cmd("genfrac")
+		w.raw("{}{}{0pt}{}")
+		w.group(f.Num)
+		w.group(f.Den)
+	default:
+		w.cmd("frac")
+		w.group(f.Num)
+		w.group(f.Den)
+	}
+}
+
+func (r *Radical) writeLaTeX(w *latexWriter) {
+	w.cmd("sqrt")
+	if len(r.Degree) > 0 {
+		w.raw("[")
+		writeLaTeXList(w, r.Degree)
+		w.raw("]")
+	}
+	w.group(r.Base)
+}
+
+// writeScriptBase writes the base of a script, in braces unless it is a single
+// character or object.
+func writeScriptBase(w *latexWriter, base []Element) {
+	if len(base) == 1 {
+		if r, ok := base[0].(*Run); !ok || len([]rune(r.Text)) == 1 || r.Style == "p" && functionNames[r.Text] {
+			writeLaTeXList(w, base)
+			return
+		}
+	}
+	w.group(base)
+}
+
+func (s *Subscript) writeLaTeX(w *latexWriter) {
+	writeScriptBase(w, s.Base)
+	w.raw("_")
+	w.group(s.Sub)
+}
+
+func (s *Superscript) writeLaTeX(w *latexWriter) {
+	writeScriptBase(w, s.Base)
+	w.raw("^")
+	w.group(s.Sup)
+}
+
+func (s *SubSuperscript) writeLaTeX(w *latexWriter) {
+	writeScriptBase(w, s.Base)
+	w.raw("_")
+	w.group(s.Sub)
+	w.raw("^")
+	w.group(s.Sup)
+}
+
+func (s *PreSubSuperscript) writeLaTeX(w *latexWriter) {
+	w.raw("{}_")
+	w.group(s.Sub)
+	w.raw("^")
+	w.group(s.Sup)
+	writeScriptBase(w, s.Base)
+}
+
+func (n *Nary) writeLaTeX(w *latexWriter) {
+	if cmd, ok := naryCmds[n.Operator()]; ok {
+		w.cmd(cmd)
+	} else {
+		w.cmd("mathop")
+		w.raw("{")
+		w.text(n.Operator())
+		w.raw("}")
+	}
+	if len(n.Sub) > 0 {
+		w.raw("_")
+		w.group(n.Sub)
+	}
+	if len(n.Sup) > 0 {
+		w.raw("^")
+		w.group(n.Sup)
+	}
+	w.group(n.Base)
+}
+
+// writeRows writes the rows of a matrix environment.
+func writeRows(w *latexWriter, rows [][][]Element) {
+	for i, row := range rows {
+		if i > 0 {
+			w.raw(` \\ `)
+		}
+		for j, cell := range row {
+			if j > 0 {
+				w.raw(" & ")
+			}
+			writeLaTeXList(w, cell)
+		}
+	}
+}
+
+func (m *Matrix) writeLaTeX(w *latexWriter) {
+	w.cmd("begin")
+	w.raw("{matrix}")
+	writeRows(w, m.Rows)
+	w.cmd("end")
+	w.raw("{matrix}")
+}
+
+// latexDelimiter returns the LaTeX form of a delimiter character for \left and \right.
+func latexDelimiter(s string) string {
+	switch s {
+	case "":
+		return "."
+	case "{", "}":
+		return `\` + s
+	case "‖":
+		return `\|`
+	}
+	if cmd := symbolCmds[s]; cmd != "" && s != "|" {
+		return `\` + cmd
+	}
+	return s
+}
+
+func (dl *Delimiter) writeLaTeX(w *latexWriter) {
+	if len(dl.Elems) == 1 && len(dl.Elems[0]) == 1 {
+		switch inner := dl.Elems[0][0].(type) {
+		case *Matrix:
+			for env, delims := range matrixDelimiters {
+				if env != "smallmatrix" && delims == [2]string{dl.Begin, dl.End} {
+					w.cmd("begin")
+					w.raw("{" + env + "}")
+					writeRows(w, inner.Rows)
+					w.cmd("end")
+					w.raw("{" + env + "}")
+					return
+				}
+			}
+		case *EquationArray:
+			if dl.Begin == "{" && dl.End == "" {
+				w.cmd("begin")
+				w.raw("{cases}")
+				inner.writeRows(w)
+				w.cmd("end")
+				w.raw("{cases}")
+				return
+			}
+		case *Fraction:
+			if inner.Type == "noBar" && dl.Begin == "(" && dl.End == ")" {
+				w.cmd("binom")
+				w.group(inner.Num)
+				w.group(inner.Den)
+				return
+			}
+		}
+	}
+
+	w.cmd("left")
+	w.raw(latexDelimiter(dl.Begin))
+	for i, elems := range dl.Elems {
+		if i > 0 {
+			w.cmd("middle")
+			w.raw(latexDelimiter(dl.Sep))
+		}
+		writeLaTeXList(w, elems)
+	}
+	w.cmd("right")
+	w.raw(latexDelimiter(dl.End))
+}
+
+func (f *Function) writeLaTeX(w *latexWriter) {
+	if len(f.Name) == 1 {
+		if r, ok := f.Name[0].(*Run); ok && !functionNames[r.Text] && isLetters(r.Text) {
+			w.cmd("operatorname")
+			w.raw("{" + r.Text + "}")
+			w.group(f.Base)
+			return
+		}
+	}
+	writeLaTeXList(w, f.Name)
+	w.group(f.Base)
+}
+
+func (a *Accent) writeLaTeX(w *latexWriter) {
+	if cmd, ok := accentCmds[a.Mark()]; ok {
+		w.cmd(cmd)
+	} else {
+		w.cmd("overset")
+		w.raw("{")
+		w.text(a.Mark())
+		w.raw("}")
+	}
+	w.group(a.Base)
+}
+
+func (b *Bar) writeLaTeX(w *latexWriter) {
+	if b.Top {
+		w.cmd("overline")
+	} else {
+		w.cmd("underline")
+	}
+	w.group(b.Base)
+}
+
+// isLimitName reports whether the elements are the name of a function with limits,
+// such as lim.
+func isLimitName(elems []Element) bool {
+	if len(elems) != 1 {
+		return false
+	}
+	r, ok := elems[0].(*Run)
+	return ok && limitFunctions[r.Text]
+}
+
+func (l *LimitLower) writeLaTeX(w *latexWriter) {
+	if isLimitName(l.Base) {
+		writeLaTeXList(w, l.Base)
+		w.raw("_")
+		w.group(l.Lim)
+		return
+	}
+	w.cmd("underset")
+	w.group(l.Lim)
+	w.group(l.Base)
+}
+
+func (l *LimitUpper) writeLaTeX(w *latexWriter) {
+	w.cmd("overset")
+	w.group(l.Lim)
+	w.group(l.Base)
+}
+
+func (ea *EquationArray) writeRows(w *latexWriter) {
+	for i, row := range ea.Rows {
+		if i > 0 {
+			w.raw(` \\ `)
+		}
+		writeLaTeXList(w, row)
+	}
+}
+
+func (ea *EquationArray) writeLaTeX(w *latexWriter) {
+	w.cmd("begin")
+	w.raw("{aligned}")
+	ea.writeRows(w)
+	w.cmd("end")
+	w.raw("{aligned}")
+}
+
+// isLetters reports whether s is a non-empty string of letters.
+func isLetters(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return s != ""
+}
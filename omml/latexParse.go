@@ -0,0 +1,695 @@
+package omml
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenKind is the kind of a LaTeX token.
+type tokenKind int
+
+const (
+	tokChar  tokenKind = iota // A character
+	tokCmd                    // A control sequence such as \frac, without the backslash
+	tokOpen                   // {
+	tokClose                  // }
+	tokSup                    // ^
+	tokSub                    // _
+	tokAmp                    // &
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+	pos  int // Byte offset of the token in the source
+	end  int // Byte offset after the token
+}
+
+// tokenize splits LaTeX math into tokens, dropping whitespace and comments.
+func tokenize(src string) []token {
+	var toks []token
+	// Offsets follow the decoded widths, so that an invalid byte, decoded as U+FFFD,
+	// still counts as one byte of the source.
+	var (
+		runes   []rune
+		offsets []int
+	)
+	for off := 0; off < len(src); {
+		r, size := utf8.DecodeRuneInString(src[off:])
+		runes = append(runes, r)
+		offsets = append(offsets, off)
+		off += size
+	}
+	offsets = append(offsets, len(src))
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '%':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			continue
+		case r == '\\':
+			i++
+			if i < len(runes) && unicode.IsLetter(runes[i]) && runes[i] < unicode.MaxASCII {
+				for i < len(runes) && unicode.IsLetter(runes[i]) && runes[i] < unicode.MaxASCII {
+					i++
+				}
+			} else if i < len(runes) {
+				i++
+			}
+			toks = append(toks, token{kind: tokCmd, val: string(runes[start+1 : i]), pos: offsets[start], end: offsets[i]})
+			continue
+		}
+
+		kind := tokChar
+		switch r {
+		case '{':
+			kind = tokOpen
+		case '}':
+			kind = tokClose
+		case '^':
+			kind = tokSup
+		case '_':
+			kind = tokSub
+		case '&':
+			kind = tokAmp
+		}
+		i++
+		toks = append(toks, token{kind: kind, val: string(r), pos: offsets[start], end: offsets[i]})
+	}
+	return toks
+}
+
+// FromLaTeX converts LaTeX math, without delimiters such as "$", to an equation.
+//
+// It supports the common subset of LaTeX used in documents: fractions (\frac, \binom),
+// roots (\sqrt), scripts, n-ary operators (\sum, \int...), \left...\right delimiters,
+// matrix, cases and aligned environments, functions (\sin, \lim, \operatorname), accents
+// (\hat, \vec, \overline...), \text and font commands, Greek letters and the common
+// symbols. Unsupported commands are reported as errors.
+func FromLaTeX(src string) (*Math, error) {
+	p := &latexParser{src: src, toks: tokenize(src)}
+	elems, err := p.sequence(nil)
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected %s", p.peek().describe())
+	}
+	return &Math{Elements: elems}, nil
+}
+
+// latexParser is a recursive descent parser of LaTeX math.
+type latexParser struct {
+	src  string
+	toks []token
+	pos  int
+}
+
+func (p *latexParser) eof() bool {
+	return p.pos >= len(p.toks)
+}
+
+func (p *latexParser) peek() token {
+	if p.eof() {
+		return token{kind: -1}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *latexParser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *latexParser) errorf(format string, args ...any) error {
+	offset := len(p.src)
+	if !p.eof() {
+		offset = p.peek().pos
+	}
+	return fmt.Errorf("latex: %s at offset %d", fmt.Sprintf(format, args...), offset)
+}
+
+func (t token) describe() string {
+	switch {
+	case t.kind == -1:
+		return "end of input"
+	case t.kind == tokCmd:
+		return `\` + t.val
+	}
+	return fmt.Sprintf("%q", t.val)
+}
+
+// isCmd reports whether the token is one of the control sequences.
+func (t token) isCmd(names ...string) bool {
+	if t.kind != tokCmd {
+		return false
+	}
+	for _, n := range names {
+		if t.val == n {
+			return true
+		}
+	}
+	return false
+}
+
+// endsSequence reports whether the token ends any sequence: a closing brace, a cell or row
+// separator, or the end of a delimiter or environment.
+func (t token) endsSequence() bool {
+	return t.kind == -1 || t.kind == tokClose || t.kind == tokAmp || t.isCmd(`\`, "right", "middle", "end")
+}
+
+// sequence parses elements until the end of the input, a token that ends any sequence, or
+// a token for which stop returns true. Scripts are attached to the atom before them, and
+// adjacent runs of the same style are merged.
+func (p *latexParser) sequence(stop func(token) bool) ([]Element, error) {
+	var elems []Element
+	atom := -1 // Index of the first element of the last atom
+
+	for !p.peek().endsSequence() && (stop == nil || !stop(p.peek())) {
+		t := p.peek()
+		if t.kind == tokSup || t.kind == tokSub || (t.kind == tokChar && t.val == "'") {
+			var base []Element
+			if atom >= 0 {
+				base = append(base, elems[atom:]...)
+				elems = elems[:atom]
+			}
+			scripted, err := p.scripts(base)
+			if err != nil {
+				return nil, err
+			}
+			atom = len(elems)
+			elems = append(elems, scripted)
+			continue
+		}
+
+		parsed, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		if parsed == nil {
+			continue
+		}
+		atom = len(elems)
+		elems = append(elems, parsed...)
+	}
+	return mergeRuns(elems), nil
+}
+
+// scripts parses the subscript, superscript and primes after a base.
+func (p *latexParser) scripts(base []Element) (Element, error) {
+	var sub, sup []Element
+	hasSub, hasSup := false, false
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokChar && t.val == "'":
+			p.next()
+			sup = append(sup, &Run{Text: "′"})
+			hasSup = true
+			continue
+		case t.kind == tokSub && !hasSub:
+			p.next()
+			arg, err := p.argument()
+			if err != nil {
+				return nil, err
+			}
+			sub, hasSub = arg, true
+			continue
+		case t.kind == tokSup && !hasSup:
+			p.next()
+			arg, err := p.argument()
+			if err != nil {
+				return nil, err
+			}
+			sup, hasSup = append(sup, arg...), true
+			continue
+		case t.kind == tokSub || t.kind == tokSup:
+			return nil, p.errorf("double script")
+		}
+		break
+	}
+
+	switch {
+	case hasSub && hasSup:
+		return &SubSuperscript{Base: base, Sub: sub, Sup: sup}, nil
+	case hasSub:
+		return &Subscript{Base: base, Sub: sub}, nil
+	}
+	return &Superscript{Base: base, Sup: sup}, nil
+}
+
+// argument parses a command or script argument: a braced group or a single atom.
+func (p *latexParser) argument() ([]Element, error) {
+	if p.peek().kind == tokOpen {
+		return p.group()
+	}
+	if p.peek().endsSequence() || p.peek().kind == tokSup || p.peek().kind == tokSub {
+		return nil, p.errorf("missing argument before %s", p.peek().describe())
+	}
+	return p.atom()
+}
+
+// group parses a braced group.
+func (p *latexParser) group() ([]Element, error) {
+	if p.next().kind != tokOpen {
+		p.pos--
+		return nil, p.errorf("expected {")
+	}
+	elems, err := p.sequence(nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.next().kind != tokClose {
+		p.pos--
+		return nil, p.errorf("expected }")
+	}
+	return elems, nil
+}
+
+// rawGroup returns the source text of a braced group, as used by \text.
+func (p *latexParser) rawGroup() (string, error) {
+	open := p.next()
+	if open.kind != tokOpen {
+		p.pos--
+		return "", p.errorf("expected {")
+	}
+	for depth := 1; !p.eof(); {
+		t := p.next()
+		switch t.kind {
+		case tokOpen:
+			depth++
+		case tokClose:
+			depth--
+			if depth == 0 {
+				return p.src[open.end:t.pos], nil
+			}
+		}
+	}
+	return "", p.errorf("expected }")
+}
+
+// operand parses the operand of an n-ary operator or function: the elements up to the
+// next relation or additive operator.
+func (p *latexParser) operand() ([]Element, error) {
+	return p.sequence(func(t token) bool {
+		switch t.kind {
+		case tokChar:
+			return relations[charOf(t.val)]
+		case tokCmd:
+			return relations[symbolChars[t.val]]
+		}
+		return false
+	})
+}
+
+// charOf returns the character a LaTeX character stands for in math.
+func charOf(s string) string {
+	if s == "-" {
+		return "−"
+	}
+	return s
+}
+
+// atom parses the next atom: a character, a group or a command with its arguments. It
+// returns no elements for commands that only affect spacing or size.
+func (p *latexParser) atom() ([]Element, error) {
+	t := p.next()
+	switch t.kind {
+	case tokOpen:
+		p.pos--
+		return p.group()
+	case tokChar:
+		return []Element{&Run{Text: charOf(t.val)}}, nil
+	case tokCmd:
+		return p.command(t.val)
+	}
+	p.pos--
+	return nil, p.errorf("unexpected %s", t.describe())
+}
+
+// command parses a control sequence and its arguments.
+func (p *latexParser) command(name string) ([]Element, error) {
+	if char, ok := symbolChars[name]; ok {
+		return []Element{&Run{Text: char}}, nil
+	}
+	if char, ok := naryOperators[name]; ok {
+		return p.nary(char)
+	}
+	if functionNames[name] {
+		return p.function(&Run{Text: name, Style: "p"})
+	}
+	if char, ok := accentChars[name]; ok {
+		base, err := p.argument()
+		if err != nil {
+			return nil, err
+		}
+		return []Element{&Accent{Char: char, Base: base}}, nil
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "cfrac", "binom", "overset", "underset":
+		first, err := p.argument()
+		if err != nil {
+			return nil, err
+		}
+		second, err := p.argument()
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "binom":
+			return []Element{NewDelimiter("(", ")", []Element{&Fraction{Type: "noBar", Num: first, Den: second}})}, nil
+		case "overset":
+			return []Element{&LimitUpper{Base: second, Lim: first}}, nil
+		case "underset":
+			return []Element{&LimitLower{Base: second, Lim: first}}, nil
+		}
+		return []Element{&Fraction{Num: first, Den: second}}, nil
+
+	case "sqrt":
+		var degree []Element
+		if t := p.peek(); t.kind == tokChar && t.val == "[" {
+			p.next()
+			var err error
+			if degree, err = p.sequence(func(t token) bool { return t.kind == tokChar && t.val == "]" }); err != nil {
+				return nil, err
+			}
+			if t := p.next(); t.kind != tokChar || t.val != "]" {
+				p.pos--
+				return nil, p.errorf("expected ]")
+			}
+		}
+		base, err := p.argument()
+		if err != nil {
+			return nil, err
+		}
+		return []Element{&Radical{Degree: degree, Base: base}}, nil
+
+	case "overline", "underline":
+		base, err := p.argument()
+		if err != nil {
+			return nil, err
+		}
+		return []Element{&Bar{Top: name == "overline", Base: base}}, nil
+
+	case "operatorname":
+		text, err := p.rawGroup()
+		if err != nil {
+			return nil, err
+		}
+		return p.function(&Run{Text: strings.TrimSpace(text), Style: "p"})
+
+	case "text", "textrm", "mbox", "textnormal":
+		text, err := p.rawGroup()
+		if err != nil {
+			return nil, err
+		}
+		return []Element{&Run{Text: text, Normal: true}}, nil
+
+	case "mathrm", "mathbf", "mathit", "boldsymbol", "mathbb":
+		elems, err := p.argument()
+		if err != nil {
+			return nil, err
+		}
+		styleRuns(elems, name)
+		return elems, nil
+
+	case "left":
+		return p.delimiter()
+
+	case "begin":
+		return p.environment()
+
+	case "displaystyle", "textstyle", "limits", "nolimits", "big", "Big", "bigg", "Bigg",
+		"bigl", "bigr", "Bigl", "Bigr", "!":
+		return nil, nil
+	}
+	return nil, fmt.Errorf(`latex: unsupported command \%s`, name)
+}
+
+// styleRuns applies a font command to the runs among the elements.
+func styleRuns(elems []Element, cmd string) {
+	for _, el := range elems {
+		r, ok := el.(*Run)
+		if !ok {
+			continue
+		}
+		switch cmd {
+		case "mathrm":
+			r.Style = "p"
+		case "mathbf":
+			r.Style = "b"
+		case "boldsymbol":
+			r.Style = "bi"
+		case "mathit":
+			r.Style = "i"
+		case "mathbb":
+			r.Text = strings.Map(func(c rune) rune {
+				if ds, ok := doubleStruck[c]; ok {
+					return ds
+				}
+				if c >= 'A' && c <= 'Z' {
+					return 0x1D538 + (c - 'A')
+				}
+				return c
+			}, r.Text)
+		}
+	}
+}
+
+// nary parses the limits and operand of an n-ary operator.
+func (p *latexParser) nary(char string) ([]Element, error) {
+	n := &Nary{Char: char, LimLoc: "undOvr"}
+	if strings.Contains("∫∬∭∮", char) {
+		n.LimLoc = "subSup"
+	}
+	for {
+		t := p.peek()
+		if t.isCmd("limits", "nolimits") {
+			p.next()
+			continue
+		}
+		if t.kind != tokSub && t.kind != tokSup {
+			break
+		}
+		p.next()
+		arg, err := p.argument()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == tokSub {
+			n.Sub = arg
+		} else {
+			n.Sup = arg
+		}
+	}
+	base, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	n.Base = base
+	return []Element{n}, nil
+}
+
+// function parses the scripts and argument of a function such as \sin or \lim.
+func (p *latexParser) function(name *Run) ([]Element, error) {
+	f := &Function{Name: []Element{name}}
+	if t := p.peek(); t.kind == tokSub || t.kind == tokSup {
+		if t.kind == tokSub && limitFunctions[name.Text] {
+			p.next()
+			lim, err := p.argument()
+			if err != nil {
+				return nil, err
+			}
+			f.Name = []Element{&LimitLower{Base: f.Name, Lim: lim}}
+		} else {
+			scripted, err := p.scripts(f.Name)
+			if err != nil {
+				return nil, err
+			}
+			f.Name = []Element{scripted}
+		}
+	}
+	base, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	f.Base = base
+	return []Element{f}, nil
+}
+
+// delimiterChar parses the delimiter after \left, \middle or \right.
+func (p *latexParser) delimiterChar() (string, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokChar && t.val == ".":
+		return "", nil
+	case t.kind == tokChar:
+		return t.val, nil
+	case t.kind == tokCmd:
+		if char, ok := symbolChars[t.val]; ok {
+			return char, nil
+		}
+	}
+	p.pos--
+	return "", p.errorf("invalid delimiter %s", t.describe())
+}
+
+// delimiter parses the content of \left...\right.
+func (p *latexParser) delimiter() ([]Element, error) {
+	begin, err := p.delimiterChar()
+	if err != nil {
+		return nil, err
+	}
+	d := &Delimiter{Begin: begin, Sep: "|"}
+	for {
+		elems, err := p.sequence(nil)
+		if err != nil {
+			return nil, err
+		}
+		d.Elems = append(d.Elems, elems)
+
+		switch t := p.next(); {
+		case t.isCmd("middle"):
+			if d.Sep, err = p.delimiterChar(); err != nil {
+				return nil, err
+			}
+		case t.isCmd("right"):
+			if d.End, err = p.delimiterChar(); err != nil {
+				return nil, err
+			}
+			return []Element{d}, nil
+		default:
+			p.pos--
+			return nil, p.errorf(`expected \right`)
+		}
+	}
+}
+
+// environment parses a \begin{...}...\end{...} environment.
+func (p *latexParser) environment() ([]Element, error) {
+	env, err := p.rawGroup()
+	if err != nil {
+		return nil, err
+	}
+	env = strings.TrimSpace(env)
+	if env == "array" {
+		// The column specification is not kept.
+		if _, err := p.rawGroup(); err != nil {
+			return nil, err
+		}
+	}
+
+	var rows [][][]Element
+	for {
+		var row [][]Element
+		for {
+			cell, err := p.sequence(nil)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, cell)
+			if p.peek().kind != tokAmp {
+				break
+			}
+			p.next()
+		}
+		rows = append(rows, row)
+
+		t := p.next()
+		if t.isCmd(`\`) {
+			if p.peek().isCmd("end") {
+				p.next()
+				break
+			}
+			continue
+		}
+		if !t.isCmd("end") {
+			p.pos--
+			return nil, p.errorf(`expected \end{%s}`, env)
+		}
+		break
+	}
+	if end, err := p.rawGroup(); err != nil {
+		return nil, err
+	} else if strings.TrimSpace(end) != env {
+		return nil, fmt.Errorf(`latex: \begin{%s} ended by \end{%s}`, env, end)
+	}
+
+	switch env {
+	case "cases", "aligned", "align", "align*", "gathered", "split", "eqnarray", "eqnarray*":
+		ea := &EquationArray{}
+		for _, row := range rows {
+			var line []Element
+			for i, cell := range row {
+				if i > 0 {
+					line = append(line, &Run{Text: "&"})
+				}
+				line = append(line, cell...)
+			}
+			ea.Rows = append(ea.Rows, mergeRuns(line))
+		}
+		if env == "cases" {
+			return []Element{NewDelimiter("{", "", []Element{ea})}, nil
+		}
+		return []Element{ea}, nil
+	case "array":
+		return []Element{&Matrix{Rows: rows}}, nil
+	}
+
+	delims, ok := matrixDelimiters[env]
+	if !ok {
+		return nil, fmt.Errorf("latex: unsupported environment %s", env)
+	}
+	m := &Matrix{Rows: rows}
+	if delims[0] == "" && delims[1] == "" {
+		return []Element{m}, nil
+	}
+	return []Element{NewDelimiter(delims[0], delims[1], []Element{m})}, nil
+}
+
+// mergeRuns joins adjacent runs of the same style into one run, as Word writes them.
+func mergeRuns(elems []Element) []Element {
+	var merged []Element
+	for i := 0; i < len(elems); {
+		first, ok := elems[i].(*Run)
+		if !ok || first.Props != nil {
+			merged = append(merged, elems[i])
+			i++
+			continue
+		}
+
+		// Find the end of the runs that can join the first one.
+		end := i + 1
+		for end < len(elems) {
+			r, ok := elems[end].(*Run)
+			if !ok || r.Style != first.Style || r.Normal != first.Normal || r.Props != nil {
+				break
+			}
+			end++
+		}
+		if end == i+1 {
+			merged = append(merged, first)
+			i++
+			continue
+		}
+
+		var text strings.Builder
+		for _, el := range elems[i:end] {
+			text.WriteString(el.(*Run).Text)
+		}
+		merged = append(merged, &Run{Text: text.String(), Style: first.Style, Normal: first.Normal})
+		i = end
+	}
+	return merged
+}
@@ -0,0 +1,118 @@
+package omml
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestLaTeXRoundTrip(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		xml      string // Expected in the OMML
+	}{
+		{`x^2+y^2=z^2`, `x^{2}+y^{2}=z^{2}`, `<m:sSup><m:e><m:r><m:t xml:space="preserve">x</m:t></m:r></m:e><m:sup><m:r><m:t xml:space="preserve">2</m:t></m:r></m:sup></m:sSup>`},
+		{`\frac{a+b}{2}`, `\frac{a+b}{2}`, `<m:f><m:num>`},
+		{`\sqrt{x}+\sqrt[3]{y}`, `\sqrt{x}+\sqrt[3]{y}`, `<m:radPr><m:degHide m:val="1"></m:degHide></m:radPr>`},
+		{`a_{ij}^{2}`, `a_{ij}^{2}`, `<m:sSubSup>`},
+		{`\sum_{i=1}^{n} i = \frac{n(n+1)}{2}`, `\sum_{i=1}^{n}{i}=\frac{n(n+1)}{2}`, `<m:naryPr><m:chr m:val="∑"></m:chr><m:limLoc m:val="undOvr"></m:limLoc></m:naryPr>`},
+		{`\int_0^1 f(x)\,dx`, `\int_{0}^{1}{f(x)\,dx}`, `<m:limLoc m:val="subSup">`},
+		{`\left( \frac{1}{x} \right)`, `\left(\frac{1}{x}\right)`, `<m:d><m:e><m:f>`},
+		{`\left[ a \middle| b \right.`, `\left[a\middle|b\right.`, `<m:dPr><m:begChr m:val="["></m:begChr><m:endChr m:val=""></m:endChr></m:dPr>`},
+		{`\begin{pmatrix} 1 & 0 \\ 0 & 1 \end{pmatrix}`, `\begin{pmatrix}1 & 0 \\ 0 & 1\end{pmatrix}`, `<m:d><m:e><m:m><m:mr>`},
+		{`\sin^2 x + \cos^2 x = 1`, `\sin^{2}{x}+\cos^{2}{x}=1`, `<m:func><m:fName><m:sSup>`},
+		{`\lim_{x \to 0} \frac{\sin x}{x}`, `\lim_{x\to0}{\frac{\sin{x}}{x}}`, `<m:fName><m:limLow><m:e><m:r><m:rPr><m:sty m:val="p"></m:sty></m:rPr><m:t xml:space="preserve">lim</m:t>`},
+		{`\hat{x} + \vec{v} + \overline{AB}`, `\hat{x}+\vec{v}+\overline{AB}`, `<m:acc><m:accPr><m:chr m:val="⃗"></m:chr></m:accPr>`},
+		{`\alpha\beta + \Gamma`, `\alpha\beta+\Gamma`, `<m:t xml:space="preserve">αβ+Γ</m:t>`},
+		{`E = mc^2 \text{ where } c > 0`, `E=mc^{2}\text{ where }c>0`, `<m:rPr><m:nor></m:nor></m:rPr><m:t xml:space="preserve"> where </m:t>`},
+		{`\binom{n}{k}`, `\binom{n}{k}`, `<m:fPr><m:type m:val="noBar"></m:type></m:fPr>`},
+		{`f(x) = \begin{cases} x & x \ge 0 \\ -x & x < 0 \end{cases}`, `f(x)=\begin{cases}x&x\geq0 \\ -x&x<0\end{cases}`, `<m:eqArr>`},
+		{`x \in \mathbb{R}`, `x\in\mathbb{R}`, `ℝ`},
+		{`f'(x)`, `f^{'}(x)`, `<m:sSup>`},
+		{`\operatorname{sgn} x`, `\operatorname{sgn}{x}`, `<m:func>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			m, err := FromLaTeX(tt.input)
+			if err != nil {
+				t.Fatalf("FromLaTeX: %v", err)
+			}
+			if got := m.LaTeX(); got != tt.expected {
+				t.Errorf("LaTeX() = %s, want %s", got, tt.expected)
+			}
+
+			out, err := xml.Marshal(m)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if !strings.Contains(string(out), tt.xml) {
+				t.Errorf("OMML %s does not contain %s", out, tt.xml)
+			}
+
+			// The OMML reads back to the same LaTeX.
+			var back Math
+			if err := xml.Unmarshal(out, &back); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if got := back.LaTeX(); got != tt.expected {
+				t.Errorf("LaTeX() after round trip = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFromLaTeXErrors(t *testing.T) {
+	for _, input := range []string{`\frac{a}`, `x^`, `\left( x`, `a}`, `\unknowncommand`, `\begin{pmatrix} a \end{bmatrix}`, `x^2^3`, "\\begin{\xf2}", "\\text{\xff"} {
+		if _, err := FromLaTeX(input); err == nil {
+			t.Errorf("FromLaTeX(%q) expected an error", input)
+		}
+	}
+}
+
+func TestMathML(t *testing.T) {
+	m, err := FromLaTeX(`\frac{-b \pm \sqrt{b^2-4ac}}{2a}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<math xmlns="http://www.w3.org/1998/Math/MathML"><mfrac><mrow><mo>−</mo><mi>b</mi><mo>±</mo><msqrt><mrow><msup><mi>b</mi><mn>2</mn></msup><mo>−</mo><mn>4</mn><mi>a</mi><mi>c</mi></mrow></msqrt></mrow><mrow><mn>2</mn><mi>a</mi></mrow></mfrac></math>`
+	if got := m.MathML(); got != expected {
+		t.Errorf("MathML() = %s\nwant %s", got, expected)
+	}
+	if got := m.Text(); got != "(−b±√(b^2−4ac))/(2a)" {
+		t.Errorf("Text() = %s", got)
+	}
+}
+
+func TestMergeRuns(t *testing.T) {
+	props := &Raw{}
+	frac := &Fraction{}
+	single := &Run{Text: "y", Style: "b"}
+	merged := mergeRuns([]Element{
+		&Run{Text: "a"}, &Run{Text: "b"}, &Run{Text: "c"},
+		single,
+		&Run{Text: "d", Props: props}, &Run{Text: "e"},
+		frac,
+		&Run{Text: "f", Normal: true}, &Run{Text: "g", Normal: true},
+	})
+
+	if len(merged) != 6 {
+		t.Fatalf("mergeRuns returned %d elements, want 6", len(merged))
+	}
+	for i, want := range []string{"abc", "y", "d", "e", "", "fg"} {
+		if i == 4 {
+			if merged[i] != frac {
+				t.Errorf("element %d is %T, want the fraction", i, merged[i])
+			}
+			continue
+		}
+		r, ok := merged[i].(*Run)
+		if !ok || r.Text != want {
+			t.Errorf("element %d is %#v, want run %q", i, merged[i], want)
+		}
+	}
+	if merged[1] != single {
+		t.Error("a run with nothing to join should be kept as is")
+	}
+}
@@ -0,0 +1,169 @@
+package omml
+
+import (
+	"strings"
+)
+
+func writeLinearList(sb *strings.Builder, elems []Element) {
+	for _, el := range elems {
+		el.writeLinear(sb)
+	}
+}
+
+// writeLinearArg writes an argument in linear form, in parentheses unless it is a single
+// character.
+func writeLinearArg(sb *strings.Builder, elems []Element) {
+	var arg strings.Builder
+	writeLinearList(&arg, elems)
+	s := arg.String()
+	if len([]rune(s)) == 1 {
+		sb.WriteString(s)
+		return
+	}
+	sb.WriteString("(" + s + ")")
+}
+
+func (r *Run) writeLinear(sb *strings.Builder) {
+	sb.WriteString(r.Text)
+}
+
+func (r *Raw) writeLinear(sb *strings.Builder) {
+	sb.WriteString(r.Text())
+}
+
+func (f *Fraction) writeLinear(sb *strings.Builder) {
+	writeLinearArg(sb, f.Num)
+	if f.Type == "noBar" {
+		sb.WriteString("¦")
+	} else {
+		sb.WriteString("/")
+	}
+	writeLinearArg(sb, f.Den)
+}
+
+func (r *Radical) writeLinear(sb *strings.Builder) {
+	sb.WriteString("√")
+	if len(r.Degree) > 0 {
+		sb.WriteString("(")
+		writeLinearList(sb, r.Degree)
+		sb.WriteString("&")
+		writeLinearList(sb, r.Base)
+		sb.WriteString(")")
+		return
+	}
+	writeLinearArg(sb, r.Base)
+}
+
+func (s *Subscript) writeLinear(sb *strings.Builder) {
+	writeLinearArg(sb, s.Base)
+	sb.WriteString("_")
+	writeLinearArg(sb, s.Sub)
+}
+
+func (s *Superscript) writeLinear(sb *strings.Builder) {
+	writeLinearArg(sb, s.Base)
+	sb.WriteString("^")
+	writeLinearArg(sb, s.Sup)
+}
+
+func (s *SubSuperscript) writeLinear(sb *strings.Builder) {
+	writeLinearArg(sb, s.Base)
+	sb.WriteString("_")
+	writeLinearArg(sb, s.Sub)
+	sb.WriteString("^")
+	writeLinearArg(sb, s.Sup)
+}
+
+func (s *PreSubSuperscript) writeLinear(sb *strings.Builder) {
+	sb.WriteString("_")
+	writeLinearArg(sb, s.Sub)
+	sb.WriteString("^")
+	writeLinearArg(sb, s.Sup)
+	writeLinearArg(sb, s.Base)
+}
+
+func (n *Nary) writeLinear(sb *strings.Builder) {
+	sb.WriteString(n.Operator())
+	if len(n.Sub) > 0 {
+		sb.WriteString("_")
+		writeLinearArg(sb, n.Sub)
+	}
+	if len(n.Sup) > 0 {
+		sb.WriteString("^")
+		writeLinearArg(sb, n.Sup)
+	}
+	sb.WriteString(" ")
+	writeLinearList(sb, n.Base)
+}
+
+// writeLinearRows writes rows of cells in Word's linear matrix form, "■(a&b@c&d)".
+func writeLinearRows(sb *strings.Builder, prefix string, rows [][][]Element) {
+	sb.WriteString(prefix + "(")
+	for i, row := range rows {
+		if i > 0 {
+			sb.WriteString("@")
+		}
+		for j, cell := range row {
+			if j > 0 {
+				sb.WriteString("&")
+			}
+			writeLinearList(sb, cell)
+		}
+	}
+	sb.WriteString(")")
+}
+
+func (m *Matrix) writeLinear(sb *strings.Builder) {
+	writeLinearRows(sb, "■", m.Rows)
+}
+
+func (dl *Delimiter) writeLinear(sb *strings.Builder) {
+	sb.WriteString(dl.Begin)
+	for i, elems := range dl.Elems {
+		if i > 0 {
+			sb.WriteString(dl.Sep)
+		}
+		writeLinearList(sb, elems)
+	}
+	sb.WriteString(dl.End)
+}
+
+func (f *Function) writeLinear(sb *strings.Builder) {
+	writeLinearList(sb, f.Name)
+	sb.WriteString(" ")
+	writeLinearList(sb, f.Base)
+}
+
+func (a *Accent) writeLinear(sb *strings.Builder) {
+	writeLinearArg(sb, a.Base)
+	sb.WriteString(a.Mark())
+}
+
+func (b *Bar) writeLinear(sb *strings.Builder) {
+	if b.Top {
+		sb.WriteString("¯")
+	} else {
+		sb.WriteString("▁")
+	}
+	writeLinearArg(sb, b.Base)
+}
+
+func (l *LimitLower) writeLinear(sb *strings.Builder) {
+	writeLinearList(sb, l.Base)
+	sb.WriteString("┬")
+	writeLinearArg(sb, l.Lim)
+}
+
+func (l *LimitUpper) writeLinear(sb *strings.Builder) {
+	writeLinearList(sb, l.Base)
+	sb.WriteString("┴")
+	writeLinearArg(sb, l.Lim)
+}
+
+func (ea *EquationArray) writeLinear(sb *strings.Builder) {
+	rows := make([][][]Element, len(ea.Rows))
+	for i, row := range ea.Rows {
+		rows[i] = [][]Element{row}
+	}
+	writeLinearRows(sb, "█", rows)
+}
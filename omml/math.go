@@ -0,0 +1,503 @@
+package omml
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+const (
+	// Namespace is the namespace of OMML elements, bound to the "m" prefix.
+	Namespace = "http://schemas.openxmlformats.org/officeDocument/2006/math"
+
+	wmlNamespace = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+)
+
+// NamespaceAttr declares the "m" prefix; it is written on math elements that are placed
+// in parts which may not declare it.
+var NamespaceAttr = xml.Attr{Name: xml.Name{Local: "xmlns:m"}, Value: Namespace}
+
+// Element is a math object of an equation, such as a run of text, a fraction or a matrix.
+type Element interface {
+	xml.Marshaler
+
+	writeLaTeX(w *latexWriter)
+	writeMathML(sb *strings.Builder)
+	writeLinear(sb *strings.Builder)
+}
+
+// Math is an equation (<m:oMath>). Inside a paragraph it is inline math; inside a MathPara
+// it is displayed on its own line.
+type Math struct {
+	Elements []Element
+}
+
+// MathPara is a math paragraph (<m:oMathPara>) of displayed equations.
+type MathPara struct {
+	// Justification of the equations: "center" (the default), "centerGroup", "left" or
+	// "right".
+	Justification string
+
+	Equations []*Math
+}
+
+func (m Math) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "m:oMath"}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeElements(e, m.Elements); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (m *Math) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
+	m.Elements, err = decodeElements(d)
+	return err
+}
+
+func (mp MathPara) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "m:oMathPara"}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if mp.Justification != "" {
+		pr := xml.StartElement{Name: xml.Name{Local: "m:oMathParaPr"}}
+		if err := e.EncodeToken(pr); err != nil {
+			return err
+		}
+		if err := encodeVal(e, "m:jc", mp.Justification); err != nil {
+			return err
+		}
+		if err := e.EncodeToken(pr.End()); err != nil {
+			return err
+		}
+	}
+	for _, m := range mp.Equations {
+		if err := m.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (mp *MathPara) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeChildren(d, func(child xml.StartElement) error {
+		switch child.Name.Local {
+		case "oMathParaPr":
+			var pr properties
+			if err := d.DecodeElement(&pr, &child); err != nil {
+				return err
+			}
+			mp.Justification = pr.Jc.value("")
+		case "oMath":
+			m := &Math{}
+			if err := d.DecodeElement(m, &child); err != nil {
+				return err
+			}
+			mp.Equations = append(mp.Equations, m)
+		default:
+			return d.Skip()
+		}
+		return nil
+	})
+}
+
+// LaTeX returns the equation as LaTeX math, without delimiters such as "$".
+func (m *Math) LaTeX() string {
+	w := &latexWriter{}
+	writeLaTeXList(w, m.Elements)
+	return strings.TrimSpace(w.String())
+}
+
+// MathML returns the equation as a MathML <math> element.
+func (m *Math) MathML() string {
+	return m.mathML(false)
+}
+
+func (m *Math) mathML(display bool) string {
+	var sb strings.Builder
+	sb.WriteString(`<math xmlns="http://www.w3.org/1998/Math/MathML"`)
+	if display {
+		sb.WriteString(` display="block"`)
+	}
+	sb.WriteString(`>`)
+	writeMathMLRow(&sb, m.Elements)
+	sb.WriteString(`</math>`)
+	return sb.String()
+}
+
+// Text returns the equation in a linear form close to Word's linear format, such as
+// "x^2+1/(a+b)".
+func (m *Math) Text() string {
+	var sb strings.Builder
+	writeLinearList(&sb, m.Elements)
+	return sb.String()
+}
+
+// LaTeX returns the equations of the math paragraph as LaTeX, separated by "\\".
+func (mp *MathPara) LaTeX() string {
+	parts := make([]string, len(mp.Equations))
+	for i, m := range mp.Equations {
+		parts[i] = m.LaTeX()
+	}
+	return strings.Join(parts, ` \\ `)
+}
+
+// MathML returns the equations of the math paragraph as MathML <math> elements in
+// display mode.
+func (mp *MathPara) MathML() string {
+	var sb strings.Builder
+	for _, m := range mp.Equations {
+		sb.WriteString(m.mathML(true))
+	}
+	return sb.String()
+}
+
+// Text returns the equations of the math paragraph in linear form, one per line.
+func (mp *MathPara) Text() string {
+	parts := make([]string, len(mp.Equations))
+	for i, m := range mp.Equations {
+		parts[i] = m.Text()
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Run is a run of math text (<m:r>).
+type Run struct {
+	Text string
+
+	// Style is the math style: "p" (plain), "b" (bold), "i" (italic) or "bi". Empty
+	// leaves letters italic and other characters upright.
+	Style string
+
+	// Normal marks ordinary text inside the equation, as written by \text.
+	Normal bool
+
+	// Props are the WordprocessingML run properties (<w:rPr>) of the run, if any.
+	Props *Raw
+}
+
+func (r Run) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "m:r"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if r.Normal || r.Style != "" {
+		pr := xml.StartElement{Name: xml.Name{Local: "m:rPr"}}
+		if err := e.EncodeToken(pr); err != nil {
+			return err
+		}
+		if r.Normal {
+			if err := encodeEmpty(e, "m:nor"); err != nil {
+				return err
+			}
+		} else if err := encodeVal(e, "m:sty", r.Style); err != nil {
+			return err
+		}
+		if err := e.EncodeToken(pr.End()); err != nil {
+			return err
+		}
+	}
+	if r.Props != nil {
+		if err := r.Props.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	t := xml.StartElement{
+		Name: xml.Name{Local: "m:t"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xml:space"}, Value: "preserve"}},
+	}
+	if err := e.EncodeElement(r.Text, t); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (r *Run) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeChildren(d, func(child xml.StartElement) error {
+		switch {
+		case child.Name.Local == "rPr" && child.Name.Space == wmlNamespace:
+			r.Props = &Raw{}
+			return d.DecodeElement(r.Props, &child)
+		case child.Name.Local == "rPr":
+			var pr properties
+			if err := d.DecodeElement(&pr, &child); err != nil {
+				return err
+			}
+			r.Style = pr.Sty.value("")
+			r.Normal = pr.Nor.on()
+		case child.Name.Local == "t":
+			var text string
+			if err := d.DecodeElement(&text, &child); err != nil {
+				return err
+			}
+			r.Text += text
+		default:
+			return d.Skip()
+		}
+		return nil
+	})
+}
+
+// Raw is a math element that this package does not model, such as a box or a phantom. It
+// is kept as is, so that it survives a round trip.
+type Raw struct {
+	// Name is the qualified name of the element, such as "m:box".
+	Name  string
+	Attrs []xml.Attr
+
+	// InnerXML is the content of the element.
+	InnerXML string
+}
+
+func (r Raw) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.Encode(struct {
+		XMLName  xml.Name
+		Attrs    []xml.Attr `xml:",any,attr"`
+		InnerXML string     `xml:",innerxml"`
+	}{xml.Name{Local: r.Name}, r.Attrs, r.InnerXML})
+}
+
+func (r *Raw) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	r.Name = qualifiedName(start.Name)
+	r.Attrs = nil
+	for _, attr := range start.Attr {
+		attr.Name = xml.Name{Local: qualifiedName(attr.Name)}
+		r.Attrs = append(r.Attrs, attr)
+	}
+
+	var inner struct {
+		InnerXML string `xml:",innerxml"`
+	}
+	if err := d.DecodeElement(&inner, &start); err != nil {
+		return err
+	}
+	r.InnerXML = inner.InnerXML
+	return nil
+}
+
+// Text returns the text of the math runs inside the element.
+func (r *Raw) Text() string {
+	var sb strings.Builder
+	d := xml.NewDecoder(strings.NewReader("<raw>" + r.InnerXML + "</raw>"))
+	d.Strict = false
+	inText := false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			inText = t.Name.Local == "t"
+		case xml.EndElement:
+			inText = false
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// qualifiedName returns the prefixed name of an element or attribute of a math zone.
+func qualifiedName(name xml.Name) string {
+	switch name.Space {
+	case "":
+		return name.Local
+	case Namespace:
+		return "m:" + name.Local
+	case wmlNamespace:
+		return "w:" + name.Local
+	case "http://www.w3.org/XML/1998/namespace":
+		return "xml:" + name.Local
+	}
+	if !strings.Contains(name.Space, ":") {
+		// Unbound prefixes are kept as they are.
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// encodeElements writes the elements in order.
+func encodeElements(e *xml.Encoder, elems []Element) error {
+	for _, el := range elems {
+		if err := el.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeArg writes an argument of a math object, such as <m:num>. Arguments are written
+// even when empty, as OMML requires them.
+func encodeArg(e *xml.Encoder, name string, elems []Element) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeElements(e, elems); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// encodeVal writes a property element with an m:val attribute.
+func encodeVal(e *xml.Encoder, name, val string) error {
+	start := xml.StartElement{
+		Name: xml.Name{Local: name},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "m:val"}, Value: val}},
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// encodeEmpty writes an element without attributes or content.
+func encodeEmpty(e *xml.Encoder, name string) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// encodeProps writes a property element, such as <m:fPr>, holding the given m:val
+// properties in order. Nothing is written when there are no properties.
+func encodeProps(e *xml.Encoder, name string, props ...[2]string) error {
+	if len(props) == 0 {
+		return nil
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, p := range props {
+		if err := encodeVal(e, p[0], p[1]); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// decodeChildren calls fn for each child element of the element being decoded, until its
+// end. fn must consume the child element.
+func decodeChildren(d *xml.Decoder, fn func(child xml.StartElement) error) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if err := fn(t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// decodeElements decodes the math objects inside the element being decoded.
+func decodeElements(d *xml.Decoder) ([]Element, error) {
+	var elems []Element
+	err := decodeChildren(d, func(child xml.StartElement) error {
+		var el Element
+		switch child.Name.Local {
+		case "r":
+			if child.Name.Space == wmlNamespace {
+				el = &Raw{}
+			} else {
+				el = &Run{}
+			}
+		case "f":
+			el = &Fraction{}
+		case "rad":
+			el = &Radical{}
+		case "sSub":
+			el = &Subscript{}
+		case "sSup":
+			el = &Superscript{}
+		case "sSubSup":
+			el = &SubSuperscript{}
+		case "sPre":
+			el = &PreSubSuperscript{}
+		case "nary":
+			el = &Nary{}
+		case "m":
+			el = &Matrix{}
+		case "d":
+			el = &Delimiter{}
+		case "func":
+			el = &Function{}
+		case "acc":
+			el = &Accent{}
+		case "bar":
+			el = &Bar{}
+		case "limLow":
+			el = &LimitLower{}
+		case "limUpp":
+			el = &LimitUpper{}
+		case "eqArr":
+			el = &EquationArray{}
+		case "argPr", "ctrlPr":
+			return d.Skip()
+		default:
+			el = &Raw{}
+		}
+		if err := d.DecodeElement(el, &child); err != nil {
+			return err
+		}
+		elems = append(elems, el)
+		return nil
+	})
+	return elems, err
+}
+
+// val is a property element with an m:val attribute.
+type val struct {
+	Val *string `xml:"val,attr"`
+}
+
+// value returns the value of the property, or def when the property or its value is
+// missing.
+func (v *val) value(def string) string {
+	if v == nil || v.Val == nil {
+		return def
+	}
+	return *v.Val
+}
+
+// on reports whether an on/off property is set; a property without a value is on.
+func (v *val) on() bool {
+	if v == nil {
+		return false
+	}
+	switch v.value("on") {
+	case "on", "1", "true":
+		return true
+	}
+	return false
+}
+
+// properties holds the properties of any math object. Each object reads the ones that
+// apply to it.
+type properties struct {
+	Type    *val `xml:"type"`
+	DegHide *val `xml:"degHide"`
+	Chr     *val `xml:"chr"`
+	LimLoc  *val `xml:"limLoc"`
+	SubHide *val `xml:"subHide"`
+	SupHide *val `xml:"supHide"`
+	BegChr  *val `xml:"begChr"`
+	SepChr  *val `xml:"sepChr"`
+	EndChr  *val `xml:"endChr"`
+	Pos     *val `xml:"pos"`
+	Jc      *val `xml:"jc"`
+	Sty     *val `xml:"sty"`
+	Nor     *val `xml:"nor"`
+}
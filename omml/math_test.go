@@ -0,0 +1,55 @@
+package omml
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// wordMathPara is a math paragraph as Word writes it, with control properties, run
+// properties and a box, which this package keeps as raw XML.
+const wordMathPara = `<m:oMathPara xmlns:m="http://schemas.openxmlformats.org/officeDocument/2006/math" xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<m:oMathParaPr><m:jc m:val="left"/></m:oMathParaPr><m:oMath>` +
+	`<m:sSup><m:sSupPr><m:ctrlPr><w:rPr><w:rFonts w:ascii="Cambria Math"/><w:i/></w:rPr></m:ctrlPr></m:sSupPr>` +
+	`<m:e><m:r><w:rPr><w:rFonts w:ascii="Cambria Math"/></w:rPr><m:t>e</m:t></m:r></m:e>` +
+	`<m:sup><m:r><m:t>iπ</m:t></m:r></m:sup></m:sSup>` +
+	`<m:r><m:t>+</m:t></m:r><m:box><m:e><m:r><m:t>1</m:t></m:r></m:e></m:box><m:r><m:t>=0</m:t></m:r>` +
+	`</m:oMath></m:oMathPara>`
+
+func TestUnmarshalMathPara(t *testing.T) {
+	var mp MathPara
+	if err := xml.Unmarshal([]byte(wordMathPara), &mp); err != nil {
+		t.Fatal(err)
+	}
+	if mp.Justification != "left" || len(mp.Equations) != 1 {
+		t.Fatalf("unexpected math paragraph %+v", mp)
+	}
+	if got := mp.LaTeX(); got != `e^{i\pi}+1=0` {
+		t.Errorf("LaTeX() = %s", got)
+	}
+	if got := mp.Text(); got != "e^(iπ)+1=0" {
+		t.Errorf("Text() = %s", got)
+	}
+
+	sup, ok := mp.Equations[0].Elements[0].(*Superscript)
+	if !ok {
+		t.Fatalf("expected a superscript, got %T", mp.Equations[0].Elements[0])
+	}
+	if run := sup.Base[0].(*Run); run.Props == nil || !strings.Contains(run.Props.InnerXML, "Cambria Math") {
+		t.Errorf("run properties not kept: %+v", run.Props)
+	}
+
+	out, err := xml.Marshal(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<m:oMathPara><m:oMathParaPr><m:jc m:val="left"></m:jc></m:oMathParaPr>`,
+		`<m:r><w:rPr><w:rFonts w:ascii="Cambria Math"/></w:rPr><m:t xml:space="preserve">e</m:t></m:r>`,
+		`<m:box><m:e><m:r><m:t>1</m:t></m:r></m:e></m:box>`,
+	} {
+		if !strings.Contains(string(out), expected) {
+			t.Errorf("%s does not contain %s", out, expected)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package omml
+
+import (
+	"encoding/xml"
+	"strings"
+	"unicode"
+)
+
+// escape returns s with the XML special characters escaped.
+func escape(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// writeMathMLRow writes the elements as one MathML row.
+func writeMathMLRow(sb *strings.Builder, elems []Element) {
+	if len(elems) == 1 {
+		if r, ok := elems[0].(*Run); !ok || r.isToken() {
+			elems[0].writeMathML(sb)
+			return
+		}
+	}
+	sb.WriteString("<mrow>")
+	for _, el := range elems {
+		el.writeMathML(sb)
+	}
+	sb.WriteString("</mrow>")
+}
+
+// writeToken writes a MathML token element.
+func writeToken(sb *strings.Builder, tag, text string, attrs ...string) {
+	sb.WriteString("<" + tag)
+	for _, attr := range attrs {
+		sb.WriteString(" " + attr)
+	}
+	sb.WriteString(">" + escape(text) + "</" + tag + ">")
+}
+
+// isToken reports whether the run is written as a single MathML token.
+func (r *Run) isToken() bool {
+	if r.Normal || r.Style == "p" && isLetters(r.Text) || len([]rune(r.Text)) <= 1 {
+		return true
+	}
+	for _, c := range r.Text {
+		if !unicode.IsDigit(c) && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Run) writeMathML(sb *strings.Builder) {
+	switch {
+	case r.Normal:
+		writeToken(sb, "mtext", r.Text)
+		return
+	case r.Style == "p" && isLetters(r.Text):
+		writeToken(sb, "mi", r.Text)
+		return
+	}
+
+	var variant []string
+	switch r.Style {
+	case "p":
+		variant = []string{`mathvariant="normal"`}
+	case "b":
+		variant = []string{`mathvariant="bold"`}
+	case "bi":
+		variant = []string{`mathvariant="bold-italic"`}
+	}
+
+	runes := []rune(r.Text)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case unicode.IsDigit(c):
+			j := i
+			for j+1 < len(runes) && (unicode.IsDigit(runes[j+1]) || runes[j+1] == '.' && j+2 < len(runes) && unicode.IsDigit(runes[j+2])) {
+				j++
+			}
+			writeToken(sb, "mn", string(runes[i:j+1]))
+			i = j
+		case unicode.IsLetter(c):
+			writeToken(sb, "mi", string(c), variant...)
+		case unicode.IsSpace(c):
+			writeToken(sb, "mspace", "", `width="0.2em"`)
+		default:
+			writeToken(sb, "mo", string(c))
+		}
+	}
+}
+
+func (r *Raw) writeMathML(sb *strings.Builder) {
+	writeToken(sb, "mtext", r.Text())
+}
+
+func (f *Fraction) writeMathML(sb *strings.Builder) {
+	switch f.Type {
+	case "lin", "skw":
+		sb.WriteString("<mrow>")
+		writeMathMLRow(sb, f.Num)
+		writeToken(sb, "mo", "/")
+		writeMathMLRow(sb, f.Den)
+		sb.WriteString("</mrow>")
+		return
+	case "noBar":
+		sb.WriteString(`<mfrac linethickness="0">`)
+	default:
+		sb.WriteString("<mfrac>")
+	}
+	writeMathMLRow(sb, f.Num)
+	writeMathMLRow(sb, f.Den)
+	sb.WriteString("</mfrac>")
+}
+
+func (r *Radical) writeMathML(sb *strings.Builder) {
+	if len(r.Degree) == 0 {
+		sb.WriteString("<msqrt>")
+		writeMathMLRow(sb, r.Base)
+		sb.WriteString("</msqrt>")
+		return
+	}
+	sb.WriteString("<mroot>")
+	writeMathMLRow(sb, r.Base)
+	writeMathMLRow(sb, r.Degree)
+	sb.WriteString("</mroot>")
+}
+
+// writeMathMLElement writes a MathML element holding the rows in order.
+func writeMathMLElement(sb *strings.Builder, tag string, rows ...[]Element) {
+	sb.WriteString("<" + tag + ">")
+	for _, row := range rows {
+		writeMathMLRow(sb, row)
+	}
+	sb.WriteString("</" + tag + ">")
+}
+
+func (s *Subscript) writeMathML(sb *strings.Builder) {
+	writeMathMLElement(sb, "msub", s.Base, s.Sub)
+}
+
+func (s *Superscript) writeMathML(sb *strings.Builder) {
+	writeMathMLElement(sb, "msup", s.Base, s.Sup)
+}
+
+func (s *SubSuperscript) writeMathML(sb *strings.Builder) {
+	writeMathMLElement(sb, "msubsup", s.Base, s.Sub, s.Sup)
+}
+
+func (s *PreSubSuperscript) writeMathML(sb *strings.Builder) {
+	sb.WriteString("<mmultiscripts>")
+	writeMathMLRow(sb, s.Base)
+	sb.WriteString("<mprescripts/>")
+	writeMathMLRow(sb, s.Sub)
+	writeMathMLRow(sb, s.Sup)
+	sb.WriteString("</mmultiscripts>")
+}
+
+func (n *Nary) writeMathML(sb *strings.Builder) {
+	sb.WriteString("<mrow>")
+	op := &Run{Text: n.Operator()}
+	under, over := "msub", "msup"
+	both := "msubsup"
+	if n.LimLoc == "undOvr" {
+		under, over, both = "munder", "mover", "munderover"
+	}
+	switch {
+	case len(n.Sub) > 0 && len(n.Sup) > 0:
+		writeMathMLElement(sb, both, []Element{op}, n.Sub, n.Sup)
+	case len(n.Sub) > 0:
+		writeMathMLElement(sb, under, []Element{op}, n.Sub)
+	case len(n.Sup) > 0:
+		writeMathMLElement(sb, over, []Element{op}, n.Sup)
+	default:
+		op.writeMathML(sb)
+	}
+	writeMathMLRow(sb, n.Base)
+	sb.WriteString("</mrow>")
+}
+
+// writeMathMLTable writes rows of cells as a MathML table.
+func writeMathMLTable(sb *strings.Builder, rows [][][]Element) {
+	sb.WriteString("<mtable>")
+	for _, row := range rows {
+		sb.WriteString("<mtr>")
+		for _, cell := range row {
+			writeMathMLElement(sb, "mtd", cell)
+		}
+		sb.WriteString("</mtr>")
+	}
+	sb.WriteString("</mtable>")
+}
+
+func (m *Matrix) writeMathML(sb *strings.Builder) {
+	writeMathMLTable(sb, m.Rows)
+}
+
+func (dl *Delimiter) writeMathML(sb *strings.Builder) {
+	sb.WriteString("<mrow>")
+	if dl.Begin != "" {
+		writeToken(sb, "mo", dl.Begin, `fence="true"`)
+	}
+	for i, elems := range dl.Elems {
+		if i > 0 {
+			writeToken(sb, "mo", dl.Sep, `separator="true"`)
+		}
+		writeMathMLRow(sb, elems)
+	}
+	if dl.End != "" {
+		writeToken(sb, "mo", dl.End, `fence="true"`)
+	}
+	sb.WriteString("</mrow>")
+}
+
+func (f *Function) writeMathML(sb *strings.Builder) {
+	sb.WriteString("<mrow>")
+	writeMathMLRow(sb, f.Name)
+	writeToken(sb, "mo", "\u2061")
+	writeMathMLRow(sb, f.Base)
+	sb.WriteString("</mrow>")
+}
+
+func (a *Accent) writeMathML(sb *strings.Builder) {
+	sb.WriteString(`<mover accent="true">`)
+	writeMathMLRow(sb, a.Base)
+	writeToken(sb, "mo", a.Mark())
+	sb.WriteString("</mover>")
+}
+
+func (b *Bar) writeMathML(sb *strings.Builder) {
+	if b.Top {
+		sb.WriteString("<mover>")
+		writeMathMLRow(sb, b.Base)
+		writeToken(sb, "mo", "¯")
+		sb.WriteString("</mover>")
+		return
+	}
+	sb.WriteString("<munder>")
+	writeMathMLRow(sb, b.Base)
+	writeToken(sb, "mo", "_")
+	sb.WriteString("</munder>")
+}
+
+func (l *LimitLower) writeMathML(sb *strings.Builder) {
+	writeMathMLElement(sb, "munder", l.Base, l.Lim)
+}
+
+func (l *LimitUpper) writeMathML(sb *strings.Builder) {
+	writeMathMLElement(sb, "mover", l.Base, l.Lim)
+}
+
+func (ea *EquationArray) writeMathML(sb *strings.Builder) {
+	rows := make([][][]Element, len(ea.Rows))
+	for i, row := range ea.Rows {
+		rows[i] = splitAlignment(row)
+	}
+	writeMathMLTable(sb, rows)
+}
+
+// splitAlignment splits a row of an equation array into cells at its "&" alignment
+// points.
+func splitAlignment(row []Element) [][]Element {
+	cells := [][]Element{nil}
+	for _, el := range row {
+		r, ok := el.(*Run)
+		if !ok || !strings.Contains(r.Text, "&") {
+			cells[len(cells)-1] = append(cells[len(cells)-1], el)
+			continue
+		}
+		for i, part := range strings.Split(r.Text, "&") {
+			if i > 0 {
+				cells = append(cells, nil)
+			}
+			if part != "" {
+				cells[len(cells)-1] = append(cells[len(cells)-1], &Run{Text: part, Style: r.Style, Normal: r.Normal})
+			}
+		}
+	}
+	return cells
+}
@@ -0,0 +1,132 @@
+package omml
+
+// symbols maps LaTeX commands to the characters they stand for. When several commands
+// map to the same character, the first one is used to write LaTeX.
+var symbols = []struct {
+	cmd  string
+	char string
+}{
+	// Greek letters
+	{"alpha", "α"}, {"beta", "β"}, {"gamma", "γ"}, {"delta", "δ"}, {"epsilon", "ϵ"},
+	{"varepsilon", "ε"}, {"zeta", "ζ"}, {"eta", "η"}, {"theta", "θ"}, {"vartheta", "ϑ"},
+	{"iota", "ι"}, {"kappa", "κ"}, {"lambda", "λ"}, {"mu", "μ"}, {"nu", "ν"}, {"xi", "ξ"},
+	{"pi", "π"}, {"varpi", "ϖ"}, {"rho", "ρ"}, {"varrho", "ϱ"}, {"sigma", "σ"},
+	{"varsigma", "ς"}, {"tau", "τ"}, {"upsilon", "υ"}, {"phi", "ϕ"}, {"varphi", "φ"},
+	{"chi", "χ"}, {"psi", "ψ"}, {"omega", "ω"},
+	{"Gamma", "Γ"}, {"Delta", "Δ"}, {"Theta", "Θ"}, {"Lambda", "Λ"}, {"Xi", "Ξ"}, {"Pi", "Π"},
+	{"Sigma", "Σ"}, {"Upsilon", "Υ"}, {"Phi", "Φ"}, {"Psi", "Ψ"}, {"Omega", "Ω"},
+
+	// Operators and relations
+	{"pm", "±"}, {"mp", "∓"}, {"times", "×"}, {"div", "÷"}, {"cdot", "⋅"}, {"ast", "∗"},
+	{"star", "⋆"}, {"circ", "∘"}, {"bullet", "∙"}, {"oplus", "⊕"}, {"otimes", "⊗"},
+	{"cap", "∩"}, {"cup", "∪"}, {"wedge", "∧"}, {"land", "∧"}, {"vee", "∨"}, {"lor", "∨"},
+	{"setminus", "∖"}, {"neg", "¬"}, {"lnot", "¬"},
+	{"leq", "≤"}, {"le", "≤"}, {"geq", "≥"}, {"ge", "≥"}, {"neq", "≠"}, {"ne", "≠"},
+	{"ll", "≪"}, {"gg", "≫"}, {"approx", "≈"}, {"equiv", "≡"}, {"sim", "∼"}, {"simeq", "≃"},
+	{"cong", "≅"}, {"propto", "∝"}, {"in", "∈"}, {"notin", "∉"}, {"ni", "∋"},
+	{"subset", "⊂"}, {"supset", "⊃"}, {"subseteq", "⊆"}, {"supseteq", "⊇"}, {"perp", "⊥"},
+	{"parallel", "∥"}, {"mid", "∣"},
+	{"to", "→"}, {"rightarrow", "→"}, {"leftarrow", "←"}, {"gets", "←"},
+	{"leftrightarrow", "↔"}, {"Rightarrow", "⇒"}, {"implies", "⇒"}, {"Leftarrow", "⇐"},
+	{"Leftrightarrow", "⇔"}, {"iff", "⇔"}, {"mapsto", "↦"}, {"uparrow", "↑"},
+	{"downarrow", "↓"},
+
+	// Miscellaneous symbols
+	{"infty", "∞"}, {"partial", "∂"}, {"nabla", "∇"}, {"forall", "∀"}, {"exists", "∃"},
+	{"emptyset", "∅"}, {"varnothing", "∅"}, {"hbar", "ℏ"}, {"ell", "ℓ"}, {"Re", "ℜ"},
+	{"Im", "ℑ"}, {"aleph", "ℵ"}, {"angle", "∠"}, {"degree", "°"}, {"prime", "′"},
+	{"ldots", "…"}, {"dots", "…"}, {"cdots", "⋯"}, {"vdots", "⋮"}, {"ddots", "⋱"},
+	{"therefore", "∴"}, {"because", "∵"},
+
+	// Delimiters
+	{"langle", "⟨"}, {"rangle", "⟩"}, {"lfloor", "⌊"}, {"rfloor", "⌋"}, {"lceil", "⌈"},
+	{"rceil", "⌉"}, {"lvert", "|"}, {"rvert", "|"}, {"vert", "|"}, {"lVert", "‖"},
+	{"rVert", "‖"}, {"Vert", "‖"}, {"|", "‖"}, {"{", "{"}, {"}", "}"}, {"lbrace", "{"},
+	{"rbrace", "}"},
+
+	// Spaces and escaped characters
+	{",", "\u2009"}, {":", "\u205f"}, {";", "\u2005"}, {"quad", "\u2003"},
+	{"qquad", "\u2003\u2003"}, {" ", " "}, {"%", "%"}, {"#", "#"}, {"$", "$"}, {"_", "_"},
+	{"&", "&"}, {"backslash", "\\"},
+}
+
+// naryOperators maps LaTeX commands of n-ary operators to their characters.
+var naryOperators = map[string]string{
+	"sum": "∑", "prod": "∏", "coprod": "∐", "int": "∫", "iint": "∬", "iiint": "∭",
+	"oint": "∮", "bigcup": "⋃", "bigcap": "⋂", "bigoplus": "⨁", "bigotimes": "⨂",
+	"bigvee": "⋁", "bigwedge": "⋀",
+}
+
+// functionNames are the LaTeX commands written as upright function names.
+var functionNames = map[string]bool{
+	"sin": true, "cos": true, "tan": true, "cot": true, "sec": true, "csc": true,
+	"arcsin": true, "arccos": true, "arctan": true, "sinh": true, "cosh": true, "tanh": true,
+	"coth": true, "log": true, "ln": true, "lg": true, "exp": true, "det": true, "dim": true,
+	"gcd": true, "hom": true, "ker": true, "max": true, "min": true, "sup": true, "inf": true,
+	"lim": true, "liminf": true, "limsup": true, "arg": true, "deg": true, "Pr": true,
+}
+
+// limitFunctions are the function names whose subscripts are written below the name.
+var limitFunctions = map[string]bool{
+	"lim": true, "liminf": true, "limsup": true, "max": true, "min": true, "sup": true,
+	"inf": true, "det": true, "gcd": true, "Pr": true,
+}
+
+// accents maps LaTeX accent commands to the combining characters OMML uses.
+var accents = []struct {
+	cmd  string
+	char string
+}{
+	{"hat", "\u0302"}, {"widehat", "\u0302"}, {"tilde", "\u0303"}, {"widetilde", "\u0303"},
+	{"bar", "\u0305"}, {"breve", "\u0306"}, {"dot", "\u0307"}, {"ddot", "\u0308"},
+	{"check", "\u030c"}, {"vec", "\u20d7"}, {"overrightarrow", "\u20d7"},
+}
+
+// doubleStruck maps letters to the double-struck letters written by \mathbb.
+var doubleStruck = map[rune]rune{
+	'C': 'ℂ', 'H': 'ℍ', 'N': 'ℕ', 'P': 'ℙ', 'Q': 'ℚ', 'R': 'ℝ', 'Z': 'ℤ',
+}
+
+// matrixDelimiters maps LaTeX matrix environments to the delimiters around them.
+var matrixDelimiters = map[string][2]string{
+	"matrix": {"", ""}, "smallmatrix": {"", ""}, "pmatrix": {"(", ")"}, "bmatrix": {"[", "]"},
+	"Bmatrix": {"{", "}"}, "vmatrix": {"|", "|"}, "Vmatrix": {"‖", "‖"},
+}
+
+var (
+	symbolChars   = map[string]string{} // LaTeX command to character
+	symbolCmds    = map[string]string{} // Character to LaTeX command
+	accentChars   = map[string]string{} // LaTeX command to combining character
+	accentCmds    = map[string]string{} // Combining character to LaTeX command
+	naryCmds      = map[string]string{} // N-ary operator character to LaTeX command
+	doubleLetters = map[rune]rune{}     // Double-struck letter to letter
+)
+
+func init() {
+	for _, s := range symbols {
+		symbolChars[s.cmd] = s.char
+		if _, ok := symbolCmds[s.char]; !ok {
+			symbolCmds[s.char] = s.cmd
+		}
+	}
+	for _, a := range accents {
+		accentChars[a.cmd] = a.char
+		if _, ok := accentCmds[a.char]; !ok {
+			accentCmds[a.char] = a.cmd
+		}
+	}
+	for cmd, char := range naryOperators {
+		naryCmds[char] = cmd
+	}
+	for letter, ds := range doubleStruck {
+		doubleLetters[ds] = letter
+	}
+}
+
+// relations are the characters that end the operand of an n-ary operator or function.
+var relations = map[string]bool{
+	"+": true, "−": true, "=": true, "<": true, ">": true, ",": true, ";": true, "±": true,
+	"∓": true, "≤": true, "≥": true, "≠": true, "≈": true, "≡": true, "∼": true, "≃": true,
+	"≅": true, "∝": true, "→": true, "←": true, "↔": true, "⇒": true, "⇐": true, "⇔": true,
+	"∈": true, "∉": true, "⊂": true, "⊃": true, "⊆": true, "⊇": true, "≪": true, "≫": true,
+}
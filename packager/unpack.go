@@ -131,6 +131,24 @@ func Unpack(content *[]byte) (*docx.RootDoc, error) {
 			}
 			delete(fileIndex, stylesPath)
 			rd.DocStyles = stylesObj
+		case constants.SettingsType:
+			if relation.Target == "" {
+				continue
+			}
+			settingsPath := path.Join(wordDir, relation.Target)
+			settingsFile, ok := fileIndex[settingsPath]
+			if !ok {
+				// A relationship to a missing part leaves the document without settings.
+				continue
+			}
+
+			// Load Settings
+			settingsObj, err := docx.LoadSettings(settingsPath, settingsFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load settings %s: %v", settingsPath, err)
+			}
+			delete(fileIndex, settingsPath)
+			rd.Settings = settingsObj
 		case constants.HeaderType:
 			// 处理 Header
 			headerFileName := relation.Target
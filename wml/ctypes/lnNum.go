@@ -0,0 +1,64 @@
+package ctypes
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// Line Numbering Settings : w:lnNumType
+type LineNumbering struct {
+	CountBy  *int                     // Line Number Increments to Display
+	Start    *int                     // Line Numbering Starting Value
+	Distance *int                     // Distance Between Text and Line Numbering, in twips
+	Restart  stypes.LineNumberRestart // Line Numbering Restart Setting
+}
+
+func (l LineNumbering) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "w:lnNumType"
+	start.Attr = nil
+
+	if l.CountBy != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:countBy"}, Value: strconv.Itoa(*l.CountBy)})
+	}
+	if l.Start != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:start"}, Value: strconv.Itoa(*l.Start)})
+	}
+	if l.Distance != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:distance"}, Value: strconv.Itoa(*l.Distance)})
+	}
+	if l.Restart != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:restart"}, Value: string(l.Restart)})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (l *LineNumbering) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "countBy", "start", "distance":
+			val, err := strconv.Atoi(attr.Value)
+			if err != nil {
+				return err
+			}
+			switch attr.Name.Local {
+			case "countBy":
+				l.CountBy = &val
+			case "start":
+				l.Start = &val
+			default:
+				l.Distance = &val
+			}
+		case "restart":
+			if err := l.Restart.UnmarshalXMLAttr(attr); err != nil {
+				return err
+			}
+		}
+	}
+	return d.Skip()
+}
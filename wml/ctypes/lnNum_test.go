@@ -0,0 +1,58 @@
+package ctypes
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iEvan-lhr/docx-agent/internal"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+func TestLineNumberingAndCols(t *testing.T) {
+	sectPr := SectionProp{
+		LnNumType: &LineNumbering{
+			CountBy:  internal.ToPtr(5),
+			Distance: internal.ToPtr(360),
+			Restart:  stypes.LineNumberRestartNewSection,
+		},
+		PageNum: &PageNumbering{Format: stypes.NumFmtDecimal},
+		Cols: &Cols{
+			EqualWidth: internal.ToPtr(false),
+			Cols:       []Col{{Width: 3000, Space: 720}, {Width: 5000}},
+		},
+	}
+
+	output, err := xml.Marshal(sectPr)
+	if err != nil {
+		t.Fatalf("Error marshaling SectionProp: %v", err)
+	}
+
+	got := string(output)
+	expected := `<w:lnNumType w:countBy="5" w:distance="360" w:restart="newSection"></w:lnNumType>` +
+		`<w:pgNumType w:fmt="decimal"></w:pgNumType>` +
+		`<w:cols w:equalWidth="false"><w:col w:w="3000" w:space="720"></w:col><w:col w:w="5000" w:space="0"></w:col></w:cols>`
+	if !strings.Contains(got, expected) {
+		t.Errorf("Expected XML to contain\n%s\ngot\n%s", expected, got)
+	}
+
+	var decoded SectionProp
+	if err := xml.Unmarshal(output, &decoded); err != nil {
+		t.Fatalf("Error unmarshaling SectionProp: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.LnNumType, sectPr.LnNumType) {
+		t.Errorf("LnNumType mismatch\nExpected: %#v\nActual:   %#v", sectPr.LnNumType, decoded.LnNumType)
+	}
+	if !reflect.DeepEqual(decoded.Cols, sectPr.Cols) {
+		t.Errorf("Cols mismatch\nExpected: %#v\nActual:   %#v", sectPr.Cols, decoded.Cols)
+	}
+
+	var cols Cols
+	if err := xml.Unmarshal([]byte(`<w:cols w:num="2" w:space="425" w:sep="1"/>`), &cols); err != nil {
+		t.Fatalf("Error unmarshaling Cols: %v", err)
+	}
+	if cols.Num == nil || *cols.Num != 2 || cols.Space == nil || *cols.Space != "425" || cols.Sep == nil || !*cols.Sep {
+		t.Errorf("Unexpected Cols: %#v", cols)
+	}
+}
@@ -0,0 +1,384 @@
+package ctypes
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+// Numbering Definitions
+//
+// Numbering is a parsed view of the abstract numbering definitions and numbering
+// instances of a numbering part. Elements it does not model, such as picture bullets,
+// are not retained.
+type Numbering struct {
+	//1. Abstract Numbering Definitions
+	AbstractNums []AbstractNum `xml:"abstractNum"`
+
+	//2. Numbering Definition Instances
+	Nums []Num `xml:"num"`
+}
+
+// AbstractNum returns the abstract numbering definition with the given ID, or nil.
+func (n *Numbering) AbstractNum(id int) *AbstractNum {
+	for i := range n.AbstractNums {
+		if n.AbstractNums[i].ID == id {
+			return &n.AbstractNums[i]
+		}
+	}
+	return nil
+}
+
+// Num returns the numbering instance with the given ID, or nil.
+func (n *Numbering) Num(id int) *Num {
+	for i := range n.Nums {
+		if n.Nums[i].ID == id {
+			return &n.Nums[i]
+		}
+	}
+	return nil
+}
+
+// Abstract Numbering Definition
+type AbstractNum struct {
+	//Abstract Numbering Definition ID
+	ID int `xml:"abstractNumId,attr"`
+
+	//Sequence:
+
+	//1. Abstract Numbering Definition Identifier
+	Nsid *GenSingleStrVal[stypes.LongHexNum] `xml:"nsid,omitempty"`
+
+	//2. Abstract Numbering Definition Type
+	MultiLevelType *CTString `xml:"multiLevelType,omitempty"`
+
+	//3. Numbering Template Code
+	Tmpl *GenSingleStrVal[stypes.LongHexNum] `xml:"tmpl,omitempty"`
+
+	//4. Abstract Numbering Definition Name
+	Name *CTString `xml:"name,omitempty"`
+
+	//5. Numbering Style Definition
+	StyleLink *CTString `xml:"styleLink,omitempty"`
+
+	//6. Numbering Style Reference
+	NumStyleLink *CTString `xml:"numStyleLink,omitempty"`
+
+	//7. Numbering Level Definitions
+	Levels []NumLevel `xml:"lvl"`
+}
+
+// Level returns the definition of the given level, or nil.
+func (a *AbstractNum) Level(ilvl int) *NumLevel {
+	for i := range a.Levels {
+		if a.Levels[i].Ilvl == ilvl {
+			return &a.Levels[i]
+		}
+	}
+	return nil
+}
+
+func (a AbstractNum) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "w:abstractNum"
+	start.Attr = []xml.Attr{{Name: xml.Name{Local: "w:abstractNumId"}, Value: strconv.Itoa(a.ID)}}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if a.Nsid != nil {
+		if err := a.Nsid.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:nsid"}}); err != nil {
+			return fmt.Errorf("nsid: %w", err)
+		}
+	}
+
+	if a.MultiLevelType != nil {
+		if err := a.MultiLevelType.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:multiLevelType"}}); err != nil {
+			return fmt.Errorf("multiLevelType: %w", err)
+		}
+	}
+
+	if a.Tmpl != nil {
+		if err := a.Tmpl.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:tmpl"}}); err != nil {
+			return fmt.Errorf("tmpl: %w", err)
+		}
+	}
+
+	if a.Name != nil {
+		if err := a.Name.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:name"}}); err != nil {
+			return fmt.Errorf("name: %w", err)
+		}
+	}
+
+	if a.StyleLink != nil {
+		if err := a.StyleLink.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:styleLink"}}); err != nil {
+			return fmt.Errorf("styleLink: %w", err)
+		}
+	}
+
+	if a.NumStyleLink != nil {
+		if err := a.NumStyleLink.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:numStyleLink"}}); err != nil {
+			return fmt.Errorf("numStyleLink: %w", err)
+		}
+	}
+
+	for _, lvl := range a.Levels {
+		if err := lvl.MarshalXML(e, xml.StartElement{}); err != nil {
+			return fmt.Errorf("lvl: %w", err)
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// Numbering Level Definition
+type NumLevel struct {
+	//Numbering Level
+	Ilvl int `xml:"ilvl,attr"`
+
+	//Template Code
+	Tplc *string `xml:"tplc,attr,omitempty"`
+
+	//Tentative Numbering
+	Tentative *stypes.OnOff `xml:"tentative,attr,omitempty"`
+
+	//Sequence:
+
+	//1. Starting Value
+	Start *DecimalNum `xml:"start,omitempty"`
+
+	//2. Numbering Format
+	NumFmt *NumFmt `xml:"numFmt,omitempty"`
+
+	//3. Restart Numbering Level Symbol
+	LvlRestart *DecimalNum `xml:"lvlRestart,omitempty"`
+
+	//4. Paragraph Style's Associated Numbering Level
+	PStyle *CTString `xml:"pStyle,omitempty"`
+
+	//5. Display All Levels Using Arabic Numerals
+	IsLgl *OnOff `xml:"isLgl,omitempty"`
+
+	//6. Content Between Numbering Symbol and Paragraph Text
+	Suff *CTString `xml:"suff,omitempty"`
+
+	//7. Numbering Level Text
+	LvlText *CTString `xml:"lvlText,omitempty"`
+
+	//8. Picture Numbering Symbol Definition Reference
+	LvlPicBulletID *DecimalNum `xml:"lvlPicBulletId,omitempty"`
+
+	//9. Justification
+	LvlJc *CTString `xml:"lvlJc,omitempty"`
+
+	//10. Numbering Level Associated Paragraph Properties
+	PPr *ParagraphProp `xml:"pPr,omitempty"`
+
+	//11. Numbering Symbol Run Properties
+	RPr *RunProperty `xml:"rPr,omitempty"`
+}
+
+func (l NumLevel) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "w:lvl"
+	start.Attr = []xml.Attr{{Name: xml.Name{Local: "w:ilvl"}, Value: strconv.Itoa(l.Ilvl)}}
+	if l.Tplc != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:tplc"}, Value: *l.Tplc})
+	}
+	if l.Tentative != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:tentative"}, Value: string(*l.Tentative)})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if l.Start != nil {
+		if err := l.Start.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:start"}}); err != nil {
+			return fmt.Errorf("start: %w", err)
+		}
+	}
+
+	if l.NumFmt != nil {
+		if err := l.NumFmt.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:numFmt"}}); err != nil {
+			return fmt.Errorf("numFmt: %w", err)
+		}
+	}
+
+	if l.LvlRestart != nil {
+		if err := l.LvlRestart.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:lvlRestart"}}); err != nil {
+			return fmt.Errorf("lvlRestart: %w", err)
+		}
+	}
+
+	if l.PStyle != nil {
+		if err := l.PStyle.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:pStyle"}}); err != nil {
+			return fmt.Errorf("pStyle: %w", err)
+		}
+	}
+
+	if l.IsLgl != nil {
+		if err := l.IsLgl.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:isLgl"}}); err != nil {
+			return fmt.Errorf("isLgl: %w", err)
+		}
+	}
+
+	if l.Suff != nil {
+		if err := l.Suff.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:suff"}}); err != nil {
+			return fmt.Errorf("suff: %w", err)
+		}
+	}
+
+	if l.LvlText != nil {
+		if err := l.LvlText.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:lvlText"}}); err != nil {
+			return fmt.Errorf("lvlText: %w", err)
+		}
+	}
+
+	if l.LvlPicBulletID != nil {
+		if err := l.LvlPicBulletID.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:lvlPicBulletId"}}); err != nil {
+			return fmt.Errorf("lvlPicBulletId: %w", err)
+		}
+	}
+
+	if l.LvlJc != nil {
+		if err := l.LvlJc.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:lvlJc"}}); err != nil {
+			return fmt.Errorf("lvlJc: %w", err)
+		}
+	}
+
+	if l.PPr != nil {
+		if err := l.PPr.MarshalXML(e, xml.StartElement{}); err != nil {
+			return fmt.Errorf("pPr: %w", err)
+		}
+	}
+
+	if l.RPr != nil {
+		if err := l.RPr.MarshalXML(e, xml.StartElement{}); err != nil {
+			return fmt.Errorf("rPr: %w", err)
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// Numbering Definition Instance
+type Num struct {
+	//Numbering Definition Instance ID
+	ID int `xml:"numId,attr"`
+
+	//Sequence:
+
+	//1. Abstract Numbering Definition Reference
+	AbstractNumID DecimalNum `xml:"abstractNumId"`
+
+	//2. Numbering Level Definition Overrides
+	Overrides []LvlOverride `xml:"lvlOverride"`
+}
+
+// Override returns the override of the given level, or nil.
+func (n *Num) Override(ilvl int) *LvlOverride {
+	for i := range n.Overrides {
+		if n.Overrides[i].Ilvl == ilvl {
+			return &n.Overrides[i]
+		}
+	}
+	return nil
+}
+
+func (n Num) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "w:num"
+	start.Attr = []xml.Attr{{Name: xml.Name{Local: "w:numId"}, Value: strconv.Itoa(n.ID)}}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if err := n.AbstractNumID.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:abstractNumId"}}); err != nil {
+		return fmt.Errorf("abstractNumId: %w", err)
+	}
+
+	for _, o := range n.Overrides {
+		if err := o.MarshalXML(e, xml.StartElement{}); err != nil {
+			return fmt.Errorf("lvlOverride: %w", err)
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// Numbering Level Definition Override
+type LvlOverride struct {
+	//Numbering Level ID
+	Ilvl int `xml:"ilvl,attr"`
+
+	//Sequence:
+
+	//1. Numbering Level Starting Value Override
+	StartOverride *DecimalNum `xml:"startOverride,omitempty"`
+
+	//2. Numbering Level Override Definition
+	Lvl *NumLevel `xml:"lvl,omitempty"`
+}
+
+func (o LvlOverride) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "w:lvlOverride"
+	start.Attr = []xml.Attr{{Name: xml.Name{Local: "w:ilvl"}, Value: strconv.Itoa(o.Ilvl)}}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if o.StartOverride != nil {
+		if err := o.StartOverride.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:startOverride"}}); err != nil {
+			return fmt.Errorf("startOverride: %w", err)
+		}
+	}
+
+	if o.Lvl != nil {
+		// The level always describes the overridden level.
+		lvl := *o.Lvl
+		lvl.Ilvl = o.Ilvl
+		if err := lvl.MarshalXML(e, xml.StartElement{}); err != nil {
+			return fmt.Errorf("lvl: %w", err)
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// Numbering Format
+type NumFmt struct {
+	//Numbering Format Type
+	Val stypes.NumFmt `xml:"val,attr"`
+
+	//Custom Defined Number Format
+	Format *string `xml:"format,attr,omitempty"`
+}
+
+func NewNumFmt(value stypes.NumFmt) *NumFmt {
+	return &NumFmt{Val: value}
+}
+
+func (n NumFmt) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:val"}, Value: string(n.Val)})
+	if n.Format != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:format"}, Value: *n.Format})
+	}
+	return e.EncodeElement("", start)
+}
+
+// UnmarshalXML keeps the format as written: numbering parts in the wild use values, such
+// as "custom", that are newer than the enumeration of stypes.NumFmt.
+func (n *NumFmt) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "val":
+			n.Val = stypes.NumFmt(attr.Value)
+		case "format":
+			n.Format = &attr.Value
+		}
+	}
+	return d.Skip()
+}
@@ -0,0 +1,110 @@
+package ctypes
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/iEvan-lhr/docx-agent/internal"
+	"github.com/iEvan-lhr/docx-agent/wml/stypes"
+)
+
+func TestNumLevel_MarshalXML(t *testing.T) {
+	left := 720
+	hanging := uint64(360)
+	lvl := NumLevel{
+		Ilvl:       1,
+		Tplc:       internal.ToPtr("04090019"),
+		Start:      NewDecimalNum(1),
+		NumFmt:     NewNumFmt(stypes.NumFmtLowerLetter),
+		LvlRestart: NewDecimalNum(1),
+		IsLgl:      &OnOff{},
+		LvlText:    NewCTString("%1.%2"),
+		LvlJc:      NewCTString("left"),
+		PPr:        &ParagraphProp{Indent: &Indent{Left: &left, Hanging: &hanging}},
+	}
+
+	var result strings.Builder
+	encoder := xml.NewEncoder(&result)
+	if err := lvl.MarshalXML(encoder, xml.StartElement{}); err != nil {
+		t.Fatalf("Error marshaling XML: %v", err)
+	}
+	encoder.Flush()
+
+	expected := `<w:lvl w:ilvl="1" w:tplc="04090019"><w:start w:val="1"></w:start><w:numFmt w:val="lowerLetter"></w:numFmt>` +
+		`<w:lvlRestart w:val="1"></w:lvlRestart><w:isLgl></w:isLgl><w:lvlText w:val="%1.%2"></w:lvlText><w:lvlJc w:val="left"></w:lvlJc>` +
+		`<w:pPr><w:ind w:left="720" w:hanging="360"></w:ind></w:pPr></w:lvl>`
+	if result.String() != expected {
+		t.Errorf("Expected XML:\n%s\nBut got:\n%s", expected, result.String())
+	}
+}
+
+func TestNumbering_UnmarshalXML(t *testing.T) {
+	input := `<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+		<w:numPicBullet w:numPicBulletId="0"><w:pict/></w:numPicBullet>
+		<w:abstractNum w:abstractNumId="3">
+			<w:nsid w:val="1A2B3C4D"/>
+			<w:multiLevelType w:val="multilevel"/>
+			<w:lvl w:ilvl="0"><w:start w:val="4"/><w:numFmt w:val="custom" w:format="001, 002, 003, ..."/><w:lvlText w:val="%1"/></w:lvl>
+			<w:lvl w:ilvl="1"><w:numFmt w:val="bullet"/><w:lvlText w:val="o"/><w:rPr><w:rFonts w:ascii="Courier New"/></w:rPr></w:lvl>
+		</w:abstractNum>
+		<w:num w:numId="7">
+			<w:abstractNumId w:val="3"/>
+			<w:lvlOverride w:ilvl="0"><w:startOverride w:val="10"/></w:lvlOverride>
+		</w:num>
+	</w:numbering>`
+
+	var n Numbering
+	if err := xml.Unmarshal([]byte(input), &n); err != nil {
+		t.Fatalf("Error unmarshaling XML: %v", err)
+	}
+
+	an := n.AbstractNum(3)
+	if an == nil || len(an.Levels) != 2 {
+		t.Fatalf("Expected abstract definition 3 with two levels, got %+v", an)
+	}
+	if an.Nsid == nil || an.Nsid.Val != "1A2B3C4D" {
+		t.Errorf("Expected nsid 1A2B3C4D, got %+v", an.Nsid)
+	}
+	first := an.Level(0)
+	if first.Start.Val != 4 || first.NumFmt.Val != "custom" || first.NumFmt.Format == nil || *first.NumFmt.Format != "001, 002, 003, ..." {
+		t.Errorf("Unexpected first level: %+v", first)
+	}
+	if second := an.Level(1); second.RPr == nil || second.RPr.Fonts.Ascii != "Courier New" {
+		t.Errorf("Expected run properties on the second level, got %+v", second)
+	}
+
+	num := n.Num(7)
+	if num == nil || num.AbstractNumID.Val != 3 {
+		t.Fatalf("Expected instance 7 of definition 3, got %+v", num)
+	}
+	if o := num.Override(0); o == nil || o.StartOverride.Val != 10 {
+		t.Errorf("Expected start override 10, got %+v", o)
+	}
+	if n.Num(1) != nil || an.Level(5) != nil {
+		t.Error("Expected nil for missing elements")
+	}
+}
+
+func TestNum_MarshalXML(t *testing.T) {
+	num := Num{
+		ID:            7,
+		AbstractNumID: DecimalNum{Val: 3},
+		Overrides: []LvlOverride{
+			{Ilvl: 0, StartOverride: NewDecimalNum(1)},
+			{Ilvl: 1, Lvl: &NumLevel{LvlText: NewCTString("%2)")}},
+		},
+	}
+
+	output, err := xml.Marshal(num)
+	if err != nil {
+		t.Fatalf("Error marshaling XML: %v", err)
+	}
+
+	expected := `<w:num w:numId="7"><w:abstractNumId w:val="3"></w:abstractNumId>` +
+		`<w:lvlOverride w:ilvl="0"><w:startOverride w:val="1"></w:startOverride></w:lvlOverride>` +
+		`<w:lvlOverride w:ilvl="1"><w:lvl w:ilvl="1"><w:lvlText w:val="%2)"></w:lvlText></w:lvl></w:lvlOverride></w:num>`
+	if string(output) != expected {
+		t.Errorf("Expected XML:\n%s\nBut got:\n%s", expected, string(output))
+	}
+}
@@ -153,30 +153,113 @@ func (p *PgBorders) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	}
 }
 
+// Column Definitions : w:cols
 type Cols struct {
-	Space *string `xml:"space,attr,omitempty"`
+	Num        *int    // Number of Equal Width Columns
+	Space      *string // Spacing Between Equal Width Columns, in twips
+	EqualWidth *bool   // Equal Column Widths
+	Sep        *bool   // Draw Line Between Columns
+	Cols       []Col   // Single Column Definitions, used when the widths differ
+}
+
+// Single Column Definition : w:col
+type Col struct {
+	Width int // Column Width, in twips
+	Space int // Space Before Following Column, in twips
 }
 
 func (c Cols) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	start.Name.Local = "w:cols"
+	start.Attr = nil
+
+	if c.EqualWidth != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:equalWidth"}, Value: strconv.FormatBool(*c.EqualWidth)})
+	}
+	if c.Space != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:space"}, Value: *c.Space})
+	}
+	if c.Num != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:num"}, Value: strconv.Itoa(*c.Num)})
+	}
+	if c.Sep != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:sep"}, Value: strconv.FormatBool(*c.Sep)})
+	}
 
 	if err := e.EncodeToken(start); err != nil {
 		return err
 	}
 
-	if c.Space != nil {
-		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:space"}, Value: *c.Space})
+	for _, col := range c.Cols {
+		colStart := xml.StartElement{
+			Name: xml.Name{Local: "w:col"},
+			Attr: []xml.Attr{
+				{Name: xml.Name{Local: "w:w"}, Value: strconv.Itoa(col.Width)},
+				{Name: xml.Name{Local: "w:space"}, Value: strconv.Itoa(col.Space)},
+			},
+		}
+		if err := e.EncodeToken(colStart); err != nil {
+			return err
+		}
+		if err := e.EncodeToken(colStart.End()); err != nil {
+			return err
+		}
 	}
 
 	return e.EncodeToken(xml.EndElement{Name: start.Name})
-
 }
+
 func (c *Cols) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	for _, attr := range start.Attr {
-		if attr.Name.Local == "id" {
+		switch attr.Name.Local {
+		case "space":
 			c.Space = internal.ToPtr(attr.Value)
+		case "num":
+			num, err := strconv.Atoi(attr.Value)
+			if err != nil {
+				return err
+			}
+			c.Num = &num
+		case "equalWidth":
+			c.EqualWidth = internal.ToPtr(parseOnOffAttr(attr.Value))
+		case "sep":
+			c.Sep = internal.ToPtr(parseOnOffAttr(attr.Value))
 		}
+	}
 
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch elem := token.(type) {
+		case xml.StartElement:
+			if elem.Name.Local == "col" {
+				var col Col
+				for _, attr := range elem.Attr {
+					val, err := strconv.Atoi(attr.Value)
+					if err != nil {
+						return err
+					}
+					switch attr.Name.Local {
+					case "w":
+						col.Width = val
+					case "space":
+						col.Space = val
+					}
+				}
+				c.Cols = append(c.Cols, col)
+			}
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
 	}
-	return d.Skip() // 空元素
+}
+
+// parseOnOffAttr reports whether an ST_OnOff attribute value is on.
+func parseOnOffAttr(value string) bool {
+	return value == "1" || value == "true" || value == "on"
 }
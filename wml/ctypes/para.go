@@ -3,6 +3,7 @@ package ctypes
 import (
 	"encoding/xml"
 	"github.com/iEvan-lhr/docx-agent/internal"
+	"github.com/iEvan-lhr/docx-agent/omml"
 	"github.com/iEvan-lhr/docx-agent/wml/stypes"
 )
 
@@ -27,8 +28,16 @@ type Paragraph struct {
 }
 
 type ParagraphChild struct {
-	Link *Hyperlink // w:hyperlink
-	Run  *Run       // i.e w:r
+	Link *Hyperlink      // w:hyperlink
+	Run  *Run            // i.e w:r
+	Ins  *RunTrackChange // w:ins
+	Del  *RunTrackChange // w:del
+
+	Math     *omml.Math     // m:oMath
+	MathPara *omml.MathPara // m:oMathPara
+
+	PermStart *PermStart // w:permStart
+	PermEnd   *PermEnd   // w:permEnd
 }
 
 func (p Paragraph) MarshalXML(e *xml.Encoder, start xml.StartElement) (err error) {
@@ -84,6 +93,52 @@ func (p Paragraph) MarshalXML(e *xml.Encoder, start xml.StartElement) (err error
 				return err
 			}
 		}
+
+		if cElem.Ins != nil {
+			if err = cElem.Ins.MarshalXML(e, xml.StartElement{
+				Name: xml.Name{Local: "w:ins"},
+			}); err != nil {
+				return err
+			}
+		}
+
+		if cElem.Del != nil {
+			if err = cElem.Del.MarshalXML(e, xml.StartElement{
+				Name: xml.Name{Local: "w:del"},
+			}); err != nil {
+				return err
+			}
+		}
+
+		// The math namespace is declared on the element, as documents do not always
+		// declare it on the root.
+		if cElem.Math != nil {
+			if err = cElem.Math.MarshalXML(e, xml.StartElement{
+				Attr: []xml.Attr{omml.NamespaceAttr},
+			}); err != nil {
+				return err
+			}
+		}
+
+		if cElem.MathPara != nil {
+			if err = cElem.MathPara.MarshalXML(e, xml.StartElement{
+				Attr: []xml.Attr{omml.NamespaceAttr},
+			}); err != nil {
+				return err
+			}
+		}
+
+		if cElem.PermStart != nil {
+			if err = cElem.PermStart.MarshalXML(e, xml.StartElement{}); err != nil {
+				return err
+			}
+		}
+
+		if cElem.PermEnd != nil {
+			if err = cElem.PermEnd.MarshalXML(e, xml.StartElement{}); err != nil {
+				return err
+			}
+		}
 	}
 	if p.BookmarkStart != nil {
 		propsElement := xml.StartElement{Name: xml.Name{Local: "w:bookmarkStart"}}
@@ -148,6 +203,48 @@ loop:
 				}
 
 				p.Children = append(p.Children, ParagraphChild{Link: r})
+			case "ins":
+				tc := new(RunTrackChange)
+				if err = d.DecodeElement(tc, &elem); err != nil {
+					return err
+				}
+
+				p.Children = append(p.Children, ParagraphChild{Ins: tc})
+			case "del":
+				tc := new(RunTrackChange)
+				if err = d.DecodeElement(tc, &elem); err != nil {
+					return err
+				}
+
+				p.Children = append(p.Children, ParagraphChild{Del: tc})
+			case "oMath":
+				m := new(omml.Math)
+				if err = d.DecodeElement(m, &elem); err != nil {
+					return err
+				}
+
+				p.Children = append(p.Children, ParagraphChild{Math: m})
+			case "oMathPara":
+				mp := new(omml.MathPara)
+				if err = d.DecodeElement(mp, &elem); err != nil {
+					return err
+				}
+
+				p.Children = append(p.Children, ParagraphChild{MathPara: mp})
+			case "permStart":
+				ps := new(PermStart)
+				if err = d.DecodeElement(ps, &elem); err != nil {
+					return err
+				}
+
+				p.Children = append(p.Children, ParagraphChild{PermStart: ps})
+			case "permEnd":
+				pe := new(PermEnd)
+				if err = d.DecodeElement(pe, &elem); err != nil {
+					return err
+				}
+
+				p.Children = append(p.Children, ParagraphChild{PermEnd: pe})
 			case "pPr":
 				p.Property = &ParagraphProp{}
 				if err = d.DecodeElement(p.Property, &elem); err != nil {
@@ -0,0 +1,89 @@
+package ctypes
+
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// Range Permission Start
+//
+// Marks the start of a range of a protected document which the given user or group may
+// still edit.
+type PermStart struct {
+	ID       string
+	Ed       string // Single User
+	EdGrp    string // Editor Group
+	ColFirst *int   // First Table Column Covered
+	ColLast  *int   // Last Table Column Covered
+}
+
+func (p PermStart) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "w:permStart"
+	start.Attr = []xml.Attr{{Name: xml.Name{Local: "w:id"}, Value: p.ID}}
+
+	if p.EdGrp != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:edGrp"}, Value: p.EdGrp})
+	}
+	if p.Ed != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:ed"}, Value: p.Ed})
+	}
+	if p.ColFirst != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:colFirst"}, Value: strconv.Itoa(*p.ColFirst)})
+	}
+	if p.ColLast != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:colLast"}, Value: strconv.Itoa(*p.ColLast)})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (p *PermStart) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "id":
+			p.ID = attr.Value
+		case "ed":
+			p.Ed = attr.Value
+		case "edGrp":
+			p.EdGrp = attr.Value
+		case "colFirst", "colLast":
+			col, err := strconv.Atoi(attr.Value)
+			if err != nil {
+				return err
+			}
+			if attr.Name.Local == "colFirst" {
+				p.ColFirst = &col
+			} else {
+				p.ColLast = &col
+			}
+		}
+	}
+	return d.Skip()
+}
+
+// Range Permission End
+type PermEnd struct {
+	ID string
+}
+
+func (p PermEnd) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "w:permEnd"
+	start.Attr = []xml.Attr{{Name: xml.Name{Local: "w:id"}, Value: p.ID}}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (p *PermEnd) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "id" {
+			p.ID = attr.Value
+		}
+	}
+	return d.Skip()
+}
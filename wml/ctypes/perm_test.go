@@ -0,0 +1,31 @@
+package ctypes
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestPerm_RoundTrip(t *testing.T) {
+	input := `<w:p xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:permStart w:id="3" w:edGrp="everyone" w:colFirst="0" w:colLast="1"/><w:r><w:t>Name</w:t></w:r><w:permEnd w:id="3"/></w:p>`
+
+	var p Paragraph
+	if err := xml.Unmarshal([]byte(input), &p); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Children) != 3 || p.Children[0].PermStart == nil || p.Children[2].PermEnd == nil {
+		t.Fatalf("unexpected children %+v", p.Children)
+	}
+	if ps := p.Children[0].PermStart; ps.ID != "3" || ps.EdGrp != "everyone" || *ps.ColFirst != 0 || *ps.ColLast != 1 {
+		t.Errorf("unexpected permStart %+v", ps)
+	}
+
+	out, err := xml.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<w:p><w:permStart w:id="3" w:edGrp="everyone" w:colFirst="0" w:colLast="1"></w:permStart><w:r><w:t>Name</w:t></w:r><w:permEnd w:id="3"></w:permEnd></w:p>`
+	if string(out) != expected {
+		t.Errorf("got %s\nwant %s", out, expected)
+	}
+}
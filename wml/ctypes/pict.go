@@ -58,6 +58,11 @@ loop:
 					return fmt.Errorf("unmarshalling Group: %w", err)
 				}
 				// --- ^^^^ 修改 ^^^^ ---
+			} else if elem.Name.Local == "shape" && elem.Name.Space == constants.XMLNS_V {
+				p.Shape = new(Shape)
+				if err := d.DecodeElement(p.Shape, &elem); err != nil {
+					return fmt.Errorf("unmarshalling Shape: %w", err)
+				}
 			} else {
 				// 跳过其他不认识的子元素
 				if err := d.Skip(); err != nil {
@@ -89,7 +94,9 @@ type Group struct {
 }
 
 type Shape struct {
+	ID    string `xml:"id,attr,omitempty"`
 	Type  string `xml:"type,attr,omitempty"`
+	Alt   string `xml:"alt,attr,omitempty"`
 	Style string `xml:"style,attr,omitempty"`
 
 	ImageData *ImageData `xml:"imagedata,omitempty"`
@@ -494,10 +501,15 @@ func (i *VImageData) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 // MarshalXML implements the xml.Marshaler interface.
 func (b Shape) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	start.Name.Local = "v:shape"
-	start.Attr = []xml.Attr{
-		{Name: xml.Name{Local: "type"}, Value: b.Type},
-		{Name: xml.Name{Local: "style"}, Value: b.Style},
+	start.Attr = nil
+	if b.ID != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "id"}, Value: b.ID})
+	}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "type"}, Value: b.Type})
+	if b.Alt != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "alt"}, Value: b.Alt})
 	}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "style"}, Value: b.Style})
 
 	err := e.EncodeToken(start)
 	if err != nil {
@@ -216,6 +216,13 @@ loop:
 				}
 
 				r.Children = append(r.Children, RunChild{Text: txt})
+			case "delText":
+				txt := NewText()
+				if err = d.DecodeElement(txt, &elem); err != nil {
+					return err
+				}
+
+				r.Children = append(r.Children, RunChild{DelText: txt})
 			case "rPr":
 				r.Property = &RunProperty{}
 				if err = d.DecodeElement(r.Property, &elem); err != nil {
@@ -0,0 +1,96 @@
+package ctypes
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	"github.com/iEvan-lhr/docx-agent/internal"
+)
+
+// RunTrackChange represents a tracked insertion (w:ins) or deletion (w:del) of run content
+// within a paragraph.
+type RunTrackChange struct {
+	// Attributes
+	ID     int     // Annotation Identifier
+	Author string  // Annotation Author
+	Date   *string // Annotation Date
+
+	// Runs inserted or deleted by the revision
+	Runs []*Run
+}
+
+// NewRunTrackChange creates a new RunTrackChange with the given identifier and author.
+func NewRunTrackChange(id int, author string) *RunTrackChange {
+	return &RunTrackChange{
+		ID:     id,
+		Author: author,
+	}
+}
+
+// MarshalXML implements the xml.Marshaler interface for the RunTrackChange type.
+// The element name (w:ins or w:del) must be provided by the caller in start.
+func (t RunTrackChange) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = []xml.Attr{
+		{Name: xml.Name{Local: "w:id"}, Value: strconv.Itoa(t.ID)},
+		{Name: xml.Name{Local: "w:author"}, Value: t.Author},
+	}
+
+	if t.Date != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:date"}, Value: *t.Date})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, run := range t.Runs {
+		if err := run.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface for the RunTrackChange type.
+func (t *RunTrackChange) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "id":
+			id, err := strconv.Atoi(attr.Value)
+			if err != nil {
+				return err
+			}
+			t.ID = id
+		case "author":
+			t.Author = attr.Value
+		case "date":
+			t.Date = internal.ToPtr(attr.Value)
+		}
+	}
+
+	for {
+		currentToken, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch elem := currentToken.(type) {
+		case xml.StartElement:
+			switch elem.Name.Local {
+			case "r":
+				r := NewRun()
+				if err = d.DecodeElement(r, &elem); err != nil {
+					return err
+				}
+				t.Runs = append(t.Runs, r)
+			default:
+				if err = d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
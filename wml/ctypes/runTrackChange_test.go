@@ -0,0 +1,90 @@
+package ctypes
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/iEvan-lhr/docx-agent/internal"
+)
+
+func TestRunTrackChange_MarshalXML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    RunTrackChange
+		expected string
+	}{
+		{
+			name: "Insertion with run",
+			input: RunTrackChange{
+				ID:     1,
+				Author: "Compare",
+				Date:   internal.ToPtr("2024-01-02T03:04:05Z"),
+				Runs:   []*Run{{Children: []RunChild{{Text: TextFromString("new")}}}},
+			},
+			expected: `<w:ins w:id="1" w:author="Compare" w:date="2024-01-02T03:04:05Z"><w:r><w:t>new</w:t></w:r></w:ins>`,
+		},
+		{
+			name: "Deletion with deleted text",
+			input: RunTrackChange{
+				ID:     2,
+				Author: "Compare",
+				Runs:   []*Run{{Children: []RunChild{{DelText: TextFromString("old")}}}},
+			},
+			expected: `<w:del w:id="2" w:author="Compare"><w:r><w:delText>old</w:delText></w:r></w:del>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result strings.Builder
+			encoder := xml.NewEncoder(&result)
+			name := "w:ins"
+			if strings.HasPrefix(tt.expected, "<w:del") {
+				name = "w:del"
+			}
+
+			err := tt.input.MarshalXML(encoder, xml.StartElement{Name: xml.Name{Local: name}})
+			if err != nil {
+				t.Fatalf("Error marshaling XML: %v", err)
+			}
+
+			encoder.Flush()
+
+			if result.String() != tt.expected {
+				t.Errorf("Expected XML:\n%s\nGot:\n%s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestRunTrackChange_UnmarshalXML(t *testing.T) {
+	inputXML := `<w:p><w:r><w:t>kept </w:t></w:r>` +
+		`<w:del w:id="3" w:author="Jane" w:date="2024-01-02T03:04:05Z"><w:r><w:delText>old</w:delText></w:r></w:del>` +
+		`<w:ins w:id="4" w:author="Jane"><w:r><w:t>new</w:t></w:r></w:ins></w:p>`
+
+	var p Paragraph
+	if err := xml.Unmarshal([]byte(inputXML), &p); err != nil {
+		t.Fatalf("Error unmarshaling XML: %v", err)
+	}
+
+	if len(p.Children) != 3 {
+		t.Fatalf("Expected 3 paragraph children, got %d", len(p.Children))
+	}
+
+	del := p.Children[1].Del
+	if del == nil || del.ID != 3 || del.Author != "Jane" || del.Date == nil {
+		t.Fatalf("Unexpected deletion: %+v", del)
+	}
+	if len(del.Runs) != 1 || del.Runs[0].Children[0].DelText == nil || del.Runs[0].Children[0].DelText.Text != "old" {
+		t.Errorf("Expected deleted run with text 'old', got %+v", del.Runs)
+	}
+
+	ins := p.Children[2].Ins
+	if ins == nil || ins.ID != 4 || ins.Date != nil {
+		t.Fatalf("Unexpected insertion: %+v", ins)
+	}
+	if len(ins.Runs) != 1 || ins.Runs[0].Children[0].Text.Text != "new" {
+		t.Errorf("Expected inserted run with text 'new', got %+v", ins.Runs)
+	}
+}
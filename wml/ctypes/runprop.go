@@ -9,6 +9,12 @@ import (
 
 // RunProperty represents the properties of a run of text within a paragraph.
 type RunProperty struct {
+	// Inserted Paragraph Mark, only valid in the run properties of a paragraph mark
+	Ins *TrackChange `xml:"ins,omitempty"`
+
+	// Deleted Paragraph Mark, only valid in the run properties of a paragraph mark
+	Del *TrackChange `xml:"del,omitempty"`
+
 	//1. Referenced Character Style
 	Style *CTString `xml:"rStyle,omitempty"`
 
@@ -145,6 +151,18 @@ func (rp RunProperty) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		return err
 	}
 
+	// Paragraph mark revisions precede the formatting
+	if rp.Ins != nil {
+		if err = rp.Ins.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:ins"}}); err != nil {
+			return fmt.Errorf("ins: %w", err)
+		}
+	}
+	if rp.Del != nil {
+		if err = rp.Del.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:del"}}); err != nil {
+			return fmt.Errorf("del: %w", err)
+		}
+	}
+
 	// 1. Referenced Character Style
 	if rp.Style != nil {
 		if err = rp.Style.MarshalXML(e, xml.StartElement{
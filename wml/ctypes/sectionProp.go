@@ -19,6 +19,7 @@ type SectionProp struct {
 	TextDir          *GenSingleStrVal[stypes.TextDirection] `xml:"textDirection,omitempty"`
 	DocGrid          *DocGrid                               `xml:"docGrid,omitempty"`
 	PgBorders        *PgBorders                             `xml:"pgBorders,omitempty"`
+	LnNumType        *LineNumbering                         `xml:"lnNumType,omitempty"`
 	Cols             *Cols                                  `xml:"cols,omitempty"`
 }
 
@@ -72,8 +73,8 @@ func (s SectionProp) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 			return err
 		}
 	}
-	if s.Cols != nil {
-		if err = s.Cols.MarshalXML(e, xml.StartElement{}); err != nil {
+	if s.LnNumType != nil {
+		if err = s.LnNumType.MarshalXML(e, xml.StartElement{}); err != nil {
 			return err
 		}
 	}
@@ -82,6 +83,11 @@ func (s SectionProp) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 			return err
 		}
 	}
+	if s.Cols != nil {
+		if err = s.Cols.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
 
 	if s.FormProt != nil {
 		if err = s.FormProt.MarshalXML(e, xml.StartElement{
@@ -100,7 +106,7 @@ func (s SectionProp) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	}
 
 	if s.TextDir != nil {
-		if s.TextDir.MarshalXML(e, xml.StartElement{
+		if err = s.TextDir.MarshalXML(e, xml.StartElement{
 			Name: xml.Name{Local: "w:textDirection"},
 		}); err != nil {
 			return err
@@ -108,7 +114,7 @@ func (s SectionProp) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	}
 
 	if s.DocGrid != nil {
-		if s.DocGrid.MarshalXML(e, xml.StartElement{}); err != nil {
+		if err = s.DocGrid.MarshalXML(e, xml.StartElement{}); err != nil {
 			return err
 		}
 	}
@@ -193,6 +199,11 @@ func (s *SectionProp) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 				if err := d.DecodeElement(s.PgBorders, &elem); err != nil {
 					return err
 				}
+			case "lnNumType":
+				s.LnNumType = &LineNumbering{}
+				if err := d.DecodeElement(s.LnNumType, &elem); err != nil {
+					return err
+				}
 			case "cols":
 				s.Cols = &Cols{}
 				if err := d.DecodeElement(s.Cols, &elem); err != nil {
@@ -0,0 +1,496 @@
+package ctypes
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+
+	"github.com/iEvan-lhr/docx-agent/common/constants"
+	"github.com/iEvan-lhr/docx-agent/internal"
+)
+
+var defaultSettingsNSAttrs = []xml.Attr{
+	{Name: xml.Name{Local: "xmlns:w"}, Value: "http://schemas.openxmlformats.org/wordprocessingml/2006/main"},
+	{Name: xml.Name{Local: "xmlns:r"}, Value: "http://schemas.openxmlformats.org/officeDocument/2006/relationships"},
+	{Name: xml.Name{Local: "xmlns:m"}, Value: "http://schemas.openxmlformats.org/officeDocument/2006/math"},
+}
+
+// settingsOrder is the sequence of the children of w:settings. Children are written in
+// this order, and children outside of it, such as extensions of later Word versions,
+// after them in the order they were read.
+var settingsOrder = []string{
+	"writeProtection", "view", "zoom", "removePersonalInformation", "removeDateAndTime",
+	"doNotDisplayPageBoundaries", "displayBackgroundShape", "printPostScriptOverText",
+	"printFractionalCharacterWidth", "printFormsData", "embedTrueTypeFonts", "embedSystemFonts",
+	"saveSubsetFonts", "saveFormsData", "mirrorMargins", "alignBordersAndEdges",
+	"bordersDoNotSurroundHeader", "bordersDoNotSurroundFooter", "gutterAtTop",
+	"hideSpellingErrors", "hideGrammaticalErrors", "activeWritingStyle", "proofState",
+	"formsDesign", "attachedTemplate", "linkStyles", "stylePaneFormatFilter",
+	"stylePaneSortMethod", "documentType", "mailMerge", "revisionView", "trackRevisions",
+	"doNotTrackMoves", "doNotTrackFormatting", "documentProtection", "autoFormatOverride",
+	"styleLockTheme", "styleLockQFSet", "defaultTabStop", "autoHyphenation",
+	"consecutiveHyphenLimit", "hyphenationZone", "doNotHyphenateCaps", "showEnvelope",
+	"summaryLength", "clickAndTypeStyle", "defaultTableStyle", "evenAndOddHeaders",
+	"bookFoldRevPrinting", "bookFoldPrinting", "bookFoldPrintingSheets",
+	"drawingGridHorizontalSpacing", "drawingGridVerticalSpacing",
+	"displayHorizontalDrawingGridEvery", "displayVerticalDrawingGridEvery",
+	"doNotUseMarginsForDrawingGridOrigin", "drawingGridHorizontalOrigin",
+	"drawingGridVerticalOrigin", "doNotShadeFormData", "noPunctuationKerning",
+	"characterSpacingControl", "printTwoOnOne", "strictFirstAndLastChars", "noLineBreaksAfter",
+	"noLineBreaksBefore", "savePreviewPicture", "doNotValidateAgainstSchema", "saveInvalidXml",
+	"ignoreMixedContent", "alwaysShowPlaceholderText", "doNotDemarcateInvalidXml",
+	"saveXmlDataOnly", "useXSLTWhenSaving", "saveThroughXslt", "showXMLTags",
+	"alwaysMergeEmptyNamespace", "updateFields", "hdrShapeDefaults", "footnotePr", "endnotePr",
+	"compat", "docVars", "rsids", "mathPr", "attachedSchema", "themeFontLang",
+	"clrSchemeMapping", "doNotIncludeSubdocsInStats", "doNotAutoCompressPictures",
+	"forceUpgrade", "captions", "readModeInkLockDown", "smartTagType", "schemaLibrary",
+	"shapeDefaults", "doNotEmbedSmartTags", "decimalSymbol", "listSeparator",
+}
+
+// Document Settings
+//
+// The commonly used settings are typed fields. All other children are kept as raw
+// elements and written back unchanged.
+type Settings struct {
+	RelativePath string `xml:"-"`
+	Attr         []xml.Attr
+
+	// Mirror Page Margins
+	MirrorMargins *OnOff
+
+	// Position Gutter At Top of Page
+	GutterAtTop *OnOff
+
+	// Track Revisions to Document
+	TrackRevisions *OnOff
+
+	// Document Editing Restrictions
+	DocumentProtection *DocProtect
+
+	// Distance Between Automatic Tab Stops, in twips
+	DefaultTabStop *DecimalNum
+
+	// Automatically Hyphenate Document Contents When Displayed
+	AutoHyphenation *OnOff
+
+	// Maximum Number of Consecutively Hyphenated Lines
+	ConsecutiveHyphenLimit *DecimalNum
+
+	// Hyphenation Zone, in twips
+	HyphenationZone *DecimalNum
+
+	// Do Not Hyphenate Words in ALL CAPITAL LETTERS
+	DoNotHyphenateCaps *OnOff
+
+	// Different Even/Odd Page Headers and Footers
+	EvenAndOddHeaders *OnOff
+
+	// Automatically Recalculate Fields on Open
+	UpdateFields *OnOff
+
+	// Compatibility Settings
+	Compat *Compat
+
+	// Extra holds the children without a typed field, in the order they were read.
+	Extra []RawElement
+}
+
+// Document Editing Restrictions
+type DocProtect struct {
+	Edit        string // none, readOnly, comments, trackedChanges or forms
+	Formatting  *bool
+	Enforcement *bool
+
+	// Password hash, as written by Word 2010 and later
+	AlgorithmName string
+	HashValue     string
+	SaltValue     string
+	SpinCount     *int
+
+	// Other attributes, such as the legacy cryptographic ones
+	Attrs []xml.Attr
+}
+
+// Compatibility Settings
+type Compat struct {
+	// Options holds the legacy compatibility options, such as w:useFELayout.
+	Options []RawElement
+
+	// Settings holds the w:compatSetting children.
+	Settings []CompatSetting
+}
+
+// Compatibility Setting
+type CompatSetting struct {
+	Name string
+	URI  string
+	Val  string
+}
+
+// RawElement is an element kept as it was read: its prefixed name, its attributes and
+// its content as raw XML.
+type RawElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr
+	InnerXML string
+}
+
+// local returns the name of the element without its prefix.
+func (r RawElement) local() string {
+	for i := len(r.XMLName.Local) - 1; i >= 0; i-- {
+		if r.XMLName.Local[i] == ':' {
+			return r.XMLName.Local[i+1:]
+		}
+	}
+	return r.XMLName.Local
+}
+
+func (r RawElement) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: r.XMLName.Local}, Attr: r.Attrs}
+	return e.EncodeElement(struct {
+		Inner string `xml:",innerxml"`
+	}{r.InnerXML}, start)
+}
+
+// typed returns the typed children which are set, keyed by local name.
+func (s *Settings) typed() map[string]xml.Marshaler {
+	elems := make(map[string]xml.Marshaler)
+	for _, t := range []struct {
+		name string
+		set  bool
+		elem xml.Marshaler
+	}{
+		{"mirrorMargins", s.MirrorMargins != nil, s.MirrorMargins},
+		{"gutterAtTop", s.GutterAtTop != nil, s.GutterAtTop},
+		{"trackRevisions", s.TrackRevisions != nil, s.TrackRevisions},
+		{"documentProtection", s.DocumentProtection != nil, s.DocumentProtection},
+		{"defaultTabStop", s.DefaultTabStop != nil, s.DefaultTabStop},
+		{"autoHyphenation", s.AutoHyphenation != nil, s.AutoHyphenation},
+		{"consecutiveHyphenLimit", s.ConsecutiveHyphenLimit != nil, s.ConsecutiveHyphenLimit},
+		{"hyphenationZone", s.HyphenationZone != nil, s.HyphenationZone},
+		{"doNotHyphenateCaps", s.DoNotHyphenateCaps != nil, s.DoNotHyphenateCaps},
+		{"evenAndOddHeaders", s.EvenAndOddHeaders != nil, s.EvenAndOddHeaders},
+		{"updateFields", s.UpdateFields != nil, s.UpdateFields},
+		{"compat", s.Compat != nil, s.Compat},
+	} {
+		if t.set {
+			elems[t.name] = t.elem
+		}
+	}
+	return elems
+}
+
+func (s *Settings) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "w:settings"
+
+	if len(s.Attr) == 0 {
+		start.Attr = append(start.Attr, defaultSettingsNSAttrs...)
+	} else {
+		start.Attr = s.Attr
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	typed := s.typed()
+	written := make([]bool, len(s.Extra))
+	for _, name := range settingsOrder {
+		if elem, ok := typed[name]; ok {
+			if err := elem.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "w:" + name}}); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		for i, raw := range s.Extra {
+			if !written[i] && raw.local() == name {
+				if err := raw.MarshalXML(e, xml.StartElement{}); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+				written[i] = true
+			}
+		}
+	}
+	for i, raw := range s.Extra {
+		if !written[i] {
+			if err := raw.MarshalXML(e, xml.StartElement{}); err != nil {
+				return fmt.Errorf("%s: %w", raw.XMLName.Local, err)
+			}
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+func (s *Settings) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
+	// Prefixes of the namespaces declared on the root, used to name the raw children
+	prefixes := map[string]string{"http://www.w3.org/XML/1998/namespace": "xml"}
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" {
+			prefixes[attr.Value] = attr.Name.Local
+		}
+	}
+
+	s.Attr = make([]xml.Attr, 0, len(start.Attr))
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+			s.Attr = append(s.Attr, attr)
+			continue
+		}
+		ns := attr.Name.Space
+		if ns != "xmlns" {
+			local, ok := prefixOf(prefixes, ns)
+			if !ok {
+				continue
+			}
+			ns = local
+		}
+		s.Attr = append(s.Attr, xml.Attr{
+			Name:  xml.Name{Local: fmt.Sprintf("%s:%s", ns, attr.Name.Local)},
+			Value: attr.Value,
+		})
+	}
+
+	for {
+		currentToken, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch elem := currentToken.(type) {
+		case xml.StartElement:
+			var target any
+			if elem.Name.Space == constants.WMLNamespace {
+				switch elem.Name.Local {
+				case "mirrorMargins":
+					target = &s.MirrorMargins
+				case "gutterAtTop":
+					target = &s.GutterAtTop
+				case "trackRevisions":
+					target = &s.TrackRevisions
+				case "documentProtection":
+					target = &s.DocumentProtection
+				case "defaultTabStop":
+					target = &s.DefaultTabStop
+				case "autoHyphenation":
+					target = &s.AutoHyphenation
+				case "consecutiveHyphenLimit":
+					target = &s.ConsecutiveHyphenLimit
+				case "hyphenationZone":
+					target = &s.HyphenationZone
+				case "doNotHyphenateCaps":
+					target = &s.DoNotHyphenateCaps
+				case "evenAndOddHeaders":
+					target = &s.EvenAndOddHeaders
+				case "updateFields":
+					target = &s.UpdateFields
+				case "compat":
+					compat, err := decodeCompat(d, elem, prefixes)
+					if err != nil {
+						return err
+					}
+					s.Compat = compat
+					continue
+				}
+			}
+			if target != nil {
+				if err := d.DecodeElement(target, &elem); err != nil {
+					return err
+				}
+				continue
+			}
+
+			raw, err := decodeRaw(d, elem, prefixes)
+			if err != nil {
+				return err
+			}
+			s.Extra = append(s.Extra, raw)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// prefixOf returns the prefix of a namespace, from the declarations of the part or else
+// the well known prefixes.
+func prefixOf(prefixes map[string]string, ns string) (string, bool) {
+	if prefix, ok := prefixes[ns]; ok {
+		return prefix, true
+	}
+	prefix, ok := constants.NSToLocal[ns]
+	return prefix, ok
+}
+
+// prefixed returns the name with the prefix of its namespace.
+func prefixed(prefixes map[string]string, name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	if prefix, ok := prefixOf(prefixes, name.Space); ok {
+		return prefix + ":" + name.Local
+	}
+	return name.Local
+}
+
+// decodeRaw reads an element as a raw element.
+func decodeRaw(d *xml.Decoder, start xml.StartElement, prefixes map[string]string) (RawElement, error) {
+	var inner struct {
+		InnerXML string `xml:",innerxml"`
+	}
+	if err := d.DecodeElement(&inner, &start); err != nil {
+		return RawElement{}, err
+	}
+
+	raw := RawElement{
+		XMLName:  xml.Name{Local: prefixed(prefixes, start.Name)},
+		InnerXML: inner.InnerXML,
+	}
+	for _, attr := range start.Attr {
+		name := prefixed(prefixes, attr.Name)
+		if attr.Name.Space == "xmlns" {
+			name = "xmlns:" + attr.Name.Local
+		}
+		raw.Attrs = append(raw.Attrs, xml.Attr{Name: xml.Name{Local: name}, Value: attr.Value})
+	}
+	return raw, nil
+}
+
+func decodeCompat(d *xml.Decoder, start xml.StartElement, prefixes map[string]string) (*Compat, error) {
+	compat := &Compat{}
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch elem := token.(type) {
+		case xml.StartElement:
+			if elem.Name.Local == "compatSetting" {
+				setting := CompatSetting{}
+				for _, attr := range elem.Attr {
+					switch attr.Name.Local {
+					case "name":
+						setting.Name = attr.Value
+					case "uri":
+						setting.URI = attr.Value
+					case "val":
+						setting.Val = attr.Value
+					}
+				}
+				compat.Settings = append(compat.Settings, setting)
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			raw, err := decodeRaw(d, elem, prefixes)
+			if err != nil {
+				return nil, err
+			}
+			compat.Options = append(compat.Options, raw)
+		case xml.EndElement:
+			return compat, nil
+		}
+	}
+}
+
+func (c Compat) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, option := range c.Options {
+		if err := option.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+
+	for _, setting := range c.Settings {
+		elem := xml.StartElement{
+			Name: xml.Name{Local: "w:compatSetting"},
+			Attr: []xml.Attr{
+				{Name: xml.Name{Local: "w:name"}, Value: setting.Name},
+				{Name: xml.Name{Local: "w:uri"}, Value: setting.URI},
+				{Name: xml.Name{Local: "w:val"}, Value: setting.Val},
+			},
+		}
+		if err := e.EncodeToken(elem); err != nil {
+			return err
+		}
+		if err := e.EncodeToken(elem.End()); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+func (p DocProtect) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if p.Edit != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:edit"}, Value: p.Edit})
+	}
+	if p.Formatting != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:formatting"}, Value: onOffAttr(*p.Formatting)})
+	}
+	if p.Enforcement != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:enforcement"}, Value: onOffAttr(*p.Enforcement)})
+	}
+	if p.AlgorithmName != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:algorithmName"}, Value: p.AlgorithmName})
+	}
+	if p.HashValue != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:hashValue"}, Value: p.HashValue})
+	}
+	if p.SaltValue != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:saltValue"}, Value: p.SaltValue})
+	}
+	if p.SpinCount != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:spinCount"}, Value: strconv.Itoa(*p.SpinCount)})
+	}
+	start.Attr = append(start.Attr, p.Attrs...)
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (p *DocProtect) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "edit":
+			p.Edit = attr.Value
+		case "formatting":
+			p.Formatting = internal.ToPtr(parseOnOffAttr(attr.Value))
+		case "enforcement":
+			p.Enforcement = internal.ToPtr(parseOnOffAttr(attr.Value))
+		case "algorithmName":
+			p.AlgorithmName = attr.Value
+		case "hashValue":
+			p.HashValue = attr.Value
+		case "saltValue":
+			p.SaltValue = attr.Value
+		case "spinCount":
+			count, err := strconv.Atoi(attr.Value)
+			if err != nil {
+				return fmt.Errorf("spinCount: %w", err)
+			}
+			p.SpinCount = &count
+		default:
+			// Attributes of other namespaces keep them, with their usual prefix if any.
+			if prefix, ok := constants.NSToLocal[attr.Name.Space]; ok {
+				attr.Name = xml.Name{Local: prefix + ":" + attr.Name.Local}
+			}
+			p.Attrs = append(p.Attrs, attr)
+		}
+	}
+	return d.Skip()
+}
+
+// onOffAttr returns the value of an on/off attribute.
+func onOffAttr(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
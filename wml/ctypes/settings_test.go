@@ -0,0 +1,59 @@
+package ctypes
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+const wordSettings = `<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:m="http://schemas.openxmlformats.org/officeDocument/2006/math" xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml" xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" xmlns:ext="http://example.com/ext" mc:Ignorable="w14">` +
+	`<w:zoom w:percent="120"/>` +
+	`<w:documentProtection w:edit="readOnly" w:enforcement="1" w:algorithmName="SHA-512" w:spinCount="100000" w:cryptProviderType="rsaAES" ext:note="1"/>` +
+	`<w:defaultTabStop w:val="720"/>` +
+	`<w:compat><w:useFELayout/><w:compatSetting w:name="compatibilityMode" w:uri="http://schemas.microsoft.com/office/word" w:val="14"/></w:compat>` +
+	`<m:mathPr><m:mathFont m:val="Cambria Math"/></m:mathPr>` +
+	`<w14:docId w14:val="24062061"/>` +
+	`<w:listSeparator w:val=","/>` +
+	`</w:settings>`
+
+func TestSettings_RoundTrip(t *testing.T) {
+	var s Settings
+	if err := xml.Unmarshal([]byte(wordSettings), &s); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.DefaultTabStop == nil || s.DefaultTabStop.Val != 720 {
+		t.Errorf("defaultTabStop = %+v", s.DefaultTabStop)
+	}
+	if p := s.DocumentProtection; p == nil || p.Edit != "readOnly" || p.Enforcement == nil || !*p.Enforcement || *p.SpinCount != 100000 {
+		t.Errorf("documentProtection = %+v", s.DocumentProtection)
+	}
+	if s.Compat == nil || len(s.Compat.Settings) != 1 || s.Compat.Settings[0].Val != "14" || len(s.Compat.Options) != 1 {
+		t.Errorf("compat = %+v", s.Compat)
+	}
+	if len(s.Extra) != 4 {
+		t.Fatalf("expected 4 raw children, got %d", len(s.Extra))
+	}
+
+	// A typed setting added after reading is written in schema order.
+	s.EvenAndOddHeaders = &OnOff{}
+
+	out, err := xml.Marshal(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<w:zoom w:percent="120"></w:zoom>` +
+		`<w:documentProtection w:edit="readOnly" w:enforcement="1" w:algorithmName="SHA-512" w:spinCount="100000" w:cryptProviderType="rsaAES" xmlns:ext="http://example.com/ext" ext:note="1"></w:documentProtection>` +
+		`<w:defaultTabStop w:val="720"></w:defaultTabStop>` +
+		`<w:evenAndOddHeaders></w:evenAndOddHeaders>` +
+		`<w:compat><w:useFELayout></w:useFELayout><w:compatSetting w:name="compatibilityMode" w:uri="http://schemas.microsoft.com/office/word" w:val="14"></w:compatSetting></w:compat>` +
+		`<m:mathPr><m:mathFont m:val="Cambria Math"/></m:mathPr>` +
+		`<w:listSeparator w:val=","></w:listSeparator>` +
+		`<w14:docId w14:val="24062061"></w14:docId>`
+	if !strings.Contains(string(out), expected) {
+		t.Errorf("settings XML\n%s\ndoes not contain\n%s", out, expected)
+	}
+	if !strings.HasPrefix(string(out), `<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`) || !strings.Contains(string(out), `mc:Ignorable="w14"`) {
+		t.Errorf("namespace declarations not kept: %s", out)
+	}
+}
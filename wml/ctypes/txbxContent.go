@@ -0,0 +1,77 @@
+package ctypes
+
+import (
+	"encoding/xml"
+
+	"github.com/iEvan-lhr/docx-agent/dml"
+)
+
+func init() {
+	dml.RegisterTextBoxContent(func() dml.TextBoxContent {
+		return new(TxbxContent)
+	})
+}
+
+// TxbxContent is the rich text content of a text box, <w:txbxContent>.
+type TxbxContent struct {
+	// Choice: ZeroOrMore paragraphs and tables, in document order
+	Contents []TCBlockContent
+}
+
+func (t TxbxContent) MarshalXML(e *xml.Encoder, start xml.StartElement) (err error) {
+	start.Name.Local = "w:txbxContent"
+
+	if err = e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, elem := range t.Contents {
+		if err = elem.MarshalXML(e, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+func (t *TxbxContent) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
+loop:
+	for {
+		currentToken, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch elem := currentToken.(type) {
+		case xml.StartElement:
+			switch elem.Name.Local {
+			case "p":
+				para := Paragraph{}
+				if err = d.DecodeElement(&para, &elem); err != nil {
+					return err
+				}
+
+				t.Contents = append(t.Contents, TCBlockContent{
+					Paragraph: &para,
+				})
+			case "tbl":
+				tbl := Table{}
+				if err = d.DecodeElement(&tbl, &elem); err != nil {
+					return err
+				}
+
+				t.Contents = append(t.Contents, TCBlockContent{
+					Table: &tbl,
+				})
+			default:
+				if err = d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			break loop
+		}
+	}
+
+	return nil
+}
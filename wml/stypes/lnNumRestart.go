@@ -0,0 +1,39 @@
+package stypes
+
+import (
+	"encoding/xml"
+	"errors"
+)
+
+// LineNumberRestart is when the line numbers of a section restart.
+type LineNumberRestart string
+
+const (
+	LineNumberRestartNewPage    LineNumberRestart = "newPage"    //Restart Line Numbering on Each Page
+	LineNumberRestartNewSection LineNumberRestart = "newSection" //Restart Line Numbering for Each Section
+	LineNumberRestartContinuous LineNumberRestart = "continuous" //Continue Line Numbering From Previous Section
+)
+
+func LineNumberRestartFromStr(value string) (LineNumberRestart, error) {
+	switch value {
+	case "newPage":
+		return LineNumberRestartNewPage, nil
+	case "newSection":
+		return LineNumberRestartNewSection, nil
+	case "continuous":
+		return LineNumberRestartContinuous, nil
+	default:
+		return "", errors.New("Invalid Line Number Restart")
+	}
+}
+
+func (l *LineNumberRestart) UnmarshalXMLAttr(attr xml.Attr) error {
+	val, err := LineNumberRestartFromStr(attr.Value)
+	if err != nil {
+		return err
+	}
+
+	*l = val
+
+	return nil
+}
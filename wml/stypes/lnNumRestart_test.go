@@ -0,0 +1,52 @@
+package stypes
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestLineNumberRestartFromStr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected LineNumberRestart
+	}{
+		{"newPage", LineNumberRestartNewPage},
+		{"newSection", LineNumberRestartNewSection},
+		{"continuous", LineNumberRestartContinuous},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := LineNumberRestartFromStr(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %s but got %s", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := LineNumberRestartFromStr("everyLine"); err == nil {
+		t.Error("Expected error for invalid value, but got none")
+	}
+}
+
+func TestLineNumberRestart_UnmarshalXMLAttr(t *testing.T) {
+	type Element struct {
+		XMLName xml.Name          `xml:"element"`
+		Restart LineNumberRestart `xml:"restart,attr"`
+	}
+
+	var elem Element
+	if err := xml.Unmarshal([]byte(`<element restart="newSection"></element>`), &elem); err != nil {
+		t.Fatalf("Error unmarshaling XML: %v", err)
+	}
+	if elem.Restart != LineNumberRestartNewSection {
+		t.Errorf("Expected %s but got %s", LineNumberRestartNewSection, elem.Restart)
+	}
+
+	if err := xml.Unmarshal([]byte(`<element restart="everyLine"></element>`), &elem); err == nil {
+		t.Error("Expected error for invalid value, but got none")
+	}
+}